@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/buiminhduc234/audit-log-api/internal/app"
+)
+
+// auditlog runs the API and the workers in one process, e.g.
+//
+//	auditlog --roles=api,index,archive,cleanup
+func main() {
+	rolesFlag := flag.String("roles", "all", "comma-separated roles to run: all, api, index, archive, cleanup, export, scheduler, outbox")
+	flag.Parse()
+
+	roles, err := app.ParseRoles(*rolesFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --roles: %v\n", err)
+		os.Exit(2)
+	}
+
+	app.Main(roles...)
+}
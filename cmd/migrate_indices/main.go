@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/opensearch"
+)
+
+// migrate_indices moves the logs of the daily indices behind the write aliases
+// of their tenants, one index at a time. A daily index is deleted once its
+// logs are copied, so an interrupted run continues where it stopped when it
+// runs again.
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Define command line flags
+	tenantID := flag.String("tenant", "", "Tenant ID to migrate, every tenant when empty")
+	dryRun := flag.Bool("dry-run", false, "List the daily indices without migrating them")
+	flag.Parse()
+
+	// Initialize OpenSearch
+	osConfig := config.DefaultOpenSearchConfig()
+	osClient, err := osConfig.GetClient()
+	if err != nil {
+		log.Fatalf("Failed to connect to OpenSearch: %v", err)
+	}
+	osRepo := opensearch.NewRepository(osClient, osConfig)
+
+	// Interrupting stops after the current index
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	indices, err := osRepo.LegacyIndices(ctx, *tenantID)
+	if err != nil {
+		log.Fatalf("Failed to list daily indices: %v", err)
+	}
+	fmt.Printf("%d daily indices to migrate\n", len(indices))
+
+	if *dryRun {
+		for _, index := range indices {
+			fmt.Printf("  %s: tenant %s, %s\n", index.Name, index.TenantID, index.Day.Format(domain.DayLayout))
+		}
+		return
+	}
+
+	// The logs are copied into indices the policy manages
+	if err := osRepo.Setup(ctx, nil); err != nil {
+		log.Fatalf("Failed to set up OpenSearch: %v", err)
+	}
+
+	var migrated int
+	var copied int64
+	for _, index := range indices {
+		if ctx.Err() != nil {
+			break
+		}
+
+		logs, err := osRepo.MigrateLegacyIndex(ctx, index)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to migrate %s after %d indices: %v\nRun the migration again to continue\n",
+				index.Name, migrated, err)
+			os.Exit(1)
+		}
+		migrated++
+		copied += logs
+		fmt.Printf("  %s: %d logs copied to %s\n", index.Name, logs, osConfig.GetAliasName(index.TenantID))
+	}
+
+	fmt.Printf("\nMigrated %d of %d daily indices, %d logs copied\n", migrated, len(indices), copied)
+	if migrated < len(indices) {
+		fmt.Fprintln(os.Stderr, "Interrupted, run the migration again to continue")
+		os.Exit(1)
+	}
+}
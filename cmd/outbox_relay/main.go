@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/buiminhduc234/audit-log-api/internal/app"
+)
+
+func main() {
+	app.Main(app.RoleOutbox)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/composite"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+// reindex compares the logs in PostgreSQL with the OpenSearch indices and
+// indexes the missing ones. It records its progress as a reindex job, an
+// interrupted run continues with -resume <job id>.
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Define command line flags
+	tenantID := flag.String("tenant", "", "Tenant ID to reconcile, every tenant when empty")
+	from := flag.String("from", "", "First day to reconcile (YYYY-MM-DD)")
+	to := flag.String("to", time.Now().UTC().Format(domain.DayLayout), "Last day to reconcile (YYYY-MM-DD)")
+	dryRun := flag.Bool("dry-run", false, "Report the drift without indexing the missing logs")
+	compareIDs := flag.Bool("compare-ids", false, "Compare the log IDs of every day, not only of days whose counts differ")
+	resume := flag.String("resume", "", "ID of an interrupted reindex job to continue")
+	flag.Parse()
+
+	if *resume == "" && *from == "" {
+		log.Fatal("Either -from or -resume is required")
+	}
+
+	// Initialize PostgreSQL with database connections
+	dbConnections, err := config.NewDatabaseConnections()
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer dbConnections.Close()
+
+	// Initialize OpenSearch
+	osConfig := config.DefaultOpenSearchConfig()
+	osClient, err := osConfig.GetClient()
+	if err != nil {
+		log.Fatalf("Failed to connect to OpenSearch: %v", err)
+	}
+
+	// The job runs here, it is never enqueued
+	repo := composite.NewCompositeRepository(dbConnections, osClient, osConfig)
+	reindexService := service.NewReindexService(repo, nil)
+
+	// Interrupting stops after the current batch and records the job as failed
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var job *domain.ReindexJob
+	if *resume != "" {
+		job, err = reindexService.Reopen(ctx, *resume)
+	} else {
+		job, err = reindexService.Prepare(ctx, dto.CreateReindexJobRequest{
+			TenantID:   *tenantID,
+			StartDate:  *from,
+			EndDate:    *to,
+			DryRun:     *dryRun,
+			CompareIDs: *compareIDs,
+		})
+	}
+	if err != nil {
+		log.Fatalf("Failed to prepare reindex job: %v", err)
+	}
+	fmt.Printf("Reindex job %s: %s to %s, dry run: %t\n",
+		job.ID, job.StartDate.Format(domain.DayLayout), job.EndDate.Format(domain.DayLayout), job.DryRun)
+
+	var report *domain.ReindexJob
+	runErr := reindexService.Run(ctx, job.ID, func(progress *domain.ReindexJob) {
+		report = progress
+		if progress.CursorTenantID != nil && progress.CursorDate != nil {
+			fmt.Printf("  tenant %s up to %s: %d days checked, %d drifted, %d logs missing\n",
+				*progress.CursorTenantID, progress.CursorDate.Format(domain.DayLayout),
+				progress.CheckedDays, progress.DriftedDays, progress.MissingLogs)
+		}
+	})
+
+	if report != nil {
+		printReport(report)
+	}
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "Reindex job %s failed: %v\nContinue it with -resume %s\n", job.ID, runErr, job.ID)
+		os.Exit(1)
+	}
+}
+
+func printReport(job *domain.ReindexJob) {
+	fmt.Printf("\nReindex job %s %s\n", job.ID, job.Status)
+	fmt.Printf("Checked days:   %d\n", job.CheckedDays)
+	fmt.Printf("Drifted days:   %d\n", job.DriftedDays)
+	fmt.Printf("Missing logs:   %d\n", job.MissingLogs)
+	fmt.Printf("Extra logs:     %d\n", job.ExtraLogs)
+	fmt.Printf("Reindexed logs: %d\n", job.ReindexedLogs)
+
+	if len(job.Drift) == 0 {
+		return
+	}
+	fmt.Printf("\n%-36s  %-10s  %10s  %10s  %10s\n", "TENANT", "DAY", "POSTGRES", "OPENSEARCH", "MISSING")
+	for _, drift := range job.Drift {
+		fmt.Printf("%-36s  %-10s  %10d  %10d  %10d\n",
+			drift.TenantID, drift.Day, drift.PostgresCount, drift.OpenSearchCount, drift.MissingLogs)
+	}
+}
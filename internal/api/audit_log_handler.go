@@ -2,15 +2,20 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/buiminhduc234/audit-log-api/internal/service/export"
 	contextutils "github.com/buiminhduc234/audit-log-api/internal/utils"
 	"github.com/buiminhduc234/audit-log-api/pkg/utils"
 )
@@ -20,10 +25,13 @@ type AuditLogService interface {
 	Create(ctx context.Context, req dto.CreateAuditLogRequest) error
 	BulkCreate(ctx context.Context, reqs []dto.CreateAuditLogRequest) error
 	GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error)
-	List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error)
+	List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) (*dto.ListAuditLogsResponse, error)
 	GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error)
 	GetStatsV2(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error)
+	GetTimeSeries(ctx context.Context, query *domain.TimeSeriesQuery) (*dto.TimeSeriesResponse, error)
 	ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error
+	VerifyChain(ctx context.Context, tenantID string, startTime, endTime time.Time) (*dto.ChainVerificationResponse, error)
+	Export(ctx context.Context, filter *domain.AuditLogFilter, fn func(logs []domain.AuditLog) error) error
 }
 
 type AuditLogHandler struct {
@@ -45,6 +53,7 @@ func NewAuditLogHandler(service AuditLogService) *AuditLogHandler {
 // @Success 201
 // @Failure 400 {object} dto.Error
 // @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
 // @Failure 500 {object} dto.Error
 // @Router  /logs [post]
 func (h *AuditLogHandler) CreateLog(c *gin.Context) {
@@ -55,6 +64,10 @@ func (h *AuditLogHandler) CreateLog(c *gin.Context) {
 	}
 
 	if err := h.service.Create(h.RequestCtx(c), log); err != nil {
+		if errors.Is(err, service.ErrTenantMismatch) {
+			c.JSON(http.StatusForbidden, dto.Error{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
 		return
 	}
@@ -72,6 +85,7 @@ func (h *AuditLogHandler) CreateLog(c *gin.Context) {
 // @Success 201
 // @Failure 400 {object} dto.Error
 // @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
 // @Failure 500 {object} dto.Error
 // @Router  /logs/bulk [post]
 func (h *AuditLogHandler) BulkCreateLogs(c *gin.Context) {
@@ -82,6 +96,10 @@ func (h *AuditLogHandler) BulkCreateLogs(c *gin.Context) {
 	}
 
 	if err := h.service.BulkCreate(h.RequestCtx(c), logs); err != nil {
+		if errors.Is(err, service.ErrTenantMismatch) {
+			c.JSON(http.StatusForbidden, dto.Error{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
 		return
 	}
@@ -91,7 +109,7 @@ func (h *AuditLogHandler) BulkCreateLogs(c *gin.Context) {
 
 // GetLog Get a specific audit log by ID
 // @Summary Get audit log
-// @Description Get an audit log entry by its ID
+// @Description Get an audit log entry by its ID, with the field-level diff between its before and after state
 // @Tags    audit_logs
 // @Produce json
 // @Param   id path string true "Log ID"
@@ -118,25 +136,31 @@ func (h *AuditLogHandler) GetLog(c *gin.Context) {
 
 // ListLogs Get a list of audit logs with filtering
 // @Summary List audit logs
-// @Description Get a list of audit logs with filtering options
+// @Description Get a list of audit logs with filtering options. Parameters named after a path inside metadata, before_state or after_state filter on the value at the path, with an optional operator in brackets: metadata.request_id=abc, after_state.count[gte]=10, metadata.trace_id[exists]=true. The operators are eq, exists, gt, gte, lt, lte and prefix. Paths the tenant has not declared as indexed are searched in PostgreSQL.
 // @Tags    audit_logs
 // @Produce json
 // @Param   page query int false "Page number"
 // @Param   page_size query int false "Page size"
+// @Param   cursor query string false "Opaque cursor from next_cursor of the previous page, takes precedence over page"
+// @Param   include_archived query bool false "Also search the S3 archives of ranges that were removed from the live stores, each item is marked with its source"
+// @Param   q query string false "Search query, terms are field:value pairs combined with AND, OR, NOT and parentheses, a trailing * matches by prefix" example:"severity:(ERROR OR CRITICAL) AND NOT user_id:svc-* AND metadata.region:eu"
 // @Param   user_id query string false "Filter by user ID"
 // @Param   action query string false "Filter by action"
 // @Param   resource_type query string false "Filter by resource type"
+// @Param   resource_id query string false "Filter by resource ID"
 // @Param   severity query string false "Filter by severity"
+// @Param   changed_field query string false "Filter by a field changed between before_state and after_state, or a field inside it" example:"email"
 // @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
 // @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
-// @Success 200 {array} dto.AuditLogResponse
+// @Success 200 {object} dto.ListAuditLogsResponse
+// @Failure 400 {object} dto.QueryError
 // @Failure 401 {object} dto.Error
 // @Failure 500 {object} dto.Error
 // @Router  /logs [get]
 func (h *AuditLogHandler) ListLogs(c *gin.Context) {
 	filter, err := getFilterFromQuery(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		filterError(c, err)
 		return
 	}
 
@@ -149,15 +173,18 @@ func (h *AuditLogHandler) ListLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, logs)
 }
 
-// ExportLogs Export audit logs in JSON or CSV format
+// ExportLogs Export audit logs as a file
 // @Summary Export audit logs
-// @Description Export audit logs with filtering options in JSON or CSV format
+// @Description Streams audit logs matching the filters as JSON, NDJSON, CSV (RFC 4180) or Parquet. Logs are read in batches, so large ranges can be exported.
 // @Tags    audit_logs
-// @Produce json,text/csv
-// @Param   format query string false "Export format (json or csv)" default(json)
+// @Produce json,application/x-ndjson,text/csv,application/vnd.apache.parquet
+// @Param   format query string false "Export format (json, ndjson, csv or parquet)" default(json)
+// @Param   fields query string false "Comma separated list of fields to export, all fields by default" example:"id,timestamp,action,message"
+// @Param   q query string false "Search query, as for listing logs" example:"severity:(ERROR OR CRITICAL)"
 // @Param   user_id query string false "Filter by user ID"
 // @Param   action query string false "Filter by action"
 // @Param   resource_type query string false "Filter by resource type"
+// @Param   resource_id query string false "Filter by resource ID"
 // @Param   severity query string false "Filter by severity"
 // @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
 // @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
@@ -167,43 +194,71 @@ func (h *AuditLogHandler) ListLogs(c *gin.Context) {
 // @Failure 500 {object} dto.Error
 // @Router  /logs/export [get]
 func (h *AuditLogHandler) ExportLogs(c *gin.Context) {
-	format := c.DefaultQuery("format", "json")
-	if format != "json" && format != "csv" {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: "Invalid format. Must be 'json' or 'csv'"})
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.FormatJSON)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: "Invalid format. Must be 'json', 'ndjson', 'csv' or 'parquet'"})
 		return
 	}
 
-	filter, err := getFilterFromQuery(c)
+	fields, err := export.ParseFields(c.Query("fields"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
 		return
 	}
 
-	logs, err := h.service.List(h.RequestCtx(c), filter, false)
+	filter, err := getFilterFromQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		filterError(c, err)
 		return
 	}
 
-	switch format {
-	case "json":
-		c.Header("Content-Disposition", "attachment; filename=audit_logs.json")
-		c.JSON(http.StatusOK, logs)
-	case "csv":
-		c.Header("Content-Disposition", "attachment; filename=audit_logs.csv")
-		c.Header("Content-Type", "text/csv")
-
-		// Write CSV header
-		c.Writer.Write([]byte("ID,TenantID,UserID,SessionID,Action,Resource,ResourceID,IPAddress,UserAgent,Severity,Timestamp\n"))
-
-		// Write each log entry as CSV
-		for _, log := range logs {
-			row := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%s,%s,%s,%s\n",
-				log.ID, log.TenantID, log.UserID, log.SessionID, log.Action,
-				log.ResourceType, log.ResourceID, log.IPAddress, log.UserAgent,
-				log.Severity, log.Timestamp.Format(time.RFC3339))
-			c.Writer.Write([]byte(row))
+	writer, err := export.NewWriter(format, c.Writer, fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	// The status and headers are sent with the first batch, so that a failure
+	// before anything was streamed can still be reported as an error
+	started := false
+	start := func() {
+		started = true
+		c.Header("Content-Disposition", "attachment; filename="+format.FileName())
+		c.Header("Content-Type", format.ContentType())
+		c.Status(http.StatusOK)
+	}
+
+	err = h.service.Export(h.RequestCtx(c), filter, func(logs []domain.AuditLog) error {
+		if !started {
+			start()
+		}
+		for i := range logs {
+			if err := writer.Write(&logs[i]); err != nil {
+				return err
+			}
 		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+			return
+		}
+		// The response is already on its way, leave it truncated so that the
+		// client does not mistake it for a complete export
+		_ = c.Error(err)
+		return
+	}
+
+	if !started {
+		start()
+	}
+	if err := writer.Close(); err != nil {
+		_ = c.Error(err)
 	}
 }
 
@@ -221,7 +276,7 @@ func (h *AuditLogHandler) ExportLogs(c *gin.Context) {
 func (h *AuditLogHandler) GetStats(c *gin.Context) {
 	filter, err := getFilterFromQuery(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		filterError(c, err)
 		return
 	}
 
@@ -234,6 +289,112 @@ func (h *AuditLogHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetTimeSeries Get audit log counts over time
+// @Summary Get a time series of log counts
+// @Description Counts the logs matching the filters in each bucket of the interval, per group of values of the group_by dimensions. The time range is widened to whole buckets. Intervals of whole hours are counted from the hourly stats unless the filters or dimensions need the logs themselves. The groups beyond the top_n with the most logs are folded into one group whose values are "other".
+// @Tags    audit_logs
+// @Produce json
+// @Param   interval query string false "Bucket width, a duration of at least a minute such as 5m, 1h or 1d" default(1h)
+// @Param   group_by query string false "Comma separated dimensions among action, severity, resource_type and user_id" example:"severity,action"
+// @Param   top_n query int false "Number of groups kept, at most 100, the others are folded into other" default(10)
+// @Param   action query string false "Filter by action"
+// @Param   resource_type query string false "Filter by resource type"
+// @Param   severity query string false "Filter by severity"
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Success 200 {object} dto.TimeSeriesResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/stats/timeseries [get]
+func (h *AuditLogHandler) GetTimeSeries(c *gin.Context) {
+	filter, err := getFilterFromQuery(c)
+	if err != nil {
+		filterError(c, err)
+		return
+	}
+
+	query := &domain.TimeSeriesQuery{Filter: *filter, Interval: time.Hour}
+	if interval := c.Query("interval"); interval != "" {
+		if query.Interval, err = parseInterval(interval); err != nil {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+			return
+		}
+	}
+	if query.GroupBy, err = domain.ParseStatsDimensions(c.Query("group_by")); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+	if topN := c.Query("top_n"); topN != "" {
+		if query.TopN, err = strconv.Atoi(topN); err != nil || query.TopN < 1 {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: "top_n must be a positive integer"})
+			return
+		}
+	}
+
+	series, err := h.service.GetTimeSeries(h.RequestCtx(c), query)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyBuckets) {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// parseInterval parses the width of the buckets of a time series, a duration
+// or a number of days
+func parseInterval(s string) (time.Duration, error) {
+	var interval time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", s)
+		}
+		interval = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if interval, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid interval %q", s)
+		}
+	}
+	if interval < time.Minute || interval%time.Second != 0 {
+		return 0, fmt.Errorf("interval must be whole seconds and at least a minute")
+	}
+	return interval, nil
+}
+
+// VerifyChain Verify the tamper-evident hash chain of audit logs
+// @Summary Verify audit log hash chain
+// @Description Walks the tenant's hash chain across the logs in the time range and reports the first broken link
+// @Tags    audit_logs
+// @Produce json
+// @Param   start_time query string true "Verify from this time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Verify until this time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Success 200 {object} dto.ChainVerificationResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /logs/verify [get]
+func (h *AuditLogHandler) VerifyChain(c *gin.Context) {
+	filter, err := getFilterFromQuery(c)
+	if err != nil {
+		filterError(c, err)
+		return
+	}
+
+	result, err := h.service.VerifyChain(h.RequestCtx(c), filter.TenantID, filter.StartTime, filter.EndTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 	tenantID := c.GetString(string(contextutils.TenantIDKey))
 	if tenantID == "" {
@@ -245,11 +406,13 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 		UserID:       c.Query("user_id"),
 		Action:       c.Query("action"),
 		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
 		Severity:     c.Query("severity"),
 		SessionID:    c.Query("session_id"),
 		IPAddress:    c.Query("ip_address"),
 		UserAgent:    c.Query("user_agent"),
 		Message:      c.Query("message"),
+		ChangedField: c.Query("changed_field"),
 	}
 
 	// Parse pagination
@@ -263,6 +426,32 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 			filter.PageSize = size
 		}
 	}
+	if includeArchived := c.Query("include_archived"); includeArchived != "" {
+		include, err := strconv.ParseBool(includeArchived)
+		if err != nil {
+			return nil, fmt.Errorf("include_archived must be a boolean")
+		}
+		filter.IncludeArchived = include
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query, err := domain.ParseLogQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		filter.Query = query
+	}
+	jsonFilters, err := getJSONFiltersFromQuery(c)
+	if err != nil {
+		return nil, err
+	}
+	filter.JSONFilters = jsonFilters
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := domain.DecodeLogCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
 
 	// Parse time filters
 	if startTime := c.Query("start_time"); startTime != "" {
@@ -290,6 +479,43 @@ func getFilterFromQuery(c *gin.Context) (*domain.AuditLogFilter, error) {
 	return filter, nil
 }
 
+// getJSONFiltersFromQuery parses the JSON path filters among the query
+// parameters, such as metadata.request_id=abc or after_state.count[gte]=10.
+// Repeated parameters each filter the logs.
+func getJSONFiltersFromQuery(c *gin.Context) ([]domain.JSONFilter, error) {
+	params := c.Request.URL.Query()
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if domain.IsJSONFilterKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var filters []domain.JSONFilter
+	for _, key := range keys {
+		for _, value := range params[key] {
+			filter, err := domain.ParseJSONFilter(key, value)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, *filter)
+		}
+	}
+	return filters, nil
+}
+
+// filterError responds to a filter that failed to parse, with the position
+// of the error when the search query is malformed
+func filterError(c *gin.Context, err error) {
+	var syntaxErr *domain.QuerySyntaxError
+	if errors.As(err, &syntaxErr) {
+		c.JSON(http.StatusBadRequest, dto.QueryError{Error: err.Error(), Position: syntaxErr.Position})
+		return
+	}
+	c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+}
+
 // Cleanup Schedule cleanup operation for audit logs
 // @Summary Schedule cleanup operation
 // @Description Enqueues an archive job message to SQS for logs before the specified date
@@ -6,11 +6,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
 	contextutils "github.com/buiminhduc234/audit-log-api/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
@@ -46,9 +48,12 @@ func (m *MockAuditLogService) GetByID(ctx context.Context, id string) (*dto.Audi
 	return args.Get(0).(*dto.AuditLogResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error) {
+func (m *MockAuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) (*dto.ListAuditLogsResponse, error) {
 	args := m.Called(ctx, filter, usePagination)
-	return args.Get(0).([]dto.AuditLogResponse), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListAuditLogsResponse), args.Error(1)
 }
 
 func (m *MockAuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error) {
@@ -61,11 +66,32 @@ func (m *MockAuditLogService) GetStatsV2(ctx context.Context, filter *domain.Aud
 	return args.Get(0).(*dto.GetAuditLogStatsResponse), args.Error(1)
 }
 
+func (m *MockAuditLogService) GetTimeSeries(ctx context.Context, query *domain.TimeSeriesQuery) (*dto.TimeSeriesResponse, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TimeSeriesResponse), args.Error(1)
+}
+
 func (m *MockAuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error {
 	args := m.Called(ctx, tenantID, beforeDate)
 	return args.Error(0)
 }
 
+func (m *MockAuditLogService) VerifyChain(ctx context.Context, tenantID string, startTime, endTime time.Time) (*dto.ChainVerificationResponse, error) {
+	args := m.Called(ctx, tenantID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ChainVerificationResponse), args.Error(1)
+}
+
+func (m *MockAuditLogService) Export(ctx context.Context, filter *domain.AuditLogFilter, fn func(logs []domain.AuditLog) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
 func (s *AuditLogHandlerTestSuite) SetupTest() {
 	gin.SetMode(gin.TestMode)
 	s.router = gin.New()
@@ -122,6 +148,36 @@ func (s *AuditLogHandlerTestSuite) TestCreateLog_Success() {
 	s.mockService.AssertExpectations(s.T())
 }
 
+func (s *AuditLogHandlerTestSuite) TestCreateLog_OtherTenant() {
+	// Arrange
+	req := dto.CreateAuditLogRequest{
+		TenantID:     "tenant2",
+		UserID:       "user1",
+		Action:       "create",
+		ResourceType: "user",
+		ResourceID:   "resource1",
+		Message:      "Test message",
+		Severity:     "info",
+		Timestamp:    time.Now(),
+	}
+
+	s.mockService.On("Create", mock.Anything, mock.Anything).Return(service.ErrTenantMismatch)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/logs", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.CreateLog(c)
+
+	// Assert
+	s.Equal(http.StatusForbidden, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
 func (s *AuditLogHandlerTestSuite) TestBulkCreateLogs_Success() {
 	// Arrange
 	now := time.Now()
@@ -234,7 +290,13 @@ func (s *AuditLogHandlerTestSuite) TestListLogs_Success() {
 		},
 	}
 
-	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return(expectedLogs, nil)
+	expectedPage := &dto.ListAuditLogsResponse{
+		Items:            expectedLogs,
+		NextCursor:       "next",
+		ApproximateTotal: 20,
+	}
+
+	s.mockService.On("List", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), true).Return(expectedPage, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -246,11 +308,307 @@ func (s *AuditLogHandlerTestSuite) TestListLogs_Success() {
 
 	// Assert
 	s.Equal(http.StatusOK, w.Code)
-	var response []dto.AuditLogResponse
+	var response dto.ListAuditLogsResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	s.NoError(err)
-	s.Len(response, 2)
-	s.Equal(expectedLogs[0].ID, response[0].ID)
-	s.Equal(expectedLogs[1].ID, response[1].ID)
+	s.Len(response.Items, 2)
+	s.Equal(expectedLogs[0].ID, response.Items[0].ID)
+	s.Equal(expectedLogs[1].ID, response.Items[1].ID)
+	s.Equal("next", response.NextCursor)
+	s.Equal(int64(20), response.ApproximateTotal)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_WithCursor() {
+	// Arrange
+	cursor := &domain.LogCursor{
+		Timestamp: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		ID:        "log2",
+	}
+
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(filter *domain.AuditLogFilter) bool {
+		return filter.After != nil && filter.After.ID == cursor.ID && filter.After.Timestamp.Equal(cursor.Timestamp)
+	}), true).Return(&dto.ListAuditLogsResponse{Items: []dto.AuditLogResponse{}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?cursor="+cursor.Encode()+"&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_InvalidCursor() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?cursor=not-a-cursor&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_IncludeArchived() {
+	// Arrange
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.IncludeArchived
+	}), true).Return(&dto.ListAuditLogsResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?include_archived=true&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_WithQuery() {
+	// Arrange
+	q := "severity:(ERROR OR CRITICAL) AND NOT user_id:svc-*"
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.Query != nil && f.Query.Raw == q
+	}), true).Return(&dto.ListAuditLogsResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?q="+url.QueryEscape(q)+"&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_InvalidQuery() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?q="+url.QueryEscape("severity:(ERROR OR")+"&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	var response dto.QueryError
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal(19, response.Position)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_WithJSONFilters() {
+	// Arrange
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return len(f.JSONFilters) == 2 &&
+			f.JSONFilters[0].Name() == "after_state.count" && f.JSONFilters[0].Op == domain.JSONFilterGte && f.JSONFilters[0].Value == "10" &&
+			f.JSONFilters[1].Name() == "metadata.request_id" && f.JSONFilters[1].Op == domain.JSONFilterEq && f.JSONFilters[1].Value == "abc"
+	}), true).Return(&dto.ListAuditLogsResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?metadata.request_id=abc&"+url.QueryEscape("after_state.count[gte]")+"=10&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_WithChangedField() {
+	// Arrange
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.ChangedField == "email"
+	}), true).Return(&dto.ListAuditLogsResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?changed_field=email&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_WithResourceID() {
+	// Arrange
+	s.mockService.On("List", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.ResourceType == "user" && f.ResourceID == "user123"
+	}), true).Return(&dto.ListAuditLogsResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?resource_type=user&resource_id=user123&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetTimeSeries_Success() {
+	// Arrange
+	expected := &dto.TimeSeriesResponse{
+		Interval: "1h0m0s",
+		GroupBy:  []string{"severity", "action"},
+		Series:   []dto.TimeSeriesSeriesResponse{{Group: map[string]string{"severity": "ERROR", "action": "DELETE"}, Total: 3, Counts: []int64{1, 2}}},
+	}
+	s.mockService.On("GetTimeSeries", mock.Anything, mock.MatchedBy(func(q *domain.TimeSeriesQuery) bool {
+		return q.Filter.TenantID == "tenant1" && q.Interval == 15*time.Minute && q.TopN == 5 &&
+			len(q.GroupBy) == 2 && q.GroupBy[0] == domain.StatsBySeverity && q.GroupBy[1] == domain.StatsByAction
+	})).Return(expected, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats/timeseries?interval=15m&group_by=severity,action&top_n=5&start_time=2024-01-01&end_time=2024-01-01", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.GetTimeSeries(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.TimeSeriesResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().Len(response.Series, 1)
+	s.Equal([]int64{1, 2}, response.Series[0].Counts)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestGetTimeSeries_InvalidParameters() {
+	for _, params := range []string{"interval=30s", "interval=1x", "group_by=message", "group_by=action,action", "top_n=0"} {
+		// Arrange
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/logs/stats/timeseries?"+params+"&start_time=2024-01-01&end_time=2024-01-01", nil)
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+		// Act
+		s.handler.GetTimeSeries(c)
+
+		// Assert
+		s.Equal(http.StatusBadRequest, w.Code, params)
+	}
+	s.mockService.AssertNotCalled(s.T(), "GetTimeSeries", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestListLogs_InvalidJSONFilter() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs?"+url.QueryEscape("metadata.count[between]")+"=10&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ListLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_CSV() {
+	// Arrange
+	logs := []domain.AuditLog{
+		{
+			ID:        "log1",
+			Action:    "CREATE",
+			Message:   "Created \"order\", total 10,5",
+			Metadata:  json.RawMessage(`{"key":"value"}`),
+			Timestamp: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	s.mockService.On("Export", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func([]domain.AuditLog) error)
+			s.Require().NoError(fn(logs))
+		}).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?format=csv&fields=id,message,metadata,timestamp&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Equal("id,message,metadata,timestamp\r\n"+
+		"log1,\"Created \"\"order\"\", total 10,5\",\"{\"\"key\"\":\"\"value\"\"}\",2024-06-01T12:00:00Z\r\n", w.Body.String())
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_NDJSON() {
+	// Arrange
+	logs := []domain.AuditLog{
+		{ID: "log1", Action: "CREATE"},
+		{ID: "log2", Action: "DELETE"},
+	}
+
+	s.mockService.On("Export", mock.Anything, mock.AnythingOfType("*domain.AuditLogFilter"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			fn := args.Get(2).(func([]domain.AuditLog) error)
+			s.Require().NoError(fn(logs))
+		}).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?format=ndjson&fields=id,action&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("{\"id\":\"log1\",\"action\":\"CREATE\"}\n{\"id\":\"log2\",\"action\":\"DELETE\"}\n", w.Body.String())
 	s.mockService.AssertExpectations(s.T())
 }
+
+func (s *AuditLogHandlerTestSuite) TestExportLogs_UnknownField() {
+	// Arrange
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/logs/export?format=csv&fields=id,password&start_time=2024-01-01T00:00:00Z&end_time=2024-12-31T23:59:59Z", nil)
+	c.Set(string(contextutils.TenantIDKey), "tenant1")
+
+	// Act
+	s.handler.ExportLogs(c)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Export", mock.Anything, mock.Anything, mock.Anything)
+}
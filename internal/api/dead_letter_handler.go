@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name DeadLetterService --output ../mocks
+type DeadLetterService interface {
+	List(ctx context.Context, queueName string, limit int) ([]dto.DeadLetterResponse, error)
+	Get(ctx context.Context, queueName, messageID string) (*dto.DeadLetterResponse, error)
+	Redrive(ctx context.Context, queueName string, req dto.RedriveDeadLettersRequest) (*dto.RedriveDeadLettersResponse, error)
+	Purge(ctx context.Context, queueName string) error
+}
+
+type DeadLetterHandler struct {
+	*BaseHandler
+	service DeadLetterService
+}
+
+func NewDeadLetterHandler(service DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{service: service}
+}
+
+// ListDeadLetters List the dead letters of a queue
+// @Summary List dead letters
+// @Description Returns the messages a worker moved to the dead-letter queue of a queue after they kept failing, with the reason of the last failure. The messages stay in the dead-letter queue.
+// @Tags    admin
+// @Produce json
+// @Param   queue path string true "Queue" Enums(index, archive, cleanup, export)
+// @Param   limit query int false "Maximum number of messages (default 100, max 1000)"
+// @Success 200 {array} dto.DeadLetterResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/dlq/{queue} [get]
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	letters, err := h.service.List(h.RequestCtx(c), c.Param("queue"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownQueue), errors.Is(err, service.ErrDeadLetterNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, letters)
+}
+
+// GetDeadLetter Get a dead letter
+// @Summary Get dead letter
+// @Description Returns a message of the dead-letter queue of a queue with its original body
+// @Tags    admin
+// @Produce json
+// @Param   queue path string true "Queue" Enums(index, archive, cleanup, export)
+// @Param   id path string true "Message ID"
+// @Success 200 {object} dto.DeadLetterResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/dlq/{queue}/{id} [get]
+func (h *DeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	letter, err := h.service.Get(h.RequestCtx(c), c.Param("queue"), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownQueue), errors.Is(err, service.ErrDeadLetterNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, letter)
+}
+
+// RedriveDeadLetters Send dead letters back to their queue
+// @Summary Redrive dead letters
+// @Description Sends the selected messages of the dead-letter queue back to the queue, every message when no message IDs are given. A redriven message starts over with a receive count of zero.
+// @Tags    admin
+// @Accept  json
+// @Produce json
+// @Param   queue path string true "Queue" Enums(index, archive, cleanup, export)
+// @Param   body body dto.RedriveDeadLettersRequest false "Messages to redrive"
+// @Success 200 {object} dto.RedriveDeadLettersResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/dlq/{queue}/redrive [post]
+func (h *DeadLetterHandler) RedriveDeadLetters(c *gin.Context) {
+	var req dto.RedriveDeadLettersRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+			return
+		}
+	}
+
+	resp, err := h.service.Redrive(h.RequestCtx(c), c.Param("queue"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownQueue), errors.Is(err, service.ErrDeadLetterNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// PurgeDeadLetters Delete the dead letters of a queue
+// @Summary Purge dead letters
+// @Description Deletes every message of the dead-letter queue of a queue. SQS allows one purge of a queue per minute.
+// @Tags    admin
+// @Produce json
+// @Param   queue path string true "Queue" Enums(index, archive, cleanup, export)
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/dlq/{queue} [delete]
+func (h *DeadLetterHandler) PurgeDeadLetters(c *gin.Context) {
+	if err := h.service.Purge(h.RequestCtx(c), c.Param("queue")); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownQueue), errors.Is(err, service.ErrDeadLetterNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadLetterHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockDeadLetterService
+	handler     *DeadLetterHandler
+}
+
+type MockDeadLetterService struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterService) List(ctx context.Context, queueName string, limit int) ([]dto.DeadLetterResponse, error) {
+	args := m.Called(ctx, queueName, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.DeadLetterResponse), args.Error(1)
+}
+
+func (m *MockDeadLetterService) Get(ctx context.Context, queueName, messageID string) (*dto.DeadLetterResponse, error) {
+	args := m.Called(ctx, queueName, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.DeadLetterResponse), args.Error(1)
+}
+
+func (m *MockDeadLetterService) Redrive(ctx context.Context, queueName string, req dto.RedriveDeadLettersRequest) (*dto.RedriveDeadLettersResponse, error) {
+	args := m.Called(ctx, queueName, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RedriveDeadLettersResponse), args.Error(1)
+}
+
+func (m *MockDeadLetterService) Purge(ctx context.Context, queueName string) error {
+	args := m.Called(ctx, queueName)
+	return args.Error(0)
+}
+
+func (s *DeadLetterHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockDeadLetterService)
+	s.handler = NewDeadLetterHandler(s.mockService)
+
+	// Setup routes
+	s.router.GET("/admin/dlq/:queue", s.handler.ListDeadLetters)
+	s.router.GET("/admin/dlq/:queue/:id", s.handler.GetDeadLetter)
+	s.router.POST("/admin/dlq/:queue/redrive", s.handler.RedriveDeadLetters)
+	s.router.DELETE("/admin/dlq/:queue", s.handler.PurgeDeadLetters)
+}
+
+func TestDeadLetterHandler(t *testing.T) {
+	suite.Run(t, new(DeadLetterHandlerTestSuite))
+}
+
+func (s *DeadLetterHandlerTestSuite) TestListDeadLetters_Success() {
+	// Arrange
+	letters := []dto.DeadLetterResponse{{MessageID: "msg1", Queue: "index", Reason: "boom", ReceiveCount: 5}}
+	s.mockService.On("List", mock.Anything, "index", 10).Return(letters, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/dlq/index?limit=10", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.DeadLetterResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Len(response, 1)
+	s.Equal("boom", response[0].Reason)
+}
+
+func (s *DeadLetterHandlerTestSuite) TestListDeadLetters_InvalidLimit() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/dlq/index?limit=-1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *DeadLetterHandlerTestSuite) TestGetDeadLetter_UnknownQueue() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "missing", "msg1").Return(nil, service.ErrUnknownQueue)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/dlq/missing/msg1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *DeadLetterHandlerTestSuite) TestRedriveDeadLetters_Selected() {
+	// Arrange
+	req := dto.RedriveDeadLettersRequest{MessageIDs: []string{"msg1", "msg2"}}
+	s.mockService.On("Redrive", mock.Anything, "archive", req).Return(&dto.RedriveDeadLettersResponse{Redriven: 2}, nil)
+
+	body := `{"message_ids":["msg1","msg2"]}`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/admin/dlq/archive/redrive", bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.RedriveDeadLettersResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal(2, response.Redriven)
+}
+
+func (s *DeadLetterHandlerTestSuite) TestRedriveDeadLetters_AllWithoutBody() {
+	// Arrange
+	s.mockService.On("Redrive", mock.Anything, "index", dto.RedriveDeadLettersRequest{}).Return(&dto.RedriveDeadLettersResponse{Redriven: 7}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/dlq/index/redrive", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *DeadLetterHandlerTestSuite) TestPurgeDeadLetters_Success() {
+	// Arrange
+	s.mockService.On("Purge", mock.Anything, "cleanup").Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/admin/dlq/cleanup", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNoContent, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
@@ -4,3 +4,9 @@ package dto
 type Error struct {
 	Error string `json:"error" example:"error message"`
 }
+
+// QueryError represents a search query that failed to parse
+type QueryError struct {
+	Error    string `json:"error" example:"invalid query at position 10: expected a value for field severity, found end of query"`
+	Position int    `json:"position" example:"10"`
+}
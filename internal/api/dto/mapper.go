@@ -21,30 +21,50 @@ func (r *CreateAuditLogRequest) ToAuditLog() *domain.AuditLog {
 		AfterState:   r.AfterState,
 		Metadata:     r.Metadata,
 		Timestamp:    r.Timestamp,
+		// Stored with the log so that it can be filtered by changed field
+		ChangedFields: domain.ChangedFields(domain.DiffStates(r.BeforeState, r.AfterState)),
 	}
 }
 
 // FromAuditLog converts an AuditLog domain model to an AuditLogResponse DTO
 func FromAuditLog(log *domain.AuditLog) *AuditLogResponse {
 	return &AuditLogResponse{
-		ID:           log.ID,
-		TenantID:     log.TenantID,
-		UserID:       log.UserID,
-		SessionID:    log.SessionID,
-		IPAddress:    log.IPAddress,
-		UserAgent:    log.UserAgent,
-		Action:       log.Action,
-		ResourceType: log.ResourceType,
-		ResourceID:   log.ResourceID,
-		Severity:     log.Severity,
-		Message:      log.Message,
-		BeforeState:  log.BeforeState,
-		AfterState:   log.AfterState,
-		Metadata:     log.Metadata,
-		Timestamp:    log.Timestamp,
+		ID:            log.ID,
+		TenantID:      log.TenantID,
+		UserID:        log.UserID,
+		SessionID:     log.SessionID,
+		IPAddress:     log.IPAddress,
+		UserAgent:     log.UserAgent,
+		Action:        log.Action,
+		ResourceType:  log.ResourceType,
+		ResourceID:    log.ResourceID,
+		Severity:      log.Severity,
+		Message:       log.Message,
+		BeforeState:   log.BeforeState,
+		AfterState:    log.AfterState,
+		Metadata:      log.Metadata,
+		ChangedFields: log.ChangedFields,
+		Timestamp:     log.Timestamp,
+		ChainSeq:      log.ChainSeq,
+		PrevHash:      log.PrevHash,
+		Hash:          log.Hash,
 	}
 }
 
+// FromFieldChanges converts the changes between the states of a log to FieldChangeResponse DTOs
+func FromFieldChanges(changes []domain.FieldChange) []FieldChangeResponse {
+	responses := make([]FieldChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = FieldChangeResponse{
+			Path:     change.Path,
+			Type:     string(change.Type),
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+		}
+	}
+	return responses
+}
+
 func FromAuditLogs(logs []domain.AuditLog) []AuditLogResponse {
 	responses := make([]AuditLogResponse, len(logs))
 	for i, log := range logs {
@@ -52,3 +72,210 @@ func FromAuditLogs(logs []domain.AuditLog) []AuditLogResponse {
 	}
 	return responses
 }
+
+func FromSessionSummary(session *domain.SessionSummary) SessionSummaryResponse {
+	response := SessionSummaryResponse{
+		SessionID:    session.SessionID,
+		UserIDs:      session.UserIDs,
+		StartTime:    session.StartTime,
+		EndTime:      session.EndTime,
+		EventCount:   session.EventCount,
+		IPAddresses:  session.IPAddresses,
+		UserAgents:   session.UserAgents,
+		ActionCounts: session.ActionCounts,
+	}
+	if session.FirstEvent != nil {
+		response.FirstEvent = FromAuditLog(session.FirstEvent)
+	}
+	if session.LastEvent != nil {
+		response.LastEvent = FromAuditLog(session.LastEvent)
+	}
+	return response
+}
+
+// ToAuditLogFilter converts a CreateExportJobRequest DTO to the filter of the
+// logs to export
+func (r *CreateExportJobRequest) ToAuditLogFilter(tenantID string) domain.AuditLogFilter {
+	return domain.AuditLogFilter{
+		TenantID:     tenantID,
+		UserID:       r.UserID,
+		SessionID:    r.SessionID,
+		IPAddress:    r.IPAddress,
+		UserAgent:    r.UserAgent,
+		Action:       r.Action,
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		Message:      r.Message,
+		Severity:     r.Severity,
+		StartTime:    r.StartTime,
+		EndTime:      r.EndTime,
+	}
+}
+
+// FromExportJob converts an ExportJob domain model to an ExportJobResponse DTO
+func FromExportJob(job *domain.ExportJob) *ExportJobResponse {
+	return &ExportJobResponse{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		Format:      job.Format,
+		Fields:      job.Fields,
+		RowCount:    job.RowCount,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+}
+
+// FromRestoreJob converts a RestoreJob domain model to a RestoreJobResponse DTO
+func FromRestoreJob(job *domain.RestoreJob) *RestoreJobResponse {
+	return &RestoreJobResponse{
+		ID:           job.ID,
+		ArchiveKey:   job.ArchiveKey,
+		Status:       string(job.Status),
+		TotalLogs:    job.TotalLogs,
+		RestoredLogs: job.RestoredLogs,
+		SkippedLogs:  job.SkippedLogs,
+		IndexedLogs:  job.IndexedLogs,
+		ChainValid:   job.ChainValid,
+		Error:        job.Error,
+		CreatedAt:    job.CreatedAt,
+		StartedAt:    job.StartedAt,
+		CompletedAt:  job.CompletedAt,
+	}
+}
+
+// FromReindexJob converts a ReindexJob domain model to a ReindexJobResponse DTO
+func FromReindexJob(job *domain.ReindexJob) *ReindexJobResponse {
+	resp := &ReindexJobResponse{
+		ID:             job.ID,
+		TenantID:       job.TenantID,
+		StartDate:      job.StartDate.Format(domain.DayLayout),
+		EndDate:        job.EndDate.Format(domain.DayLayout),
+		DryRun:         job.DryRun,
+		CompareIDs:     job.CompareIDs,
+		Status:         string(job.Status),
+		CursorTenantID: job.CursorTenantID,
+		CheckedDays:    job.CheckedDays,
+		DriftedDays:    job.DriftedDays,
+		MissingLogs:    job.MissingLogs,
+		ExtraLogs:      job.ExtraLogs,
+		ReindexedLogs:  job.ReindexedLogs,
+		Drift:          make([]IndexDriftResponse, len(job.Drift)),
+		Error:          job.Error,
+		CreatedBy:      job.CreatedBy,
+		CreatedAt:      job.CreatedAt,
+		StartedAt:      job.StartedAt,
+		CompletedAt:    job.CompletedAt,
+	}
+	if job.CursorDate != nil {
+		resp.CursorDate = job.CursorDate.Format(domain.DayLayout)
+	}
+	for i, drift := range job.Drift {
+		resp.Drift[i] = IndexDriftResponse{
+			TenantID:        drift.TenantID,
+			Day:             drift.Day,
+			PostgresCount:   drift.PostgresCount,
+			OpenSearchCount: drift.OpenSearchCount,
+			MissingLogs:     drift.MissingLogs,
+		}
+	}
+	return resp
+}
+
+// FromArchiveObject converts an ArchiveObject domain model to an ArchiveResponse DTO
+func FromArchiveObject(obj domain.ArchiveObject) ArchiveResponse {
+	return ArchiveResponse{
+		Key:          obj.Key,
+		Size:         obj.Size,
+		LastModified: obj.LastModified,
+	}
+}
+
+// FromRetentionPolicy converts a RetentionPolicy domain model to a RetentionPolicyResponse DTO
+func FromRetentionPolicy(policy *domain.RetentionPolicy) *RetentionPolicyResponse {
+	return &RetentionPolicyResponse{
+		TenantID:         policy.TenantID,
+		ArchiveAfterDays: policy.ArchiveAfterDays,
+		DeleteAfterDays:  policy.DeleteAfterDays,
+		KeepSeverities:   policy.KeepSeverities,
+		Enabled:          policy.Enabled,
+		LastScheduledAt:  policy.LastScheduledAt,
+		UpdatedAt:        policy.UpdatedAt,
+	}
+}
+
+// FromIndexedPaths converts the indexed paths of a tenant to an IndexedPathsResponse DTO
+func FromIndexedPaths(tenant *domain.Tenant) *IndexedPathsResponse {
+	paths := make([]IndexedPathResponse, 0, len(tenant.IndexedPaths))
+	for _, path := range tenant.IndexedPaths {
+		paths = append(paths, IndexedPathResponse{Path: path.Path, Type: path.Type})
+	}
+	return &IndexedPathsResponse{TenantID: tenant.ID, Paths: paths}
+}
+
+// ToLegalHold converts a CreateLegalHoldRequest DTO to a LegalHold domain model
+func (r *CreateLegalHoldRequest) ToLegalHold(tenantID string) *domain.LegalHold {
+	logIDs := r.LogIDs
+	if logIDs == nil {
+		logIDs = []string{}
+	}
+	return &domain.LegalHold{
+		TenantID:     tenantID,
+		Reason:       r.Reason,
+		UserID:       r.UserID,
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		StartTime:    r.StartTime,
+		EndTime:      r.EndTime,
+		LogIDs:       logIDs,
+	}
+}
+
+// FromLegalHold converts a LegalHold domain model to a LegalHoldResponse DTO
+func FromLegalHold(hold *domain.LegalHold) *LegalHoldResponse {
+	return &LegalHoldResponse{
+		ID:           hold.ID,
+		Reason:       hold.Reason,
+		UserID:       hold.UserID,
+		ResourceType: hold.ResourceType,
+		ResourceID:   hold.ResourceID,
+		StartTime:    hold.StartTime,
+		EndTime:      hold.EndTime,
+		LogIDs:       hold.LogIDs,
+		Active:       hold.Active(),
+		CreatedBy:    hold.CreatedBy,
+		CreatedAt:    hold.CreatedAt,
+		ReleasedBy:   hold.ReleasedBy,
+		ReleasedAt:   hold.ReleasedAt,
+	}
+}
+
+// FromChainBreak converts a ChainBreak domain model to a ChainBreakResponse DTO
+func FromChainBreak(brk *domain.ChainBreak) *ChainBreakResponse {
+	return &ChainBreakResponse{
+		LogID:    brk.LogID,
+		ChainSeq: brk.ChainSeq,
+		Reason:   brk.Reason,
+		Expected: brk.Expected,
+		Actual:   brk.Actual,
+	}
+}
+
+// FromDeadLetter converts a DeadLetter domain model to a DeadLetterResponse DTO
+func FromDeadLetter(letter *domain.DeadLetter) *DeadLetterResponse {
+	resp := &DeadLetterResponse{
+		MessageID:    letter.MessageID,
+		Queue:        letter.Queue,
+		Body:         letter.Body,
+		Reason:       letter.Reason,
+		ReceiveCount: letter.ReceiveCount,
+	}
+	if !letter.FailedAt.IsZero() {
+		resp.FailedAt = &letter.FailedAt
+	}
+	if !letter.SentAt.IsZero() {
+		resp.SentAt = &letter.SentAt
+	}
+	return resp
+}
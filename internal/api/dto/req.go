@@ -9,6 +9,11 @@ type CreateTenantRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+type UpdateTenantRequest struct {
+	Name      string `json:"name" example:"My Tenant"`
+	RateLimit *int   `json:"rate_limit" binding:"omitempty,min=1" example:"1000"`
+}
+
 type CreateAuditLogRequest struct {
 	TenantID     string          `json:"tenant_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"`
 	UserID       string          `json:"user_id" example:"123456"`
@@ -25,3 +30,67 @@ type CreateAuditLogRequest struct {
 	Metadata     json.RawMessage `json:"metadata" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
 	Timestamp    time.Time       `json:"timestamp" binding:"required" example:"2025-07-17T21:20:48Z"`
 }
+
+type CreateExportJobRequest struct {
+	Format       string    `json:"format" binding:"required,oneof=json ndjson csv parquet" example:"parquet"`
+	Fields       []string  `json:"fields" example:"id,timestamp,action,message"`
+	StartTime    time.Time `json:"start_time" binding:"required" example:"2025-07-01T00:00:00Z"`
+	EndTime      time.Time `json:"end_time" binding:"required" example:"2025-07-31T23:59:59Z"`
+	UserID       string    `json:"user_id" example:"123456"`
+	SessionID    string    `json:"session_id" example:"sess_123456"`
+	IPAddress    string    `json:"ip_address" example:"192.168.1.1"`
+	UserAgent    string    `json:"user_agent" example:"Mozilla/5.0"`
+	Action       string    `json:"action" example:"CREATE"`
+	ResourceType string    `json:"resource_type" example:"user"`
+	ResourceID   string    `json:"resource_id" example:"user123"`
+	Severity     string    `json:"severity" example:"INFO"`
+	Message      string    `json:"message" example:"created"`
+}
+
+type RetentionPolicyRequest struct {
+	ArchiveAfterDays int      `json:"archive_after_days" binding:"required,min=1" example:"90"`
+	DeleteAfterDays  int      `json:"delete_after_days" binding:"min=0" example:"400"`
+	KeepSeverities   []string `json:"keep_severities" binding:"dive,oneof=INFO WARNING ERROR CRITICAL" example:"CRITICAL"`
+	Enabled          *bool    `json:"enabled" example:"true"`
+}
+
+// IndexedPathsRequest replaces the paths inside the JSON fields of the logs
+// that are indexed in OpenSearch for a tenant
+type IndexedPathsRequest struct {
+	Paths []IndexedPathRequest `json:"paths" binding:"max=100,dive"`
+}
+
+type IndexedPathRequest struct {
+	Path string `json:"path" binding:"required" example:"metadata.request_id"`
+	// Type defaults to keyword
+	Type string `json:"type" binding:"omitempty,oneof=keyword long double date" example:"keyword"`
+}
+
+type CreateRestoreJobRequest struct {
+	ArchiveKey string `json:"archive_key" binding:"required" example:"audit-logs/550e8400-e29b-41d4-a716-446655440000/audit_logs_550e8400-e29b-41d4-a716-446655440000_before_2025-01-01_00-00-00.json"`
+}
+
+type CreateReindexJobRequest struct {
+	// TenantID limits the job to one tenant, every tenant is compared when empty
+	TenantID   string `json:"tenant_id" binding:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	StartDate  string `json:"start_date" binding:"required,datetime=2006-01-02" example:"2025-07-01"`
+	EndDate    string `json:"end_date" binding:"required,datetime=2006-01-02" example:"2025-07-31"`
+	DryRun     bool   `json:"dry_run" example:"true"`
+	CompareIDs bool   `json:"compare_ids" example:"false"`
+}
+
+// RedriveDeadLettersRequest selects the dead letters to send back to their queue
+type RedriveDeadLettersRequest struct {
+	// MessageIDs selects the messages, every dead letter is redriven when empty
+	MessageIDs []string `json:"message_ids" example:"5fea7756-0ea4-451a-a703-a558b933e274"`
+}
+
+type CreateLegalHoldRequest struct {
+	Reason       string     `json:"reason" binding:"required" example:"Litigation 2025-CV-0042"`
+	UserID       string     `json:"user_id" example:"123456"`
+	ResourceType string     `json:"resource_type" example:"user"`
+	ResourceID   string     `json:"resource_id" example:"user123"`
+	StartTime    *time.Time `json:"start_time" example:"2025-01-01T00:00:00Z"`
+	EndTime      *time.Time `json:"end_time" example:"2025-06-30T23:59:59Z"`
+	LogIDs       []string   `json:"log_ids" binding:"omitempty,dive,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
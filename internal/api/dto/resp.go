@@ -9,27 +9,225 @@ import (
 type CreateTenantResponse struct {
 	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
 	Name      string    `json:"name" example:"My Tenant"`
+	RateLimit int       `json:"rate_limit" example:"1000"`
 	CreatedAt time.Time `json:"created_at" example:"2025-07-17T21:20:48Z"`
 	UpdatedAt time.Time `json:"updated_at" example:"2025-07-17T21:20:48Z"`
 }
 
 // AuditLogResponse represents a single audit log entry in the response
 type AuditLogResponse struct {
-	ID           string          `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	TenantID     string          `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	UserID       string          `json:"user_id" example:"123456"`
-	SessionID    string          `json:"session_id" example:"sess_123456"`
-	IPAddress    string          `json:"ip_address" example:"192.168.1.1"`
-	UserAgent    string          `json:"user_agent" example:"Mozilla/5.0"`
-	Action       string          `json:"action" example:"CREATE"`
-	ResourceType string          `json:"resource_type" example:"user"`
-	ResourceID   string          `json:"resource_id" example:"user123"`
-	Severity     string          `json:"severity" example:"INFO"`
-	Message      string          `json:"message" example:"User created successfully"`
-	BeforeState  json.RawMessage `json:"before_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"old name\\"}"`
-	AfterState   json.RawMessage `json:"after_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"new name\\"}"`
-	Metadata     json.RawMessage `json:"metadata,omitempty" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
-	Timestamp    time.Time       `json:"timestamp" example:"2025-07-17T21:20:48Z"`
+	ID            string                `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID      string                `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID        string                `json:"user_id" example:"123456"`
+	SessionID     string                `json:"session_id" example:"sess_123456"`
+	IPAddress     string                `json:"ip_address" example:"192.168.1.1"`
+	UserAgent     string                `json:"user_agent" example:"Mozilla/5.0"`
+	Action        string                `json:"action" example:"CREATE"`
+	ResourceType  string                `json:"resource_type" example:"user"`
+	ResourceID    string                `json:"resource_id" example:"user123"`
+	Severity      string                `json:"severity" example:"INFO"`
+	Message       string                `json:"message" example:"User created successfully"`
+	BeforeState   json.RawMessage       `json:"before_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"old name\\"}"`
+	AfterState    json.RawMessage       `json:"after_state,omitempty" swaggertype:"string" example:"{\\"name\\":\\"new name\\"}"`
+	Metadata      json.RawMessage       `json:"metadata,omitempty" swaggertype:"string" example:"{\\"key\\":\\"value\\"}"`
+	ChangedFields []string              `json:"changed_fields,omitempty" example:"name"`
+	Diff          []FieldChangeResponse `json:"diff,omitempty"`
+	Timestamp     time.Time             `json:"timestamp" example:"2025-07-17T21:20:48Z"`
+	ChainSeq      int64                 `json:"chain_seq,omitempty" example:"42"`
+	PrevHash      string                `json:"prev_hash,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Hash          string                `json:"hash,omitempty" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+	Source        string                `json:"source,omitempty" enums:"live,archive" example:"live"`
+}
+
+// FieldChangeResponse is a value that differs between the before and after
+// state of a log
+type FieldChangeResponse struct {
+	Path     string          `json:"path" example:"address.city"`
+	Type     string          `json:"type" enums:"added,removed,changed" example:"changed"`
+	OldValue json.RawMessage `json:"old_value,omitempty" swaggertype:"string" example:"\"Berlin\""`
+	NewValue json.RawMessage `json:"new_value,omitempty" swaggertype:"string" example:"\"Hamburg\""`
+}
+
+// Sources of the logs listed with include_archived
+const (
+	LogSourceLive    = "live"
+	LogSourceArchive = "archive"
+)
+
+// ListAuditLogsResponse represents a page of audit logs
+type ListAuditLogsResponse struct {
+	Items            []AuditLogResponse `json:"items"`
+	NextCursor       string             `json:"next_cursor,omitempty" example:"eyJ0IjoiMjAyNS0wNy0xN1QyMToyMDo0OFoiLCJpZCI6IjU1MGU4NDAwIn0"`
+	ApproximateTotal int64              `json:"approximate_total" example:"1000"`
+}
+
+// ResourceHistoryResponse is the timeline of a resource, oldest first, and
+// its state replayed from the after states of the timeline
+type ResourceHistoryResponse struct {
+	ResourceType string             `json:"resource_type" example:"user"`
+	ResourceID   string             `json:"resource_id" example:"user123"`
+	AsOf         *time.Time         `json:"as_of,omitempty" example:"2025-07-17T21:20:48Z"`
+	State        json.RawMessage    `json:"state,omitempty" swaggertype:"object"`
+	Deleted      bool               `json:"deleted" example:"false"`
+	StateLogID   string             `json:"state_log_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Events       []AuditLogResponse `json:"events"`
+	Truncated    bool               `json:"truncated" example:"false"`
+}
+
+// SessionSummaryResponse sums up the logs of a session
+type SessionSummaryResponse struct {
+	SessionID    string            `json:"session_id" example:"sess_123456"`
+	UserIDs      []string          `json:"user_ids" example:"123456"`
+	StartTime    time.Time         `json:"start_time" example:"2025-07-17T21:20:48Z"`
+	EndTime      time.Time         `json:"end_time" example:"2025-07-17T21:45:12Z"`
+	EventCount   int64             `json:"event_count" example:"42"`
+	IPAddresses  []string          `json:"ip_addresses" example:"192.168.1.1"`
+	UserAgents   []string          `json:"user_agents" example:"Mozilla/5.0"`
+	ActionCounts map[string]int64  `json:"action_counts" example:"CREATE:2,VIEW:40"`
+	FirstEvent   *AuditLogResponse `json:"first_event,omitempty"`
+	LastEvent    *AuditLogResponse `json:"last_event,omitempty"`
+}
+
+// UserActivityResponse lists the sessions of a user, the most recent first
+type UserActivityResponse struct {
+	UserID        string                   `json:"user_id" example:"123456"`
+	Sessions      []SessionSummaryResponse `json:"sessions"`
+	TotalSessions int64                    `json:"total_sessions" example:"12"`
+}
+
+// SessionResponse is a session with its events, oldest first
+type SessionResponse struct {
+	SessionSummaryResponse
+	Events    []AuditLogResponse `json:"events"`
+	Truncated bool               `json:"truncated" example:"false"`
+}
+
+// ExportJobResponse represents the state of an asynchronous export job
+type ExportJobResponse struct {
+	ID          string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status      string     `json:"status" example:"COMPLETED"`
+	Format      string     `json:"format" example:"parquet"`
+	Fields      []string   `json:"fields" example:"id,timestamp,action,message"`
+	RowCount    int64      `json:"row_count" example:"1000000"`
+	DownloadURL string     `json:"download_url,omitempty" example:"https://audit-log-archives.s3.amazonaws.com/exports/..."`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2025-07-17T21:25:48Z"`
+	ExpiresAt   time.Time  `json:"expires_at" example:"2025-07-24T21:20:48Z"`
+}
+
+// RestoreJobResponse represents the progress of a restore of archived logs
+type RestoreJobResponse struct {
+	ID           string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ArchiveKey   string     `json:"archive_key" example:"audit-logs/550e8400-e29b-41d4-a716-446655440000/audit_logs_550e8400-e29b-41d4-a716-446655440000_before_2025-01-01_00-00-00.json"`
+	Status       string     `json:"status" example:"COMPLETED"`
+	TotalLogs    int64      `json:"total_logs" example:"1000"`
+	RestoredLogs int64      `json:"restored_logs" example:"990"`
+	SkippedLogs  int64      `json:"skipped_logs" example:"10"`
+	IndexedLogs  int64      `json:"indexed_logs" example:"1000"`
+	ChainValid   *bool      `json:"chain_valid,omitempty" example:"true"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	StartedAt    *time.Time `json:"started_at,omitempty" example:"2025-07-17T21:20:50Z"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" example:"2025-07-17T21:25:48Z"`
+}
+
+// ReindexJobResponse represents the progress and drift report of a
+// reconciliation of PostgreSQL against OpenSearch
+type ReindexJobResponse struct {
+	ID             string               `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID       *string              `json:"tenant_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	StartDate      string               `json:"start_date" example:"2025-07-01"`
+	EndDate        string               `json:"end_date" example:"2025-07-31"`
+	DryRun         bool                 `json:"dry_run" example:"true"`
+	CompareIDs     bool                 `json:"compare_ids" example:"false"`
+	Status         string               `json:"status" example:"COMPLETED"`
+	CursorTenantID *string              `json:"cursor_tenant_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CursorDate     string               `json:"cursor_date,omitempty" example:"2025-07-31"`
+	CheckedDays    int64                `json:"checked_days" example:"31"`
+	DriftedDays    int64                `json:"drifted_days" example:"2"`
+	MissingLogs    int64                `json:"missing_logs" example:"120"`
+	ExtraLogs      int64                `json:"extra_logs" example:"0"`
+	ReindexedLogs  int64                `json:"reindexed_logs" example:"0"`
+	Drift          []IndexDriftResponse `json:"drift"`
+	Error          string               `json:"error,omitempty"`
+	CreatedBy      string               `json:"created_by,omitempty" example:"admin@example.com"`
+	CreatedAt      time.Time            `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	StartedAt      *time.Time           `json:"started_at,omitempty" example:"2025-07-17T21:20:50Z"`
+	CompletedAt    *time.Time           `json:"completed_at,omitempty" example:"2025-07-17T21:25:48Z"`
+}
+
+// IndexDriftResponse represents a day on which the logs of a tenant in
+// OpenSearch differ from the logs in PostgreSQL
+type IndexDriftResponse struct {
+	TenantID        string `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Day             string `json:"day" example:"2025-07-14"`
+	PostgresCount   int64  `json:"postgres_count" example:"1000"`
+	OpenSearchCount int64  `json:"opensearch_count" example:"880"`
+	MissingLogs     int64  `json:"missing_logs" example:"120"`
+}
+
+// DeadLetterResponse represents a queue message a worker gave up on
+type DeadLetterResponse struct {
+	MessageID    string     `json:"message_id" example:"5fea7756-0ea4-451a-a703-a558b933e274"`
+	Queue        string     `json:"queue" example:"index"`
+	Body         string     `json:"body" example:"{\"type\":\"BULK_INDEX\",\"tenant_id\":\"550e8400-e29b-41d4-a716-446655440000\"}"`
+	Reason       string     `json:"reason,omitempty" example:"failed to bulk index logs: context deadline exceeded"`
+	ReceiveCount int        `json:"receive_count" example:"5"`
+	FailedAt     *time.Time `json:"failed_at,omitempty" example:"2025-07-17T21:20:48Z"`
+	SentAt       *time.Time `json:"sent_at,omitempty" example:"2025-07-17T21:20:48Z"`
+}
+
+// RedriveDeadLettersResponse counts the dead letters sent back to their queue
+type RedriveDeadLettersResponse struct {
+	Redriven int `json:"redriven" example:"3"`
+}
+
+// ArchiveResponse represents an archive of logs stored in S3
+type ArchiveResponse struct {
+	Key          string    `json:"key" example:"audit-logs/550e8400-e29b-41d4-a716-446655440000/audit_logs_550e8400-e29b-41d4-a716-446655440000_before_2025-01-01_00-00-00.json"`
+	Size         int64     `json:"size" example:"1048576"`
+	LastModified time.Time `json:"last_modified" example:"2025-01-01T02:00:00Z"`
+}
+
+// RetentionPolicyResponse represents the retention policy of a tenant
+type RetentionPolicyResponse struct {
+	TenantID         string     `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ArchiveAfterDays int        `json:"archive_after_days" example:"90"`
+	DeleteAfterDays  int        `json:"delete_after_days" example:"400"`
+	KeepSeverities   []string   `json:"keep_severities" example:"CRITICAL"`
+	Enabled          bool       `json:"enabled" example:"true"`
+	LastScheduledAt  *time.Time `json:"last_scheduled_at,omitempty" example:"2025-07-17T02:00:00Z"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"2025-07-17T21:20:48Z"`
+}
+
+// IndexedPathsResponse lists the paths inside the JSON fields of the logs
+// that are indexed in OpenSearch for a tenant
+type IndexedPathsResponse struct {
+	TenantID string                `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Paths    []IndexedPathResponse `json:"paths"`
+}
+
+type IndexedPathResponse struct {
+	Path string `json:"path" example:"metadata.request_id"`
+	Type string `json:"type" example:"keyword"`
+}
+
+// LegalHoldResponse represents a legal hold on the logs of a tenant
+type LegalHoldResponse struct {
+	ID           string     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Reason       string     `json:"reason" example:"Litigation 2025-CV-0042"`
+	UserID       string     `json:"user_id,omitempty" example:"123456"`
+	ResourceType string     `json:"resource_type,omitempty" example:"user"`
+	ResourceID   string     `json:"resource_id,omitempty" example:"user123"`
+	StartTime    *time.Time `json:"start_time,omitempty" example:"2025-01-01T00:00:00Z"`
+	EndTime      *time.Time `json:"end_time,omitempty" example:"2025-06-30T23:59:59Z"`
+	LogIDs       []string   `json:"log_ids,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Active       bool       `json:"active" example:"true"`
+	CreatedBy    string     `json:"created_by,omitempty" example:"123456"`
+	CreatedAt    time.Time  `json:"created_at" example:"2025-07-17T21:20:48Z"`
+	ReleasedBy   string     `json:"released_by,omitempty" example:"123456"`
+	ReleasedAt   *time.Time `json:"released_at,omitempty" example:"2025-08-01T09:00:00Z"`
 }
 
 // GetAuditLogStatsResponse represents statistics about audit logs
@@ -39,3 +237,46 @@ type GetAuditLogStatsResponse struct {
 	SeverityCounts map[string]int64 `json:"severity_counts" example:"INFO:80,WARNING:15,ERROR:5"`
 	ResourceCounts map[string]int64 `json:"resource_counts" example:"user:60,order:40"`
 }
+
+// TimeSeriesResponse holds the number of logs in each bucket per group, the
+// counts of each series are in the order of the buckets
+type TimeSeriesResponse struct {
+	Interval  string                     `json:"interval" example:"1h0m0s"`
+	GroupBy   []string                   `json:"group_by" example:"severity"`
+	StartTime time.Time                  `json:"start_time" example:"2024-03-20T00:00:00Z"`
+	EndTime   time.Time                  `json:"end_time" example:"2024-03-21T00:00:00Z"`
+	Buckets   []time.Time                `json:"buckets" example:"2024-03-20T00:00:00Z"`
+	Series    []TimeSeriesSeriesResponse `json:"series"`
+}
+
+// TimeSeriesSeriesResponse is the series of a group of values of the
+// dimensions, the long tail is folded into the group of "other" values
+type TimeSeriesSeriesResponse struct {
+	Group  map[string]string `json:"group" example:"severity:ERROR"`
+	Total  int64             `json:"total" example:"120"`
+	Counts []int64           `json:"counts" example:"10,0,110"`
+}
+
+// ChainVerificationResponse represents the result of walking a tenant's hash chain
+type ChainVerificationResponse struct {
+	TenantID        string              `json:"tenant_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	StartTime       time.Time           `json:"start_time" example:"2025-07-17T00:00:00Z"`
+	EndTime         time.Time           `json:"end_time" example:"2025-07-17T23:59:59Z"`
+	Valid           bool                `json:"valid" example:"true"`
+	CheckedLogs     int64               `json:"checked_logs" example:"100"`
+	FirstSeq        int64               `json:"first_seq" example:"1"`
+	LastSeq         int64               `json:"last_seq" example:"100"`
+	Anchored        bool                `json:"anchored" example:"true"`
+	AnchorPrevHash  string              `json:"anchor_prev_hash" example:""`
+	LastHash        string              `json:"last_hash" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+	FirstBrokenLink *ChainBreakResponse `json:"first_broken_link,omitempty"`
+}
+
+// ChainBreakResponse represents the first link of a hash chain that failed verification
+type ChainBreakResponse struct {
+	LogID    string `json:"log_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ChainSeq int64  `json:"chain_seq" example:"42"`
+	Reason   string `json:"reason" example:"hash_mismatch"`
+	Expected string `json:"expected" example:"60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"`
+	Actual   string `json:"actual" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
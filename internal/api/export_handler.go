@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/buiminhduc234/audit-log-api/internal/service/export"
+)
+
+//go:generate mockery --name ExportJobService --output ../mocks
+type ExportJobService interface {
+	Create(ctx context.Context, req dto.CreateExportJobRequest) (*dto.ExportJobResponse, error)
+	Get(ctx context.Context, id string) (*dto.ExportJobResponse, error)
+}
+
+type ExportHandler struct {
+	*BaseHandler
+	service ExportJobService
+}
+
+func NewExportHandler(service ExportJobService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// CreateExport Create an asynchronous export job
+// @Summary Create export job
+// @Description Enqueues an export of the logs matching the filters. The file is written to S3 and can be downloaded from the job once it has completed.
+// @Tags    exports
+// @Accept  json
+// @Produce json
+// @Param   body body dto.CreateExportJobRequest true "Export job object"
+// @Success 202 {object} dto.ExportJobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	var req dto.CreateExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+	if req.StartTime.After(req.EndTime) {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: "start_time must be before end_time"})
+		return
+	}
+
+	fields, err := export.ParseFields(strings.Join(req.Fields, ","))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+	req.Fields = fields
+
+	job, err := h.service.Create(h.RequestCtx(c), req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExport Get an export job
+// @Summary Get export job
+// @Description Returns the status and row count of an export job, with a pre-signed download URL once it has completed
+// @Tags    exports
+// @Produce json
+// @Param   id path string true "Export job ID"
+// @Success 200 {object} dto.ExportJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	job, err := h.service.Get(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrExportJobNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
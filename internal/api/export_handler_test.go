@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExportHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockExportJobService
+	handler     *ExportHandler
+}
+
+type MockExportJobService struct {
+	mock.Mock
+}
+
+func (m *MockExportJobService) Create(ctx context.Context, req dto.CreateExportJobRequest) (*dto.ExportJobResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportJobResponse), args.Error(1)
+}
+
+func (m *MockExportJobService) Get(ctx context.Context, id string) (*dto.ExportJobResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExportJobResponse), args.Error(1)
+}
+
+func (s *ExportHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockExportJobService)
+	s.handler = NewExportHandler(s.mockService)
+
+	// Setup routes
+	s.router.POST("/exports", s.handler.CreateExport)
+	s.router.GET("/exports/:id", s.handler.GetExport)
+}
+
+func TestExportHandler(t *testing.T) {
+	suite.Run(t, new(ExportHandlerTestSuite))
+}
+
+func (s *ExportHandlerTestSuite) TestCreateExport_Success() {
+	// Arrange
+	expectedJob := &dto.ExportJobResponse{
+		ID:     "job1",
+		Status: "PENDING",
+		Format: "parquet",
+		Fields: []string{"id", "timestamp"},
+	}
+
+	s.mockService.On("Create", mock.Anything, mock.MatchedBy(func(req dto.CreateExportJobRequest) bool {
+		return req.Format == "parquet" && len(req.Fields) == 2
+	})).Return(expectedJob, nil)
+
+	body := `{"format":"parquet","fields":["id","timestamp"],"start_time":"2024-01-01T00:00:00Z","end_time":"2024-01-31T00:00:00Z"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/exports", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.ExportJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("job1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ExportHandlerTestSuite) TestCreateExport_InvalidFormat() {
+	// Arrange
+	body := `{"format":"xml","start_time":"2024-01-01T00:00:00Z","end_time":"2024-01-31T00:00:00Z"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/exports", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *ExportHandlerTestSuite) TestGetExport_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "missing").Return(nil, service.ErrExportJobNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/exports/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
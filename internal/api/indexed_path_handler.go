@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name IndexedPathService --output ../mocks
+type IndexedPathService interface {
+	Get(ctx context.Context, tenantID string) (*dto.IndexedPathsResponse, error)
+	Put(ctx context.Context, tenantID string, req dto.IndexedPathsRequest) (*dto.IndexedPathsResponse, error)
+}
+
+type IndexedPathHandler struct {
+	*BaseHandler
+	service IndexedPathService
+}
+
+func NewIndexedPathHandler(service IndexedPathService) *IndexedPathHandler {
+	return &IndexedPathHandler{service: service}
+}
+
+// GetIndexedPaths godoc
+// @Summary Get the indexed paths of a tenant
+// @Description Returns the paths inside metadata, before_state and after_state that are indexed in OpenSearch for the tenant. Filters on other paths are answered by PostgreSQL.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.IndexedPathsResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/indexed-paths [get]
+func (h *IndexedPathHandler) GetIndexedPaths(c *gin.Context) {
+	paths, err := h.service.Get(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrTenantNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, paths)
+}
+
+// PutIndexedPaths godoc
+// @Summary Set the indexed paths of a tenant
+// @Description Replaces the paths inside metadata, before_state and after_state that are indexed in OpenSearch for the tenant. Only declared paths are mapped, which keeps arbitrary payloads from exhausting the field limit of the indices. A mapped path keeps its type, redeclaring it with another type is a conflict.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param body body dto.IndexedPathsRequest true "Indexed paths"
+// @Success 200 {object} dto.IndexedPathsResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/indexed-paths [put]
+func (h *IndexedPathHandler) PutIndexedPaths(c *gin.Context) {
+	var req dto.IndexedPathsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	paths, err := h.service.Put(h.RequestCtx(c), c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidIndexedPath):
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		case errors.Is(err, service.ErrTenantNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		case errors.Is(err, domain.ErrIndexedPathConflict):
+			c.JSON(http.StatusConflict, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, paths)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexedPathHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockIndexedPathService
+	handler     *IndexedPathHandler
+}
+
+type MockIndexedPathService struct {
+	mock.Mock
+}
+
+func (m *MockIndexedPathService) Get(ctx context.Context, tenantID string) (*dto.IndexedPathsResponse, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IndexedPathsResponse), args.Error(1)
+}
+
+func (m *MockIndexedPathService) Put(ctx context.Context, tenantID string, req dto.IndexedPathsRequest) (*dto.IndexedPathsResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IndexedPathsResponse), args.Error(1)
+}
+
+func (s *IndexedPathHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockIndexedPathService)
+	s.handler = NewIndexedPathHandler(s.mockService)
+
+	// Setup routes
+	s.router.GET("/tenants/:id/indexed-paths", s.handler.GetIndexedPaths)
+	s.router.PUT("/tenants/:id/indexed-paths", s.handler.PutIndexedPaths)
+}
+
+func TestIndexedPathHandler(t *testing.T) {
+	suite.Run(t, new(IndexedPathHandlerTestSuite))
+}
+
+func (s *IndexedPathHandlerTestSuite) TestPutIndexedPaths_Success() {
+	// Arrange
+	expected := &dto.IndexedPathsResponse{
+		TenantID: "tenant1",
+		Paths:    []dto.IndexedPathResponse{{Path: "metadata.request_id", Type: "keyword"}},
+	}
+	s.mockService.On("Put", mock.Anything, "tenant1", mock.MatchedBy(func(req dto.IndexedPathsRequest) bool {
+		return len(req.Paths) == 1 && req.Paths[0].Path == "metadata.request_id"
+	})).Return(expected, nil)
+
+	body := `{"paths":[{"path":"metadata.request_id"}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/indexed-paths", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.IndexedPathsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal(expected.Paths, response.Paths)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *IndexedPathHandlerTestSuite) TestPutIndexedPaths_InvalidType() {
+	// Arrange
+	body := `{"paths":[{"path":"metadata.request_id","type":"object"}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/indexed-paths", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *IndexedPathHandlerTestSuite) TestPutIndexedPaths_Conflict() {
+	// Arrange
+	err := fmt.Errorf("failed to map indexed paths: %w", domain.ErrIndexedPathConflict)
+	s.mockService.On("Put", mock.Anything, "tenant1", mock.Anything).Return(nil, err)
+
+	body := `{"paths":[{"path":"metadata.count","type":"long"}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/indexed-paths", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *IndexedPathHandlerTestSuite) TestGetIndexedPaths_TenantNotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1").Return(nil, service.ErrTenantNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/tenants/tenant1/indexed-paths", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name LegalHoldService --output ../mocks
+type LegalHoldService interface {
+	Create(ctx context.Context, req dto.CreateLegalHoldRequest) (*dto.LegalHoldResponse, error)
+	List(ctx context.Context, includeReleased bool) ([]dto.LegalHoldResponse, error)
+	Release(ctx context.Context, id string) (*dto.LegalHoldResponse, error)
+}
+
+type LegalHoldHandler struct {
+	*BaseHandler
+	service LegalHoldService
+}
+
+func NewLegalHoldHandler(service LegalHoldService) *LegalHoldHandler {
+	return &LegalHoldHandler{service: service}
+}
+
+// CreateLegalHold Place a legal hold
+// @Summary Create legal hold
+// @Description Keeps the matching logs from being deleted by cleanups until the hold is released. A hold either lists log_ids or filters by user, resource and time range. The hold is recorded in the audit log.
+// @Tags    legal_holds
+// @Accept  json
+// @Produce json
+// @Param   body body dto.CreateLegalHoldRequest true "Legal hold object"
+// @Success 201 {object} dto.LegalHoldResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /legal-holds [post]
+func (h *LegalHoldHandler) CreateLegalHold(c *gin.Context) {
+	var req dto.CreateLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	hold, err := h.service.Create(h.RequestCtx(c), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLegalHold) {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// ListLegalHolds List legal holds
+// @Summary List legal holds
+// @Description Returns the legal holds of the tenant, newest first. Released holds are only listed when include_released is set.
+// @Tags    legal_holds
+// @Produce json
+// @Param   include_released query bool false "Also list released holds"
+// @Success 200 {array} dto.LegalHoldResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /legal-holds [get]
+func (h *LegalHoldHandler) ListLegalHolds(c *gin.Context) {
+	var includeReleased bool
+	if v := c.Query("include_released"); v != "" {
+		var err error
+		if includeReleased, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: "include_released must be a boolean"})
+			return
+		}
+	}
+
+	holds, err := h.service.List(h.RequestCtx(c), includeReleased)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+// ReleaseLegalHold Release a legal hold
+// @Summary Release legal hold
+// @Description Lifts the hold, later cleanups may delete the logs it held. The release is recorded in the audit log.
+// @Tags    legal_holds
+// @Produce json
+// @Param   id path string true "Legal hold ID"
+// @Success 200 {object} dto.LegalHoldResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /legal-holds/{id}/release [post]
+func (h *LegalHoldHandler) ReleaseLegalHold(c *gin.Context) {
+	hold, err := h.service.Release(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLegalHoldNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		case errors.Is(err, service.ErrLegalHoldReleased):
+			c.JSON(http.StatusConflict, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type LegalHoldHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockLegalHoldService
+	handler     *LegalHoldHandler
+}
+
+type MockLegalHoldService struct {
+	mock.Mock
+}
+
+func (m *MockLegalHoldService) Create(ctx context.Context, req dto.CreateLegalHoldRequest) (*dto.LegalHoldResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LegalHoldResponse), args.Error(1)
+}
+
+func (m *MockLegalHoldService) List(ctx context.Context, includeReleased bool) ([]dto.LegalHoldResponse, error) {
+	args := m.Called(ctx, includeReleased)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.LegalHoldResponse), args.Error(1)
+}
+
+func (m *MockLegalHoldService) Release(ctx context.Context, id string) (*dto.LegalHoldResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.LegalHoldResponse), args.Error(1)
+}
+
+func (s *LegalHoldHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockLegalHoldService)
+	s.handler = NewLegalHoldHandler(s.mockService)
+
+	// Setup routes
+	s.router.POST("/legal-holds", s.handler.CreateLegalHold)
+	s.router.GET("/legal-holds", s.handler.ListLegalHolds)
+	s.router.POST("/legal-holds/:id/release", s.handler.ReleaseLegalHold)
+}
+
+func TestLegalHoldHandler(t *testing.T) {
+	suite.Run(t, new(LegalHoldHandlerTestSuite))
+}
+
+func (s *LegalHoldHandlerTestSuite) TestCreateLegalHold_Success() {
+	// Arrange
+	expected := &dto.LegalHoldResponse{ID: "hold1", Reason: "Litigation", UserID: "user1", Active: true}
+	s.mockService.On("Create", mock.Anything, mock.MatchedBy(func(req dto.CreateLegalHoldRequest) bool {
+		return req.Reason == "Litigation" && req.UserID == "user1"
+	})).Return(expected, nil)
+
+	body := `{"reason":"Litigation","user_id":"user1"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/legal-holds", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusCreated, w.Code)
+	var response dto.LegalHoldResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("hold1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *LegalHoldHandlerTestSuite) TestCreateLegalHold_InvalidLogID() {
+	// Arrange
+	body := `{"reason":"Litigation","log_ids":["not-a-uuid"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/legal-holds", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *LegalHoldHandlerTestSuite) TestListLegalHolds_IncludeReleased() {
+	// Arrange
+	s.mockService.On("List", mock.Anything, true).Return([]dto.LegalHoldResponse{{ID: "hold1"}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/legal-holds?include_released=true", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *LegalHoldHandlerTestSuite) TestReleaseLegalHold_AlreadyReleased() {
+	// Arrange
+	s.mockService.On("Release", mock.Anything, "hold1").Return(nil, service.ErrLegalHoldReleased)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/legal-holds/hold1/release", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name ReindexService --output ../mocks
+type ReindexService interface {
+	Create(ctx context.Context, req dto.CreateReindexJobRequest) (*dto.ReindexJobResponse, error)
+	Get(ctx context.Context, id string) (*dto.ReindexJobResponse, error)
+	Resume(ctx context.Context, id string) (*dto.ReindexJobResponse, error)
+}
+
+type ReindexHandler struct {
+	*BaseHandler
+	service ReindexService
+}
+
+func NewReindexHandler(service ReindexService) *ReindexHandler {
+	return &ReindexHandler{service: service}
+}
+
+// CreateReindex Reconcile OpenSearch against PostgreSQL
+// @Summary Create reindex job
+// @Description Enqueues a comparison of the per-day log counts of a tenant, or of every tenant, in PostgreSQL and OpenSearch. Days with logs missing from OpenSearch are compared by ID and the missing logs are indexed, unless dry_run is set. compare_ids compares every day by ID.
+// @Tags    admin
+// @Accept  json
+// @Produce json
+// @Param   body body dto.CreateReindexJobRequest true "Reindex job object"
+// @Success 202 {object} dto.ReindexJobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/reindex [post]
+func (h *ReindexHandler) CreateReindex(c *gin.Context) {
+	var req dto.CreateReindexJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	job, err := h.service.Create(h.RequestCtx(c), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReindexRange):
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		case errors.Is(err, service.ErrTenantNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetReindex Get a reindex job
+// @Summary Get reindex job
+// @Description Returns the progress of a reindex job and the days on which OpenSearch drifted from PostgreSQL
+// @Tags    admin
+// @Produce json
+// @Param   id path string true "Reindex job ID"
+// @Success 200 {object} dto.ReindexJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/reindex/{id} [get]
+func (h *ReindexHandler) GetReindex(c *gin.Context) {
+	job, err := h.service.Get(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrReindexJobNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ResumeReindex Resume a reindex job
+// @Summary Resume reindex job
+// @Description Enqueues a failed or interrupted reindex job again. It continues after the last day it completed. A running job can be resumed once it has not made progress for five minutes.
+// @Tags    admin
+// @Produce json
+// @Param   id path string true "Reindex job ID"
+// @Success 202 {object} dto.ReindexJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /admin/reindex/{id}/resume [post]
+func (h *ReindexHandler) ResumeReindex(c *gin.Context) {
+	job, err := h.service.Resume(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReindexJobNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		case errors.Is(err, service.ErrReindexJobCompleted), errors.Is(err, service.ErrReindexJobRunning):
+			c.JSON(http.StatusConflict, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReindexHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockReindexService
+	handler     *ReindexHandler
+}
+
+type MockReindexService struct {
+	mock.Mock
+}
+
+func (m *MockReindexService) Create(ctx context.Context, req dto.CreateReindexJobRequest) (*dto.ReindexJobResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReindexJobResponse), args.Error(1)
+}
+
+func (m *MockReindexService) Get(ctx context.Context, id string) (*dto.ReindexJobResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReindexJobResponse), args.Error(1)
+}
+
+func (m *MockReindexService) Resume(ctx context.Context, id string) (*dto.ReindexJobResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReindexJobResponse), args.Error(1)
+}
+
+func (s *ReindexHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockReindexService)
+	s.handler = NewReindexHandler(s.mockService)
+
+	// Setup routes
+	s.router.POST("/admin/reindex", s.handler.CreateReindex)
+	s.router.GET("/admin/reindex/:id", s.handler.GetReindex)
+	s.router.POST("/admin/reindex/:id/resume", s.handler.ResumeReindex)
+}
+
+func TestReindexHandler(t *testing.T) {
+	suite.Run(t, new(ReindexHandlerTestSuite))
+}
+
+func (s *ReindexHandlerTestSuite) TestCreateReindex_Success() {
+	// Arrange
+	req := dto.CreateReindexJobRequest{StartDate: "2025-07-01", EndDate: "2025-07-31", DryRun: true}
+	expectedJob := &dto.ReindexJobResponse{ID: "job1", StartDate: "2025-07-01", EndDate: "2025-07-31", DryRun: true, Status: "PENDING"}
+
+	s.mockService.On("Create", mock.Anything, req).Return(expectedJob, nil)
+
+	body := `{"start_date":"2025-07-01","end_date":"2025-07-31","dry_run":true}`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest(http.MethodPost, "/admin/reindex", bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, httpReq)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.ReindexJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("job1", response.ID)
+	s.True(response.DryRun)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ReindexHandlerTestSuite) TestCreateReindex_InvalidDate() {
+	// Arrange
+	body := `{"start_date":"07/01/2025","end_date":"2025-07-31"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/reindex", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *ReindexHandlerTestSuite) TestCreateReindex_InvalidRange() {
+	// Arrange
+	s.mockService.On("Create", mock.Anything, mock.Anything).Return(nil, service.ErrInvalidReindexRange)
+
+	body := `{"start_date":"2025-08-01","end_date":"2025-07-31"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/reindex", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *ReindexHandlerTestSuite) TestGetReindex_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "missing").Return(nil, service.ErrReindexJobNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/reindex/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *ReindexHandlerTestSuite) TestResumeReindex_Completed() {
+	// Arrange
+	s.mockService.On("Resume", mock.Anything, "job1").Return(nil, service.ErrReindexJobCompleted)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/admin/reindex/job1/resume", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+}
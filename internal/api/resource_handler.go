@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	contextutils "github.com/buiminhduc234/audit-log-api/internal/utils"
+	"github.com/buiminhduc234/audit-log-api/pkg/utils"
+)
+
+//go:generate mockery --name ResourceHistoryService --output ../mocks
+type ResourceHistoryService interface {
+	History(ctx context.Context, tenantID, resourceType, resourceID string, asOf time.Time) (*dto.ResourceHistoryResponse, error)
+}
+
+type ResourceHandler struct {
+	*BaseHandler
+	service ResourceHistoryService
+}
+
+func NewResourceHandler(service ResourceHistoryService) *ResourceHandler {
+	return &ResourceHandler{service: service}
+}
+
+// GetHistory godoc
+// @Summary Get the history of a resource
+// @Description Returns the audit logs of a resource as a timeline, oldest first, merged from the live logs and the S3 archives, each item is marked with its source. The state of the resource is replayed from the after_state snapshots of the timeline: a snapshot replaces the state and a DELETE clears it. With as_of, the timeline stops at that instant and the state is the one the resource had then. Only the latest 10000 events are returned, truncated tells when older ones were left out.
+// @Tags    resources
+// @Produce json
+// @Param   type path string true "Resource type" example:"user"
+// @Param   id path string true "Resource ID" example:"user123"
+// @Param   as_of query string false "Reconstruct the state at this instant (RFC3339 or YYYY-MM-DD for the end of the day)" example:"2024-03-20T12:00:00Z"
+// @Success 200 {object} dto.ResourceHistoryResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /resources/{type}/{id}/history [get]
+func (h *ResourceHandler) GetHistory(c *gin.Context) {
+	tenantID := c.GetString(string(contextutils.TenantIDKey))
+	if tenantID == "" {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: "tenant_id is required"})
+		return
+	}
+
+	var asOf time.Time
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		t, err := utils.ParseUserTime(asOfStr, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.Error{Error: "Invalid as_of format: " + err.Error()})
+			return
+		}
+		asOf = t
+	}
+
+	history, err := h.service.History(h.RequestCtx(c), tenantID, c.Param("type"), c.Param("id"), asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
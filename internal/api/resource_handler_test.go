@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	contextutils "github.com/buiminhduc234/audit-log-api/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResourceHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockResourceHistoryService
+	handler     *ResourceHandler
+}
+
+type MockResourceHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockResourceHistoryService) History(ctx context.Context, tenantID, resourceType, resourceID string, asOf time.Time) (*dto.ResourceHistoryResponse, error) {
+	args := m.Called(ctx, tenantID, resourceType, resourceID, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ResourceHistoryResponse), args.Error(1)
+}
+
+func (s *ResourceHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockResourceHistoryService)
+	s.handler = NewResourceHandler(s.mockService)
+
+	// Setup routes
+	s.router.Use(func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	s.router.GET("/resources/:type/:id/history", s.handler.GetHistory)
+}
+
+func TestResourceHandler(t *testing.T) {
+	suite.Run(t, new(ResourceHandlerTestSuite))
+}
+
+func (s *ResourceHandlerTestSuite) TestGetHistory_AsOf() {
+	// Arrange
+	asOf := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	expected := &dto.ResourceHistoryResponse{
+		ResourceType: "user",
+		ResourceID:   "user123",
+		AsOf:         &asOf,
+		State:        json.RawMessage(`{"name":"Alice"}`),
+		StateLogID:   "log1",
+		Events:       []dto.AuditLogResponse{{ID: "log1", Action: "CREATE", Source: dto.LogSourceLive}},
+	}
+	s.mockService.On("History", mock.Anything, "tenant1", "user", "user123", mock.MatchedBy(func(t time.Time) bool {
+		return t.Equal(asOf)
+	})).Return(expected, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/resources/user/user123/history?as_of=2024-03-20T12:00:00Z", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.ResourceHistoryResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Equal("log1", response.StateLogID)
+	s.JSONEq(`{"name":"Alice"}`, string(response.State))
+	s.Require().Len(response.Events, 1)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ResourceHandlerTestSuite) TestGetHistory_InvalidAsOf() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/resources/user/user123/history?as_of=yesterday", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "History")
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name RestoreService --output ../mocks
+type RestoreService interface {
+	Create(ctx context.Context, req dto.CreateRestoreJobRequest) (*dto.RestoreJobResponse, error)
+	Get(ctx context.Context, id string) (*dto.RestoreJobResponse, error)
+	ListArchives(ctx context.Context) ([]dto.ArchiveResponse, error)
+}
+
+type RestoreHandler struct {
+	*BaseHandler
+	service RestoreService
+}
+
+func NewRestoreHandler(service RestoreService) *RestoreHandler {
+	return &RestoreHandler{service: service}
+}
+
+// CreateRestore Restore an archive of logs
+// @Summary Create restore job
+// @Description Enqueues a restore of an S3 archive back into PostgreSQL and OpenSearch. Logs that are already stored are skipped.
+// @Tags    restores
+// @Accept  json
+// @Produce json
+// @Param   body body dto.CreateRestoreJobRequest true "Restore job object"
+// @Success 202 {object} dto.RestoreJobResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /restores [post]
+func (h *RestoreHandler) CreateRestore(c *gin.Context) {
+	var req dto.CreateRestoreJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	job, err := h.service.Create(h.RequestCtx(c), req)
+	if err != nil {
+		if errors.Is(err, service.ErrArchiveNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetRestore Get a restore job
+// @Summary Get restore job
+// @Description Returns the progress of a restore job, and its completion report once it has finished
+// @Tags    restores
+// @Produce json
+// @Param   id path string true "Restore job ID"
+// @Success 200 {object} dto.RestoreJobResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /restores/{id} [get]
+func (h *RestoreHandler) GetRestore(c *gin.Context) {
+	job, err := h.service.Get(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrRestoreJobNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ListArchives List archives of logs
+// @Summary List archives
+// @Description Lists the S3 archives of the tenant that can be restored
+// @Tags    restores
+// @Produce json
+// @Success 200 {array} dto.ArchiveResponse
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /restores/archives [get]
+func (h *RestoreHandler) ListArchives(c *gin.Context) {
+	archives, err := h.service.ListArchives(h.RequestCtx(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, archives)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RestoreHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockRestoreService
+	handler     *RestoreHandler
+}
+
+type MockRestoreService struct {
+	mock.Mock
+}
+
+func (m *MockRestoreService) Create(ctx context.Context, req dto.CreateRestoreJobRequest) (*dto.RestoreJobResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RestoreJobResponse), args.Error(1)
+}
+
+func (m *MockRestoreService) Get(ctx context.Context, id string) (*dto.RestoreJobResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RestoreJobResponse), args.Error(1)
+}
+
+func (m *MockRestoreService) ListArchives(ctx context.Context) ([]dto.ArchiveResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.ArchiveResponse), args.Error(1)
+}
+
+func (s *RestoreHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockRestoreService)
+	s.handler = NewRestoreHandler(s.mockService)
+
+	// Setup routes
+	s.router.POST("/restores", s.handler.CreateRestore)
+	s.router.GET("/restores/archives", s.handler.ListArchives)
+	s.router.GET("/restores/:id", s.handler.GetRestore)
+}
+
+func TestRestoreHandler(t *testing.T) {
+	suite.Run(t, new(RestoreHandlerTestSuite))
+}
+
+func (s *RestoreHandlerTestSuite) TestCreateRestore_Success() {
+	// Arrange
+	expectedJob := &dto.RestoreJobResponse{
+		ID:         "job1",
+		ArchiveKey: "audit-logs/tenant1/archive.json",
+		Status:     "PENDING",
+	}
+
+	s.mockService.On("Create", mock.Anything, dto.CreateRestoreJobRequest{ArchiveKey: "audit-logs/tenant1/archive.json"}).
+		Return(expectedJob, nil)
+
+	body := `{"archive_key":"audit-logs/tenant1/archive.json"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/restores", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusAccepted, w.Code)
+	var response dto.RestoreJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("job1", response.ID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RestoreHandlerTestSuite) TestCreateRestore_MissingArchiveKey() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/restores", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *RestoreHandlerTestSuite) TestCreateRestore_ArchiveNotFound() {
+	// Arrange
+	s.mockService.On("Create", mock.Anything, mock.Anything).Return(nil, service.ErrArchiveNotFound)
+
+	body := `{"archive_key":"audit-logs/tenant2/archive.json"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/restores", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *RestoreHandlerTestSuite) TestGetRestore_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "missing").Return(nil, service.ErrRestoreJobNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/restores/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RestoreHandlerTestSuite) TestListArchives_Success() {
+	// Arrange
+	archives := []dto.ArchiveResponse{{Key: "audit-logs/tenant1/archive.json", Size: 1024}}
+	s.mockService.On("ListArchives", mock.Anything).Return(archives, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/restores/archives", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response []dto.ArchiveResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Len(response, 1)
+}
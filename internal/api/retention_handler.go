@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name RetentionService --output ../mocks
+type RetentionService interface {
+	Get(ctx context.Context, tenantID string) (*dto.RetentionPolicyResponse, error)
+	Put(ctx context.Context, tenantID string, req dto.RetentionPolicyRequest) (*dto.RetentionPolicyResponse, error)
+	Delete(ctx context.Context, tenantID string) error
+}
+
+type RetentionHandler struct {
+	*BaseHandler
+	service RetentionService
+}
+
+func NewRetentionHandler(service RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: service}
+}
+
+// GetRetentionPolicy godoc
+// @Summary Get the retention policy of a tenant
+// @Description Returns how long the logs of the tenant are kept before they are archived and deleted
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} dto.RetentionPolicyResponse
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/retention-policy [get]
+func (h *RetentionHandler) GetRetentionPolicy(c *gin.Context) {
+	policy, err := h.service.Get(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrRetentionPolicyNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// PutRetentionPolicy godoc
+// @Summary Set the retention policy of a tenant
+// @Description Creates or replaces the retention policy of the tenant. The retention scheduler archives logs older than archive_after_days and the cleanup worker deletes logs older than delete_after_days, except for the kept severities. A delete_after_days of 0 never deletes logs.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param body body dto.RetentionPolicyRequest true "Retention policy"
+// @Success 200 {object} dto.RetentionPolicyResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/retention-policy [put]
+func (h *RetentionHandler) PutRetentionPolicy(c *gin.Context) {
+	var req dto.RetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	policy, err := h.service.Put(h.RequestCtx(c), c.Param("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRetentionPolicy):
+			c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		case errors.Is(err, service.ErrTenantNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeleteRetentionPolicy godoc
+// @Summary Delete the retention policy of a tenant
+// @Description Removes the retention policy, the logs of the tenant are then only archived and cleaned up on request
+// @Tags tenants
+// @Param id path string true "Tenant ID"
+// @Success 204
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id}/retention-policy [delete]
+func (h *RetentionHandler) DeleteRetentionPolicy(c *gin.Context) {
+	if err := h.service.Delete(h.RequestCtx(c), c.Param("id")); err != nil {
+		if errors.Is(err, service.ErrRetentionPolicyNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RetentionHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockRetentionService
+	handler     *RetentionHandler
+}
+
+type MockRetentionService struct {
+	mock.Mock
+}
+
+func (m *MockRetentionService) Get(ctx context.Context, tenantID string) (*dto.RetentionPolicyResponse, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RetentionPolicyResponse), args.Error(1)
+}
+
+func (m *MockRetentionService) Put(ctx context.Context, tenantID string, req dto.RetentionPolicyRequest) (*dto.RetentionPolicyResponse, error) {
+	args := m.Called(ctx, tenantID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RetentionPolicyResponse), args.Error(1)
+}
+
+func (m *MockRetentionService) Delete(ctx context.Context, tenantID string) error {
+	args := m.Called(ctx, tenantID)
+	return args.Error(0)
+}
+
+func (s *RetentionHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockRetentionService)
+	s.handler = NewRetentionHandler(s.mockService)
+
+	// Setup routes
+	s.router.GET("/tenants/:id/retention-policy", s.handler.GetRetentionPolicy)
+	s.router.PUT("/tenants/:id/retention-policy", s.handler.PutRetentionPolicy)
+	s.router.DELETE("/tenants/:id/retention-policy", s.handler.DeleteRetentionPolicy)
+}
+
+func TestRetentionHandler(t *testing.T) {
+	suite.Run(t, new(RetentionHandlerTestSuite))
+}
+
+func (s *RetentionHandlerTestSuite) TestPutRetentionPolicy_Success() {
+	// Arrange
+	expected := &dto.RetentionPolicyResponse{
+		TenantID:         "tenant1",
+		ArchiveAfterDays: 30,
+		DeleteAfterDays:  365,
+		KeepSeverities:   []string{"CRITICAL"},
+		Enabled:          true,
+	}
+	s.mockService.On("Put", mock.Anything, "tenant1", mock.MatchedBy(func(req dto.RetentionPolicyRequest) bool {
+		return req.ArchiveAfterDays == 30 && req.DeleteAfterDays == 365
+	})).Return(expected, nil)
+
+	body := `{"archive_after_days":30,"delete_after_days":365,"keep_severities":["CRITICAL"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/retention-policy", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.RetentionPolicyResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal(365, response.DeleteAfterDays)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RetentionHandlerTestSuite) TestPutRetentionPolicy_InvalidSeverity() {
+	// Arrange
+	body := `{"archive_after_days":30,"keep_severities":["DEBUG"]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/retention-policy", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *RetentionHandlerTestSuite) TestPutRetentionPolicy_DeleteBeforeArchive() {
+	// Arrange
+	s.mockService.On("Put", mock.Anything, "tenant1", mock.Anything).Return(nil, service.ErrInvalidRetentionPolicy)
+
+	body := `{"archive_after_days":90,"delete_after_days":30}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1/retention-policy", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RetentionHandlerTestSuite) TestGetRetentionPolicy_NotFound() {
+	// Arrange
+	s.mockService.On("Get", mock.Anything, "tenant1").Return(nil, service.ErrRetentionPolicyNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/tenants/tenant1/retention-policy", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
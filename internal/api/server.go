@@ -10,24 +10,55 @@ import (
 )
 
 type Server struct {
-	tenant    *TenantHandler
-	auditLog  *AuditLogHandler
-	websocket *WebSocketHandler
-	auth      *middleware.AuthMiddleware
+	tenant      *TenantHandler
+	tenantPurge *TenantPurgeHandler
+	auditLog    *AuditLogHandler
+	websocket   *WebSocketHandler
+	export      *ExportHandler
+	restore     *RestoreHandler
+	retention   *RetentionHandler
+	indexedPath *IndexedPathHandler
+	resource    *ResourceHandler
+	session     *SessionHandler
+	legalHold   *LegalHoldHandler
+	reindex     *ReindexHandler
+	dlq         *DeadLetterHandler
+	auth        *middleware.AuthMiddleware
+	rateLimit   *middleware.RateLimitMiddleware
 }
 
 func NewServer(
 	tenantService *service.TenantService,
+	tenantPurgeService *service.TenantPurgeService,
 	auditLogService *service.AuditLogService,
+	exportJobService *service.ExportJobService,
+	restoreService *service.RestoreService,
+	retentionService *service.RetentionService,
+	indexedPathService *service.IndexedPathService,
+	legalHoldService *service.LegalHoldService,
+	reindexService *service.ReindexService,
+	deadLetterService *service.DeadLetterService,
 	auth *middleware.AuthMiddleware,
+	rateLimit *middleware.RateLimitMiddleware,
 	logger *logger.Logger,
 	pubsub *pubsub.RedisPubSub,
 ) *Server {
 	return &Server{
-		tenant:    NewTenantHandler(tenantService),
-		auditLog:  NewAuditLogHandler(auditLogService),
-		websocket: NewWebSocketHandler(auditLogService, logger, pubsub),
-		auth:      auth,
+		tenant:      NewTenantHandler(tenantService),
+		tenantPurge: NewTenantPurgeHandler(tenantPurgeService),
+		auditLog:    NewAuditLogHandler(auditLogService),
+		websocket:   NewWebSocketHandler(auditLogService, logger, pubsub),
+		export:      NewExportHandler(exportJobService),
+		restore:     NewRestoreHandler(restoreService),
+		retention:   NewRetentionHandler(retentionService),
+		indexedPath: NewIndexedPathHandler(indexedPathService),
+		resource:    NewResourceHandler(auditLogService),
+		session:     NewSessionHandler(auditLogService),
+		legalHold:   NewLegalHoldHandler(legalHoldService),
+		reindex:     NewReindexHandler(reindexService),
+		dlq:         NewDeadLetterHandler(deadLetterService),
+		auth:        auth,
+		rateLimit:   rateLimit,
 	}
 }
 
@@ -37,19 +68,74 @@ func (s *Server) SetupRoutes(api *gin.RouterGroup) {
 		{
 			tenants.POST("", s.tenant.CreateTenant)
 			tenants.GET("", s.tenant.ListTenants)
+			tenants.PUT("/:id", s.tenant.UpdateTenant)
+			tenants.DELETE("/:id", s.tenantPurge.PurgeTenant)
+			tenants.GET("/:id/retention-policy", s.retention.GetRetentionPolicy)
+			tenants.PUT("/:id/retention-policy", s.retention.PutRetentionPolicy)
+			tenants.DELETE("/:id/retention-policy", s.retention.DeleteRetentionPolicy)
+			tenants.GET("/:id/indexed-paths", s.indexedPath.GetIndexedPaths)
+			tenants.PUT("/:id/indexed-paths", s.indexedPath.PutIndexedPaths)
 		}
 
 		logs := api.Group("/logs", s.auth.JWTAuth(), s.auth.RequireRole("user"))
 		{
-			logs.POST("", s.auditLog.CreateLog)
+			logs.POST("", s.rateLimit.Limit(middleware.SingleEntry), s.auditLog.CreateLog)
 			logs.GET("", s.auditLog.ListLogs)
 			logs.GET("/:id", s.auditLog.GetLog)
 			logs.GET("/export", s.auditLog.ExportLogs)
 			logs.GET("/stats", s.auditLog.GetStats)
-			logs.POST("/bulk", s.auditLog.BulkCreateLogs)
+			logs.GET("/stats/timeseries", s.auditLog.GetTimeSeries)
+			logs.GET("/verify", s.auth.RequireRole("auditor"), s.auditLog.VerifyChain)
+			logs.POST("/bulk", s.rateLimit.Limit(middleware.BulkEntries), s.auditLog.BulkCreateLogs)
 			logs.DELETE("/cleanup", s.auth.RequireRole("auditor"), s.auditLog.Cleanup)
 			logs.GET("/stream", s.websocket.HandleWebSocket)
 		}
+
+		resources := api.Group("/resources", s.auth.JWTAuth(), s.auth.RequireRole("user"))
+		{
+			resources.GET("/:type/:id/history", s.resource.GetHistory)
+		}
+
+		users := api.Group("/users", s.auth.JWTAuth(), s.auth.RequireRole("user"))
+		{
+			users.GET("/:id/activity", s.session.GetUserActivity)
+		}
+
+		sessions := api.Group("/sessions", s.auth.JWTAuth(), s.auth.RequireRole("user"))
+		{
+			sessions.GET("/:id", s.session.GetSession)
+		}
+
+		exports := api.Group("/exports", s.auth.JWTAuth(), s.auth.RequireRole("user"))
+		{
+			exports.POST("", s.export.CreateExport)
+			exports.GET("/:id", s.export.GetExport)
+		}
+
+		restores := api.Group("/restores", s.auth.JWTAuth(), s.auth.RequireRole("auditor"))
+		{
+			restores.POST("", s.restore.CreateRestore)
+			restores.GET("/archives", s.restore.ListArchives)
+			restores.GET("/:id", s.restore.GetRestore)
+		}
+
+		legalHolds := api.Group("/legal-holds", s.auth.JWTAuth(), s.auth.RequireRole("auditor"))
+		{
+			legalHolds.POST("", s.legalHold.CreateLegalHold)
+			legalHolds.GET("", s.legalHold.ListLegalHolds)
+			legalHolds.POST("/:id/release", s.legalHold.ReleaseLegalHold)
+		}
+
+		admin := api.Group("/admin", s.auth.JWTAuth(), s.auth.RequireRole("admin"))
+		{
+			admin.POST("/reindex", s.reindex.CreateReindex)
+			admin.GET("/reindex/:id", s.reindex.GetReindex)
+			admin.POST("/reindex/:id/resume", s.reindex.ResumeReindex)
+			admin.GET("/dlq/:queue", s.dlq.ListDeadLetters)
+			admin.GET("/dlq/:queue/:id", s.dlq.GetDeadLetter)
+			admin.POST("/dlq/:queue/redrive", s.dlq.RedriveDeadLetters)
+			admin.DELETE("/dlq/:queue", s.dlq.PurgeDeadLetters)
+		}
 	}
 }
 
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name SessionService --output ../mocks
+type SessionService interface {
+	UserActivity(ctx context.Context, filter *domain.AuditLogFilter) (*dto.UserActivityResponse, error)
+	GetSession(ctx context.Context, filter *domain.AuditLogFilter) (*dto.SessionResponse, error)
+}
+
+type SessionHandler struct {
+	*BaseHandler
+	service SessionService
+}
+
+func NewSessionHandler(service SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+// GetUserActivity godoc
+// @Summary Get the activity of a user
+// @Description Groups the logs of a user in the time range by session ID, the sessions with the most recent events first. Each session comes with its start and end time, its distinct IP addresses and user agents, the number of logs per action and its first and last event. Logs without a session ID are left out. The other filters of listing logs apply as well.
+// @Tags    users
+// @Produce json
+// @Param   id path string true "User ID"
+// @Param   page_size query int false "Number of sessions, at most 500" default(20)
+// @Param   action query string false "Filter by action"
+// @Param   resource_type query string false "Filter by resource type"
+// @Param   ip_address query string false "Filter by IP address"
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Success 200 {object} dto.UserActivityResponse
+// @Failure 400 {object} dto.QueryError
+// @Failure 401 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /users/{id}/activity [get]
+func (h *SessionHandler) GetUserActivity(c *gin.Context) {
+	filter, err := getFilterFromQuery(c)
+	if err != nil {
+		filterError(c, err)
+		return
+	}
+	filter.UserID = c.Param("id")
+
+	activity, err := h.service.UserActivity(h.RequestCtx(c), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// GetSession godoc
+// @Summary Get a session
+// @Description Sums up the logs of a session in the time range as the activity of a user does and returns its latest 1000 events, oldest first. truncated tells when older events were left out.
+// @Tags    sessions
+// @Produce json
+// @Param   id path string true "Session ID"
+// @Param   start_time query string true "Filter by start time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T00:00:00Z"
+// @Param   end_time query string true "Filter by end time (RFC3339 or YYYY-MM-DD)" example:"2024-03-20T23:59:59Z"
+// @Success 200 {object} dto.SessionResponse
+// @Failure 400 {object} dto.QueryError
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /sessions/{id} [get]
+func (h *SessionHandler) GetSession(c *gin.Context) {
+	filter, err := getFilterFromQuery(c)
+	if err != nil {
+		filterError(c, err)
+		return
+	}
+	filter.SessionID = c.Param("id")
+
+	session, err := h.service.GetSession(h.RequestCtx(c), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
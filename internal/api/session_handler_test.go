@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	contextutils "github.com/buiminhduc234/audit-log-api/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SessionHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockSessionService
+	handler     *SessionHandler
+}
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) UserActivity(ctx context.Context, filter *domain.AuditLogFilter) (*dto.UserActivityResponse, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserActivityResponse), args.Error(1)
+}
+
+func (m *MockSessionService) GetSession(ctx context.Context, filter *domain.AuditLogFilter) (*dto.SessionResponse, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SessionResponse), args.Error(1)
+}
+
+func (s *SessionHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockSessionService)
+	s.handler = NewSessionHandler(s.mockService)
+
+	// Setup routes
+	s.router.Use(func(c *gin.Context) {
+		c.Set(string(contextutils.TenantIDKey), "tenant1")
+	})
+	s.router.GET("/users/:id/activity", s.handler.GetUserActivity)
+	s.router.GET("/sessions/:id", s.handler.GetSession)
+}
+
+func TestSessionHandler(t *testing.T) {
+	suite.Run(t, new(SessionHandlerTestSuite))
+}
+
+func (s *SessionHandlerTestSuite) TestGetUserActivity_Success() {
+	// Arrange
+	expected := &dto.UserActivityResponse{
+		UserID:        "user1",
+		Sessions:      []dto.SessionSummaryResponse{{SessionID: "sess1", EventCount: 3}},
+		TotalSessions: 1,
+	}
+	s.mockService.On("UserActivity", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.TenantID == "tenant1" && f.UserID == "user1" && f.PageSize == 5
+	})).Return(expected, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/user1/activity?page_size=5&start_time=2024-01-01&end_time=2024-01-31", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.UserActivityResponse
+	s.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	s.Require().Len(response.Sessions, 1)
+	s.Equal("sess1", response.Sessions[0].SessionID)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *SessionHandlerTestSuite) TestGetUserActivity_MissingTimeRange() {
+	// Arrange
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/users/user1/activity", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "UserActivity", mock.Anything, mock.Anything)
+}
+
+func (s *SessionHandlerTestSuite) TestGetSession_NotFound() {
+	// Arrange
+	s.mockService.On("GetSession", mock.Anything, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.SessionID == "missing"
+	})).Return(nil, service.ErrSessionNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/sessions/missing?start_time=2024-01-01&end_time=2024-01-31", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
@@ -2,12 +2,14 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
 )
 
 //go:generate mockery --name TenantService --output ../mocks
@@ -74,3 +76,55 @@ func (h *TenantHandler) ListTenants(c *gin.Context) {
 
 	c.JSON(http.StatusOK, tenants)
 }
+
+// UpdateTenant godoc
+// @Summary Update a tenant
+// @Description Update a tenant's name or ingestion rate limit. Rate limit changes take effect immediately.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param body body dto.UpdateTenantRequest true "Tenant fields to update"
+// @Success 200 {object} dto.CreateTenantResponse
+// @Failure 400 {object} dto.Error
+// @Failure 401 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router /tenants/{id} [put]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	var req dto.UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Error{Error: err.Error()})
+		return
+	}
+
+	tenant, err := h.service.GetByID(h.RequestCtx(c), c.Param("id"))
+	if errors.Is(err, service.ErrTenantNotFound) {
+		c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	if req.Name != "" {
+		tenant.Name = req.Name
+	}
+	if req.RateLimit != nil {
+		tenant.RateLimit = *req.RateLimit
+	}
+
+	if err := h.service.Update(h.RequestCtx(c), tenant); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateTenantResponse{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		RateLimit: tenant.RateLimit,
+		CreatedAt: tenant.CreatedAt,
+		UpdatedAt: tenant.UpdatedAt,
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -64,6 +65,7 @@ func (s *TenantHandlerTestSuite) SetupTest() {
 	// Setup routes
 	s.router.POST("/tenants", s.handler.CreateTenant)
 	s.router.GET("/tenants", s.handler.ListTenants)
+	s.router.PUT("/tenants/:id", s.handler.UpdateTenant)
 }
 
 func TestTenantHandler(t *testing.T) {
@@ -144,3 +146,48 @@ func (s *TenantHandlerTestSuite) TestListTenants_Success() {
 	s.Equal(expectedTenants[1].Name, response[1].Name)
 	s.mockService.AssertExpectations(s.T())
 }
+
+func (s *TenantHandlerTestSuite) TestUpdateTenant_Success() {
+	// Arrange
+	tenant := &domain.Tenant{
+		ID:        "tenant1",
+		Name:      "Tenant 1",
+		RateLimit: 1000,
+	}
+
+	s.mockService.On("GetByID", mock.Anything, "tenant1").Return(tenant, nil)
+	s.mockService.On("Update", mock.Anything, mock.MatchedBy(func(t *domain.Tenant) bool {
+		return t.ID == "tenant1" && t.Name == "Tenant 1" && t.RateLimit == 250
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/tenant1", bytes.NewBufferString(`{"rate_limit": 250}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response dto.CreateTenantResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal(250, response.RateLimit)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantHandlerTestSuite) TestUpdateTenant_NotFound() {
+	// Arrange
+	s.mockService.On("GetByID", mock.Anything, "missing").Return(nil, service.ErrTenantNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/tenants/missing", bytes.NewBufferString(`{"rate_limit": 250}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+	s.mockService.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+)
+
+//go:generate mockery --name TenantPurgeService --output ../mocks
+type TenantPurgeService interface {
+	Purge(ctx context.Context, tenantID string) (*domain.DeletionReceipt, error)
+}
+
+type TenantPurgeHandler struct {
+	*BaseHandler
+	service TenantPurgeService
+}
+
+func NewTenantPurgeHandler(service TenantPurgeService) *TenantPurgeHandler {
+	return &TenantPurgeHandler{service: service}
+}
+
+// PurgeTenant Offboard a tenant
+// @Summary Purge tenant
+// @Description Deletes the tenant and everything stored for it: its logs, OpenSearch indices, S3 archives and export files, and every PostgreSQL row. A tenant with active legal holds is not purged. Returns a receipt signed with HMAC-SHA256 over its signing payload. A purge that failed can be run again.
+// @Tags    tenants
+// @Produce json
+// @Param   id path string true "Tenant ID"
+// @Success 200 {object} domain.DeletionReceipt
+// @Failure 401 {object} dto.Error
+// @Failure 403 {object} dto.Error
+// @Failure 404 {object} dto.Error
+// @Failure 409 {object} dto.Error
+// @Failure 500 {object} dto.Error
+// @Router  /tenants/{id} [delete]
+func (h *TenantPurgeHandler) PurgeTenant(c *gin.Context) {
+	receipt, err := h.service.Purge(h.RequestCtx(c), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTenantNotFound):
+			c.JSON(http.StatusNotFound, dto.Error{Error: err.Error()})
+		case errors.Is(err, service.ErrTenantUnderLegalHold):
+			c.JSON(http.StatusConflict, dto.Error{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.Error{Error: err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
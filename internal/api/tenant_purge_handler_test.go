@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TenantPurgeHandlerTestSuite struct {
+	suite.Suite
+	router      *gin.Engine
+	mockService *MockTenantPurgeService
+	handler     *TenantPurgeHandler
+}
+
+type MockTenantPurgeService struct {
+	mock.Mock
+}
+
+func (m *MockTenantPurgeService) Purge(ctx context.Context, tenantID string) (*domain.DeletionReceipt, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DeletionReceipt), args.Error(1)
+}
+
+func (s *TenantPurgeHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.router = gin.New()
+	s.mockService = new(MockTenantPurgeService)
+	s.handler = NewTenantPurgeHandler(s.mockService)
+
+	// Setup routes
+	s.router.DELETE("/tenants/:id", s.handler.PurgeTenant)
+}
+
+func TestTenantPurgeHandler(t *testing.T) {
+	suite.Run(t, new(TenantPurgeHandlerTestSuite))
+}
+
+func (s *TenantPurgeHandlerTestSuite) TestPurgeTenant_Success() {
+	// Arrange
+	receipt := &domain.DeletionReceipt{ID: "receipt1", TenantID: "tenant1", DeletedLogs: 42, Algorithm: domain.ReceiptAlgorithm, Signature: "abc"}
+	s.mockService.On("Purge", mock.Anything, "tenant1").Return(receipt, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/tenants/tenant1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusOK, w.Code)
+	var response domain.DeletionReceipt
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	s.NoError(err)
+	s.Equal("tenant1", response.TenantID)
+	s.Equal(int64(42), response.DeletedLogs)
+	s.Equal("abc", response.Signature)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TenantPurgeHandlerTestSuite) TestPurgeTenant_NotFound() {
+	// Arrange
+	s.mockService.On("Purge", mock.Anything, "missing").Return(nil, service.ErrTenantNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/tenants/missing", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *TenantPurgeHandlerTestSuite) TestPurgeTenant_UnderLegalHold() {
+	// Arrange
+	s.mockService.On("Purge", mock.Anything, "tenant1").Return(nil, service.ErrTenantUnderLegalHold)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/tenants/tenant1", nil)
+
+	// Act
+	s.router.ServeHTTP(w, req)
+
+	// Assert
+	s.Equal(http.StatusConflict, w.Code)
+}
@@ -0,0 +1,356 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+
+	"github.com/buiminhduc234/audit-log-api/docs"
+	"github.com/buiminhduc234/audit-log-api/internal/api"
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/metrics"
+	"github.com/buiminhduc234/audit-log-api/internal/middleware"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/opensearch"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/buiminhduc234/audit-log-api/internal/service/pubsub"
+	"github.com/buiminhduc234/audit-log-api/internal/service/ratelimit"
+	"github.com/buiminhduc234/audit-log-api/internal/worker"
+)
+
+// openSearchSetupTimeout bounds the setup of the OpenSearch indices when the
+// index worker starts
+const openSearchSetupTimeout = 2 * time.Minute
+
+// Components builds the components of a role on the shared dependencies
+func (d *Dependencies) Components(role Role) ([]Component, error) {
+	switch role {
+	case RoleAPI:
+		return d.apiComponents()
+	case RoleIndex:
+		return d.indexComponents()
+	case RoleArchive:
+		return d.archiveComponents()
+	case RoleCleanup:
+		return d.cleanupComponents()
+	case RoleExport:
+		return d.exportComponents()
+	case RoleScheduler:
+		return d.schedulerComponents()
+	case RoleOutbox:
+		return d.outboxComponents()
+	default:
+		return nil, fmt.Errorf("unknown role %q", role)
+	}
+}
+
+func (d *Dependencies) apiComponents() ([]Component, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	repo, err := d.Repository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+	s3Storage, err := d.Storage()
+	if err != nil {
+		return nil, err
+	}
+	redisClient, err := d.Redis()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize Redis pub/sub
+	redisPubSub := pubsub.NewRedisPubSub(redisClient, d.logger)
+
+	// Initialize services
+	tenantService := service.NewTenantService(repo)
+	tenantPurgeService := service.NewTenantPurgeService(repo, s3Storage, []byte(cfg.ReceiptSigningKey))
+	auditLogService := service.NewAuditLogService(repo, queueService)
+	auditLogService.SetArchiveStorage(s3Storage)
+	exportJobService := service.NewExportJobService(repo, queueService, s3Storage)
+	restoreService := service.NewRestoreService(repo, queueService, s3Storage)
+	retentionService := service.NewRetentionService(repo)
+	indexedPathService := service.NewIndexedPathService(repo)
+	legalHoldService := service.NewLegalHoldService(repo, auditLogService)
+	reindexService := service.NewReindexService(repo, queueService)
+	deadLetterService := service.NewDeadLetterService(queueService)
+
+	// Initialize per-tenant rate limiting backed by Redis
+	rateLimiter := ratelimit.NewRedisRateLimiter(redisClient, repo.Tenant())
+	tenantService.SetRateLimitInvalidator(rateLimiter)
+
+	// Initialize middleware
+	authMiddleware := middleware.NewAuthMiddleware(cfg)
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(rateLimiter, d.logger)
+
+	// Initialize server
+	server := api.NewServer(
+		tenantService,
+		tenantPurgeService,
+		auditLogService,
+		exportJobService,
+		restoreService,
+		retentionService,
+		indexedPathService,
+		legalHoldService,
+		reindexService,
+		deadLetterService,
+		authMiddleware,
+		rateLimitMiddleware,
+		d.logger,
+		redisPubSub,
+	)
+
+	// Wire up WebSocket broadcaster
+	auditLogService.SetWebSocketBroadcaster(server.GetWebSocketHandler())
+
+	// Initialize router
+	router := gin.Default()
+
+	// Swagger documentation endpoint
+	docs.SwaggerInfo.Title = "Audit Log API"
+	docs.SwaggerInfo.Description = "A comprehensive audit logging API system"
+	docs.SwaggerInfo.Version = "1.0"
+	docs.SwaggerInfo.Host = fmt.Sprintf("localhost:%d", cfg.ServerPort)
+	docs.SwaggerInfo.BasePath = "/api/v1"
+	docs.SwaggerInfo.Schemes = []string{"http"}
+
+	// Swagger UI endpoint
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Health check endpoint
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	// Setup API routes
+	apiGroup := router.Group("/api/v1")
+	server.SetupRoutes(apiGroup)
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.ServerPort),
+		Handler: router,
+	}
+
+	// The WebSocket hub runs for as long as the process
+	apiServer := ServerComponent("API server", srv)
+	serve := apiServer.Start
+	apiServer.Start = func(fail func(error)) error {
+		server.StartWebSocketHub()
+		return serve(fail)
+	}
+	return []Component{apiServer}, nil
+}
+
+func (d *Dependencies) indexComponents() ([]Component, error) {
+	osRepo, err := d.OpenSearchRepository()
+	if err != nil {
+		return nil, err
+	}
+	// Reindex jobs compare the stored logs with the indices
+	repo, err := d.Repository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+
+	reindexService := service.NewReindexService(repo, queueService)
+
+	sqsWorker := worker.NewSQSWorker(
+		queueService,
+		osRepo,
+		reindexService,
+		d.logger,
+		4,             // worker count
+		5*time.Second, // poll interval
+		config.DefaultIndexBatchConfig(),
+	)
+
+	// The templates, the ISM policy and the aliases are in place before the
+	// first log is indexed
+	indexWorker := WorkerComponent("index worker", sqsWorker)
+	start := indexWorker.Start
+	indexWorker.Start = func(fail func(error)) error {
+		if err := d.setupOpenSearch(osRepo); err != nil {
+			return err
+		}
+		return start(fail)
+	}
+	return []Component{indexWorker}, nil
+}
+
+// setupOpenSearch installs the templates and the ISM policy and creates the
+// write aliases of the existing tenants, later tenants get theirs with their
+// first log
+func (d *Dependencies) setupOpenSearch(osRepo opensearch.Repository) error {
+	pgRepo, err := d.PostgresRepository()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), openSearchSetupTimeout)
+	defer cancel()
+
+	tenants, err := pgRepo.Tenant().List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+	tenantIDs := make([]string, 0, len(tenants))
+	for _, tenant := range tenants {
+		tenantIDs = append(tenantIDs, tenant.ID)
+	}
+
+	if err := osRepo.Setup(ctx, tenantIDs); err != nil {
+		return fmt.Errorf("failed to set up OpenSearch: %w", err)
+	}
+	return nil
+}
+
+func (d *Dependencies) archiveComponents() ([]Component, error) {
+	// Restored logs are indexed again
+	repo, err := d.Repository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+	s3Client, s3Config, err := d.S3()
+	if err != nil {
+		return nil, err
+	}
+	s3Storage, err := d.Storage()
+	if err != nil {
+		return nil, err
+	}
+
+	restoreService := service.NewRestoreService(repo, queueService, s3Storage)
+
+	archiveWorker := worker.NewArchiveWorker(
+		queueService,
+		repo,
+		restoreService,
+		d.logger,
+		1,             // worker count
+		5*time.Second, // poll interval
+		s3Client,      // S3 client
+		s3Config,      // S3 configuration
+	)
+	return []Component{WorkerComponent("archive worker", archiveWorker)}, nil
+}
+
+func (d *Dependencies) cleanupComponents() ([]Component, error) {
+	// Deleted logs are removed from the index too
+	repo, err := d.Repository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+
+	cleanupWorker := worker.NewCleanupWorker(
+		queueService,
+		service.NewCleanupService(repo),
+		d.logger,
+		1,             // worker count
+		5*time.Second, // poll interval
+	)
+	return []Component{WorkerComponent("cleanup worker", cleanupWorker)}, nil
+}
+
+func (d *Dependencies) exportComponents() ([]Component, error) {
+	pgRepo, err := d.PostgresRepository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+	s3Storage, err := d.Storage()
+	if err != nil {
+		return nil, err
+	}
+
+	exportWorker := worker.NewExportWorker(
+		queueService,
+		service.NewExportJobService(pgRepo, queueService, s3Storage),
+		d.logger,
+		2,             // worker count
+		5*time.Second, // poll interval
+		time.Hour,     // expiry interval
+	)
+	return []Component{WorkerComponent("export worker", exportWorker)}, nil
+}
+
+func (d *Dependencies) schedulerComponents() ([]Component, error) {
+	// Parse the retention schedule
+	retentionConfig := config.DefaultRetentionConfig()
+	schedule, err := retentionConfig.GetSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse retention schedule: %w", err)
+	}
+
+	pgRepo, err := d.PostgresRepository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler := worker.NewRetentionScheduler(
+		queueService,
+		pgRepo,
+		d.logger,
+		schedule,
+	)
+	name := fmt.Sprintf("retention scheduler (schedule: %s)", retentionConfig.Schedule)
+	return []Component{WorkerComponent(name, scheduler)}, nil
+}
+
+func (d *Dependencies) outboxComponents() ([]Component, error) {
+	outboxConfig := config.DefaultOutboxConfig()
+
+	pgRepo, err := d.PostgresRepository()
+	if err != nil {
+		return nil, err
+	}
+	queueService, err := d.Queue()
+	if err != nil {
+		return nil, err
+	}
+
+	relay := worker.NewOutboxRelay(
+		service.NewOutboxService(pgRepo, queueService),
+		d.logger,
+		2,             // worker count
+		1*time.Second, // poll interval
+	)
+
+	// Serve the relay's lag and throughput metrics
+	metricsServer := metrics.NewServer(outboxConfig.MetricsAddr)
+	return []Component{
+		ServerComponent(fmt.Sprintf("metrics server on %s", outboxConfig.MetricsAddr), metricsServer),
+		WorkerComponent("outbox relay", relay),
+	}, nil
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	opensearchclient "github.com/opensearch-project/opensearch-go/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/composite"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/opensearch"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/postgres"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/internal/service/storage"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// Dependencies connects to the databases, queues and stores the roles share.
+// Each connection is made the first time a role asks for it, so a process
+// only connects to what its roles use, and every role of the process uses the
+// same connection.
+type Dependencies struct {
+	logger *logger.Logger
+
+	dbConnections *config.DatabaseConnections
+	osConfig      *config.OpenSearchConfig
+	osClient      *opensearchclient.Client
+	repo          repository.Repository
+	pgRepo        repository.PostgresRepository
+	osRepo        opensearch.Repository
+	queueService  *queue.Service
+	s3Config      *config.S3Config
+	s3Client      *s3.Client
+	s3Storage     *storage.S3Storage
+	redisClient   *redis.Client
+}
+
+func NewDependencies(logger *logger.Logger) *Dependencies {
+	return &Dependencies{logger: logger}
+}
+
+// Database returns the writer and reader PostgreSQL connections
+func (d *Dependencies) Database() (*config.DatabaseConnections, error) {
+	if d.dbConnections == nil {
+		dbConnections, err := config.NewDatabaseConnections()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		d.logger.Info("Database connections established - writer and reader connected")
+		d.dbConnections = dbConnections
+	}
+	return d.dbConnections, nil
+}
+
+// OpenSearch returns the OpenSearch client and its configuration
+func (d *Dependencies) OpenSearch() (*opensearchclient.Client, *config.OpenSearchConfig, error) {
+	if d.osClient == nil {
+		osConfig := config.DefaultOpenSearchConfig()
+		osClient, err := osConfig.GetClient()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to OpenSearch: %w", err)
+		}
+		d.logger.Info("OpenSearch connection established")
+		d.osConfig = osConfig
+		d.osClient = osClient
+	}
+	return d.osClient, d.osConfig, nil
+}
+
+// Repository returns the repository over PostgreSQL and OpenSearch
+func (d *Dependencies) Repository() (repository.Repository, error) {
+	if d.repo == nil {
+		dbConnections, err := d.Database()
+		if err != nil {
+			return nil, err
+		}
+		osClient, osConfig, err := d.OpenSearch()
+		if err != nil {
+			return nil, err
+		}
+		d.repo = composite.NewCompositeRepository(dbConnections, osClient, osConfig)
+	}
+	return d.repo, nil
+}
+
+// PostgresRepository returns the repository over PostgreSQL alone, for the
+// roles that never touch OpenSearch
+func (d *Dependencies) PostgresRepository() (repository.PostgresRepository, error) {
+	if d.pgRepo == nil {
+		dbConnections, err := d.Database()
+		if err != nil {
+			return nil, err
+		}
+		d.pgRepo = postgres.NewPostgresRepository(dbConnections)
+	}
+	return d.pgRepo, nil
+}
+
+// OpenSearchRepository returns the OpenSearch repository the index worker
+// writes through
+func (d *Dependencies) OpenSearchRepository() (opensearch.Repository, error) {
+	if d.osRepo == nil {
+		osClient, osConfig, err := d.OpenSearch()
+		if err != nil {
+			return nil, err
+		}
+		d.osRepo = opensearch.NewRepository(osClient, osConfig)
+	}
+	return d.osRepo, nil
+}
+
+// Queue returns the queue service on the backend selected by QUEUE_BACKEND.
+// The roles of a process share it, so the in-memory backend delivers the
+// messages of one role to another.
+func (d *Dependencies) Queue() (*queue.Service, error) {
+	if d.queueService == nil {
+		queueConfig := config.DefaultQueueConfig()
+		queueBackend, err := queue.NewBackend(queueConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to the queue: %w", err)
+		}
+		d.logger.Infof("Queue connection established (backend: %s)", queueConfig.Backend)
+		d.queueService = queue.NewService(queueBackend, queueConfig.Retry)
+	}
+	return d.queueService, nil
+}
+
+// S3 returns the S3 client and its configuration
+func (d *Dependencies) S3() (*s3.Client, *config.S3Config, error) {
+	if d.s3Client == nil {
+		s3Config := config.DefaultS3Config()
+		s3Client, err := s3Config.GetClient(context.Background())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to S3: %w", err)
+		}
+		d.s3Config = s3Config
+		d.s3Client = s3Client
+	}
+	return d.s3Client, d.s3Config, nil
+}
+
+// Storage returns the S3 storage of the exports and archives
+func (d *Dependencies) Storage() (*storage.S3Storage, error) {
+	if d.s3Storage == nil {
+		s3Client, s3Config, err := d.S3()
+		if err != nil {
+			return nil, err
+		}
+		d.s3Storage = storage.NewS3Storage(s3Client, s3Config)
+	}
+	return d.s3Storage, nil
+}
+
+// Redis returns the Redis client
+func (d *Dependencies) Redis() (*redis.Client, error) {
+	if d.redisClient == nil {
+		redisClient, err := config.DefaultRedisConfig().GetClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		d.redisClient = redisClient
+	}
+	return d.redisClient, nil
+}
+
+// Close closes the connections that were made
+func (d *Dependencies) Close() {
+	if d.redisClient != nil {
+		if err := d.redisClient.Close(); err != nil {
+			d.logger.Errorf("Failed to close Redis client: %v", err)
+		}
+	}
+	if d.dbConnections != nil {
+		if err := d.dbConnections.Close(); err != nil {
+			d.logger.Errorf("Failed to close database connections: %v", err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Role is a part of the system a process runs
+type Role string
+
+const (
+	RoleAPI       Role = "api"
+	RoleIndex     Role = "index"
+	RoleArchive   Role = "archive"
+	RoleCleanup   Role = "cleanup"
+	RoleExport    Role = "export"
+	RoleScheduler Role = "scheduler"
+	RoleOutbox    Role = "outbox"
+)
+
+// AllRoles lists the roles in the order they are started. The workers start
+// before the API so that the messages it sends are picked up, and the API is
+// stopped first so that no new work arrives while the workers drain.
+var AllRoles = []Role{
+	RoleOutbox,
+	RoleIndex,
+	RoleArchive,
+	RoleCleanup,
+	RoleExport,
+	RoleScheduler,
+	RoleAPI,
+}
+
+// ParseRoles reads a comma-separated list of roles, "all" selects every role.
+// The roles are returned in start order without duplicates.
+func ParseRoles(value string) ([]Role, error) {
+	selected := make(map[Role]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			return slices.Clone(AllRoles), nil
+		}
+
+		role := Role(name)
+		if !slices.Contains(AllRoles, role) {
+			return nil, fmt.Errorf("unknown role %q", name)
+		}
+		selected[role] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no roles given")
+	}
+
+	roles := make([]Role, 0, len(selected))
+	for _, role := range AllRoles {
+		if selected[role] {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RolesTestSuite struct {
+	suite.Suite
+}
+
+func TestRoles(t *testing.T) {
+	suite.Run(t, new(RolesTestSuite))
+}
+
+func (s *RolesTestSuite) TestParseRoles() {
+	tests := []struct {
+		name    string
+		value   string
+		want    []Role
+		wantErr string
+	}{
+		{name: "all", value: "all", want: AllRoles},
+		{name: "all among others", value: "api,all", want: AllRoles},
+		{name: "start order", value: "api,index,outbox", want: []Role{RoleOutbox, RoleIndex, RoleAPI}},
+		{name: "case and spaces", value: " API , Export ", want: []Role{RoleExport, RoleAPI}},
+		{name: "duplicates", value: "index,api,index", want: []Role{RoleIndex, RoleAPI}},
+		{name: "empty entries", value: ",scheduler,,", want: []Role{RoleScheduler}},
+		{name: "unknown", value: "api,reports", wantErr: `unknown role "reports"`},
+		{name: "empty", value: "", wantErr: "no roles given"},
+		{name: "only separators", value: " , ,", wantErr: "no roles given"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Act
+			roles, err := ParseRoles(tt.value)
+
+			// Assert
+			if tt.wantErr != "" {
+				s.EqualError(err, tt.wantErr)
+				s.Nil(roles)
+				return
+			}
+			s.NoError(err)
+			s.Equal(tt.want, roles)
+		})
+	}
+}
+
+func (s *RolesTestSuite) TestParseRoles_AllIsACopy() {
+	// Act
+	roles, err := ParseRoles("all")
+	s.Require().NoError(err)
+	roles[0] = RoleAPI
+
+	// Assert
+	s.Equal(RoleOutbox, AllRoles[0])
+}
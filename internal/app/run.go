@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// Main runs the roles in this process until it receives SIGINT or SIGTERM,
+// and exits the process when a role fails
+func Main(roles ...Role) {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Initialize logger
+	appLogger := logger.NewLogger(os.Getenv("APP_ENV"))
+	defer appLogger.Sync()
+
+	if err := run(appLogger, roles); err != nil {
+		appLogger.Fatal("Stopped after a failure", err)
+	}
+	appLogger.Info("Stopped")
+}
+
+func run(appLogger *logger.Logger, roles []Role) error {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, string(role))
+	}
+	appLogger.Infof("Running roles: %s", strings.Join(names, ","))
+
+	deps := NewDependencies(appLogger)
+	defer deps.Close()
+
+	// The workers drain their messages until the deadline shared by every
+	// component
+	workerConfig := config.DefaultWorkerConfig()
+	supervisor := NewSupervisor(appLogger, workerConfig.DrainTimeout)
+	for _, role := range roles {
+		components, err := deps.Components(role)
+		if err != nil {
+			return err
+		}
+		supervisor.Add(components...)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return supervisor.Run(ctx)
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// Component is a long-running part of a role that the supervisor starts and
+// stops
+type Component struct {
+	Name string
+	// Start starts the component without blocking. Errors that stop the
+	// component after it started are reported to fail.
+	Start func(fail func(error)) error
+	// Stop stops the component, waiting for the work in progress until ctx is
+	// done
+	Stop func(ctx context.Context) error
+}
+
+// Supervisor runs the components of a process together. It starts them in
+// order, and when the process is asked to stop or one of them fails, it stops
+// the started ones in reverse order.
+type Supervisor struct {
+	logger          *logger.Logger
+	shutdownTimeout time.Duration
+	components      []Component
+}
+
+func NewSupervisor(logger *logger.Logger, shutdownTimeout time.Duration) *Supervisor {
+	return &Supervisor{
+		logger:          logger,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+func (s *Supervisor) Add(components ...Component) {
+	s.components = append(s.components, components...)
+}
+
+// Run starts the components and blocks until ctx is done or a component
+// fails. It returns the error of the component that failed to start or
+// stopped unexpectedly.
+func (s *Supervisor) Run(ctx context.Context) error {
+	failed := make(chan error, len(s.components))
+
+	var runErr error
+	started := 0
+	for _, component := range s.components {
+		fail := func(err error) {
+			failed <- fmt.Errorf("%s failed: %w", component.Name, err)
+		}
+
+		s.logger.Infof("Starting %s...", component.Name)
+		if err := component.Start(fail); err != nil {
+			runErr = fmt.Errorf("failed to start %s: %w", component.Name, err)
+			break
+		}
+		started++
+	}
+
+	if runErr == nil {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Shutting down...")
+		case runErr = <-failed:
+			s.logger.Error("Shutting down after a failure", runErr)
+		}
+	}
+
+	// Every component shares one shutdown deadline
+	stopCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	for i := started - 1; i >= 0; i-- {
+		component := s.components[i]
+		s.logger.Infof("Stopping %s...", component.Name)
+		if err := component.Stop(stopCtx); err != nil {
+			s.logger.Errorf("Failed to stop %s: %v", component.Name, err)
+		}
+	}
+
+	return runErr
+}
+
+// lifecycle is how the workers are run, Start returns once their goroutines
+// run and Stop returns once they finished or returned their messages
+type lifecycle interface {
+	Start()
+	Stop(ctx context.Context)
+}
+
+// WorkerComponent supervises a worker. The worker drains its messages until
+// the shutdown deadline and returns the rest to the queue.
+func WorkerComponent(name string, w lifecycle) Component {
+	return Component{
+		Name: name,
+		Start: func(fail func(error)) error {
+			w.Start()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			w.Stop(ctx)
+			return nil
+		},
+	}
+}
+
+// ServerComponent supervises an HTTP server. The listener is opened when the
+// component starts, so a port in use fails the start.
+func ServerComponent(name string, srv *http.Server) Component {
+	return Component{
+		Name: name,
+		Start: func(fail func(error)) error {
+			listener, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			return srv.Shutdown(ctx)
+		},
+	}
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+type SupervisorTestSuite struct {
+	suite.Suite
+	supervisor *Supervisor
+
+	mu     sync.Mutex
+	events []string
+	fails  map[string]func(error)
+}
+
+func (s *SupervisorTestSuite) SetupTest() {
+	s.supervisor = NewSupervisor(&logger.Logger{Logger: zap.NewNop()}, time.Minute)
+	s.events = nil
+	s.fails = make(map[string]func(error))
+}
+
+func TestSupervisor(t *testing.T) {
+	suite.Run(t, new(SupervisorTestSuite))
+}
+
+// component records its start and stop, failing them with the errors given
+func (s *SupervisorTestSuite) component(name string, startErr, stopErr error) Component {
+	return Component{
+		Name: name,
+		Start: func(fail func(error)) error {
+			s.record("start " + name)
+			s.mu.Lock()
+			s.fails[name] = fail
+			s.mu.Unlock()
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			s.record("stop " + name)
+			return stopErr
+		},
+	}
+}
+
+func (s *SupervisorTestSuite) record(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *SupervisorTestSuite) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.events...)
+}
+
+func (s *SupervisorTestSuite) TestRun_StopsInReverseOrderOnShutdown() {
+	// Arrange
+	s.supervisor.Add(
+		s.component("outbox", nil, nil),
+		s.component("index", nil, nil),
+		s.component("api", nil, nil),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := s.supervisor.Run(ctx)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{
+		"start outbox", "start index", "start api",
+		"stop api", "stop index", "stop outbox",
+	}, s.recorded())
+}
+
+func (s *SupervisorTestSuite) TestRun_StopsStartedComponentsWhenStartFails() {
+	// Arrange
+	s.supervisor.Add(
+		s.component("outbox", nil, nil),
+		s.component("index", errors.New("queue unreachable"), nil),
+		s.component("api", nil, nil),
+	)
+
+	// Act
+	err := s.supervisor.Run(context.Background())
+
+	// Assert
+	s.EqualError(err, "failed to start index: queue unreachable")
+	s.Equal([]string{"start outbox", "start index", "stop outbox"}, s.recorded())
+}
+
+func (s *SupervisorTestSuite) TestRun_StopsEveryComponentWhenOneFails() {
+	// Arrange
+	s.supervisor.Add(
+		s.component("outbox", nil, nil),
+		s.component("index", nil, nil),
+		s.component("api", nil, nil),
+	)
+	cause := errors.New("listener closed")
+
+	// Act
+	done := make(chan error, 1)
+	go func() { done <- s.supervisor.Run(context.Background()) }()
+	s.Eventually(func() bool { return len(s.recorded()) == 3 }, time.Second, time.Millisecond)
+	s.mu.Lock()
+	fail := s.fails["index"]
+	s.mu.Unlock()
+	fail(cause)
+
+	// Assert
+	select {
+	case err := <-done:
+		s.ErrorIs(err, cause)
+		s.EqualError(err, "index failed: listener closed")
+	case <-time.After(time.Second):
+		s.FailNow("supervisor kept running after a component failed")
+	}
+	s.Equal([]string{
+		"start outbox", "start index", "start api",
+		"stop api", "stop index", "stop outbox",
+	}, s.recorded())
+}
+
+func (s *SupervisorTestSuite) TestRun_StopsOthersWhenStopFails() {
+	// Arrange
+	s.supervisor.Add(
+		s.component("outbox", nil, nil),
+		s.component("api", nil, errors.New("shutdown timed out")),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := s.supervisor.Run(ctx)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{"start outbox", "start api", "stop api", "stop outbox"}, s.recorded())
+}
+
+func (s *SupervisorTestSuite) TestRun_SharesOneShutdownDeadline() {
+	// Arrange
+	var deadlines []time.Time
+	for _, name := range []string{"outbox", "api"} {
+		s.supervisor.Add(Component{
+			Name:  name,
+			Start: func(fail func(error)) error { return nil },
+			Stop: func(ctx context.Context) error {
+				deadline, ok := ctx.Deadline()
+				s.True(ok)
+				deadlines = append(deadlines, deadline)
+				// Slow stops use up the deadline of the next ones
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			},
+		})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	before := time.Now()
+
+	// Act
+	err := s.supervisor.Run(ctx)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(deadlines, 2)
+	s.Equal(deadlines[0], deadlines[1])
+	s.WithinDuration(before.Add(time.Minute), deadlines[0], time.Second)
+}
+
+func (s *SupervisorTestSuite) TestServerComponent_FailsToStartOnPortInUse() {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	defer listener.Close()
+	component := ServerComponent("api", &http.Server{Addr: listener.Addr().String()})
+
+	// Act
+	err = component.Start(func(error) { s.Fail("the server should not have started") })
+
+	// Assert
+	s.Error(err)
+}
+
+func (s *SupervisorTestSuite) TestServerComponent_ServesUntilStopped() {
+	// Arrange
+	srv := &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	component := ServerComponent("api", srv)
+
+	// Act
+	err := component.Start(func(err error) { s.Fail("the server failed", err) })
+	s.Require().NoError(err)
+	stopErr := component.Stop(context.Background())
+
+	// Assert
+	s.NoError(stopErr)
+	s.ErrorIs(srv.ListenAndServe(), http.ErrServerClosed)
+}
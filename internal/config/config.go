@@ -1,14 +1,22 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 )
 
+// minReceiptSigningKeyLength is the length of the shortest receipt signing key
+// accepted, the size of an HMAC-SHA256 digest
+const minReceiptSigningKeyLength = 32
+
 type Config struct {
 	ServerPort         int    `json:"server_port"`
 	JWTSecretKey       string `json:"jwt_secret_key"`
 	JWTExpirationHours int    `json:"jwt_expiration_hours"`
+	// ReceiptSigningKey signs the receipts of tenant purges, tenants cannot be
+	// purged without it
+	ReceiptSigningKey string `json:"-"`
 }
 
 func Load() (*Config, error) {
@@ -22,9 +30,16 @@ func Load() (*Config, error) {
 		jwtExpirationHours = 24
 	}
 
+	// A short key, such as a sample value, would sign receipts anyone could forge
+	receiptSigningKey := os.Getenv("RECEIPT_SIGNING_KEY")
+	if receiptSigningKey != "" && len(receiptSigningKey) < minReceiptSigningKeyLength {
+		return nil, fmt.Errorf("RECEIPT_SIGNING_KEY must be at least %d bytes long", minReceiptSigningKeyLength)
+	}
+
 	return &Config{
 		ServerPort:         serverPort,
 		JWTSecretKey:       os.Getenv("JWT_SECRET_KEY"),
 		JWTExpirationHours: jwtExpirationHours,
+		ReceiptSigningKey:  receiptSigningKey,
 	}, nil
 }
@@ -15,14 +15,26 @@ type OpenSearchConfig struct {
 	Port     string
 	Username string
 	Password string
+
+	// The write index of a tenant rolls over once it reaches either bound,
+	// given in OpenSearch units such as 30gb and 30d
+	RolloverMaxSize string
+	RolloverMaxAge  string
+	// DeleteAfter is how long an index is kept after it rolled over. It must
+	// exceed the longest retention period, the cleanup worker deletes the
+	// logs of shorter ones.
+	DeleteAfter string
 }
 
 func DefaultOpenSearchConfig() *OpenSearchConfig {
 	return &OpenSearchConfig{
-		Host:     getEnvOrDefault("OPENSEARCH_HOST", "localhost"),
-		Port:     getEnvOrDefault("OPENSEARCH_PORT", "9200"),
-		Username: getEnvOrDefault("OPENSEARCH_USERNAME", ""),
-		Password: getEnvOrDefault("OPENSEARCH_PASSWORD", ""),
+		Host:            getEnvOrDefault("OPENSEARCH_HOST", "localhost"),
+		Port:            getEnvOrDefault("OPENSEARCH_PORT", "9200"),
+		Username:        getEnvOrDefault("OPENSEARCH_USERNAME", ""),
+		Password:        getEnvOrDefault("OPENSEARCH_PASSWORD", ""),
+		RolloverMaxSize: getEnvOrDefault("OPENSEARCH_ROLLOVER_MAX_SIZE", "30gb"),
+		RolloverMaxAge:  getEnvOrDefault("OPENSEARCH_ROLLOVER_MAX_AGE", "30d"),
+		DeleteAfter:     getEnvOrDefault("OPENSEARCH_DELETE_AFTER", "3650d"),
 	}
 }
 
@@ -46,13 +58,28 @@ func (c *OpenSearchConfig) GetClient() (*opensearch.Client, error) {
 	return opensearch.NewClient(config)
 }
 
-// GetIndexName returns the index name for a given tenant and time
+// GetAliasName returns the write alias of a tenant, its logs are indexed and
+// searched through it
+// Format: audit-logs-<tenant_id>
+func (c *OpenSearchConfig) GetAliasName(tenantID string) string {
+	return fmt.Sprintf("audit-logs-%s", tenantID)
+}
+
+// GetAliasIndexPattern returns a pattern matching the indices behind the
+// alias of a tenant
+// Format: audit-logs-<tenant_id>-*
+func (c *OpenSearchConfig) GetAliasIndexPattern(tenantID string) string {
+	return fmt.Sprintf("audit-logs-%s-*", tenantID)
+}
+
+// GetIndexName returns the daily index name for a given tenant and time.
+// Daily indices are no longer written, they are read until they are migrated.
 // Format: audit_logs_<tenant_id>_YYYY_MM_DD
 func (c *OpenSearchConfig) GetIndexName(tenantID string, t time.Time) string {
 	return fmt.Sprintf("audit_logs_%s_%s", tenantID, t.Format("2006_01_02"))
 }
 
-// GetIndexPattern returns a pattern matching all indices for a tenant
+// GetIndexPattern returns a pattern matching all daily indices for a tenant
 // Format: audit_logs_<tenant_id>_*
 func (c *OpenSearchConfig) GetIndexPattern(tenantID string) string {
 	return fmt.Sprintf("audit_logs_%s_*", tenantID)
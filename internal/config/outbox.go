@@ -0,0 +1,13 @@
+package config
+
+type OutboxConfig struct {
+	// MetricsAddr is the address the outbox relay serves its metrics on
+	MetricsAddr string
+}
+
+// DefaultOutboxConfig returns default outbox relay configuration from environment variables
+func DefaultOutboxConfig() *OutboxConfig {
+	return &OutboxConfig{
+		MetricsAddr: getEnvWithDefault("OUTBOX_METRICS_ADDR", ":9102"),
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Queue backends
+const (
+	QueueBackendSQS    = "sqs"
+	QueueBackendRedis  = "redis"
+	QueueBackendMemory = "memory"
+)
+
+type QueueConfig struct {
+	// Backend is the queue the services and workers talk to: sqs, redis or
+	// memory. The memory backend only delivers within the process.
+	Backend string
+
+	// RedisPrefix prefixes the keys of the streams of the redis backend
+	RedisPrefix string
+	// RedisGroup is the consumer group the workers read the streams with
+	RedisGroup string
+	// RedisConsumer names this process within the consumer group
+	RedisConsumer string
+
+	Retry RetryPolicy
+}
+
+// RetryPolicy decides how often a failing message is received again before
+// it is dead-lettered and how long it stays hidden between two attempts
+type RetryPolicy struct {
+	MaxReceives int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns how long a message stays hidden after its nth failed receive,
+// doubling from the base delay up to the max delay
+func (p RetryPolicy) Delay(receiveCount int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < receiveCount && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// DefaultQueueConfig returns default queue configuration from environment variables
+func DefaultQueueConfig() *QueueConfig {
+	hostname, _ := os.Hostname()
+
+	return &QueueConfig{
+		Backend:       getEnvWithDefault("QUEUE_BACKEND", QueueBackendSQS),
+		RedisPrefix:   getEnvWithDefault("QUEUE_REDIS_PREFIX", "audit-log"),
+		RedisGroup:    getEnvWithDefault("QUEUE_REDIS_GROUP", "workers"),
+		RedisConsumer: getEnvWithDefault("QUEUE_REDIS_CONSUMER", fmt.Sprintf("%s-%d", hostname, os.Getpid())),
+		Retry: RetryPolicy{
+			MaxReceives: getEnvIntWithDefault("QUEUE_MAX_RECEIVES", 5),
+			BaseDelay:   getEnvDurationWithDefault("QUEUE_RETRY_BASE_DELAY", 10*time.Second),
+			MaxDelay:    getEnvDurationWithDefault("QUEUE_RETRY_MAX_DELAY", 15*time.Minute),
+		},
+	}
+}
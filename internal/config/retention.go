@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+type RetentionConfig struct {
+	// Schedule is the cron expression the retention scheduler runs on
+	Schedule string
+}
+
+// DefaultRetentionConfig returns default retention configuration from environment variables
+func DefaultRetentionConfig() *RetentionConfig {
+	return &RetentionConfig{
+		Schedule: getEnvWithDefault("RETENTION_SCHEDULE", "0 2 * * *"),
+	}
+}
+
+// GetSchedule parses the standard five field cron expression of the schedule
+func (c *RetentionConfig) GetSchedule() (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(c.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retention schedule %q: %w", c.Schedule, err)
+	}
+	return schedule, nil
+}
@@ -18,6 +18,13 @@ type SQSConfig struct {
 	IndexQueueURL   string `mapstructure:"index_queue_url"`
 	ArchiveQueueURL string `mapstructure:"archive_queue_url"`
 	CleanupQueueURL string `mapstructure:"cleanup_queue_url"`
+	ExportQueueURL  string `mapstructure:"export_queue_url"`
+
+	// Dead-letter queues the workers move a message to once it keeps failing
+	IndexDLQURL   string `mapstructure:"index_dlq_url"`
+	ArchiveDLQURL string `mapstructure:"archive_dlq_url"`
+	CleanupDLQURL string `mapstructure:"cleanup_dlq_url"`
+	ExportDLQURL  string `mapstructure:"export_dlq_url"`
 }
 
 func DefaultSQSConfig() *SQSConfig {
@@ -29,6 +36,11 @@ func DefaultSQSConfig() *SQSConfig {
 		IndexQueueURL:   getEnvOrDefault("AWS_SQS_INDEX_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-index-queue"),
 		ArchiveQueueURL: getEnvOrDefault("AWS_SQS_ARCHIVE_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-archive-queue"),
 		CleanupQueueURL: getEnvOrDefault("AWS_SQS_CLEANUP_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-cleanup-queue"),
+		ExportQueueURL:  getEnvOrDefault("AWS_SQS_EXPORT_QUEUE_URL", "http://localhost:4566/000000000000/audit-log-export-queue"),
+		IndexDLQURL:     getEnvOrDefault("AWS_SQS_INDEX_DLQ_URL", "http://localhost:4566/000000000000/audit-log-index-dlq"),
+		ArchiveDLQURL:   getEnvOrDefault("AWS_SQS_ARCHIVE_DLQ_URL", "http://localhost:4566/000000000000/audit-log-archive-dlq"),
+		CleanupDLQURL:   getEnvOrDefault("AWS_SQS_CLEANUP_DLQ_URL", "http://localhost:4566/000000000000/audit-log-cleanup-dlq"),
+		ExportDLQURL:    getEnvOrDefault("AWS_SQS_EXPORT_DLQ_URL", "http://localhost:4566/000000000000/audit-log-export-dlq"),
 	}
 }
 
@@ -0,0 +1,41 @@
+package config
+
+import "time"
+
+type WorkerConfig struct {
+	// DrainTimeout is how long stopping workers keep processing the messages
+	// they hold before they return them to the queue
+	DrainTimeout time.Duration
+}
+
+// DefaultWorkerConfig returns default worker configuration from environment variables
+func DefaultWorkerConfig() *WorkerConfig {
+	return &WorkerConfig{
+		// Stay below the 30 second grace period Kubernetes gives a pod
+		DrainTimeout: getEnvDurationWithDefault("WORKER_DRAIN_TIMEOUT", 25*time.Second),
+	}
+}
+
+// IndexBatchConfig bounds the _bulk requests the index worker merges the
+// received logs into
+type IndexBatchConfig struct {
+	// MaxLogs is the largest number of logs in a request. The worker starts
+	// there and sends smaller requests while OpenSearch rejects them.
+	MaxLogs int
+	// MaxBytes is the largest size of the messages merged into a request
+	MaxBytes int
+	// MaxWait is how long the first message of a request waits for more
+	MaxWait time.Duration
+	// Concurrency is the number of requests sent at the same time
+	Concurrency int
+}
+
+// DefaultIndexBatchConfig returns default index batching configuration from environment variables
+func DefaultIndexBatchConfig() *IndexBatchConfig {
+	return &IndexBatchConfig{
+		MaxLogs:     getEnvIntWithDefault("INDEX_BATCH_MAX_LOGS", 1000),
+		MaxBytes:    getEnvIntWithDefault("INDEX_BATCH_MAX_BYTES", 5<<20),
+		MaxWait:     getEnvDurationWithDefault("INDEX_BATCH_MAX_WAIT", time.Second),
+		Concurrency: getEnvIntWithDefault("INDEX_BATCH_CONCURRENCY", 4),
+	}
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -39,10 +41,16 @@ type AuditLog struct {
 	AfterState   json.RawMessage `gorm:"type:jsonb" json:"after_state,omitempty"`
 	Metadata     json.RawMessage `gorm:"type:jsonb" json:"metadata,omitempty"`
 	Timestamp    time.Time       `gorm:"type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"timestamp"`
+	ChainSeq     int64           `gorm:"column:chain_seq" json:"chain_seq,omitempty"`
+	PrevHash     string          `gorm:"type:text" json:"prev_hash,omitempty"`
+	Hash         string          `gorm:"type:text" json:"hash,omitempty"`
 	CreatedAt    time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt    time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	Tenant       *Tenant         `gorm:"foreignKey:TenantID" json:"-"`
 	User         *User           `gorm:"foreignKey:UserID" json:"-"`
+	// ChangedFields are the paths that differ between the before and after
+	// state, see ChangedFields. It is derived from the states and not hashed.
+	ChangedFields []string `gorm:"type:jsonb;serializer:json" json:"changed_fields,omitempty"`
 }
 
 func (AuditLog) TableName() string {
@@ -50,22 +58,89 @@ func (AuditLog) TableName() string {
 }
 
 type AuditLogFilter struct {
-	TenantID     string    `json:"tenant_id"`
-	UserID       string    `json:"user_id"`
-	SessionID    string    `json:"session_id"`
-	IPAddress    string    `json:"ip_address"`
-	UserAgent    string    `json:"user_agent"`
-	Action       string    `json:"action"`
-	ResourceType string    `json:"resource_type"`
-	ResourceID   string    `json:"resource_id"`
-	Message      string    `json:"message"`
-	Severity     string    `json:"severity"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	Page         int       `json:"page"`
-	PageSize     int       `json:"page_size"`
-	Limit        int       `json:"limit"`
-	Offset       int       `json:"offset"`
+	TenantID     string     `json:"tenant_id"`
+	UserID       string     `json:"user_id"`
+	SessionID    string     `json:"session_id"`
+	IPAddress    string     `json:"ip_address"`
+	UserAgent    string     `json:"user_agent"`
+	Action       string     `json:"action"`
+	ResourceType string     `json:"resource_type"`
+	ResourceID   string     `json:"resource_id"`
+	Message      string     `json:"message"`
+	Severity     string     `json:"severity"`
+	StartTime    time.Time  `json:"start_time"`
+	EndTime      time.Time  `json:"end_time"`
+	Page         int        `json:"page"`
+	PageSize     int        `json:"page_size"`
+	Limit        int        `json:"limit"`
+	Offset       int        `json:"offset"`
+	After        *LogCursor `json:"after,omitempty"`
+	// Query narrows the logs further with the query language of LogQuery
+	Query *LogQuery `json:"query,omitempty"`
+	// JSONFilters filter on values inside metadata, before_state and after_state
+	JSONFilters []JSONFilter `json:"json_filters,omitempty"`
+	// ChangedField matches the logs changing the field between their before
+	// and after state, or a field inside it
+	ChangedField string `json:"changed_field,omitempty"`
+	// IncludeArchived also searches the archives in S3 for logs that have
+	// been removed from the live stores
+	IncludeArchived bool `json:"include_archived,omitempty"`
+}
+
+// Matches reports whether the log satisfies the predicates of the filter, the
+// same way the PostgreSQL repository applies them. Pagination is ignored.
+func (f *AuditLogFilter) Matches(log *AuditLog) bool {
+	switch {
+	case log.TenantID != f.TenantID:
+		return false
+	case f.UserID != "" && log.UserID != f.UserID:
+		return false
+	case f.Action != "" && log.Action != f.Action:
+		return false
+	case f.ResourceType != "" && log.ResourceType != f.ResourceType:
+		return false
+	case f.ResourceID != "" && log.ResourceID != f.ResourceID:
+		return false
+	case f.Severity != "" && log.Severity != f.Severity:
+		return false
+	case f.SessionID != "" && log.SessionID != f.SessionID:
+		return false
+	case f.IPAddress != "" && log.IPAddress != f.IPAddress:
+		return false
+	case f.UserAgent != "" && !containsFold(log.UserAgent, f.UserAgent):
+		return false
+	case f.Message != "" && !containsFold(log.Message, f.Message):
+		return false
+	case !f.StartTime.IsZero() && log.Timestamp.Before(f.StartTime):
+		return false
+	case !f.EndTime.IsZero() && log.Timestamp.After(f.EndTime):
+		return false
+	case f.Query != nil && !f.Query.Matches(log):
+		return false
+	case f.ChangedField != "" && !slices.Contains(ChangedFieldsOf(log), f.ChangedField):
+		return false
+	}
+	for i := range f.JSONFilters {
+		if !f.JSONFilters[i].Matches(log) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllJSONFilters returns the JSON path filters of the filter and those
+// equivalent to the terms of its query on JSON paths
+func (f *AuditLogFilter) AllJSONFilters() []JSONFilter {
+	filters := append([]JSONFilter(nil), f.JSONFilters...)
+	if f.Query != nil {
+		filters = append(filters, f.Query.JSONFilters()...)
+	}
+	return filters
+}
+
+// containsFold reports whether substr is within s, ignoring case like ILIKE
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
 
 type AuditLogStats struct {
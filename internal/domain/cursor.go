@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// LogCursor is the keyset position of the last log of a page. Logs are
+// ordered by timestamp and then ID, both descending.
+type LogCursor struct {
+	Timestamp time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// NewLogCursor returns the cursor pointing right after the given log
+func NewLogCursor(log *AuditLog) *LogCursor {
+	return &LogCursor{
+		Timestamp: log.Timestamp,
+		ID:        log.ID,
+	}
+}
+
+// Includes reports whether the log comes after the cursor, and so belongs to
+// the pages that follow it
+func (c *LogCursor) Includes(log *AuditLog) bool {
+	return CompareLogs(log, &AuditLog{Timestamp: c.Timestamp, ID: c.ID}) > 0
+}
+
+// CompareLogs orders logs the way pages are ordered, newest first. It returns
+// a negative number when a comes before b.
+func CompareLogs(a, b *AuditLog) int {
+	if c := b.Timestamp.Compare(a.Timestamp); c != 0 {
+		return c
+	}
+	return strings.Compare(b.ID, a.ID)
+}
+
+// Encode returns the opaque string representation handed to clients
+func (c *LogCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeLogCursor parses a cursor previously returned by Encode
+func DecodeLogCursor(s string) (*LogCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor LogCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID == "" || cursor.Timestamp.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
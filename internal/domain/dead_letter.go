@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+)
+
+// DeadLetter is a queue message a worker gave up on. It keeps the original
+// body, so it can be sent back to its queue once the cause is fixed.
+type DeadLetter struct {
+	MessageID    string
+	Queue        string
+	Body         string
+	Reason       string
+	ReceiveCount int
+	FailedAt     time.Time
+	SentAt       time.Time
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReceiptAlgorithm is how deletion receipts are signed
+const ReceiptAlgorithm = "HMAC-SHA256"
+
+// DeletionReceipt records what the purge of a tenant deleted. It is not
+// stored, the tenant is gone once it is issued, and its signature lets the
+// holder prove it was issued by this service.
+type DeletionReceipt struct {
+	ID         string `json:"id"`
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+	// DeletedLogs is the number of logs deleted from PostgreSQL and
+	// DeletedIndexedLogs the number of logs the OpenSearch indices held
+	DeletedLogs        int64     `json:"deleted_logs"`
+	DeletedIndexedLogs int64     `json:"deleted_indexed_logs"`
+	DeletedArchives    int       `json:"deleted_archives"`
+	DeletedExports     int       `json:"deleted_exports"`
+	RequestedBy        string    `json:"requested_by"`
+	StartedAt          time.Time `json:"started_at"`
+	CompletedAt        time.Time `json:"completed_at"`
+	Algorithm          string    `json:"algorithm"`
+	Signature          string    `json:"signature"`
+}
+
+// SigningPayload returns what the signature covers: every other field as a
+// name=value line, in the order of the JSON fields. Strings are quoted and
+// times are in UTC RFC 3339 with nanoseconds.
+func (r *DeletionReceipt) SigningPayload() []byte {
+	fields := []string{
+		"id=" + strconv.Quote(r.ID),
+		"tenant_id=" + strconv.Quote(r.TenantID),
+		"tenant_name=" + strconv.Quote(r.TenantName),
+		fmt.Sprintf("deleted_logs=%d", r.DeletedLogs),
+		fmt.Sprintf("deleted_indexed_logs=%d", r.DeletedIndexedLogs),
+		fmt.Sprintf("deleted_archives=%d", r.DeletedArchives),
+		fmt.Sprintf("deleted_exports=%d", r.DeletedExports),
+		"requested_by=" + strconv.Quote(r.RequestedBy),
+		"started_at=" + r.StartedAt.UTC().Format(time.RFC3339Nano),
+		"completed_at=" + r.CompletedAt.UTC().Format(time.RFC3339Nano),
+		"algorithm=" + strconv.Quote(r.Algorithm),
+	}
+	return []byte(strings.Join(fields, "\n"))
+}
@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType tells how a value differs between the before and after state
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// FieldChange is a value that differs between the before and after state of
+// a log. Paths are dotted, array elements are named by their index.
+type FieldChange struct {
+	Path     string          `json:"path"`
+	Type     ChangeType      `json:"type"`
+	OldValue json.RawMessage `json:"old_value,omitempty"`
+	NewValue json.RawMessage `json:"new_value,omitempty"`
+
+	// fields are the object keys of the path, without the array indices
+	fields []string
+}
+
+// DiffStates returns the changes from the before to the after state, in the
+// order of the keys and indices of the paths. Objects are compared key by key
+// and arrays element by element, a value whose type changes is changed as a
+// whole. A missing state counts as empty, so a created resource has each of
+// its fields added.
+func DiffStates(before, after json.RawMessage) []FieldChange {
+	oldValue, hasOld := decodeState(before)
+	newValue, hasNew := decodeState(after)
+	switch {
+	case !hasOld && !hasNew:
+		return nil
+	case !hasOld:
+		oldValue = emptyLike(newValue)
+	case !hasNew:
+		newValue = emptyLike(oldValue)
+	}
+
+	var changes []FieldChange
+	diffValues(&changes, diffPath{}, oldValue, newValue)
+	return changes
+}
+
+// ChangedFields returns the paths the changes touch, for filtering logs by
+// changed field. Array indices are left out and the objects holding a changed
+// value count as changed, so a change of roles.1 or of address.city is found
+// as a change of roles or of address. Object keys of digits, such as years,
+// are kept.
+func ChangedFields(changes []FieldChange) []string {
+	seen := make(map[string]bool)
+	fields := []string{}
+	for _, change := range changes {
+		for i := range change.fields {
+			field := strings.Join(change.fields[:i+1], ".")
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ChangedFieldsOf returns the changed fields of the log, computing them from
+// its states when they were not stored with it
+func ChangedFieldsOf(log *AuditLog) []string {
+	if log.ChangedFields != nil {
+		return log.ChangedFields
+	}
+	return ChangedFields(DiffStates(log.BeforeState, log.AfterState))
+}
+
+func decodeState(state json.RawMessage) (any, bool) {
+	if len(bytes.TrimSpace(state)) == 0 {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(state))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// emptyLike returns an empty object or array to compare the value with
+func emptyLike(value any) any {
+	switch value.(type) {
+	case map[string]any:
+		return map[string]any{}
+	case []any:
+		return []any{}
+	}
+	return nil
+}
+
+// diffPath is the path of a compared value
+type diffPath struct {
+	keys []string
+	// fields are the keys naming object members
+	fields []string
+}
+
+// member returns the path of a member of the object at the path
+func (p diffPath) member(key string) diffPath {
+	return diffPath{keys: appendPath(p.keys, key), fields: appendPath(p.fields, key)}
+}
+
+// element returns the path of an element of the array at the path
+func (p diffPath) element(i int) diffPath {
+	return diffPath{keys: appendPath(p.keys, strconv.Itoa(i)), fields: p.fields}
+}
+
+func (p diffPath) change(changeType ChangeType, oldValue, newValue json.RawMessage) FieldChange {
+	return FieldChange{
+		Path:     strings.Join(p.keys, "."),
+		Type:     changeType,
+		OldValue: oldValue,
+		NewValue: newValue,
+		fields:   p.fields,
+	}
+}
+
+func diffValues(changes *[]FieldChange, path diffPath, oldValue, newValue any) {
+	switch o := oldValue.(type) {
+	case map[string]any:
+		if n, ok := newValue.(map[string]any); ok {
+			diffObjects(changes, path, o, n)
+			return
+		}
+	case []any:
+		if n, ok := newValue.([]any); ok {
+			diffArrays(changes, path, o, n)
+			return
+		}
+	}
+
+	if !jsonEqual(oldValue, newValue) {
+		*changes = append(*changes, path.change(ChangeChanged, rawJSON(oldValue), rawJSON(newValue)))
+	}
+}
+
+func diffObjects(changes *[]FieldChange, path diffPath, oldObject, newObject map[string]any) {
+	keys := make([]string, 0, len(oldObject)+len(newObject))
+	for key := range oldObject {
+		keys = append(keys, key)
+	}
+	for key := range newObject {
+		if _, ok := oldObject[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldValue, hasOld := oldObject[key]
+		newValue, hasNew := newObject[key]
+		diffMember(changes, path.member(key), oldValue, hasOld, newValue, hasNew)
+	}
+}
+
+func diffArrays(changes *[]FieldChange, path diffPath, oldArray, newArray []any) {
+	for i := 0; i < max(len(oldArray), len(newArray)); i++ {
+		var oldValue, newValue any
+		if i < len(oldArray) {
+			oldValue = oldArray[i]
+		}
+		if i < len(newArray) {
+			newValue = newArray[i]
+		}
+		diffMember(changes, path.element(i), oldValue, i < len(oldArray), newValue, i < len(newArray))
+	}
+}
+
+func diffMember(changes *[]FieldChange, path diffPath, oldValue any, hasOld bool, newValue any, hasNew bool) {
+	switch {
+	case !hasOld:
+		*changes = append(*changes, path.change(ChangeAdded, nil, rawJSON(newValue)))
+	case !hasNew:
+		*changes = append(*changes, path.change(ChangeRemoved, rawJSON(oldValue), nil))
+	default:
+		diffValues(changes, path, oldValue, newValue)
+	}
+}
+
+// appendPath returns a new path, the paths of siblings must not share an array
+func appendPath(path []string, key string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), key)
+}
+
+// jsonEqual compares two decoded scalars, numbers by their value
+func jsonEqual(a, b any) bool {
+	if x, ok := a.(json.Number); ok {
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		if errX != nil || errY != nil {
+			return x == y
+		}
+		return fx == fy
+	}
+	return bytes.Equal(rawJSON(a), rawJSON(b))
+}
+
+func rawJSON(value any) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
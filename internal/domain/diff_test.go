@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DiffTestSuite struct {
+	suite.Suite
+}
+
+func TestDiff(t *testing.T) {
+	suite.Run(t, new(DiffTestSuite))
+}
+
+// exported returns the changes as they are serialized
+func exported(changes []FieldChange) []FieldChange {
+	for i := range changes {
+		changes[i].fields = nil
+	}
+	return changes
+}
+
+func added(path, value string) FieldChange {
+	return FieldChange{Path: path, Type: ChangeAdded, NewValue: json.RawMessage(value)}
+}
+
+func removed(path, value string) FieldChange {
+	return FieldChange{Path: path, Type: ChangeRemoved, OldValue: json.RawMessage(value)}
+}
+
+func changed(path, oldValue, newValue string) FieldChange {
+	return FieldChange{Path: path, Type: ChangeChanged, OldValue: json.RawMessage(oldValue), NewValue: json.RawMessage(newValue)}
+}
+
+func (s *DiffTestSuite) TestDiffStates() {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []FieldChange
+	}{
+		{name: "no states", want: nil},
+		{name: "equal", before: `{"name":"a","tags":["x"]}`, after: `{"tags":["x"],"name":"a"}`, want: nil},
+		{
+			name:  "created",
+			after: `{"name":"a","owner":{"id":1}}`,
+			want:  []FieldChange{added("name", `"a"`), added("owner", `{"id":1}`)},
+		},
+		{
+			name:   "deleted",
+			before: `{"name":"a"}`,
+			after:  `  `,
+			want:   []FieldChange{removed("name", `"a"`)},
+		},
+		{name: "null state", before: `null`, after: `{"name":"a"}`, want: []FieldChange{changed("", `null`, `{"name":"a"}`)}},
+		{
+			name:   "keys in order",
+			before: `{"b":1,"c":2}`,
+			after:  `{"a":0,"b":3}`,
+			want:   []FieldChange{added("a", `0`), changed("b", `1`, `3`), removed("c", `2`)},
+		},
+		{
+			name:   "nested objects",
+			before: `{"address":{"city":"Hanoi","geo":{"lat":21}}}`,
+			after:  `{"address":{"city":"Hue","geo":{"lat":21,"lng":105}}}`,
+			want:   []FieldChange{changed("address.city", `"Hanoi"`, `"Hue"`), added("address.geo.lng", `105`)},
+		},
+		{
+			name:   "longer array",
+			before: `{"roles":["viewer"]}`,
+			after:  `{"roles":["admin","viewer"]}`,
+			want:   []FieldChange{changed("roles.0", `"viewer"`, `"admin"`), added("roles.1", `"viewer"`)},
+		},
+		{
+			name:   "shorter array",
+			before: `{"roles":["admin","viewer","auditor"]}`,
+			after:  `{"roles":["admin"]}`,
+			want:   []FieldChange{removed("roles.1", `"viewer"`), removed("roles.2", `"auditor"`)},
+		},
+		{
+			name:   "objects in arrays",
+			before: `{"items":[{"sku":"a","qty":1}]}`,
+			after:  `{"items":[{"sku":"a","qty":2}]}`,
+			want:   []FieldChange{changed("items.0.qty", `1`, `2`)},
+		},
+		{
+			name:   "type changes",
+			before: `{"a":"1","b":{"c":1},"c":[1],"d":null}`,
+			after:  `{"a":1,"b":[1],"c":{"0":1},"d":false}`,
+			want: []FieldChange{
+				changed("a", `"1"`, `1`),
+				changed("b", `{"c":1}`, `[1]`),
+				changed("c", `[1]`, `{"0":1}`),
+				changed("d", `null`, `false`),
+			},
+		},
+		{name: "equal numbers", before: `{"a":1,"b":1e3,"c":-0}`, after: `{"a":1.0,"b":1000,"c":0}`, want: nil},
+		{name: "different numbers", before: `{"a":1}`, after: `{"a":1.5}`, want: []FieldChange{changed("a", `1`, `1.5`)}},
+		{name: "null member", before: `{"a":null}`, after: `{}`, want: []FieldChange{removed("a", `null`)}},
+		{
+			name:   "keys with dots and digits",
+			before: `{"a.b":1,"2024":{"q1":5}}`,
+			after:  `{"a.b":2,"2024":{"q1":6}}`,
+			want:   []FieldChange{changed("2024.q1", `5`, `6`), changed("a.b", `1`, `2`)},
+		},
+		{name: "invalid state counts as missing", before: `{"a":`, after: `{"a":1}`, want: []FieldChange{added("a", `1`)}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Act
+			changes := DiffStates(json.RawMessage(tt.before), json.RawMessage(tt.after))
+
+			// Assert
+			s.Equal(tt.want, exported(changes))
+		})
+	}
+}
+
+func (s *DiffTestSuite) TestChangedFields() {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []string
+	}{
+		{name: "no changes", before: `{"a":1}`, after: `{"a":1}`, want: []string{}},
+		{name: "nested field", before: `{"address":{"city":"a"}}`, after: `{"address":{"city":"b"}}`, want: []string{"address", "address.city"}},
+		{name: "array element", before: `{"roles":["a"]}`, after: `{"roles":["a","b"]}`, want: []string{"roles"}},
+		{
+			name:   "objects in arrays",
+			before: `{"items":[{"qty":1}]}`,
+			after:  `{"items":[{"qty":2}]}`,
+			want:   []string{"items", "items.qty"},
+		},
+		{name: "numeric key", before: `{"2024":{"q1":5}}`, after: `{"2024":{"q1":6}}`, want: []string{"2024", "2024.q1"}},
+		{name: "numeric key in array", before: `{"years":[{"2024":1}]}`, after: `{"years":[{"2024":2}]}`, want: []string{"years", "years.2024"}},
+		{name: "dotted key", before: `{"a.b":1}`, after: `{"a.b":2}`, want: []string{"a.b"}},
+		{name: "whole state", before: `null`, after: `{"a":1}`, want: []string{}},
+		{name: "shared parents once", before: `{"a":{"b":1,"c":1}}`, after: `{"a":{"b":2,"c":2}}`, want: []string{"a", "a.b", "a.c"}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Act
+			fields := ChangedFields(DiffStates(json.RawMessage(tt.before), json.RawMessage(tt.after)))
+
+			// Assert
+			s.Equal(tt.want, fields)
+		})
+	}
+}
+
+func (s *DiffTestSuite) TestChangedFieldsOf_PrefersStoredFields() {
+	// Arrange
+	log := &AuditLog{
+		BeforeState:   json.RawMessage(`{"a":1}`),
+		AfterState:    json.RawMessage(`{"a":2}`),
+		ChangedFields: []string{"stored"},
+	}
+
+	// Act
+	stored := ChangedFieldsOf(log)
+	log.ChangedFields = nil
+	computed := ChangedFieldsOf(log)
+
+	// Assert
+	s.Equal([]string{"stored"}, stored)
+	s.Equal([]string{"a"}, computed)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+)
+
+type ExportJobStatus string
+
+const (
+	ExportJobPending   ExportJobStatus = "PENDING"
+	ExportJobRunning   ExportJobStatus = "RUNNING"
+	ExportJobCompleted ExportJobStatus = "COMPLETED"
+	ExportJobFailed    ExportJobStatus = "FAILED"
+)
+
+// ExportJob is an export of audit logs that runs in the background and is
+// delivered as a file in S3
+type ExportJob struct {
+	ID          string          `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID    string          `gorm:"type:uuid;not null" json:"tenant_id"`
+	Status      ExportJobStatus `gorm:"type:text;not null;default:'PENDING'" json:"status"`
+	Format      string          `gorm:"type:text;not null" json:"format"`
+	Fields      []string        `gorm:"type:jsonb;serializer:json" json:"fields"`
+	Filter      AuditLogFilter  `gorm:"type:jsonb;serializer:json;not null" json:"filter"`
+	RowCount    int64           `gorm:"not null;default:0" json:"row_count"`
+	S3Key       string          `gorm:"column:s3_key;type:text" json:"s3_key"`
+	Error       string          `gorm:"type:text" json:"error"`
+	CreatedAt   time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	StartedAt   *time.Time      `gorm:"type:timestamp with time zone" json:"started_at"`
+	CompletedAt *time.Time      `gorm:"type:timestamp with time zone" json:"completed_at"`
+	ExpiresAt   time.Time       `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
+}
+
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// ExportKeyPrefix returns the S3 prefix under which the export files of a tenant are stored
+func ExportKeyPrefix(tenantID string) string {
+	return "exports/" + tenantID + "/"
+}
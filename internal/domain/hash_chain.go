@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrChainConflict is returned when a log is appended on top of a chain head
+// that has moved since it was read, e.g. by a concurrent writer
+var ErrChainConflict = errors.New("hash chain head has changed")
+
+// Reasons reported for a broken link in the hash chain
+const (
+	ChainBreakHashMismatch     = "hash_mismatch"
+	ChainBreakPrevHashMismatch = "prev_hash_mismatch"
+	ChainBreakSequenceGap      = "sequence_gap"
+)
+
+// ChainHead is the latest link of a tenant's hash chain
+type ChainHead struct {
+	TenantID  string    `gorm:"primaryKey;type:uuid" json:"tenant_id"`
+	LastSeq   int64     `gorm:"not null;default:0" json:"last_seq"`
+	LastHash  string    `gorm:"type:text;not null;default:''" json:"last_hash"`
+	UpdatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (ChainHead) TableName() string {
+	return "audit_log_chain_heads"
+}
+
+// ChainAnchor describes the boundaries of a contiguous part of a chain so it
+// can be verified without the logs that came before or after it
+type ChainAnchor struct {
+	FirstSeq      int64  `json:"first_seq"`
+	FirstPrevHash string `json:"first_prev_hash"`
+	LastSeq       int64  `json:"last_seq"`
+	LastHash      string `json:"last_hash"`
+}
+
+// ChainBreak describes the first link that failed verification
+type ChainBreak struct {
+	LogID    string `json:"log_id"`
+	ChainSeq int64  `json:"chain_seq"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// chainPayload fixes the field order of the hashed representation of a log
+type chainPayload struct {
+	ChainSeq     int64           `json:"chain_seq"`
+	PrevHash     string          `json:"prev_hash"`
+	ID           string          `json:"id"`
+	TenantID     string          `json:"tenant_id"`
+	UserID       string          `json:"user_id"`
+	SessionID    string          `json:"session_id"`
+	IPAddress    string          `json:"ip_address"`
+	UserAgent    string          `json:"user_agent"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Message      string          `json:"message"`
+	Severity     string          `json:"severity"`
+	BeforeState  json.RawMessage `json:"before_state"`
+	AfterState   json.RawMessage `json:"after_state"`
+	Metadata     json.RawMessage `json:"metadata"`
+	Timestamp    string          `json:"timestamp"`
+}
+
+// ComputeHash returns the SHA-256 hash of the log including its PrevHash and
+// ChainSeq. JSON columns are canonicalized and the timestamp is truncated to
+// the microsecond precision of PostgreSQL, so a log read back from the
+// database hashes to the same value as the one that was written.
+func ComputeHash(log *AuditLog) string {
+	payload := chainPayload{
+		ChainSeq:     log.ChainSeq,
+		PrevHash:     log.PrevHash,
+		ID:           log.ID,
+		TenantID:     log.TenantID,
+		UserID:       log.UserID,
+		SessionID:    log.SessionID,
+		IPAddress:    log.IPAddress,
+		UserAgent:    log.UserAgent,
+		Action:       log.Action,
+		ResourceType: log.ResourceType,
+		ResourceID:   log.ResourceID,
+		Message:      log.Message,
+		Severity:     log.Severity,
+		BeforeState:  canonicalJSON(log.BeforeState),
+		AfterState:   canonicalJSON(log.AfterState),
+		Metadata:     canonicalJSON(log.Metadata),
+		Timestamp:    log.Timestamp.Truncate(time.Microsecond).UTC().Format(time.RFC3339Nano),
+	}
+
+	data, _ := json.Marshal(payload)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SealChain appends the logs to the chain starting at head, filling in their
+// ChainSeq, PrevHash and Hash, and returns the new head
+func SealChain(head ChainHead, logs []AuditLog) ChainHead {
+	for i := range logs {
+		logs[i].Timestamp = logs[i].Timestamp.Truncate(time.Microsecond)
+		logs[i].ChainSeq = head.LastSeq + 1
+		logs[i].PrevHash = head.LastHash
+		logs[i].Hash = ComputeHash(&logs[i])
+
+		head.LastSeq = logs[i].ChainSeq
+		head.LastHash = logs[i].Hash
+	}
+	return head
+}
+
+// VerifyLink checks a single log against the sequence number and hash of its
+// predecessor. It returns nil when the link is intact.
+func VerifyLink(prevSeq int64, prevHash string, log *AuditLog) *ChainBreak {
+	if log.ChainSeq != prevSeq+1 {
+		return &ChainBreak{
+			LogID:    log.ID,
+			ChainSeq: log.ChainSeq,
+			Reason:   ChainBreakSequenceGap,
+			Expected: strconv.FormatInt(prevSeq+1, 10),
+			Actual:   strconv.FormatInt(log.ChainSeq, 10),
+		}
+	}
+	if log.PrevHash != prevHash {
+		return &ChainBreak{
+			LogID:    log.ID,
+			ChainSeq: log.ChainSeq,
+			Reason:   ChainBreakPrevHashMismatch,
+			Expected: prevHash,
+			Actual:   log.PrevHash,
+		}
+	}
+	if hash := ComputeHash(log); hash != log.Hash {
+		return &ChainBreak{
+			LogID:    log.ID,
+			ChainSeq: log.ChainSeq,
+			Reason:   ChainBreakHashMismatch,
+			Expected: hash,
+			Actual:   log.Hash,
+		}
+	}
+	return nil
+}
+
+// NewChainAnchor returns the anchor of the chained logs in the slice, which
+// must be sorted by ChainSeq. Logs written before the chain existed are skipped.
+func NewChainAnchor(logs []AuditLog) *ChainAnchor {
+	var anchor *ChainAnchor
+	for i := range logs {
+		if logs[i].ChainSeq == 0 {
+			continue
+		}
+		if anchor == nil {
+			anchor = &ChainAnchor{
+				FirstSeq:      logs[i].ChainSeq,
+				FirstPrevHash: logs[i].PrevHash,
+			}
+		}
+		anchor.LastSeq = logs[i].ChainSeq
+		anchor.LastHash = logs[i].Hash
+	}
+	return anchor
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys and no
+// insignificant whitespace, matching what JSONB hands back
+func canonicalJSON(raw json.RawMessage) json.RawMessage {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(trimmed, &value); err != nil {
+		return trimmed
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return trimmed
+	}
+	return data
+}
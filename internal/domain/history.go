@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SetsState tells if the log replaces the state of its resource. The state
+// of a resource is replayed from its logs: an after state is a snapshot of
+// the whole resource, a deletion clears it and other logs leave it unchanged.
+func SetsState(log *AuditLog) bool {
+	return log.Action == string(ActionDelete) || len(bytes.TrimSpace(log.AfterState)) > 0
+}
+
+// StateAfter returns the state of the resource after a log that sets it, nil
+// once the resource is deleted
+func StateAfter(log *AuditLog) json.RawMessage {
+	if log.Action == string(ActionDelete) {
+		return nil
+	}
+	return log.AfterState
+}
@@ -0,0 +1,266 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONFilterOp is the comparison of a JSONFilter
+type JSONFilterOp string
+
+const (
+	JSONFilterEq     JSONFilterOp = "eq"
+	JSONFilterExists JSONFilterOp = "exists"
+	JSONFilterGt     JSONFilterOp = "gt"
+	JSONFilterGte    JSONFilterOp = "gte"
+	JSONFilterLt     JSONFilterOp = "lt"
+	JSONFilterLte    JSONFilterOp = "lte"
+	// JSONFilterPrefix matches the strings starting with the value
+	JSONFilterPrefix JSONFilterOp = "prefix"
+)
+
+// jsonFields are the JSON fields of a log that can be filtered by path
+var jsonFields = []string{"metadata", "before_state", "after_state"}
+
+// jsonPathKey matches the keys of the paths into the JSON fields
+var jsonPathKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var ErrInvalidJSONPath = errors.New("invalid JSON path")
+
+// JSONFilter filters logs on the value at a path inside metadata,
+// before_state or after_state. Values inside arrays on the path are each
+// compared, the filter matches when any of them does.
+type JSONFilter struct {
+	Field string       `json:"field"`
+	Path  []string     `json:"path"`
+	Op    JSONFilterOp `json:"op"`
+	// Value is compared as a number when it is one, as a string otherwise.
+	// It is "true" or "false" for exists.
+	Value string `json:"value"`
+}
+
+// IsJSONFilterKey reports whether the query parameter is a JSON path filter,
+// which starts with the name of a JSON field and a dot
+func IsJSONFilterKey(key string) bool {
+	for _, field := range jsonFields {
+		if strings.HasPrefix(key, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseJSONFilter parses a JSON path filter given as a query parameter, such
+// as metadata.request_id=abc, after_state.count[gte]=10 or
+// metadata.trace_id[exists]=true
+func ParseJSONFilter(key, value string) (*JSONFilter, error) {
+	op := JSONFilterEq
+	if open := strings.IndexByte(key, '['); open >= 0 {
+		if !strings.HasSuffix(key, "]") {
+			return nil, fmt.Errorf("%w: %s, the operator must be in brackets at the end", ErrInvalidJSONPath, key)
+		}
+		op = JSONFilterOp(key[open+1 : len(key)-1])
+		key = key[:open]
+	}
+
+	field, path, err := SplitJSONPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case JSONFilterEq, JSONFilterGt, JSONFilterGte, JSONFilterLt, JSONFilterLte, JSONFilterPrefix:
+	case JSONFilterExists:
+		exists, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s[exists] must be true or false", key)
+		}
+		value = strconv.FormatBool(exists)
+	default:
+		return nil, fmt.Errorf("unknown operator %q of %s, expected eq, exists, gt, gte, lt, lte or prefix", op, key)
+	}
+
+	return &JSONFilter{Field: field, Path: path, Op: op, Value: value}, nil
+}
+
+// SplitJSONPath splits a dotted path such as metadata.request_id into the
+// JSON field and the keys inside it
+func SplitJSONPath(name string) (string, []string, error) {
+	keys := strings.Split(name, ".")
+	if !IsJSONFilterKey(name) || len(keys) < 2 {
+		return "", nil, fmt.Errorf("%w: %s, it must start with metadata., before_state. or after_state.", ErrInvalidJSONPath, name)
+	}
+	for _, key := range keys[1:] {
+		if !jsonPathKey.MatchString(key) {
+			return "", nil, fmt.Errorf("%w: %s, keys may only hold letters, digits, _ and -", ErrInvalidJSONPath, name)
+		}
+	}
+	return keys[0], keys[1:], nil
+}
+
+// Name returns the dotted name of the field and path
+func (f *JSONFilter) Name() string {
+	return strings.Join(append([]string{f.Field}, f.Path...), ".")
+}
+
+// Matches reports whether the log satisfies the filter
+func (f *JSONFilter) Matches(log *AuditLog) bool {
+	values := JSONPathValues(logJSONField(log, f.Field), f.Path)
+	if f.Op == JSONFilterExists {
+		return (len(values) > 0) == (f.Value == "true")
+	}
+
+	for _, value := range values {
+		if f.matchesValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *JSONFilter) matchesValue(value any) bool {
+	switch f.Op {
+	case JSONFilterEq:
+		return JSONValueEquals(value, f.Value)
+	case JSONFilterPrefix:
+		s, ok := value.(string)
+		return ok && strings.HasPrefix(s, f.Value)
+	}
+
+	// Numbers compare with numbers and strings with strings, like jsonpath
+	var cmp int
+	if want, ok := ParseJSONNumber(f.Value); ok {
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		got, err := n.Float64()
+		if err != nil {
+			return false
+		}
+		cmp = compareFloats(got, want)
+	} else {
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(s, f.Value)
+	}
+
+	switch f.Op {
+	case JSONFilterGt:
+		return cmp > 0
+	case JSONFilterGte:
+		return cmp >= 0
+	case JSONFilterLt:
+		return cmp < 0
+	case JSONFilterLte:
+		return cmp <= 0
+	}
+	return false
+}
+
+// ParseJSONNumber parses the value as a finite number
+func ParseJSONNumber(s string) (float64, bool) {
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, false
+	}
+	return n, true
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// JSONValueEquals reports whether the decoded JSON scalar equals the value
+// written as text: a string holding it, a number of its value or a boolean
+func JSONValueEquals(value any, s string) bool {
+	switch v := value.(type) {
+	case string:
+		return v == s
+	case json.Number:
+		want, ok := ParseJSONNumber(s)
+		if !ok {
+			return false
+		}
+		got, err := v.Float64()
+		return err == nil && got == want
+	case bool:
+		return strconv.FormatBool(v) == s
+	}
+	return false
+}
+
+// JSONPathValues returns the non-null values at the path of the JSON
+// document, decoded with json.Number. Like jsonpath's lax mode, keys apply to
+// each element of the arrays on the path and arrays at the end are unwrapped.
+// Keys of digits also index arrays.
+func JSONPathValues(doc json.RawMessage, path []string) []any {
+	if len(doc) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(doc)))
+	dec.UseNumber()
+	var root any
+	if err := dec.Decode(&root); err != nil {
+		return nil
+	}
+
+	values := []any{root}
+	for _, key := range path {
+		var next []any
+		for _, value := range values {
+			next = append(next, jsonPathStep(value, key)...)
+		}
+		values = next
+	}
+
+	var found []any
+	for _, value := range values {
+		if elems, ok := value.([]any); ok {
+			for _, elem := range elems {
+				if elem != nil {
+					found = append(found, elem)
+				}
+			}
+		} else if value != nil {
+			found = append(found, value)
+		}
+	}
+	return found
+}
+
+func jsonPathStep(value any, key string) []any {
+	switch v := value.(type) {
+	case map[string]any:
+		if child, ok := v[key]; ok {
+			return []any{child}
+		}
+	case []any:
+		if i, err := strconv.Atoi(key); err == nil {
+			if i >= 0 && i < len(v) {
+				return []any{v[i]}
+			}
+			return nil
+		}
+		var children []any
+		for _, elem := range v {
+			children = append(children, jsonPathStep(elem, key)...)
+		}
+		return children
+	}
+	return nil
+}
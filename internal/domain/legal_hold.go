@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+)
+
+// LegalHold keeps the logs of a tenant from being deleted until it is
+// released. A hold either selects logs by filter, where every set field must
+// match, or lists the IDs of the held logs.
+type LegalHold struct {
+	ID           string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID     string     `gorm:"type:uuid;not null" json:"tenant_id"`
+	Reason       string     `gorm:"type:text;not null" json:"reason"`
+	UserID       string     `gorm:"type:text" json:"user_id"`
+	ResourceType string     `gorm:"type:text" json:"resource_type"`
+	ResourceID   string     `gorm:"type:text" json:"resource_id"`
+	StartTime    *time.Time `gorm:"type:timestamp with time zone" json:"start_time"`
+	EndTime      *time.Time `gorm:"type:timestamp with time zone" json:"end_time"`
+	LogIDs       []string   `gorm:"column:log_ids;type:jsonb;serializer:json;not null" json:"log_ids"`
+	CreatedBy    string     `gorm:"type:text" json:"created_by"`
+	ReleasedBy   string     `gorm:"type:text" json:"released_by"`
+	ReleasedAt   *time.Time `gorm:"type:timestamp with time zone" json:"released_at"`
+	CreatedAt    time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (LegalHold) TableName() string {
+	return "legal_holds"
+}
+
+// Active reports whether the hold has not been released
+func (h *LegalHold) Active() bool {
+	return h.ReleasedAt == nil
+}
+
+// HasFilter reports whether the hold selects logs by filter
+func (h *LegalHold) HasFilter() bool {
+	return h.UserID != "" || h.ResourceType != "" || h.ResourceID != "" || h.StartTime != nil || h.EndTime != nil
+}
+
+// CleanupExclusions are the logs a cleanup keeps although they are older than
+// its cutoff date
+type CleanupExclusions struct {
+	KeepSeverities []string
+	Holds          []LegalHold
+}
+
+// IsEmpty reports whether the cleanup keeps none of the logs before its cutoff
+func (e CleanupExclusions) IsEmpty() bool {
+	return len(e.KeepSeverities) == 0 && len(e.Holds) == 0
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+)
+
+// OutboxEntry is a log waiting to be published to the index queue. Entries
+// are written in the same transaction as their log, so a stored log is always
+// indexed eventually.
+type OutboxEntry struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID      string    `gorm:"type:uuid;not null" json:"tenant_id"`
+	LogID         string    `gorm:"type:uuid;not null" json:"log_id"`
+	LogTimestamp  time.Time `gorm:"type:timestamp with time zone;not null" json:"log_timestamp"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	LastError     string    `gorm:"type:text" json:"last_error"`
+	NextAttemptAt time.Time `gorm:"type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"next_attempt_at"`
+	CreatedAt     time.Time `gorm:"type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (OutboxEntry) TableName() string {
+	return "audit_log_outbox"
+}
+
+// NewOutboxEntries returns the outbox entries of the logs
+func NewOutboxEntries(logs []AuditLog) []OutboxEntry {
+	entries := make([]OutboxEntry, len(logs))
+	for i := range logs {
+		entries[i] = OutboxEntry{
+			TenantID:     logs[i].TenantID,
+			LogID:        logs[i].ID,
+			LogTimestamp: logs[i].Timestamp,
+		}
+	}
+	return entries
+}
+
+// OutboxLag describes the entries the relay has not published yet
+type OutboxLag struct {
+	Pending int64
+	// Oldest is when the oldest pending entry was written, zero when none are pending
+	Oldest time.Time
+}
+
+// outboxMaxRetryDelay caps the backoff between publish attempts
+const outboxMaxRetryDelay = 5 * time.Minute
+
+// RetryDelay is how long to wait before publishing the entry again after a
+// failed attempt. It doubles with every attempt up to five minutes.
+func (e OutboxEntry) RetryDelay() time.Duration {
+	if e.Attempts < 0 || e.Attempts >= 9 {
+		return outboxMaxRetryDelay
+	}
+	return min(time.Second<<e.Attempts, outboxMaxRetryDelay)
+}
@@ -0,0 +1,548 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// LogQuery is a parsed log search query, such as
+//
+//	severity:(ERROR OR CRITICAL) AND NOT user_id:svc-* AND metadata.region:eu
+//
+// Terms are field:value pairs combined with AND, OR, NOT and parentheses,
+// adjacent terms are ANDed. A value ending in * matches by prefix, values
+// holding spaces, colons or parentheses are quoted. A field followed by a
+// parenthesized expression applies to each value inside it. Paths into
+// metadata, before_state and after_state are dotted.
+type LogQuery struct {
+	// Raw is the query as it was written
+	Raw string
+	// Root is the root of the syntax tree
+	Root QueryNode
+}
+
+// QueryNode is a node of the syntax tree of a LogQuery
+type QueryNode interface {
+	// Matches reports whether the log satisfies the node
+	Matches(log *AuditLog) bool
+}
+
+// AndQuery matches the logs matching all of its clauses
+type AndQuery struct {
+	Clauses []QueryNode
+}
+
+// OrQuery matches the logs matching any of its clauses
+type OrQuery struct {
+	Clauses []QueryNode
+}
+
+// NotQuery matches the logs not matching its clause
+type NotQuery struct {
+	Clause QueryNode
+}
+
+// FieldQuery matches the logs whose field equals the value, or starts with it
+// for a prefix query. Text fields match when they contain the value, ignoring
+// case, or a word starting with it.
+type FieldQuery struct {
+	// Field is the queried field, the JSON field for paths
+	Field string
+	// Path is the path inside a JSON field, empty for other fields
+	Path   []string
+	Value  string
+	Prefix bool
+
+	// wordPrefix matches the words starting with the value of text prefix
+	// queries, it is compiled by the parser
+	wordPrefix *regexp.Regexp
+}
+
+// QueryFieldKind tells how the values of a queryable field are compared
+type QueryFieldKind int
+
+const (
+	// QueryFieldKeyword fields match exact values
+	QueryFieldKeyword QueryFieldKind = iota
+	// QueryFieldUUID fields are keyword fields stored as UUIDs, their values
+	// are whole UUIDs
+	QueryFieldUUID
+	// QueryFieldText fields match the values they contain
+	QueryFieldText
+	// QueryFieldIP fields match whole IP addresses
+	QueryFieldIP
+	// QueryFieldJSON fields are JSON documents queried by path
+	QueryFieldJSON
+)
+
+// queryFields are the fields a query can search and how to read them from a log
+var queryFields = map[string]struct {
+	kind  QueryFieldKind
+	value func(log *AuditLog) string
+}{
+	"id":            {QueryFieldUUID, func(log *AuditLog) string { return log.ID }},
+	"user_id":       {QueryFieldKeyword, func(log *AuditLog) string { return log.UserID }},
+	"session_id":    {QueryFieldKeyword, func(log *AuditLog) string { return log.SessionID }},
+	"action":        {QueryFieldKeyword, func(log *AuditLog) string { return log.Action }},
+	"resource_type": {QueryFieldKeyword, func(log *AuditLog) string { return log.ResourceType }},
+	"resource_id":   {QueryFieldKeyword, func(log *AuditLog) string { return log.ResourceID }},
+	"severity":      {QueryFieldKeyword, func(log *AuditLog) string { return log.Severity }},
+	"message":       {QueryFieldText, func(log *AuditLog) string { return log.Message }},
+	"user_agent":    {QueryFieldText, func(log *AuditLog) string { return log.UserAgent }},
+	"ip_address":    {QueryFieldIP, func(log *AuditLog) string { return log.IPAddress }},
+	"metadata":      {QueryFieldJSON, nil},
+	"before_state":  {QueryFieldJSON, nil},
+	"after_state":   {QueryFieldJSON, nil},
+}
+
+// Kind returns how the values of the field of the query are compared
+func (q *FieldQuery) Kind() QueryFieldKind {
+	return queryFields[q.Field].kind
+}
+
+// Name returns the dotted name of the field and path
+func (q *FieldQuery) Name() string {
+	return strings.Join(append([]string{q.Field}, q.Path...), ".")
+}
+
+func (q *AndQuery) Matches(log *AuditLog) bool {
+	for _, clause := range q.Clauses {
+		if !clause.Matches(log) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *OrQuery) Matches(log *AuditLog) bool {
+	for _, clause := range q.Clauses {
+		if clause.Matches(log) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *NotQuery) Matches(log *AuditLog) bool {
+	return !q.Clause.Matches(log)
+}
+
+func (q *FieldQuery) Matches(log *AuditLog) bool {
+	if q.Kind() == QueryFieldJSON {
+		return q.JSONFilter().Matches(log)
+	}
+
+	value := queryFields[q.Field].value(log)
+	switch {
+	case q.Kind() == QueryFieldText && q.Prefix:
+		pattern := q.wordPrefix
+		if pattern == nil {
+			// The query was not built by the parser
+			pattern = compileWordPrefix(q.Value)
+		}
+		return pattern.MatchString(value)
+	case q.Kind() == QueryFieldText:
+		return containsFold(value, q.Value)
+	case q.Prefix:
+		return strings.HasPrefix(value, q.Value)
+	default:
+		return value == q.Value
+	}
+}
+
+// compileWordPrefix returns the pattern of the words starting with the prefix,
+// ignoring case
+func compileWordPrefix(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(prefix))
+}
+
+// JSONFilter returns the JSON path filter equivalent to a query on a JSON path
+func (q *FieldQuery) JSONFilter() *JSONFilter {
+	op := JSONFilterEq
+	if q.Prefix {
+		op = JSONFilterPrefix
+	}
+	return &JSONFilter{Field: q.Field, Path: q.Path, Op: op, Value: q.Value}
+}
+
+// logJSONField returns the JSON field of the log with the given name
+func logJSONField(log *AuditLog, field string) json.RawMessage {
+	switch field {
+	case "metadata":
+		return log.Metadata
+	case "before_state":
+		return log.BeforeState
+	case "after_state":
+		return log.AfterState
+	}
+	return nil
+}
+
+// MarshalJSON stores the query as it was written, so that filters keep their
+// query when they are persisted
+func (q *LogQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.Raw)
+}
+
+func (q *LogQuery) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := ParseLogQuery(raw)
+	if err != nil {
+		return err
+	}
+	*q = *parsed
+	return nil
+}
+
+// Matches reports whether the log satisfies the query
+func (q *LogQuery) Matches(log *AuditLog) bool {
+	return q.Root.Matches(log)
+}
+
+// JSONFilters returns the filters equivalent to the terms of the query on JSON
+// paths, regardless of how the terms are combined
+func (q *LogQuery) JSONFilters() []JSONFilter {
+	var filters []JSONFilter
+	var walk func(node QueryNode)
+	walk = func(node QueryNode) {
+		switch n := node.(type) {
+		case *AndQuery:
+			for _, clause := range n.Clauses {
+				walk(clause)
+			}
+		case *OrQuery:
+			for _, clause := range n.Clauses {
+				walk(clause)
+			}
+		case *NotQuery:
+			walk(n.Clause)
+		case *FieldQuery:
+			if n.Kind() == QueryFieldJSON {
+				filters = append(filters, *n.JSONFilter())
+			}
+		}
+	}
+	walk(q.Root)
+	return filters
+}
+
+// QuerySyntaxError reports where a query failed to parse
+type QuerySyntaxError struct {
+	// Position is the 1-based position of the offending character
+	Position int
+	Message  string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Position, e.Message)
+}
+
+// ParseLogQuery parses a log search query. Errors are *QuerySyntaxError.
+func ParseLogQuery(s string) (*LogQuery, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "query is empty")
+	}
+	root, err := p.parseOr(nil)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+
+	return &LogQuery{Raw: s, Root: root}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenColon
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	// pos is the 1-based position of the first character of the token
+	pos int
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+// isKeyword reports whether the token is the operator keyword, which is only
+// an operator when it is unquoted and upper case
+func (t queryToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && t.text == keyword
+}
+
+func lexQuery(s string) ([]queryToken, error) {
+	runes := []rune(s)
+	var tokens []queryToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, text: "(", pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen, text: ")", pos: i + 1})
+			i++
+		case r == ':':
+			tokens = append(tokens, queryToken{kind: tokenColon, text: ":", pos: i + 1})
+			i++
+		case r == '"':
+			start := i
+			var text strings.Builder
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, &QuerySyntaxError{Position: start + 1, Message: "unterminated quoted value"}
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				} else if runes[i] == '"' {
+					break
+				}
+				text.WriteRune(runes[i])
+			}
+			i++
+			tokens = append(tokens, queryToken{kind: tokenString, text: text.String(), pos: start + 1})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`():"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenWord, text: string(runes[start:i]), pos: start + 1})
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// queryParser is a recursive descent parser of the query grammar
+//
+//	or      = and { "OR" and }
+//	and     = not { ["AND"] not }
+//	not     = "NOT" not | primary
+//	primary = "(" or ")" | field ":" value   outside of a field
+//	        | "(" or ")" | value             inside of a field
+//	value   = "(" or ")" | word | string
+//
+// The field of a parenthesized value applies to the values inside it.
+type queryParser struct {
+	tokens []queryToken
+	next   int
+}
+
+// queryFieldRef is the field the values being parsed belong to
+type queryFieldRef struct {
+	field string
+	path  []string
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.next]
+}
+
+func (p *queryParser) take() queryToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, args ...any) error {
+	return &QuerySyntaxError{Position: tok.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parseOr(field *queryFieldRef) (QueryNode, error) {
+	node, err := p.parseAnd(field)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := []QueryNode{node}
+	for p.peek().isKeyword("OR") {
+		p.take()
+		node, err := p.parseAnd(field)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, node)
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return &OrQuery{Clauses: clauses}, nil
+}
+
+func (p *queryParser) parseAnd(field *queryFieldRef) (QueryNode, error) {
+	node, err := p.parseNot(field)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := []QueryNode{node}
+	for {
+		tok := p.peek()
+		if tok.isKeyword("AND") {
+			p.take()
+		} else if tok.kind == tokenEOF || tok.kind == tokenRParen || tok.isKeyword("OR") {
+			break
+		}
+		node, err := p.parseNot(field)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, node)
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return &AndQuery{Clauses: clauses}, nil
+}
+
+func (p *queryParser) parseNot(field *queryFieldRef) (QueryNode, error) {
+	if !p.peek().isKeyword("NOT") {
+		return p.parsePrimary(field)
+	}
+	p.take()
+
+	node, err := p.parseNot(field)
+	if err != nil {
+		return nil, err
+	}
+	return &NotQuery{Clause: node}, nil
+}
+
+func (p *queryParser) parsePrimary(field *queryFieldRef) (QueryNode, error) {
+	tok := p.peek()
+	if tok.kind == tokenLParen {
+		return p.parseGroup(field)
+	}
+	if field != nil {
+		return p.parseValue(field)
+	}
+
+	if tok.kind != tokenWord || tok.isKeyword("AND") || tok.isKeyword("OR") || tok.isKeyword("NOT") {
+		return nil, p.errorf(tok, "expected a field, found %s", tok)
+	}
+	p.take()
+	ref, err := p.resolveField(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	if colon := p.take(); colon.kind != tokenColon {
+		return nil, p.errorf(colon, "expected ':' after field %s, found %s", tok.text, colon)
+	}
+	if p.peek().kind == tokenLParen {
+		return p.parseGroup(ref)
+	}
+	return p.parseValue(ref)
+}
+
+// parseGroup parses a parenthesized expression
+func (p *queryParser) parseGroup(field *queryFieldRef) (QueryNode, error) {
+	open := p.take()
+	node, err := p.parseOr(field)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.take(); tok.kind != tokenRParen {
+		if tok.kind == tokenEOF {
+			return nil, p.errorf(open, "unclosed parenthesis")
+		}
+		return nil, p.errorf(tok, "expected ')', found %s", tok)
+	}
+	return node, nil
+}
+
+// parseValue parses a single value of the field
+func (p *queryParser) parseValue(field *queryFieldRef) (QueryNode, error) {
+	tok := p.take()
+	if tok.kind != tokenWord && tok.kind != tokenString {
+		return nil, p.errorf(tok, "expected a value for field %s, found %s", field.field, tok)
+	}
+
+	query := &FieldQuery{Field: field.field, Path: field.path, Value: tok.text}
+	if tok.kind == tokenWord {
+		if star := strings.IndexRune(tok.text, '*'); star >= 0 {
+			if star != len(tok.text)-1 {
+				return nil, p.errorf(tok, "wildcards are only supported at the end of a value")
+			}
+			query.Value, query.Prefix = tok.text[:star], true
+		}
+	}
+
+	if query.Prefix && (query.Kind() == QueryFieldIP || query.Kind() == QueryFieldUUID) {
+		return nil, p.errorf(tok, "prefix matches are not supported on %s", field.field)
+	}
+	if query.Prefix && query.Value == "" {
+		return nil, p.errorf(tok, "a prefix needs at least one character before the wildcard")
+	}
+
+	switch {
+	case query.Kind() == QueryFieldUUID:
+		// Compared as stored, the database rejects anything else
+		id, err := uuid.Parse(query.Value)
+		if err != nil {
+			return nil, p.errorf(tok, "%s is not a valid UUID", strconv.Quote(query.Value))
+		}
+		query.Value = id.String()
+	case query.Kind() == QueryFieldText && query.Prefix:
+		query.wordPrefix = compileWordPrefix(query.Value)
+	}
+	return query, nil
+}
+
+// resolveField checks the field of a term and splits the path off JSON fields
+func (p *queryParser) resolveField(tok queryToken) (*queryFieldRef, error) {
+	parts := strings.Split(tok.text, ".")
+	spec, ok := queryFields[parts[0]]
+	if !ok {
+		return nil, p.errorf(tok, "unknown field %s", parts[0])
+	}
+
+	ref := &queryFieldRef{field: parts[0], path: parts[1:]}
+	if spec.kind == QueryFieldJSON {
+		if len(ref.path) == 0 {
+			return nil, p.errorf(tok, "field %s needs a path, such as %s.key", ref.field, ref.field)
+		}
+		if _, _, err := SplitJSONPath(tok.text); err != nil {
+			return nil, p.errorf(tok, "%s", err)
+		}
+	} else if len(ref.path) > 0 {
+		return nil, p.errorf(tok, "field %s has no nested fields", ref.field)
+	}
+	return ref, nil
+}
@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+}
+
+func TestQuery(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+// render writes the syntax tree with every group parenthesized and prefixes
+// marked by a trailing *
+func render(node QueryNode) string {
+	switch n := node.(type) {
+	case *AndQuery:
+		return renderClauses(n.Clauses, " AND ")
+	case *OrQuery:
+		return renderClauses(n.Clauses, " OR ")
+	case *NotQuery:
+		return "NOT " + render(n.Clause)
+	case *FieldQuery:
+		value := fmt.Sprintf("%q", n.Value)
+		if n.Prefix {
+			value += "*"
+		}
+		return n.Name() + ":" + value
+	}
+	return "?"
+}
+
+func renderClauses(clauses []QueryNode, sep string) string {
+	rendered := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		rendered = append(rendered, render(clause))
+	}
+	return "(" + strings.Join(rendered, sep) + ")"
+}
+
+func (s *QueryTestSuite) TestParseLogQuery() {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "term", query: "severity:ERROR", want: `severity:"ERROR"`},
+		{name: "adjacent terms are ANDed", query: "severity:ERROR action:login", want: `(severity:"ERROR" AND action:"login")`},
+		{name: "AND binds tighter than OR", query: "action:a OR action:b AND action:c", want: `(action:"a" OR (action:"b" AND action:"c"))`},
+		{name: "NOT binds tightest", query: "NOT action:a AND action:b", want: `(NOT action:"a" AND action:"b")`},
+		{name: "double negation", query: "NOT NOT action:a", want: `NOT NOT action:"a"`},
+		{name: "parentheses", query: "(action:a OR action:b) AND action:c", want: `((action:"a" OR action:"b") AND action:"c")`},
+		{name: "field group", query: "severity:(ERROR OR CRITICAL)", want: `(severity:"ERROR" OR severity:"CRITICAL")`},
+		{name: "field group with NOT", query: "action:(NOT a b)", want: `(NOT action:"a" AND action:"b")`},
+		{name: "lower case keywords are values", query: "action:(and or)", want: `(action:"and" AND action:"or")`},
+		{name: "quoted value", query: `message:"disk full: retry (later)"`, want: `message:"disk full: retry (later)"`},
+		{name: "quoted keyword", query: `action:"OR"`, want: `action:"OR"`},
+		{name: "escaped quote", query: `message:"say \"hi\""`, want: `message:"say \"hi\""`},
+		{name: "quoted wildcard is literal", query: `resource_id:"doc*"`, want: `resource_id:"doc*"`},
+		{name: "keyword prefix", query: "user_id:svc-*", want: `user_id:"svc-"*`},
+		{name: "text prefix", query: "message:fail*", want: `message:"fail"*`},
+		{name: "JSON path", query: "metadata.order.id:42", want: `metadata.order.id:"42"`},
+		{name: "JSON path prefix", query: "after_state.name:new*", want: `after_state.name:"new"*`},
+		{name: "UUID is normalized", query: "id:3F2504E0-4F89-11D3-9A0C-0305E82C3301", want: `id:"3f2504e0-4f89-11d3-9a0c-0305e82c3301"`},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Act
+			query, err := ParseLogQuery(tt.query)
+
+			// Assert
+			s.Require().NoError(err)
+			s.Equal(tt.query, query.Raw)
+			s.Equal(tt.want, render(query.Root))
+		})
+	}
+}
+
+func (s *QueryTestSuite) TestParseLogQuery_Errors() {
+	tests := []struct {
+		name     string
+		query    string
+		position int
+		message  string
+	}{
+		{name: "empty", query: "  ", position: 3, message: "query is empty"},
+		{name: "unknown field", query: "action:a colour:red", position: 10, message: "unknown field colour"},
+		{name: "missing colon", query: "action a", position: 8, message: "expected ':' after field action, found 'a'"},
+		{name: "missing value", query: "action:", position: 8, message: "expected a value for field action, found end of query"},
+		{name: "missing field", query: "action:a AND OR", position: 14, message: "expected a field, found 'OR'"},
+		{name: "unclosed parenthesis", query: "action:a AND (action:b", position: 14, message: "unclosed parenthesis"},
+		{name: "stray parenthesis", query: "action:a)", position: 9, message: "unexpected ')'"},
+		{name: "unterminated quote", query: `message:"disk`, position: 9, message: "unterminated quoted value"},
+		{name: "inner wildcard", query: "action:lo*in", position: 8, message: "wildcards are only supported at the end of a value"},
+		{name: "lone wildcard", query: "action:*", position: 8, message: "a prefix needs at least one character before the wildcard"},
+		{name: "IP prefix", query: "ip_address:10.0.*", position: 12, message: "prefix matches are not supported on ip_address"},
+		{name: "UUID prefix", query: "id:3f25*", position: 4, message: "prefix matches are not supported on id"},
+		{name: "invalid UUID", query: "severity:ERROR id:log1", position: 19, message: `"log1" is not a valid UUID`},
+		{name: "JSON field without path", query: "metadata:eu", position: 1, message: "field metadata needs a path, such as metadata.key"},
+		{name: "nested keyword field", query: "action.type:a", position: 1, message: "field action has no nested fields"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Act
+			query, err := ParseLogQuery(tt.query)
+
+			// Assert
+			s.Nil(query)
+			var syntaxErr *QuerySyntaxError
+			s.Require().ErrorAs(err, &syntaxErr)
+			s.Equal(tt.position, syntaxErr.Position)
+			s.Equal(tt.message, syntaxErr.Message)
+		})
+	}
+}
+
+func (s *QueryTestSuite) TestMatches() {
+	log := &AuditLog{
+		ID:        "3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+		UserID:    "svc-billing",
+		Action:    "update",
+		Severity:  "ERROR",
+		Message:   "Payment FAILED for order 42",
+		IPAddress: "10.0.0.1",
+		Metadata:  json.RawMessage(`{"region":"eu","order":{"id":42}}`),
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "id:3F2504E0-4F89-11D3-9A0C-0305E82C3301", want: true},
+		{query: "user_id:svc-billing", want: true},
+		{query: "user_id:svc-*", want: true},
+		{query: "user_id:billing*", want: false},
+		{query: "action:UPDATE", want: false},
+		{query: "message:failed", want: true},
+		{query: "message:ment", want: true},
+		{query: "message:fail*", want: true},
+		{query: "message:ail*", want: false},
+		{query: "ip_address:10.0.0.1", want: true},
+		{query: "metadata.region:eu", want: true},
+		{query: "metadata.order.id:42", want: true},
+		{query: "metadata.region:e*", want: true},
+		{query: "severity:(WARNING OR ERROR) AND NOT action:delete", want: true},
+		{query: "NOT user_agent:curl", want: true},
+		{query: "severity:ERROR action:delete", want: false},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.query, func() {
+			// Arrange
+			query, err := ParseLogQuery(tt.query)
+			s.Require().NoError(err)
+
+			// Act
+			matches := query.Matches(log)
+
+			// Assert
+			s.Equal(tt.want, matches)
+		})
+	}
+}
+
+func (s *QueryTestSuite) TestMatches_TextPrefixBuiltWithoutParser() {
+	// Arrange
+	query := &FieldQuery{Field: "message", Value: "pay", Prefix: true}
+
+	// Act
+	matches := query.Matches(&AuditLog{Message: "Payment failed"})
+
+	// Assert
+	s.True(matches)
+}
+
+func (s *QueryTestSuite) TestJSONFilters_CollectsNestedPathTerms() {
+	// Arrange
+	query, err := ParseLogQuery("action:a OR NOT (metadata.region:eu AND after_state.name:new*)")
+	s.Require().NoError(err)
+
+	// Act
+	filters := query.JSONFilters()
+
+	// Assert
+	s.Equal([]JSONFilter{
+		{Field: "metadata", Path: []string{"region"}, Op: JSONFilterEq, Value: "eu"},
+		{Field: "after_state", Path: []string{"name"}, Op: JSONFilterPrefix, Value: "new"},
+	}, filters)
+}
+
+func (s *QueryTestSuite) TestUnmarshalJSON_ParsesRawQuery() {
+	// Arrange
+	var query LogQuery
+
+	// Act
+	err := json.Unmarshal([]byte(`"severity:ERROR"`), &query)
+	data, marshalErr := json.Marshal(&query)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(`severity:"ERROR"`, render(query.Root))
+	s.NoError(marshalErr)
+	s.Equal(`"severity:ERROR"`, string(data))
+}
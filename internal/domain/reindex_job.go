@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+)
+
+// DayLayout is the layout of the days a reindex job compares
+const DayLayout = "2006-01-02"
+
+type ReindexJobStatus string
+
+const (
+	ReindexJobPending   ReindexJobStatus = "PENDING"
+	ReindexJobRunning   ReindexJobStatus = "RUNNING"
+	ReindexJobCompleted ReindexJobStatus = "COMPLETED"
+	ReindexJobFailed    ReindexJobStatus = "FAILED"
+)
+
+// ReindexJob compares the logs in PostgreSQL with the logs in OpenSearch per
+// tenant and per UTC day, and indexes the logs missing from OpenSearch unless
+// it is a dry run. It covers every tenant when TenantID is nil.
+type ReindexJob struct {
+	ID         string           `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID   *string          `gorm:"type:uuid" json:"tenant_id"`
+	StartDate  time.Time        `gorm:"type:date;not null" json:"start_date"`
+	EndDate    time.Time        `gorm:"type:date;not null" json:"end_date"`
+	DryRun     bool             `gorm:"not null;default:false" json:"dry_run"`
+	CompareIDs bool             `gorm:"column:compare_ids;not null;default:false" json:"compare_ids"`
+	Status     ReindexJobStatus `gorm:"type:text;not null;default:'PENDING'" json:"status"`
+	// CursorTenantID and CursorDate are the last tenant and day the job completed
+	CursorTenantID *string      `gorm:"type:uuid" json:"cursor_tenant_id"`
+	CursorDate     *time.Time   `gorm:"type:date" json:"cursor_date"`
+	CheckedDays    int64        `gorm:"not null;default:0" json:"checked_days"`
+	DriftedDays    int64        `gorm:"not null;default:0" json:"drifted_days"`
+	MissingLogs    int64        `gorm:"not null;default:0" json:"missing_logs"`
+	ExtraLogs      int64        `gorm:"not null;default:0" json:"extra_logs"`
+	ReindexedLogs  int64        `gorm:"not null;default:0" json:"reindexed_logs"`
+	Drift          []IndexDrift `gorm:"type:jsonb;serializer:json;not null" json:"drift"`
+	Error          string       `gorm:"type:text" json:"error"`
+	CreatedBy      string       `gorm:"type:text" json:"created_by"`
+	CreatedAt      time.Time    `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time    `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	StartedAt      *time.Time   `gorm:"type:timestamp with time zone" json:"started_at"`
+	CompletedAt    *time.Time   `gorm:"type:timestamp with time zone" json:"completed_at"`
+}
+
+func (ReindexJob) TableName() string {
+	return "reindex_jobs"
+}
+
+// IndexDrift is a day on which the logs of a tenant in OpenSearch differ from
+// the logs in PostgreSQL
+type IndexDrift struct {
+	TenantID        string `json:"tenant_id"`
+	Day             string `json:"day"`
+	PostgresCount   int64  `json:"postgres_count"`
+	OpenSearchCount int64  `json:"opensearch_count"`
+	// MissingLogs is the number of logs found in PostgreSQL only
+	MissingLogs int64 `json:"missing_logs"`
+}
+
+// Days returns the UTC days the job covers, in order
+func (j *ReindexJob) Days() []time.Time {
+	var days []time.Time
+	end := j.EndDate.UTC().Truncate(24 * time.Hour)
+	for day := j.StartDate.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// Completed reports whether the job has already compared the day of the
+// tenant, tenants being compared in ID order
+func (j *ReindexJob) Completed(tenantID string, day time.Time) bool {
+	if j.CursorTenantID == nil || j.CursorDate == nil {
+		return false
+	}
+	if tenantID != *j.CursorTenantID {
+		return tenantID < *j.CursorTenantID
+	}
+	return !day.After(*j.CursorDate)
+}
+
+// Advance moves the cursor past the day of the tenant
+func (j *ReindexJob) Advance(tenantID string, day time.Time) {
+	j.CursorTenantID = &tenantID
+	j.CursorDate = &day
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// archiveDateLayout is the layout of the date in the name of an archive
+const archiveDateLayout = "2006-01-02_15-04-05"
+
+type RestoreJobStatus string
+
+const (
+	RestoreJobPending   RestoreJobStatus = "PENDING"
+	RestoreJobRunning   RestoreJobStatus = "RUNNING"
+	RestoreJobCompleted RestoreJobStatus = "COMPLETED"
+	RestoreJobFailed    RestoreJobStatus = "FAILED"
+)
+
+// RestoreJob brings the logs of an S3 archive back into PostgreSQL and
+// OpenSearch. The counters are updated as the job progresses.
+type RestoreJob struct {
+	ID           string           `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TenantID     string           `gorm:"type:uuid;not null" json:"tenant_id"`
+	ArchiveKey   string           `gorm:"type:text;not null" json:"archive_key"`
+	Status       RestoreJobStatus `gorm:"type:text;not null;default:'PENDING'" json:"status"`
+	TotalLogs    int64            `gorm:"not null;default:0" json:"total_logs"`
+	RestoredLogs int64            `gorm:"not null;default:0" json:"restored_logs"`
+	SkippedLogs  int64            `gorm:"not null;default:0" json:"skipped_logs"`
+	IndexedLogs  int64            `gorm:"not null;default:0" json:"indexed_logs"`
+	ChainValid   *bool            `json:"chain_valid"`
+	Error        string           `gorm:"type:text" json:"error"`
+	CreatedAt    time.Time        `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time        `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	StartedAt    *time.Time       `gorm:"type:timestamp with time zone" json:"started_at"`
+	CompletedAt  *time.Time       `gorm:"type:timestamp with time zone" json:"completed_at"`
+}
+
+func (RestoreJob) TableName() string {
+	return "restore_jobs"
+}
+
+// Archive is the document the archive worker writes to S3
+type Archive struct {
+	TenantID   string       `json:"tenant_id"`
+	BeforeDate time.Time    `json:"before_date"`
+	ArchivedAt time.Time    `json:"archived_at"`
+	LogCount   int          `json:"log_count"`
+	Chain      *ChainAnchor `json:"chain"`
+	Logs       []AuditLog   `json:"logs"`
+}
+
+// ArchiveKeyPrefix returns the S3 prefix under which the archives of a tenant are stored
+func ArchiveKeyPrefix(tenantID string) string {
+	return "audit-logs/" + tenantID + "/"
+}
+
+// ArchiveKey returns the S3 key of the archive of the logs of a tenant before a date
+func ArchiveKey(tenantID string, beforeDate time.Time) string {
+	return ArchiveKeyPrefix(tenantID) + "audit_logs_" + tenantID + "_before_" + beforeDate.UTC().Format(archiveDateLayout) + ".json"
+}
+
+// ParseArchiveBeforeDate returns the date an archive was cut at from its key.
+// The archive holds the logs before that date that were not archived earlier.
+func ParseArchiveBeforeDate(key string) (time.Time, bool) {
+	i := strings.LastIndex(key, "_before_")
+	if i < 0 || !strings.HasSuffix(key, ".json") {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(archiveDateLayout, strings.TrimSuffix(key[i+len("_before_"):], ".json"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// ArchiveObject describes an archive stored in S3
+type ArchiveObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
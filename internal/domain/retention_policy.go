@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"time"
+)
+
+// RetentionPolicy decides how long the logs of a tenant stay in the live
+// stores. Logs are archived to S3 once they are ArchiveAfterDays old and
+// deleted once they are DeleteAfterDays old, unless their severity is kept.
+type RetentionPolicy struct {
+	TenantID         string     `gorm:"primaryKey;type:uuid" json:"tenant_id"`
+	ArchiveAfterDays int        `gorm:"not null" json:"archive_after_days"`
+	DeleteAfterDays  int        `gorm:"not null;default:0" json:"delete_after_days"`
+	KeepSeverities   []string   `gorm:"type:jsonb;serializer:json;not null" json:"keep_severities"`
+	Enabled          bool       `gorm:"not null;default:true" json:"enabled"`
+	LastScheduledAt  *time.Time `gorm:"type:timestamp with time zone" json:"last_scheduled_at"`
+	CreatedAt        time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (RetentionPolicy) TableName() string {
+	return "retention_policies"
+}
+
+// ArchiveBefore returns the date before which logs are archived. It is
+// truncated to the day, so every run on the same day writes the same archive.
+func (p *RetentionPolicy) ArchiveBefore(now time.Time) time.Time {
+	return now.UTC().AddDate(0, 0, -p.ArchiveAfterDays).Truncate(24 * time.Hour)
+}
+
+// DeleteBefore limits a requested cleanup date to the retention period of the
+// policy. It returns false when the policy never deletes logs.
+func (p *RetentionPolicy) DeleteBefore(requested, now time.Time) (time.Time, bool) {
+	if p.DeleteAfterDays <= 0 {
+		return time.Time{}, false
+	}
+	cutoff := now.UTC().AddDate(0, 0, -p.DeleteAfterDays).Truncate(24 * time.Hour)
+	if requested.Before(cutoff) {
+		return requested, true
+	}
+	return cutoff, true
+}
+
+// RetentionProgress records how far the logs of a tenant have been archived,
+// and the progress of its latest cleanup
+type RetentionProgress struct {
+	TenantID string `gorm:"primaryKey;type:uuid" json:"tenant_id"`
+	// ArchivedBefore is the date before which every log is archived to S3
+	ArchivedBefore *time.Time `gorm:"type:timestamp with time zone" json:"archived_before"`
+	// CleanupBefore is the cutoff date of the latest cleanup
+	CleanupBefore *time.Time `gorm:"type:timestamp with time zone" json:"cleanup_before"`
+	// CleanedUpTo is the date up to which the latest cleanup has completed
+	CleanedUpTo   *time.Time `gorm:"type:timestamp with time zone" json:"cleaned_up_to"`
+	DeletedLogs   int64      `gorm:"not null;default:0" json:"deleted_logs"`
+	HeldLogs      int64      `gorm:"not null;default:0" json:"held_logs"`
+	DroppedChunks int        `gorm:"not null;default:0" json:"dropped_chunks"`
+	// CleanupStartedAt is when the running cleanup started, nil when no
+	// cleanup runs
+	CleanupStartedAt *time.Time `gorm:"type:timestamp with time zone" json:"cleanup_started_at"`
+	UpdatedAt        time.Time  `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+func (RetentionProgress) TableName() string {
+	return "retention_progress"
+}
+
+// Resumes reports whether a cleanup before the date continues the latest
+// cleanup rather than starting a new one
+func (p *RetentionProgress) Resumes(beforeDate time.Time) bool {
+	return p.CleanupBefore != nil && p.CleanupBefore.Equal(beforeDate) && p.CleanedUpTo != nil
+}
+
+// LogChunk is a TimescaleDB chunk of the audit_logs hypertable. It holds the
+// logs of every tenant with a timestamp in [RangeStart, RangeEnd).
+type LogChunk struct {
+	Schema     string    `gorm:"column:chunk_schema"`
+	Name       string    `gorm:"column:chunk_name"`
+	RangeStart time.Time `gorm:"column:range_start"`
+	RangeEnd   time.Time `gorm:"column:range_end"`
+	Compressed bool      `gorm:"column:is_compressed"`
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxSessionValues bounds the distinct users, IP addresses and user agents
+// reported for a session
+const MaxSessionValues = 100
+
+// SessionSummary sums up the logs sharing a session ID
+type SessionSummary struct {
+	SessionID    string
+	UserIDs      []string
+	StartTime    time.Time
+	EndTime      time.Time
+	EventCount   int64
+	IPAddresses  []string
+	UserAgents   []string
+	ActionCounts map[string]int64
+	FirstEvent   *AuditLog
+	LastEvent    *AuditLog
+}
+
+// NewSessionSummary returns an empty summary of a session
+func NewSessionSummary(sessionID string) *SessionSummary {
+	return &SessionSummary{
+		SessionID:    sessionID,
+		UserIDs:      []string{},
+		IPAddresses:  []string{},
+		UserAgents:   []string{},
+		ActionCounts: make(map[string]int64),
+	}
+}
+
+// Add folds a log of the session into the summary
+func (s *SessionSummary) Add(log *AuditLog) {
+	s.EventCount++
+	s.ActionCounts[log.Action]++
+	s.UserIDs = addSessionValue(s.UserIDs, log.UserID)
+	s.IPAddresses = addSessionValue(s.IPAddresses, log.IPAddress)
+	s.UserAgents = addSessionValue(s.UserAgents, log.UserAgent)
+
+	if s.FirstEvent == nil || CompareLogs(log, s.FirstEvent) > 0 {
+		first := *log
+		s.FirstEvent = &first
+		s.StartTime = log.Timestamp
+	}
+	if s.LastEvent == nil || CompareLogs(log, s.LastEvent) < 0 {
+		last := *log
+		s.LastEvent = &last
+		s.EndTime = log.Timestamp
+	}
+}
+
+// addSessionValue adds a value to the sorted distinct values, up to
+// MaxSessionValues of them
+func addSessionValue(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	i, found := slices.BinarySearch(values, value)
+	if found || len(values) >= MaxSessionValues {
+		return values
+	}
+	return slices.Insert(values, i, value)
+}
+
+// CompareSessions orders sessions by their latest event, most recent first
+func CompareSessions(a, b *SessionSummary) int {
+	if c := b.EndTime.Compare(a.EndTime); c != 0 {
+		return c
+	}
+	return strings.Compare(b.SessionID, a.SessionID)
+}
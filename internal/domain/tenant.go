@@ -1,17 +1,82 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
 type Tenant struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	Name      string    `gorm:"type:text;not null" json:"name"`
-	RateLimit int       `gorm:"not null;default:1000" json:"rate_limit"`
-	CreatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID        string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Name      string `gorm:"type:text;not null" json:"name"`
+	RateLimit int    `gorm:"not null;default:1000" json:"rate_limit"`
+	// IndexedPaths are the paths inside the JSON fields of the logs that are
+	// mapped in OpenSearch, the JSON fields are not mapped dynamically
+	IndexedPaths []IndexedPath `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"indexed_paths"`
+	CreatedAt    time.Time     `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time     `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 func (Tenant) TableName() string {
 	return "tenants"
 }
+
+// Types of the indexed paths, as mapped in OpenSearch
+const (
+	IndexedPathKeyword = "keyword"
+	IndexedPathLong    = "long"
+	IndexedPathDouble  = "double"
+	IndexedPathDate    = "date"
+)
+
+// ErrIndexedPathConflict is returned when a path is already mapped in the
+// indices of the tenant with another type
+var ErrIndexedPathConflict = errors.New("indexed path conflicts with the existing mapping")
+
+// IndexedPath is a path inside metadata, before_state or after_state whose
+// values are indexed in OpenSearch
+type IndexedPath struct {
+	// Path is dotted, such as metadata.request_id
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// Searches reports whether OpenSearch can answer the JSON path filters for
+// the tenant: each path is indexed with a type its compared value fits
+func (t *Tenant) Searches(filters []JSONFilter) bool {
+	for i := range filters {
+		if !t.searches(&filters[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Tenant) searches(f *JSONFilter) bool {
+	for _, indexed := range t.IndexedPaths {
+		if indexed.Path == f.Name() {
+			return indexed.accepts(f)
+		}
+	}
+	return false
+}
+
+// accepts reports whether the filter compares the indexed values like the
+// other stores do
+func (p IndexedPath) accepts(f *JSONFilter) bool {
+	if f.Op == JSONFilterExists {
+		return true
+	}
+	_, number := ParseJSONNumber(f.Value)
+
+	switch p.Type {
+	case IndexedPathKeyword:
+		// Ranges over numbers compare them as numbers, not as keywords
+		return f.Op == JSONFilterEq || f.Op == JSONFilterPrefix || !number
+	case IndexedPathLong, IndexedPathDouble:
+		return f.Op != JSONFilterPrefix && number
+	case IndexedPathDate:
+		_, err := time.Parse(time.RFC3339, f.Value)
+		return f.Op != JSONFilterPrefix && err == nil
+	}
+	return false
+}
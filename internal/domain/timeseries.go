@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatsDimension is a field the counts of a time series can be grouped by
+type StatsDimension string
+
+const (
+	StatsByAction       StatsDimension = "action"
+	StatsBySeverity     StatsDimension = "severity"
+	StatsByResourceType StatsDimension = "resource_type"
+	StatsByUserID       StatsDimension = "user_id"
+)
+
+// StatsOtherGroup is the value of each dimension of the group counting the
+// logs beyond the top groups of a time series
+const StatsOtherGroup = "other"
+
+// timeBucketOrigin is the origin TimescaleDB aligns the buckets of
+// time_bucket to
+var timeBucketOrigin = time.Date(2000, 1, 3, 0, 0, 0, 0, time.UTC)
+
+// TimeSeriesQuery asks for the number of logs matching the filter in each
+// bucket of the interval between its start and end time, per group of values
+// of the dimensions. The groups beyond the TopN with the most logs over the
+// whole range are folded into one group whose values are StatsOtherGroup.
+type TimeSeriesQuery struct {
+	Filter   AuditLogFilter
+	Interval time.Duration
+	GroupBy  []StatsDimension
+	TopN     int
+}
+
+// TimeSeriesCount is the number of logs of a group in a bucket. The values of
+// the dimensions that are not grouped by are empty.
+type TimeSeriesCount struct {
+	Bucket       time.Time
+	Action       string
+	Severity     string
+	ResourceType string
+	UserID       string
+	Count        int64
+}
+
+// ParseStatsDimensions parses a comma separated list of dimensions
+func ParseStatsDimensions(s string) ([]StatsDimension, error) {
+	var dims []StatsDimension
+	for _, name := range strings.Split(s, ",") {
+		dim := StatsDimension(strings.TrimSpace(name))
+		switch dim {
+		case "":
+			continue
+		case StatsByAction, StatsBySeverity, StatsByResourceType, StatsByUserID:
+		default:
+			return nil, fmt.Errorf("unknown group_by dimension %q, expected action, severity, resource_type or user_id", dim)
+		}
+		for _, other := range dims {
+			if other == dim {
+				return nil, fmt.Errorf("duplicate group_by dimension %q", dim)
+			}
+		}
+		dims = append(dims, dim)
+	}
+	return dims, nil
+}
+
+// AlignToBuckets widens the time range of the filter to whole buckets, as
+// time_bucket aligns them, so that the first and last bucket count all of
+// their logs
+func (q *TimeSeriesQuery) AlignToBuckets() {
+	q.Filter.StartTime = q.bucketOf(q.Filter.StartTime)
+	q.Filter.EndTime = q.bucketOf(q.Filter.EndTime).Add(q.Interval)
+}
+
+// Buckets returns the start of each bucket in the time range of the filter
+func (q *TimeSeriesQuery) Buckets() []time.Time {
+	var buckets []time.Time
+	for bucket := q.bucketOf(q.Filter.StartTime); bucket.Before(q.Filter.EndTime); bucket = bucket.Add(q.Interval) {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+func (q *TimeSeriesQuery) bucketOf(t time.Time) time.Time {
+	offset := t.Sub(timeBucketOrigin)
+	rem := offset % q.Interval
+	if rem < 0 {
+		rem += q.Interval
+	}
+	return timeBucketOrigin.Add(offset - rem)
+}
+
+// Value returns the value of a dimension of the group
+func (c *TimeSeriesCount) Value(dim StatsDimension) string {
+	switch dim {
+	case StatsByAction:
+		return c.Action
+	case StatsBySeverity:
+		return c.Severity
+	case StatsByResourceType:
+		return c.ResourceType
+	case StatsByUserID:
+		return c.UserID
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+// Package metrics holds the Prometheus metrics of the workers
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// OutboxLagSeconds is the age of the oldest outbox entry not yet published
+	OutboxLagSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "audit_log_outbox_lag_seconds",
+		Help: "Age in seconds of the oldest audit log outbox entry not yet published to the index queue.",
+	})
+
+	// OutboxPending is the number of outbox entries not yet published
+	OutboxPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "audit_log_outbox_pending",
+		Help: "Number of audit log outbox entries not yet published to the index queue.",
+	})
+
+	// OutboxPublished counts the entries published to the index queue
+	OutboxPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "audit_log_outbox_published_total",
+		Help: "Audit log outbox entries published to the index queue.",
+	})
+
+	// OutboxFailed counts the publish attempts that failed and were scheduled again
+	OutboxFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "audit_log_outbox_failed_total",
+		Help: "Audit log outbox entries that failed to publish and were scheduled again.",
+	})
+)
@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewServer returns a server exposing the metrics on /metrics
+func NewServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/buiminhduc234/audit-log-api/internal/service/ratelimit"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// RateLimiter consumes units of a tenant's ingestion budget
+type RateLimiter interface {
+	Allow(ctx context.Context, tenantID string, cost int) (*ratelimit.Result, error)
+}
+
+// CostFunc returns the number of log entries a request ingests
+type CostFunc func(c *gin.Context) int
+
+type RateLimitMiddleware struct {
+	limiter RateLimiter
+	logger  *logger.Logger
+}
+
+func NewRateLimitMiddleware(limiter RateLimiter, logger *logger.Logger) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter: limiter,
+		logger:  logger,
+	}
+}
+
+// SingleEntry is the cost of a request that ingests one log
+func SingleEntry(c *gin.Context) int {
+	return 1
+}
+
+// BulkEntries counts the entries of a JSON array body and restores the body
+// for the handler. Malformed bodies cost a single entry and are rejected by
+// the handler.
+func BulkEntries(c *gin.Context) int {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return 1
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(body, &entries); err != nil || len(entries) == 0 {
+		return 1
+	}
+	return len(entries)
+}
+
+// Limit enforces the tenant's rate limit, counting each ingested entry
+// separately. It must run after JWTAuth.
+func (m *RateLimitMiddleware) Limit(cost CostFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := c.GetString(string(utils.TenantIDKey))
+		if tenantID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "No tenant ID found"})
+			return
+		}
+
+		entries := cost(c)
+		result, err := m.limiter.Allow(c.Request.Context(), tenantID, entries)
+		if err != nil {
+			// Fail open, losing audit logs is worse than a burst over the limit
+			m.logger.Warnf("Rate limit check failed for tenant %s: %v", tenantID, err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			if entries > result.Limit {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error": "Request contains more entries than the tenant rate limit allows",
+				})
+				return
+			}
+
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -34,6 +34,94 @@ func (_m *AuditLogRepository) BulkCreate(ctx context.Context, logs []domain.Audi
 	return r0
 }
 
+// CountByDay provides a mock function with given fields: ctx, tenantID, startTime, endTime
+func (_m *AuditLogRepository) CountByDay(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time) (map[string]int64, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByDay")
+	}
+
+	var r0 map[string]int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (map[string]int64, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) map[string]int64); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountByTenant provides a mock function with given fields: ctx, startTime, endTime
+func (_m *AuditLogRepository) CountByTenant(ctx context.Context, startTime time.Time, endTime time.Time) (map[string]int64, error) {
+	ret := _m.Called(ctx, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByTenant")
+	}
+
+	var r0 map[string]int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) (map[string]int64, error)); ok {
+		return rf(ctx, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) map[string]int64); ok {
+		r0 = rf(ctx, startTime, endTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, startTime, endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountExcluded provides a mock function with given fields: ctx, tenantID, startTime, endTime, exclusions
+func (_m *AuditLogRepository) CountExcluded(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time, exclusions domain.CleanupExclusions) (int64, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime, exclusions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountExcluded")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, domain.CleanupExclusions) (int64, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime, exclusions)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, domain.CleanupExclusions) int64); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime, exclusions)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time, domain.CleanupExclusions) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime, exclusions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, log
 func (_m *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) error {
 	ret := _m.Called(ctx, log)
@@ -52,27 +140,113 @@ func (_m *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog)
 	return r0
 }
 
-// DeleteBeforeDate provides a mock function with given fields: ctx, tenantID, beforeDate
-func (_m *AuditLogRepository) DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time) (int64, error) {
-	ret := _m.Called(ctx, tenantID, beforeDate)
+// DeleteBatch provides a mock function with given fields: ctx, tenantID, startTime, endTime, exclusions, limit
+func (_m *AuditLogRepository) DeleteBatch(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time, exclusions domain.CleanupExclusions, limit int) (int64, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime, exclusions, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteBeforeDate")
+		panic("no return value specified for DeleteBatch")
 	}
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (int64, error)); ok {
-		return rf(ctx, tenantID, beforeDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, domain.CleanupExclusions, int) (int64, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime, exclusions, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) int64); ok {
-		r0 = rf(ctx, tenantID, beforeDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time, domain.CleanupExclusions, int) int64); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime, exclusions, limit)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
-		r1 = rf(ctx, tenantID, beforeDate)
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time, domain.CleanupExclusions, int) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime, exclusions, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DropChunk provides a mock function with given fields: ctx, chunk, expectedLogs
+func (_m *AuditLogRepository) DropChunk(ctx context.Context, chunk domain.LogChunk, expectedLogs int64) (bool, error) {
+	ret := _m.Called(ctx, chunk, expectedLogs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DropChunk")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LogChunk, int64) (bool, error)); ok {
+		return rf(ctx, chunk, expectedLogs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LogChunk, int64) bool); ok {
+		r0 = rf(ctx, chunk, expectedLogs)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.LogChunk, int64) error); ok {
+		r1 = rf(ctx, chunk, expectedLogs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EstimateCount provides a mock function with given fields: ctx, filter
+func (_m *AuditLogRepository) EstimateCount(ctx context.Context, filter domain.AuditLogFilter) (int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EstimateCount")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditLogFilter) (int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditLogFilter) int64); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AuditLogFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExistingIDs provides a mock function with given fields: ctx, tenantID, ids, startTime, endTime
+func (_m *AuditLogRepository) ExistingIDs(ctx context.Context, tenantID string, ids []string, startTime time.Time, endTime time.Time) ([]string, error) {
+	ret := _m.Called(ctx, tenantID, ids, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistingIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, time.Time, time.Time) ([]string, error)); ok {
+		return rf(ctx, tenantID, ids, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, time.Time, time.Time) []string); ok {
+		r0 = rf(ctx, tenantID, ids, startTime, endTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, ids, startTime, endTime)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -110,6 +284,71 @@ func (_m *AuditLogRepository) GetByID(ctx context.Context, id string) (*domain.A
 	return r0, r1
 }
 
+// GetChainBounds provides a mock function with given fields: ctx, tenantID, startTime, endTime
+func (_m *AuditLogRepository) GetChainBounds(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time) (int64, int64, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChainBounds")
+	}
+
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (int64, int64, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) int64); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) int64); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r2 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetChainHead provides a mock function with given fields: ctx, tenantID
+func (_m *AuditLogRepository) GetChainHead(ctx context.Context, tenantID string) (*domain.ChainHead, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChainHead")
+	}
+
+	var r0 *domain.ChainHead
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.ChainHead, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.ChainHead); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ChainHead)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRecentLogs provides a mock function with given fields: ctx, tenantID, since
 func (_m *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string, since time.Time) ([]domain.AuditLog, error) {
 	ret := _m.Called(ctx, tenantID, since)
@@ -170,6 +409,71 @@ func (_m *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditL
 	return r0, r1
 }
 
+// GetTimeBounds provides a mock function with given fields: ctx, tenantID
+func (_m *AuditLogRepository) GetTimeBounds(ctx context.Context, tenantID string) (time.Time, time.Time, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTimeBounds")
+	}
+
+	var r0 time.Time
+	var r1 time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (time.Time, time.Time, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) time.Time); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) time.Time); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, tenantID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTimeSeries provides a mock function with given fields: ctx, query
+func (_m *AuditLogRepository) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]domain.TimeSeriesCount, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTimeSeries")
+	}
+
+	var r0 []domain.TimeSeriesCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TimeSeriesQuery) ([]domain.TimeSeriesCount, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TimeSeriesQuery) []domain.TimeSeriesCount); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TimeSeriesCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TimeSeriesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx, filter
 func (_m *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error) {
 	ret := _m.Called(ctx, filter)
@@ -200,6 +504,84 @@ func (_m *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFi
 	return r0, r1
 }
 
+// ListChain provides a mock function with given fields: ctx, tenantID, fromSeq, toSeq, limit
+func (_m *AuditLogRepository) ListChain(ctx context.Context, tenantID string, fromSeq int64, toSeq int64, limit int) ([]domain.AuditLog, error) {
+	ret := _m.Called(ctx, tenantID, fromSeq, toSeq, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListChain")
+	}
+
+	var r0 []domain.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, int) ([]domain.AuditLog, error)); ok {
+		return rf(ctx, tenantID, fromSeq, toSeq, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, int) []domain.AuditLog); ok {
+		r0 = rf(ctx, tenantID, fromSeq, toSeq, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64, int) error); ok {
+		r1 = rf(ctx, tenantID, fromSeq, toSeq, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListChunks provides a mock function with given fields: ctx, before
+func (_m *AuditLogRepository) ListChunks(ctx context.Context, before time.Time) ([]domain.LogChunk, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListChunks")
+	}
+
+	var r0 []domain.LogChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]domain.LogChunk, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.LogChunk); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LogChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefreshStats provides a mock function with given fields: ctx, startTime, endTime
+func (_m *AuditLogRepository) RefreshStats(ctx context.Context, startTime time.Time, endTime time.Time) error {
+	ret := _m.Called(ctx, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) error); ok {
+		r0 = rf(ctx, startTime, endTime)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewAuditLogRepository creates a new instance of AuditLogRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuditLogRepository(t interface {
@@ -54,6 +54,24 @@ func (_m *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogReq
 	return r0
 }
 
+// Export provides a mock function with given fields: ctx, filter, fn
+func (_m *AuditLogService) Export(ctx context.Context, filter *domain.AuditLogFilter, fn func([]domain.AuditLog) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, func([]domain.AuditLog) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *AuditLogService) GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error) {
 	ret := _m.Called(ctx, id)
@@ -144,24 +162,54 @@ func (_m *AuditLogService) GetStatsV2(ctx context.Context, filter *domain.AuditL
 	return r0, r1
 }
 
+// GetTimeSeries provides a mock function with given fields: ctx, query
+func (_m *AuditLogService) GetTimeSeries(ctx context.Context, query *domain.TimeSeriesQuery) (*dto.TimeSeriesResponse, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTimeSeries")
+	}
+
+	var r0 *dto.TimeSeriesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TimeSeriesQuery) (*dto.TimeSeriesResponse, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.TimeSeriesQuery) *dto.TimeSeriesResponse); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.TimeSeriesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.TimeSeriesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx, filter, usePagination
-func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error) {
+func (_m *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) (*dto.ListAuditLogsResponse, error) {
 	ret := _m.Called(ctx, filter, usePagination)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
 	}
 
-	var r0 []dto.AuditLogResponse
+	var r0 *dto.ListAuditLogsResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) ([]dto.AuditLogResponse, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) (*dto.ListAuditLogsResponse, error)); ok {
 		return rf(ctx, filter, usePagination)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) []dto.AuditLogResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, bool) *dto.ListAuditLogsResponse); ok {
 		r0 = rf(ctx, filter, usePagination)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]dto.AuditLogResponse)
+			r0 = ret.Get(0).(*dto.ListAuditLogsResponse)
 		}
 	}
 
@@ -192,6 +240,36 @@ func (_m *AuditLogService) ScheduleArchive(ctx context.Context, tenantID string,
 	return r0
 }
 
+// VerifyChain provides a mock function with given fields: ctx, tenantID, startTime, endTime
+func (_m *AuditLogService) VerifyChain(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time) (*dto.ChainVerificationResponse, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyChain")
+	}
+
+	var r0 *dto.ChainVerificationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (*dto.ChainVerificationResponse, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) *dto.ChainVerificationResponse); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ChainVerificationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAuditLogService creates a new instance of AuditLogService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuditLogService(t interface {
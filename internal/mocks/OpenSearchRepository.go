@@ -34,17 +34,47 @@ func (_m *OpenSearchRepository) BulkIndex(ctx context.Context, logs []domain.Aud
 	return r0
 }
 
-// CreateIndex provides a mock function with given fields: ctx, tenantID, t
-func (_m *OpenSearchRepository) CreateIndex(ctx context.Context, tenantID string, t time.Time) error {
-	ret := _m.Called(ctx, tenantID, t)
+// CountByDay provides a mock function with given fields: ctx, tenantID, startTime, endTime
+func (_m *OpenSearchRepository) CountByDay(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time) (map[string]int64, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByDay")
+	}
+
+	var r0 map[string]int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (map[string]int64, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) map[string]int64); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateIndex provides a mock function with given fields: ctx, tenantID
+func (_m *OpenSearchRepository) CreateIndex(ctx context.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateIndex")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
-		r0 = rf(ctx, tenantID, t)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -52,22 +82,118 @@ func (_m *OpenSearchRepository) CreateIndex(ctx context.Context, tenantID string
 	return r0
 }
 
+// DeleteBeforeDate provides a mock function with given fields: ctx, tenantID, beforeDate, exclusions
+func (_m *OpenSearchRepository) DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time, exclusions domain.CleanupExclusions) (int64, error) {
+	ret := _m.Called(ctx, tenantID, beforeDate, exclusions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBeforeDate")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, domain.CleanupExclusions) (int64, error)); ok {
+		return rf(ctx, tenantID, beforeDate, exclusions)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, domain.CleanupExclusions) int64); ok {
+		r0 = rf(ctx, tenantID, beforeDate, exclusions)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, domain.CleanupExclusions) error); ok {
+		r1 = rf(ctx, tenantID, beforeDate, exclusions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteIndex provides a mock function with given fields: ctx, tenantID
-func (_m *OpenSearchRepository) DeleteIndex(ctx context.Context, tenantID string) error {
+func (_m *OpenSearchRepository) DeleteIndex(ctx context.Context, tenantID string) (int64, error) {
 	ret := _m.Called(ctx, tenantID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteIndex")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
 		r0 = rf(ctx, tenantID)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteRange provides a mock function with given fields: ctx, tenantID, startTime, endTime
+func (_m *OpenSearchRepository) DeleteRange(ctx context.Context, tenantID string, startTime time.Time, endTime time.Time) (int64, error) {
+	ret := _m.Called(ctx, tenantID, startTime, endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRange")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) (int64, error)); ok {
+		return rf(ctx, tenantID, startTime, endTime)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) int64); ok {
+		r0 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, startTime, endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExistingIDs provides a mock function with given fields: ctx, tenantID, ids
+func (_m *OpenSearchRepository) ExistingIDs(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	ret := _m.Called(ctx, tenantID, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistingIDs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) ([]string, error)); ok {
+		return rf(ctx, tenantID, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) []string); ok {
+		r0 = rf(ctx, tenantID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, tenantID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Index provides a mock function with given fields: ctx, log
@@ -88,8 +214,26 @@ func (_m *OpenSearchRepository) Index(ctx context.Context, log *domain.AuditLog)
 	return r0
 }
 
+// PutIndexedPaths provides a mock function with given fields: ctx, tenantID, paths
+func (_m *OpenSearchRepository) PutIndexedPaths(ctx context.Context, tenantID string, paths []domain.IndexedPath) error {
+	ret := _m.Called(ctx, tenantID, paths)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PutIndexedPaths")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []domain.IndexedPath) error); ok {
+		r0 = rf(ctx, tenantID, paths)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Search provides a mock function with given fields: ctx, filter
-func (_m *OpenSearchRepository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
+func (_m *OpenSearchRepository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, error) {
 	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
@@ -97,8 +241,9 @@ func (_m *OpenSearchRepository) Search(ctx context.Context, filter *domain.Audit
 	}
 
 	var r0 []domain.AuditLog
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) ([]domain.AuditLog, error)); ok {
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) ([]domain.AuditLog, int64, error)); ok {
 		return rf(ctx, filter)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter) []domain.AuditLog); ok {
@@ -109,13 +254,56 @@ func (_m *OpenSearchRepository) Search(ctx context.Context, filter *domain.Audit
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter) int64); ok {
 		r1 = rf(ctx, filter)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(int64)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, *domain.AuditLogFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Sessions provides a mock function with given fields: ctx, filter, limit
+func (_m *OpenSearchRepository) Sessions(ctx context.Context, filter *domain.AuditLogFilter, limit int) ([]domain.SessionSummary, int64, error) {
+	ret := _m.Called(ctx, filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sessions")
+	}
+
+	var r0 []domain.SessionSummary
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int) ([]domain.SessionSummary, int64, error)); ok {
+		return rf(ctx, filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditLogFilter, int) []domain.SessionSummary); ok {
+		r0 = rf(ctx, filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SessionSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuditLogFilter, int) int64); ok {
+		r1 = rf(ctx, filter, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *domain.AuditLogFilter, int) error); ok {
+		r2 = rf(ctx, filter, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // NewOpenSearchRepository creates a new instance of OpenSearchRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -32,6 +32,146 @@ func (_m *PostgresRepository) AuditLog() repository.AuditLogRepository {
 	return r0
 }
 
+// ExportJob provides a mock function with no fields
+func (_m *PostgresRepository) ExportJob() repository.ExportJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportJob")
+	}
+
+	var r0 repository.ExportJobRepository
+	if rf, ok := ret.Get(0).(func() repository.ExportJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ExportJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// LegalHold provides a mock function with no fields
+func (_m *PostgresRepository) LegalHold() repository.LegalHoldRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LegalHold")
+	}
+
+	var r0 repository.LegalHoldRepository
+	if rf, ok := ret.Get(0).(func() repository.LegalHoldRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.LegalHoldRepository)
+		}
+	}
+
+	return r0
+}
+
+// Outbox provides a mock function with no fields
+func (_m *PostgresRepository) Outbox() repository.OutboxRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Outbox")
+	}
+
+	var r0 repository.OutboxRepository
+	if rf, ok := ret.Get(0).(func() repository.OutboxRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.OutboxRepository)
+		}
+	}
+
+	return r0
+}
+
+// ReindexJob provides a mock function with no fields
+func (_m *PostgresRepository) ReindexJob() repository.ReindexJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReindexJob")
+	}
+
+	var r0 repository.ReindexJobRepository
+	if rf, ok := ret.Get(0).(func() repository.ReindexJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.ReindexJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// RestoreJob provides a mock function with no fields
+func (_m *PostgresRepository) RestoreJob() repository.RestoreJobRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreJob")
+	}
+
+	var r0 repository.RestoreJobRepository
+	if rf, ok := ret.Get(0).(func() repository.RestoreJobRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.RestoreJobRepository)
+		}
+	}
+
+	return r0
+}
+
+// RetentionPolicy provides a mock function with no fields
+func (_m *PostgresRepository) RetentionPolicy() repository.RetentionPolicyRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetentionPolicy")
+	}
+
+	var r0 repository.RetentionPolicyRepository
+	if rf, ok := ret.Get(0).(func() repository.RetentionPolicyRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.RetentionPolicyRepository)
+		}
+	}
+
+	return r0
+}
+
+// RetentionProgress provides a mock function with no fields
+func (_m *PostgresRepository) RetentionProgress() repository.RetentionProgressRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetentionProgress")
+	}
+
+	var r0 repository.RetentionProgressRepository
+	if rf, ok := ret.Get(0).(func() repository.RetentionProgressRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repository.RetentionProgressRepository)
+		}
+	}
+
+	return r0
+}
+
 // Tenant provides a mock function with no fields
 func (_m *PostgresRepository) Tenant() repository.TenantRepository {
 	ret := _m.Called()
@@ -28,6 +28,34 @@ func (r *compositeRepository) Tenant() repository.TenantRepository {
 	return r.postgresRepo.Tenant()
 }
 
+func (r *compositeRepository) ExportJob() repository.ExportJobRepository {
+	return r.postgresRepo.ExportJob()
+}
+
+func (r *compositeRepository) RestoreJob() repository.RestoreJobRepository {
+	return r.postgresRepo.RestoreJob()
+}
+
+func (r *compositeRepository) ReindexJob() repository.ReindexJobRepository {
+	return r.postgresRepo.ReindexJob()
+}
+
+func (r *compositeRepository) RetentionPolicy() repository.RetentionPolicyRepository {
+	return r.postgresRepo.RetentionPolicy()
+}
+
+func (r *compositeRepository) LegalHold() repository.LegalHoldRepository {
+	return r.postgresRepo.LegalHold()
+}
+
+func (r *compositeRepository) RetentionProgress() repository.RetentionProgressRepository {
+	return r.postgresRepo.RetentionProgress()
+}
+
+func (r *compositeRepository) Outbox() repository.OutboxRepository {
+	return r.postgresRepo.Outbox()
+}
+
 func (r *compositeRepository) OpenSearch() repository.OpenSearchRepository {
 	return r.osRepo
 }
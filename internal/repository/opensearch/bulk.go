@@ -0,0 +1,228 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// ErrRejected is returned when OpenSearch rejects a request because it is
+// overloaded, the request should be sent again later and smaller
+var ErrRejected = errors.New("request rejected by OpenSearch")
+
+// BulkItemError is the failure of one log of a bulk request
+type BulkItemError struct {
+	// Position is the position of the log in the request
+	Position int
+	LogID    string
+	Status   int
+	Type     string
+	Reason   string
+}
+
+func (e *BulkItemError) Error() string {
+	return fmt.Sprintf("failed to index log %s: %s (%d): %s", e.LogID, e.Type, e.Status, e.Reason)
+}
+
+// Unwrap reports a rejected log as ErrRejected
+func (e *BulkItemError) Unwrap() error {
+	if e.Status == http.StatusTooManyRequests {
+		return ErrRejected
+	}
+	return nil
+}
+
+// bulkResponse is the part of a _bulk response that reports the failures
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Index  string `json:"_index"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// BulkIndexItems indexes the logs in a single _bulk request. The error is set
+// when the whole request failed, otherwise the failures of single logs are
+// returned and the other logs are indexed.
+func (r *repository) BulkIndexItems(ctx context.Context, logs []domain.AuditLog) ([]*BulkItemError, error) {
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	for _, log := range logs {
+		if err := r.ensureAlias(ctx, log.TenantID); err != nil {
+			return nil, fmt.Errorf("failed to ensure alias exists: %w", err)
+		}
+	}
+	located, err := r.locate(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build bulk request body, each log goes to the index already holding it
+	// or else to the write alias of its tenant. An alias that was deleted
+	// fails its logs instead of being created as an index.
+	var bulkBody strings.Builder
+	for _, log := range logs {
+		target := map[string]any{
+			"_index":        r.config.GetAliasName(log.TenantID),
+			"_id":           log.ID,
+			"require_alias": true,
+		}
+		if index, ok := located[locationKey(log.TenantID, log.ID)]; ok {
+			target = map[string]any{
+				"_index": index,
+				"_id":    log.ID,
+			}
+		}
+
+		actionLine, err := json.Marshal(map[string]any{"index": target})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal action: %w", err)
+		}
+		bulkBody.Write(actionLine)
+		bulkBody.WriteString("\n")
+
+		// Add document line
+		docLine, err := json.Marshal(log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal document: %w", err)
+		}
+		bulkBody.Write(docLine)
+		bulkBody.WriteString("\n")
+	}
+
+	req := opensearchapi.BulkRequest{
+		Body: strings.NewReader(bulkBody.String()),
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute bulk request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestEntityTooLarge {
+		return nil, fmt.Errorf("%w: %s", ErrRejected, res.String())
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("bulk request failed: %s", res.String())
+	}
+
+	var result bulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, nil
+	}
+
+	// The items are reported in the order of the request
+	var failures []*BulkItemError
+	for i, item := range result.Items {
+		for _, outcome := range item {
+			if outcome.Error == nil {
+				continue
+			}
+			if outcome.Error.Type == "index_not_found_exception" {
+				// The alias was deleted since it was created, the retry
+				// creates it again
+				r.tenants.Delete(logs[i].TenantID)
+			}
+			failures = append(failures, &BulkItemError{
+				Position: i,
+				LogID:    outcome.ID,
+				Status:   outcome.Status,
+				Type:     outcome.Error.Type,
+				Reason:   outcome.Error.Reason,
+			})
+		}
+	}
+	return failures, nil
+}
+
+// locate returns the indices behind the aliases of their tenants the logs are
+// already indexed in, keyed by locationKey. The write alias moves on to a new
+// index with every rollover, a log indexed again after a retry, a redelivery
+// or a reindex is written to the index holding it rather than duplicated into
+// the new one. Logs indexed within the last refresh interval are not found.
+func (r *repository) locate(ctx context.Context, logs []domain.AuditLog) (map[string]string, error) {
+	aliases := make([]string, 0, 1)
+	ids := make([]string, 0, len(logs))
+	for _, log := range logs {
+		if alias := r.config.GetAliasName(log.TenantID); !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+		ids = append(ids, log.ID)
+	}
+
+	query := map[string]any{
+		"size":    len(ids),
+		"_source": []string{"tenant_id"},
+		"query": map[string]any{
+			"ids": map[string]any{"values": ids},
+		},
+	}
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	ignoreUnavailable := true
+	req := opensearchapi.SearchRequest{
+		Index:             aliases,
+		Body:              strings.NewReader(string(queryJSON)),
+		IgnoreUnavailable: &ignoreUnavailable,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate logs: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: %s", ErrRejected, res.String())
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to locate logs: %s", res.String())
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				ID     string `json:"_id"`
+				Index  string `json:"_index"`
+				Source struct {
+					TenantID string `json:"tenant_id"`
+				} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	located := make(map[string]string, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		located[locationKey(hit.Source.TenantID, hit.ID)] = hit.Index
+	}
+	return located, nil
+}
+
+// locationKey identifies a log of a tenant in the result of locate
+func locationKey(tenantID, logID string) string {
+	return tenantID + "/" + logID
+}
@@ -0,0 +1,252 @@
+package opensearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opensearch-project/opensearch-go/v2"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// stubRequest is a request received by the OpenSearch stub
+type stubRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// openSearchStub is an OpenSearch cluster answering with canned responses.
+// The responses are looked up by method and path, unknown requests get a
+// 404.
+type openSearchStub struct {
+	server    *httptest.Server
+	mu        sync.Mutex
+	requests  []stubRequest
+	responses map[string]stubResponse
+}
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+func newOpenSearchStub() *openSearchStub {
+	stub := &openSearchStub{responses: make(map[string]stubResponse)}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, stubRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+		response, ok := stub.responses[r.Method+" "+r.URL.Path]
+		stub.mu.Unlock()
+
+		if !ok {
+			response = stubResponse{status: http.StatusNotFound, body: `{"error":{"type":"index_not_found_exception"},"status":404}`}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(response.status)
+		_, _ = w.Write([]byte(response.body))
+	}))
+	return stub
+}
+
+func (s *openSearchStub) on(method, path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[method+" "+path] = stubResponse{status: status, body: body}
+}
+
+func (s *openSearchStub) received() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubRequest(nil), s.requests...)
+}
+
+// repository returns a repository on the stub
+func (s *openSearchStub) repository() (*repository, error) {
+	client, err := opensearch.NewClient(opensearch.Config{Addresses: []string{s.server.URL}})
+	if err != nil {
+		return nil, err
+	}
+	return &repository{client: client, config: &config.OpenSearchConfig{}}, nil
+}
+
+// bulkActions reads the action lines of a _bulk body
+func bulkActions(body string) []map[string]map[string]any {
+	var actions []map[string]map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for line := 0; scanner.Scan(); line++ {
+		if line%2 == 1 {
+			continue
+		}
+		var action map[string]map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &action); err == nil {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+type BulkTestSuite struct {
+	suite.Suite
+	stub *openSearchStub
+	repo *repository
+}
+
+func (s *BulkTestSuite) SetupTest() {
+	s.stub = newOpenSearchStub()
+	repo, err := s.stub.repository()
+	s.Require().NoError(err)
+	s.repo = repo
+	s.repo.tenants.Store("tenant1", true)
+	s.repo.tenants.Store("tenant2", true)
+}
+
+func (s *BulkTestSuite) TearDownTest() {
+	s.stub.server.Close()
+}
+
+func TestBulk(t *testing.T) {
+	suite.Run(t, new(BulkTestSuite))
+}
+
+func (s *BulkTestSuite) TestBulkIndexItems_WritesIndexedLogsToTheirIndex() {
+	// Arrange
+	logs := []domain.AuditLog{
+		{ID: "log1", TenantID: "tenant1"},
+		{ID: "log2", TenantID: "tenant1"},
+		{ID: "log3", TenantID: "tenant2"},
+	}
+	// log1 was indexed before the alias rolled over, the same ID of another
+	// tenant is not log3
+	s.stub.on(http.MethodPost, "/audit-logs-tenant1,audit-logs-tenant2/_search", http.StatusOK, `{"hits":{"hits":[
+		{"_id":"log1","_index":"audit-logs-tenant1-000001","_source":{"tenant_id":"tenant1"}},
+		{"_id":"log3","_index":"audit-logs-tenant1-000002","_source":{"tenant_id":"tenant1"}}
+	]}}`)
+	s.stub.on(http.MethodPost, "/_bulk", http.StatusOK, `{"errors":false,"items":[]}`)
+
+	// Act
+	failures, err := s.repo.BulkIndexItems(context.Background(), logs)
+
+	// Assert
+	s.NoError(err)
+	s.Empty(failures)
+	requests := s.stub.received()
+	s.Require().Len(requests, 2)
+	s.Contains(requests[0].Query, "ignore_unavailable=true")
+	var query map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(requests[0].Body), &query))
+	s.Equal(map[string]any{"ids": map[string]any{"values": []any{"log1", "log2", "log3"}}}, query["query"])
+
+	s.NotContains(requests[1].Query, "require_alias")
+	s.Equal([]map[string]map[string]any{
+		{"index": {"_index": "audit-logs-tenant1-000001", "_id": "log1"}},
+		{"index": {"_index": "audit-logs-tenant1", "_id": "log2", "require_alias": true}},
+		{"index": {"_index": "audit-logs-tenant2", "_id": "log3", "require_alias": true}},
+	}, bulkActions(requests[1].Body))
+}
+
+func (s *BulkTestSuite) TestBulkIndexItems_ReportsItemFailures() {
+	// Arrange
+	logs := []domain.AuditLog{
+		{ID: "log1", TenantID: "tenant1"},
+		{ID: "log2", TenantID: "tenant1"},
+		{ID: "log3", TenantID: "tenant2"},
+	}
+	s.stub.on(http.MethodPost, "/audit-logs-tenant1,audit-logs-tenant2/_search", http.StatusOK, `{"hits":{"hits":[]}}`)
+	s.stub.on(http.MethodPost, "/_bulk", http.StatusOK, `{"errors":true,"items":[
+		{"index":{"_id":"log1","_index":"audit-logs-tenant1-000001","status":201}},
+		{"index":{"_id":"log2","status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}},
+		{"index":{"_id":"log3","status":404,"error":{"type":"index_not_found_exception","reason":"no such index [audit-logs-tenant2]"}}}
+	]}`)
+
+	// Act
+	failures, err := s.repo.BulkIndexItems(context.Background(), logs)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(failures, 2)
+	s.Equal(1, failures[0].Position)
+	s.Equal("log2", failures[0].LogID)
+	s.ErrorIs(failures[0], ErrRejected)
+	s.Equal(2, failures[1].Position)
+	s.Equal("index_not_found_exception", failures[1].Type)
+	s.False(errors.Is(failures[1], ErrRejected))
+
+	// The deleted alias is created again by the retry
+	_, known := s.repo.tenants.Load("tenant2")
+	s.False(known)
+	_, known = s.repo.tenants.Load("tenant1")
+	s.True(known)
+}
+
+func (s *BulkTestSuite) TestBulkIndexItems_RejectedRequests() {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusRequestEntityTooLarge} {
+		// Arrange
+		s.stub.on(http.MethodPost, "/audit-logs-tenant1/_search", http.StatusOK, `{"hits":{"hits":[]}}`)
+		s.stub.on(http.MethodPost, "/_bulk", status, `{"error":"rejected"}`)
+
+		// Act
+		failures, err := s.repo.BulkIndexItems(context.Background(), []domain.AuditLog{{ID: "log1", TenantID: "tenant1"}})
+
+		// Assert
+		s.ErrorIs(err, ErrRejected, "status %d", status)
+		s.Nil(failures)
+	}
+}
+
+func (s *BulkTestSuite) TestBulkIndexItems_FailedRequest() {
+	// Arrange
+	s.stub.on(http.MethodPost, "/audit-logs-tenant1/_search", http.StatusOK, `{"hits":{"hits":[]}}`)
+	s.stub.on(http.MethodPost, "/_bulk", http.StatusInternalServerError, `{"error":"boom"}`)
+
+	// Act
+	_, err := s.repo.BulkIndexItems(context.Background(), []domain.AuditLog{{ID: "log1", TenantID: "tenant1"}})
+
+	// Assert
+	s.Error(err)
+	s.False(errors.Is(err, ErrRejected))
+}
+
+func (s *BulkTestSuite) TestBulkIndexItems_LocateRejected() {
+	// Arrange
+	s.stub.on(http.MethodPost, "/audit-logs-tenant1/_search", http.StatusTooManyRequests, `{"error":"rejected"}`)
+
+	// Act
+	_, err := s.repo.BulkIndexItems(context.Background(), []domain.AuditLog{{ID: "log1", TenantID: "tenant1"}})
+
+	// Assert
+	s.ErrorIs(err, ErrRejected)
+	for _, req := range s.stub.received() {
+		s.NotEqual("/_bulk", req.Path)
+	}
+}
+
+func (s *BulkTestSuite) TestIndex_WritesIndexedLogToItsIndex() {
+	// Arrange
+	s.stub.on(http.MethodPost, "/audit-logs-tenant1/_search", http.StatusOK, `{"hits":{"hits":[
+		{"_id":"log1","_index":"audit-logs-tenant1-000001","_source":{"tenant_id":"tenant1"}}
+	]}}`)
+	s.stub.on(http.MethodPut, "/audit-logs-tenant1-000001/_doc/log1", http.StatusOK, `{"result":"updated"}`)
+
+	// Act
+	err := s.repo.Index(context.Background(), &domain.AuditLog{ID: "log1", TenantID: "tenant1"})
+
+	// Assert
+	s.NoError(err)
+	requests := s.stub.received()
+	s.Require().Len(requests, 2)
+	s.Equal("/audit-logs-tenant1-000001/_doc/log1", requests[1].Path)
+	s.NotContains(requests[1].Query, "require_alias")
+}
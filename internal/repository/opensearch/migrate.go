@@ -0,0 +1,190 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+)
+
+// legacyIndexPrefix starts the names of the daily indices
+const legacyIndexPrefix = "audit_logs_"
+
+// legacyDayLayout ends the names of the daily indices
+const legacyDayLayout = "2006_01_02"
+
+// LegacyIndex is a daily index of a tenant, written before the indices rolled
+// over behind aliases
+type LegacyIndex struct {
+	Name     string
+	TenantID string
+	Day      time.Time
+}
+
+// LegacyIndices returns the daily indices of a tenant, of every tenant when
+// the tenant ID is empty, ordered by name
+func (r *repository) LegacyIndices(ctx context.Context, tenantID string) ([]LegacyIndex, error) {
+	pattern := legacyIndexPrefix + "*"
+	if tenantID != "" {
+		pattern = r.config.GetIndexPattern(tenantID)
+	}
+
+	req := opensearchapi.CatIndicesRequest{
+		Index:  []string{pattern},
+		Format: "json",
+		H:      []string{"index"},
+		S:      []string{"index"},
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+	defer res.Body.Close()
+
+	indices := []LegacyIndex{}
+	if res.IsError() {
+		if res.StatusCode == http.StatusNotFound {
+			return indices, nil
+		}
+		return nil, fmt.Errorf("error listing indices: %s", res.String())
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode indices: %w", err)
+	}
+
+	for _, row := range rows {
+		index, ok := parseLegacyIndex(row.Index)
+		if !ok {
+			continue
+		}
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// parseLegacyIndex reads the tenant and day from the name of a daily index
+func parseLegacyIndex(name string) (LegacyIndex, bool) {
+	rest, ok := strings.CutPrefix(name, legacyIndexPrefix)
+	if !ok || len(rest) < len(legacyDayLayout)+2 {
+		return LegacyIndex{}, false
+	}
+
+	split := len(rest) - len(legacyDayLayout)
+	day, err := time.Parse(legacyDayLayout, rest[split:])
+	if err != nil || rest[split-1] != '_' {
+		return LegacyIndex{}, false
+	}
+	return LegacyIndex{Name: name, TenantID: rest[:split-1], Day: day}, true
+}
+
+// MigrateLegacyIndex copies the logs of a daily index to the write alias of
+// its tenant and deletes the daily index once every log is copied. It returns
+// the number of logs copied. Logs already behind the alias keep their newer
+// version, so a migration that was interrupted can run again.
+func (r *repository) MigrateLegacyIndex(ctx context.Context, index LegacyIndex) (int64, error) {
+	if err := r.ensureAlias(ctx, index.TenantID); err != nil {
+		return 0, fmt.Errorf("failed to ensure alias exists: %w", err)
+	}
+
+	total, err := r.count(ctx, index.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"conflicts": "proceed",
+		"source":    map[string]any{"index": index.Name},
+		"dest": map[string]any{
+			"index":   r.config.GetAliasName(index.TenantID),
+			"op_type": "create",
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal reindex request: %w", err)
+	}
+
+	waitForCompletion := true
+	refresh := true
+	req := opensearchapi.ReindexRequest{
+		Body:              strings.NewReader(string(body)),
+		WaitForCompletion: &waitForCompletion,
+		Refresh:           &refresh,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reindex %s: %w", index.Name, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, fmt.Errorf("error reindexing %s: %s", index.Name, res.String())
+	}
+
+	var result struct {
+		Created          int64             `json:"created"`
+		VersionConflicts int64             `json:"version_conflicts"`
+		Failures         []json.RawMessage `json:"failures"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode reindex response: %w", err)
+	}
+
+	// A conflict is a log that was already behind the alias
+	if len(result.Failures) > 0 || result.Created+result.VersionConflicts != total {
+		return result.Created, fmt.Errorf("reindexed %d of %d logs of %s (%d failures), the index is kept",
+			result.Created+result.VersionConflicts, total, index.Name, len(result.Failures))
+	}
+
+	delete := opensearchapi.IndicesDeleteRequest{
+		Index: []string{index.Name},
+	}
+	res, err = delete.Do(ctx, r.client)
+	if err != nil {
+		return result.Created, fmt.Errorf("failed to delete index: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return result.Created, fmt.Errorf("error deleting index: %s", res.String())
+	}
+
+	return result.Created, nil
+}
+
+// count returns the number of documents in the indices, missing ones hold
+// none
+func (r *repository) count(ctx context.Context, indices ...string) (int64, error) {
+	ignoreUnavailable := true
+	req := opensearchapi.CountRequest{
+		Index:             indices,
+		IgnoreUnavailable: &ignoreUnavailable,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == http.StatusNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error counting documents: %s", res.String())
+	}
+
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.Count, nil
+}
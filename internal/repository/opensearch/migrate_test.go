@@ -0,0 +1,147 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MigrateTestSuite struct {
+	suite.Suite
+	stub  *openSearchStub
+	repo  *repository
+	index LegacyIndex
+}
+
+func (s *MigrateTestSuite) SetupTest() {
+	s.stub = newOpenSearchStub()
+	repo, err := s.stub.repository()
+	s.Require().NoError(err)
+	s.repo = repo
+	s.index = LegacyIndex{
+		Name:     "audit_logs_tenant1_2024_03_20",
+		TenantID: "tenant1",
+		Day:      time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (s *MigrateTestSuite) TearDownTest() {
+	s.stub.server.Close()
+}
+
+func TestMigrate(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuite))
+}
+
+func (s *MigrateTestSuite) calls() []string {
+	var calls []string
+	for _, req := range s.stub.received() {
+		calls = append(calls, req.Method+" "+req.Path)
+	}
+	return calls
+}
+
+func (s *MigrateTestSuite) TestLegacyIndices_ParsesDailyIndices() {
+	// Arrange
+	s.stub.on(http.MethodGet, "/_cat/indices/audit_logs_*", http.StatusOK, `[
+		{"index":"audit_logs_tenant1_2024_03_20"},
+		{"index":"audit_logs_tenant_two_2024_03_21"},
+		{"index":"audit_logs_tenant1_latest"},
+		{"index":"audit_logs__2024_03_20"}
+	]`)
+
+	// Act
+	indices, err := s.repo.LegacyIndices(context.Background(), "")
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]LegacyIndex{
+		s.index,
+		{Name: "audit_logs_tenant_two_2024_03_21", TenantID: "tenant_two", Day: time.Date(2024, 3, 21, 0, 0, 0, 0, time.UTC)},
+	}, indices)
+	s.Contains(s.stub.received()[0].Query, "s=index")
+}
+
+func (s *MigrateTestSuite) TestLegacyIndices_NoIndices() {
+	// Act
+	indices, err := s.repo.LegacyIndices(context.Background(), "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Empty(indices)
+}
+
+func (s *MigrateTestSuite) TestMigrateLegacyIndex_MovesLogsBehindWriteAlias() {
+	// Arrange
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs-tenant1-paths", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/_index_template/audit-logs-tenant1", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/audit-logs-tenant1-000001", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPost, "/audit_logs_tenant1_2024_03_20/_count", http.StatusOK, `{"count":3}`)
+	// One log was copied by an interrupted migration before
+	s.stub.on(http.MethodPost, "/_reindex", http.StatusOK, `{"created":2,"version_conflicts":1,"failures":[]}`)
+	s.stub.on(http.MethodDelete, "/audit_logs_tenant1_2024_03_20", http.StatusOK, `{"acknowledged":true}`)
+
+	// Act
+	copied, err := s.repo.MigrateLegacyIndex(context.Background(), s.index)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), copied)
+	s.Equal([]string{
+		"PUT /_component_template/audit-logs-tenant1-paths",
+		"PUT /_index_template/audit-logs-tenant1",
+		"HEAD /_alias/audit-logs-tenant1",
+		"PUT /audit-logs-tenant1-000001",
+		"POST /audit_logs_tenant1_2024_03_20/_count",
+		"POST /_reindex",
+		"DELETE /audit_logs_tenant1_2024_03_20",
+	}, s.calls())
+
+	reindex := s.stub.received()[5]
+	s.Contains(reindex.Query, "wait_for_completion=true")
+	s.Contains(reindex.Query, "refresh=true")
+	var body map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(reindex.Body), &body))
+	s.Equal(map[string]any{
+		"conflicts": "proceed",
+		"source":    map[string]any{"index": "audit_logs_tenant1_2024_03_20"},
+		"dest":      map[string]any{"index": "audit-logs-tenant1", "op_type": "create"},
+	}, body)
+}
+
+func (s *MigrateTestSuite) TestMigrateLegacyIndex_KeepsIndexWhenLogsAreMissing() {
+	// Arrange
+	s.repo.tenants.Store("tenant1", true)
+	s.stub.on(http.MethodPost, "/audit_logs_tenant1_2024_03_20/_count", http.StatusOK, `{"count":3}`)
+	s.stub.on(http.MethodPost, "/_reindex", http.StatusOK, `{"created":1,"version_conflicts":0,"failures":[{"id":"log2"}]}`)
+
+	// Act
+	copied, err := s.repo.MigrateLegacyIndex(context.Background(), s.index)
+
+	// Assert
+	s.Error(err)
+	s.Equal(int64(1), copied)
+	s.Equal([]string{
+		"POST /audit_logs_tenant1_2024_03_20/_count",
+		"POST /_reindex",
+	}, s.calls())
+}
+
+func (s *MigrateTestSuite) TestMigrateLegacyIndex_FailedReindex() {
+	// Arrange
+	s.repo.tenants.Store("tenant1", true)
+	s.stub.on(http.MethodPost, "/audit_logs_tenant1_2024_03_20/_count", http.StatusOK, `{"count":3}`)
+	s.stub.on(http.MethodPost, "/_reindex", http.StatusInternalServerError, `{"error":"boom"}`)
+
+	// Act
+	copied, err := s.repo.MigrateLegacyIndex(context.Background(), s.index)
+
+	// Assert
+	s.Error(err)
+	s.Zero(copied)
+	s.NotContains(s.calls(), "DELETE /audit_logs_tenant1_2024_03_20")
+}
@@ -0,0 +1,85 @@
+package opensearch
+
+import (
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// compileQuery compiles the syntax tree of a log query into a bool query
+func compileQuery(node domain.QueryNode) map[string]any {
+	switch q := node.(type) {
+	case *domain.AndQuery:
+		return map[string]any{"bool": map[string]any{"must": compileClauses(q.Clauses)}}
+	case *domain.OrQuery:
+		return map[string]any{"bool": map[string]any{
+			"should":               compileClauses(q.Clauses),
+			"minimum_should_match": 1,
+		}}
+	case *domain.NotQuery:
+		return map[string]any{"bool": map[string]any{"must_not": []map[string]any{compileQuery(q.Clause)}}}
+	case *domain.FieldQuery:
+		return compileFieldQuery(q)
+	}
+	return map[string]any{"match_none": map[string]any{}}
+}
+
+func compileClauses(clauses []domain.QueryNode) []map[string]any {
+	queries := make([]map[string]any, 0, len(clauses))
+	for _, clause := range clauses {
+		queries = append(queries, compileQuery(clause))
+	}
+	return queries
+}
+
+func compileFieldQuery(q *domain.FieldQuery) map[string]any {
+	field := q.Name()
+	switch q.Kind() {
+	case domain.QueryFieldText:
+		if q.Prefix {
+			return map[string]any{"match_phrase_prefix": map[string]any{field: q.Value}}
+		}
+		return createMatchQuery(field, q.Value)
+	case domain.QueryFieldJSON:
+		return compileJSONFilter(q.JSONFilter())
+	}
+
+	if q.Prefix {
+		return map[string]any{"prefix": map[string]any{field: q.Value}}
+	}
+	return createTermQuery(field, q.Value)
+}
+
+// compileJSONFilter compiles a JSON path filter into a query on the path as
+// the tenant declared it. Indices from before the declared paths mapped the
+// strings dynamically as text with a keyword subfield, which equality and
+// prefixes also match on.
+func compileJSONFilter(f *domain.JSONFilter) map[string]any {
+	field := f.Name()
+	switch f.Op {
+	case domain.JSONFilterExists:
+		exists := map[string]any{"exists": map[string]any{"field": field}}
+		if f.Value != "true" {
+			return map[string]any{"bool": map[string]any{"must_not": []map[string]any{exists}}}
+		}
+		return exists
+	case domain.JSONFilterEq:
+		return anyOf(createTermQuery(field, f.Value), createTermQuery(field+".keyword", f.Value))
+	case domain.JSONFilterPrefix:
+		return anyOf(
+			map[string]any{"prefix": map[string]any{field: f.Value}},
+			map[string]any{"prefix": map[string]any{field + ".keyword": f.Value}},
+		)
+	}
+
+	var value any = f.Value
+	if n, ok := domain.ParseJSONNumber(f.Value); ok {
+		value = n
+	}
+	return map[string]any{"range": map[string]any{field: map[string]any{string(f.Op): value}}}
+}
+
+func anyOf(queries ...map[string]any) map[string]any {
+	return map[string]any{"bool": map[string]any{
+		"should":               queries,
+		"minimum_should_match": 1,
+	}}
+}
@@ -0,0 +1,83 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+}
+
+func TestQuery(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+func (s *QueryTestSuite) TestCompileQuery() {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "keyword", query: "severity:ERROR", want: `{"term":{"severity":"ERROR"}}`},
+		{name: "keyword prefix", query: "user_id:svc-*", want: `{"prefix":{"user_id":"svc-"}}`},
+		{
+			name:  "UUID",
+			query: "id:3F2504E0-4F89-11D3-9A0C-0305E82C3301",
+			want:  `{"term":{"id":"3f2504e0-4f89-11d3-9a0c-0305e82c3301"}}`,
+		},
+		{name: "text", query: `message:"disk full"`, want: `{"match":{"message":"disk full"}}`},
+		{name: "text prefix", query: "message:fail*", want: `{"match_phrase_prefix":{"message":"fail"}}`},
+		{
+			name:  "JSON equality",
+			query: "metadata.order.id:42",
+			want: `{"bool":{"minimum_should_match":1,"should":[
+				{"term":{"metadata.order.id":"42"}},
+				{"term":{"metadata.order.id.keyword":"42"}}
+			]}}`,
+		},
+		{
+			name:  "JSON prefix",
+			query: "after_state.name:new*",
+			want: `{"bool":{"minimum_should_match":1,"should":[
+				{"prefix":{"after_state.name":"new"}},
+				{"prefix":{"after_state.name.keyword":"new"}}
+			]}}`,
+		},
+		{
+			name:  "precedence",
+			query: "action:a OR action:b severity:ERROR",
+			want: `{"bool":{"minimum_should_match":1,"should":[
+				{"term":{"action":"a"}},
+				{"bool":{"must":[{"term":{"action":"b"}},{"term":{"severity":"ERROR"}}]}}
+			]}}`,
+		},
+		{
+			name:  "negated field group",
+			query: "NOT severity:(WARNING OR ERROR)",
+			want: `{"bool":{"must_not":[{"bool":{"minimum_should_match":1,"should":[
+				{"term":{"severity":"WARNING"}},
+				{"term":{"severity":"ERROR"}}
+			]}}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Arrange
+			query, err := domain.ParseLogQuery(tt.query)
+			s.Require().NoError(err)
+
+			// Act
+			compiled, err := json.Marshal(compileQuery(query.Root))
+
+			// Assert
+			s.Require().NoError(err)
+			s.JSONEq(tt.want, string(compiled))
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/opensearch-project/opensearch-go/v2"
@@ -20,19 +22,59 @@ type Repository interface {
 	Index(ctx context.Context, log *domain.AuditLog) error
 	// BulkIndex indexes multiple audit logs
 	BulkIndex(ctx context.Context, logs []domain.AuditLog) error
-	// Search searches audit logs with the given filter
-	Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error)
-	// CreateIndex creates an index for a tenant if it doesn't exist
-	CreateIndex(ctx context.Context, tenantID string, t time.Time) error
-	// DeleteIndex deletes an index for a tenant
-	DeleteIndex(ctx context.Context, tenantID string) error
+	// BulkIndexItems indexes multiple audit logs in one request and returns
+	// the logs that failed to index
+	BulkIndexItems(ctx context.Context, logs []domain.AuditLog) ([]*BulkItemError, error)
+	// Search searches audit logs with the given filter and returns the logs
+	// along with the total number of hits
+	Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, error)
+	// Sessions sums up the sessions of the logs matching the filter, the
+	// sessions with the most recent events first, along with the number of
+	// sessions
+	Sessions(ctx context.Context, filter *domain.AuditLogFilter, limit int) ([]domain.SessionSummary, int64, error)
+	// Setup installs the templates and the ISM policy of the indices and the
+	// write aliases of the tenants
+	Setup(ctx context.Context, tenantIDs []string) error
+	// CreateIndex creates the write alias of a tenant and its first index if
+	// the alias doesn't exist
+	CreateIndex(ctx context.Context, tenantID string) error
+	// PutIndexedPaths maps the declared paths inside the JSON fields in the
+	// indices of a tenant, a path mapped with another type is a conflict
+	PutIndexedPaths(ctx context.Context, tenantID string, paths []domain.IndexedPath) error
+	// DeleteIndex deletes every index of a tenant and returns the number of
+	// logs they held
+	DeleteIndex(ctx context.Context, tenantID string) (int64, error)
 	// Delete deletes a single audit log by ID
 	Delete(ctx context.Context, tenantID, logID string) error
+	// DeleteBeforeDate deletes the audit logs of a tenant before the date,
+	// except for the excluded ones, and returns the number of deleted logs.
+	// Indices holding only such logs are dropped as a whole.
+	DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time, exclusions domain.CleanupExclusions) (int64, error)
+	// DeleteRange deletes the audit logs of a tenant between startTime,
+	// inclusive, and endTime, exclusive, and returns the number of deleted logs
+	DeleteRange(ctx context.Context, tenantID string, startTime, endTime time.Time) (int64, error)
+	// CountByDay returns the number of indexed logs of a tenant on each UTC
+	// day between startTime, inclusive, and endTime, exclusive
+	CountByDay(ctx context.Context, tenantID string, startTime, endTime time.Time) (map[string]int64, error)
+	// ExistingIDs returns which of the given log IDs are indexed for a tenant
+	ExistingIDs(ctx context.Context, tenantID string, ids []string) ([]string, error)
+	// LegacyIndices returns the daily indices of a tenant, of every tenant when
+	// the tenant ID is empty
+	LegacyIndices(ctx context.Context, tenantID string) ([]LegacyIndex, error)
+	// MigrateLegacyIndex moves the logs of a daily index behind the alias of
+	// its tenant and returns the number of logs copied
+	MigrateLegacyIndex(ctx context.Context, index LegacyIndex) (int64, error)
 }
 
+// maxIndicesPerTenant bounds the indices of a tenant looked at by a cleanup,
+// the daily indices not migrated yet and the indices behind its alias
+const maxIndicesPerTenant = 10000
+
 type repository struct {
 	client *opensearch.Client
 	config *config.OpenSearchConfig
+	// tenants holds the tenants whose write alias is known to exist
+	tenants sync.Map
 }
 
 func NewRepository(client *opensearch.Client, config *config.OpenSearchConfig) Repository {
@@ -43,16 +85,9 @@ func NewRepository(client *opensearch.Client, config *config.OpenSearchConfig) R
 }
 
 func (r *repository) Index(ctx context.Context, log *domain.AuditLog) error {
-	// Use log timestamp for index name, fallback to current time if not set
-	indexTime := time.Now()
-	if !log.Timestamp.IsZero() {
-		indexTime = log.Timestamp
-	}
-	indexName := r.config.GetIndexName(log.TenantID, indexTime)
-
-	// Ensure index exists
-	if err := r.CreateIndex(ctx, log.TenantID, indexTime); err != nil {
-		return fmt.Errorf("failed to ensure index exists: %w", err)
+	// Ensure the write alias exists
+	if err := r.ensureAlias(ctx, log.TenantID); err != nil {
+		return fmt.Errorf("failed to ensure alias exists: %w", err)
 	}
 
 	// Convert log to JSON
@@ -61,11 +96,23 @@ func (r *repository) Index(ctx context.Context, log *domain.AuditLog) error {
 		return fmt.Errorf("failed to marshal log: %w", err)
 	}
 
-	// Create index request
+	// Create index request to the index already holding the log or else to
+	// the write alias, an alias that was deleted fails the request instead
+	// of being created as an index
+	located, err := r.locate(ctx, []domain.AuditLog{*log})
+	if err != nil {
+		return err
+	}
+	requireAlias := true
 	req := opensearchapi.IndexRequest{
-		Index:      indexName,
-		DocumentID: log.ID,
-		Body:       strings.NewReader(string(data)),
+		Index:        r.config.GetAliasName(log.TenantID),
+		DocumentID:   log.ID,
+		Body:         strings.NewReader(string(data)),
+		RequireAlias: &requireAlias,
+	}
+	if index, ok := located[locationKey(log.TenantID, log.ID)]; ok {
+		req.Index = index
+		req.RequireAlias = nil
 	}
 
 	res, err := req.Do(ctx, r.client)
@@ -75,6 +122,9 @@ func (r *repository) Index(ctx context.Context, log *domain.AuditLog) error {
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if strings.Contains(res.String(), "index_not_found_exception") {
+			r.tenants.Delete(log.TenantID)
+		}
 		return fmt.Errorf("error indexing document: %s", res.String())
 	}
 
@@ -82,91 +132,22 @@ func (r *repository) Index(ctx context.Context, log *domain.AuditLog) error {
 }
 
 func (r *repository) BulkIndex(ctx context.Context, logs []domain.AuditLog) error {
-	if len(logs) == 0 {
-		return nil
-	}
-
-	// Group logs by tenant and date
-	logGroups := make(map[string][]domain.AuditLog)
-	for _, log := range logs {
-		indexTime := time.Now()
-		if !log.Timestamp.IsZero() {
-			indexTime = log.Timestamp
-		}
-		indexName := r.config.GetIndexName(log.TenantID, indexTime)
-		logGroups[indexName] = append(logGroups[indexName], log)
-	}
-
-	// Process each group separately
-	for indexName, groupLogs := range logGroups {
-		if err := r.bulkIndexGroup(ctx, indexName, groupLogs); err != nil {
-			return fmt.Errorf("failed to bulk index group for index %s: %w", indexName, err)
-		}
-	}
-
-	return nil
-}
-
-func (r *repository) bulkIndexGroup(ctx context.Context, indexName string, logs []domain.AuditLog) error {
-	// Ensure index exists (using first log's tenant and timestamp)
-	if len(logs) > 0 {
-		indexTime := time.Now()
-		if !logs[0].Timestamp.IsZero() {
-			indexTime = logs[0].Timestamp
-		}
-		if err := r.CreateIndex(ctx, logs[0].TenantID, indexTime); err != nil {
-			return fmt.Errorf("failed to ensure index exists: %w", err)
-		}
-	}
-
-	// Build bulk request body
-	var bulkBody strings.Builder
-	for _, log := range logs {
-		action := map[string]any{
-			"index": map[string]any{
-				"_index": indexName,
-				"_id":    log.ID,
-			},
-		}
-		actionLine, err := json.Marshal(action)
-		if err != nil {
-			return fmt.Errorf("failed to marshal action: %w", err)
-		}
-		bulkBody.Write(actionLine)
-		bulkBody.WriteString("\n")
-
-		// Add document line
-		docLine, err := json.Marshal(log)
-		if err != nil {
-			return fmt.Errorf("failed to marshal document: %w", err)
-		}
-		bulkBody.Write(docLine)
-		bulkBody.WriteString("\n")
-	}
-
-	// Send bulk request
-	req := opensearchapi.BulkRequest{
-		Body: strings.NewReader(bulkBody.String()),
-	}
-
-	res, err := req.Do(ctx, r.client)
+	failures, err := r.BulkIndexItems(ctx, logs)
 	if err != nil {
-		return fmt.Errorf("failed to execute bulk request: %w", err)
+		return err
 	}
-	defer res.Body.Close()
-
-	if res.IsError() {
-		return fmt.Errorf("bulk request failed: %s", res.String())
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d logs failed to index: %w", len(failures), len(logs), failures[0])
 	}
 
 	return nil
 }
 
-func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error) {
+func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, error) {
 	// Get tenant ID from context
 	tenantID, err := utils.GetTenantIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tenant ID from context: %w", err)
+		return nil, 0, fmt.Errorf("failed to get tenant ID from context: %w", err)
 	}
 
 	// Build search query
@@ -175,32 +156,32 @@ func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter)
 	// Convert query to JSON
 	queryJSON, err := json.Marshal(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal query: %w", err)
+		return nil, 0, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
-	// Create search request using tenant's index pattern
-	req := opensearchapi.SearchRequest{
-		Index: []string{r.config.GetIndexPattern(tenantID)},
-		Body:  strings.NewReader(string(queryJSON)),
-	}
+	// Create search request using tenant's indices
+	req := r.searchRequest(tenantID, queryJSON)
 
 	// Execute search
 	res, err := req.Do(ctx, r.client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute search: %w", err)
+		return nil, 0, fmt.Errorf("failed to execute search: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
 		if res.StatusCode == 404 {
-			return []domain.AuditLog{}, nil
+			return []domain.AuditLog{}, 0, nil
 		}
-		return nil, fmt.Errorf("search request failed: %s", res.String())
+		return nil, 0, fmt.Errorf("search request failed: %s", res.String())
 	}
 
 	// Parse response
 	var searchResult struct {
 		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
 			Hits []struct {
 				Source domain.AuditLog `json:"_source"`
 			} `json:"hits"`
@@ -208,7 +189,7 @@ func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter)
 	}
 
 	if err := json.NewDecoder(res.Body).Decode(&searchResult); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, 0, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// Extract logs from response
@@ -217,7 +198,7 @@ func (r *repository) Search(ctx context.Context, filter *domain.AuditLogFilter)
 		logs = append(logs, hit.Source)
 	}
 
-	return logs, nil
+	return logs, searchResult.Hits.Total.Value, nil
 }
 
 // buildSearchQuery constructs the OpenSearch query based on the filter
@@ -226,11 +207,13 @@ func (r *repository) buildSearchQuery(filter *domain.AuditLogFilter) map[string]
 
 	// Add exact match filters (keyword fields)
 	exactMatches := map[string]string{
-		"user_id":       filter.UserID,
-		"action":        filter.Action,
-		"resource_type": filter.ResourceType,
-		"severity":      filter.Severity,
-		"session_id":    filter.SessionID,
+		"user_id":        filter.UserID,
+		"action":         filter.Action,
+		"resource_type":  filter.ResourceType,
+		"resource_id":    filter.ResourceID,
+		"severity":       filter.Severity,
+		"session_id":     filter.SessionID,
+		"changed_fields": filter.ChangedField,
 	}
 	for field, value := range exactMatches {
 		if value != "" {
@@ -259,6 +242,16 @@ func (r *repository) buildSearchQuery(filter *domain.AuditLogFilter) map[string]
 		must = append(must, createTimeRangeQuery(filter.StartTime, filter.EndTime))
 	}
 
+	// Add the query language filters
+	if filter.Query != nil {
+		must = append(must, compileQuery(filter.Query.Root))
+	}
+
+	// Add the JSON path filters
+	for i := range filter.JSONFilters {
+		must = append(must, compileJSONFilter(&filter.JSONFilters[i]))
+	}
+
 	// Construct the final query
 	query := map[string]any{
 		"query": map[string]any{
@@ -268,19 +261,29 @@ func (r *repository) buildSearchQuery(filter *domain.AuditLogFilter) map[string]
 		},
 	}
 
-	// Add pagination
-	if filter.Page > 0 && filter.PageSize > 0 {
-		query["from"] = (filter.Page - 1) * filter.PageSize
-		query["size"] = filter.PageSize
+	// Add pagination, search_after continues from the cursor without the
+	// result window limit that applies to from
+	if filter.Limit > 0 {
+		query["size"] = filter.Limit
+	}
+	if filter.After != nil {
+		query["search_after"] = []any{filter.After.Timestamp.UnixMilli(), filter.After.ID}
+	} else if filter.Offset > 0 {
+		query["from"] = filter.Offset
 	}
 
-	// Add sorting (most recent first)
+	// Add sorting (most recent first), the ID breaks ties so that pages are stable
 	query["sort"] = []map[string]any{
 		{
 			"timestamp": map[string]any{
 				"order": "desc",
 			},
 		},
+		{
+			"id": map[string]any{
+				"order": "desc",
+			},
+		},
 	}
 
 	return query
@@ -318,125 +321,480 @@ func createTimeRangeQuery(startTime, endTime time.Time) map[string]any {
 	}
 }
 
-// getIndexMapping returns the mapping for audit log index with optimized settings
-func (r *repository) getIndexMapping() string {
-	return `{
-		"mappings": {
-			"properties": {
-				"id": { "type": "keyword" },
-				"tenant_id": { "type": "keyword" },
-				"user_id": { "type": "keyword" },
-				"session_id": { "type": "keyword" },
-				"action": { "type": "keyword" },
-				"resource_type": { "type": "keyword" },
-				"resource_id": { "type": "keyword" },
-				"message": { "type": "text" },
-				"metadata": { 
-					"type": "object",
-					"dynamic": true
-				},
-				"before_state": {
-					"type": "object",
-					"dynamic": true
+// DeleteIndex deletes the indices behind the alias of the tenant, its daily
+// indices and its templates
+func (r *repository) DeleteIndex(ctx context.Context, tenantID string) (int64, error) {
+	count, err := r.count(ctx, r.readIndices(tenantID)...)
+	if err != nil {
+		return 0, err
+	}
+
+	delete := opensearchapi.IndicesDeleteRequest{
+		Index: []string{r.config.GetAliasIndexPattern(tenantID), r.config.GetIndexPattern(tenantID)},
+	}
+	r.tenants.Delete(tenantID)
+
+	res, err := delete.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete index: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, fmt.Errorf("error deleting index: %s", res.String())
+	}
+
+	deleteTemplate := opensearchapi.IndicesDeleteIndexTemplateRequest{
+		Name: r.config.GetAliasName(tenantID),
+	}
+	res, err = deleteTemplate.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete index template: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return 0, fmt.Errorf("error deleting index template: %s", res.String())
+	}
+
+	// The component template is in use until the index template is deleted
+	deletePaths := opensearchapi.ClusterDeleteComponentTemplateRequest{
+		Name: r.pathsTemplateName(tenantID),
+	}
+	res, err = deletePaths.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete component template: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return 0, fmt.Errorf("error deleting component template: %s", res.String())
+	}
+
+	return count, nil
+}
+
+// Delete deletes the log from whichever index of the tenant holds it
+func (r *repository) Delete(ctx context.Context, tenantID, logID string) error {
+	query := map[string]any{
+		"query": map[string]any{
+			"ids": map[string]any{"values": []string{logID}},
+		},
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	ignoreUnavailable := true
+	req := opensearchapi.DeleteByQueryRequest{
+		Index:             r.readIndices(tenantID),
+		Body:              strings.NewReader(string(queryJSON)),
+		Conflicts:         "proceed",
+		IgnoreUnavailable: &ignoreUnavailable,
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("error deleting document: %s", res.String())
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time, exclusions domain.CleanupExclusions) (int64, error) {
+	// Whole indices may only be dropped when no log before the date is kept
+	var dropped int64
+	if exclusions.IsEmpty() {
+		var err error
+		if dropped, err = r.dropExpiredIndices(ctx, tenantID, beforeDate); err != nil {
+			return 0, err
+		}
+	}
+
+	query := map[string]any{
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					createTermQuery("tenant_id", tenantID),
+					{"range": map[string]any{"timestamp": map[string]any{"lt": beforeDate}}},
 				},
-				"after_state": {
-					"type": "object",
-					"dynamic": true
+				"must_not": buildExclusionQueries(exclusions),
+			},
+		},
+	}
+
+	deleted, err := r.deleteByQuery(ctx, tenantID, query)
+	return dropped + deleted, err
+}
+
+func (r *repository) DeleteRange(ctx context.Context, tenantID string, startTime, endTime time.Time) (int64, error) {
+	query := map[string]any{
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					createTermQuery("tenant_id", tenantID),
+					{"range": map[string]any{"timestamp": map[string]any{"gte": startTime, "lt": endTime}}},
 				},
-				"severity": { "type": "keyword" },
-				"timestamp": { "type": "date" },
-				"ip_address": { "type": "ip" },
-				"user_agent": { "type": "text" }
-			}
+			},
 		},
-		"settings": {
-			"index": {
-				"number_of_shards": 1,
-				"number_of_replicas": 1,
-				"refresh_interval": "1s",
-				"mapping": {
-					"total_fields": {
-						"limit": 2000
-					}
-				}
-			}
+	}
+
+	return r.deleteByQuery(ctx, tenantID, query)
+}
+
+// deleteByQuery deletes the logs matching the query from the indices of the
+// tenant and returns the number of deleted logs
+func (r *repository) deleteByQuery(ctx context.Context, tenantID string, query map[string]any) (int64, error) {
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	// Logs indexed while the cleanup runs must not abort it
+	ignoreUnavailable := true
+	req := opensearchapi.DeleteByQueryRequest{
+		Index:             r.readIndices(tenantID),
+		Body:              strings.NewReader(string(queryJSON)),
+		Conflicts:         "proceed",
+		IgnoreUnavailable: &ignoreUnavailable,
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return 0, nil
 		}
-	}`
+		return 0, fmt.Errorf("error deleting documents: %s", res.String())
+	}
+
+	var result struct {
+		Deleted int64 `json:"deleted"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Deleted, nil
 }
 
-func (r *repository) CreateIndex(ctx context.Context, tenantID string, t time.Time) error {
-	indexName := r.config.GetIndexName(tenantID, t)
+// dropExpiredIndices drops the indices of the tenant whose latest log is
+// before the date and returns the number of logs they held. The write index
+// is kept, logs of any time may still be written to it.
+func (r *repository) dropExpiredIndices(ctx context.Context, tenantID string, beforeDate time.Time) (int64, error) {
+	writeIndex, err := r.writeIndex(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	query := map[string]any{
+		"size": 0,
+		"aggs": map[string]any{
+			"indices": map[string]any{
+				"terms": map[string]any{"field": "_index", "size": maxIndicesPerTenant},
+				"aggs": map[string]any{
+					"latest": map[string]any{"max": map[string]any{"field": "timestamp"}},
+				},
+			},
+		},
+	}
 
-	// Check if index exists
-	exists := opensearchapi.IndicesExistsRequest{
-		Index: []string{indexName},
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal query: %w", err)
 	}
-	res, err := exists.Do(ctx, r.client)
+
+	req := r.searchRequest(tenantID, queryJSON)
+	res, err := req.Do(ctx, r.client)
 	if err != nil {
-		return fmt.Errorf("failed to check index existence: %w", err)
+		return 0, fmt.Errorf("failed to execute search: %w", err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode == 200 {
-		return nil // Index already exists
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("search request failed: %s", res.String())
+	}
+
+	var result struct {
+		Aggregations struct {
+			Indices struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int64  `json:"doc_count"`
+					Latest   struct {
+						Value *float64 `json:"value"`
+					} `json:"latest"`
+				} `json:"buckets"`
+			} `json:"indices"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var expired []string
+	var logs int64
+	for _, bucket := range result.Aggregations.Indices.Buckets {
+		if bucket.Key == writeIndex || bucket.Latest.Value == nil {
+			continue
+		}
+		if !time.UnixMilli(int64(*bucket.Latest.Value)).Before(beforeDate) {
+			continue
+		}
+		expired = append(expired, bucket.Key)
+		logs += bucket.DocCount
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	delete := opensearchapi.IndicesDeleteRequest{
+		Index: expired,
+	}
+	res, err = delete.Do(ctx, r.client)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete indices: %w", err)
 	}
+	defer res.Body.Close()
 
-	// Create index with mapping and settings
-	create := opensearchapi.IndicesCreateRequest{
-		Index: indexName,
-		Body:  strings.NewReader(r.getIndexMapping()),
+	if res.IsError() {
+		return 0, fmt.Errorf("error deleting indices: %s", res.String())
 	}
 
-	res, err = create.Do(ctx, r.client)
+	return logs, nil
+}
+
+// writeIndex returns the index the alias of the tenant writes to, which is
+// empty when the alias does not exist
+func (r *repository) writeIndex(ctx context.Context, tenantID string) (string, error) {
+	alias := r.config.GetAliasName(tenantID)
+	req := opensearchapi.IndicesGetAliasRequest{
+		Name: []string{alias},
+	}
+	res, err := req.Do(ctx, r.client)
 	if err != nil {
-		return fmt.Errorf("failed to create index: %w", err)
+		return "", fmt.Errorf("failed to get alias: %w", err)
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
 	if res.IsError() {
-		return fmt.Errorf("error creating index: %s", res.String())
+		return "", fmt.Errorf("error getting alias: %s", res.String())
 	}
 
-	return nil
+	var indices map[string]struct {
+		Aliases map[string]struct {
+			IsWriteIndex *bool `json:"is_write_index"`
+		} `json:"aliases"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return "", fmt.Errorf("failed to decode alias: %w", err)
+	}
+
+	// An alias of a single index writes to it unless told otherwise
+	for index, aliases := range indices {
+		isWriteIndex := aliases.Aliases[alias].IsWriteIndex
+		if (isWriteIndex != nil && *isWriteIndex) || (isWriteIndex == nil && len(indices) == 1) {
+			return index, nil
+		}
+	}
+	return "", nil
 }
 
-func (r *repository) DeleteIndex(ctx context.Context, tenantID string) error {
-	indexName := r.config.GetIndexName(tenantID, time.Now()) // Assuming current time for deletion
+func (r *repository) CountByDay(ctx context.Context, tenantID string, startTime, endTime time.Time) (map[string]int64, error) {
+	query := map[string]any{
+		"size": 0,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					createTermQuery("tenant_id", tenantID),
+					{"range": map[string]any{"timestamp": map[string]any{"gte": startTime, "lt": endTime}}},
+				},
+			},
+		},
+		"aggs": map[string]any{
+			"days": map[string]any{
+				"date_histogram": map[string]any{
+					"field":          "timestamp",
+					"fixed_interval": "1d",
+					"format":         "yyyy-MM-dd",
+				},
+			},
+		},
+	}
 
-	delete := opensearchapi.IndicesDeleteRequest{
-		Index: []string{indexName},
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
-	res, err := delete.Do(ctx, r.client)
+	req := r.searchRequest(tenantID, queryJSON)
+
+	res, err := req.Do(ctx, r.client)
 	if err != nil {
-		return fmt.Errorf("failed to delete index: %w", err)
+		return nil, fmt.Errorf("failed to execute search: %w", err)
 	}
 	defer res.Body.Close()
 
+	counts := make(map[string]int64)
 	if res.IsError() {
-		return fmt.Errorf("error deleting index: %s", res.String())
+		if res.StatusCode == 404 {
+			return counts, nil
+		}
+		return nil, fmt.Errorf("search request failed: %s", res.String())
 	}
 
-	return nil
+	var result struct {
+		Aggregations struct {
+			Days struct {
+				Buckets []struct {
+					Key      string `json:"key_as_string"`
+					DocCount int64  `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"days"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, bucket := range result.Aggregations.Days.Buckets {
+		if bucket.DocCount > 0 {
+			counts[bucket.Key] = bucket.DocCount
+		}
+	}
+	return counts, nil
 }
 
-func (r *repository) Delete(ctx context.Context, tenantID, logID string) error {
-	indexName := r.config.GetIndexName(tenantID, time.Now()) // Assuming current time for deletion
+func (r *repository) ExistingIDs(ctx context.Context, tenantID string, ids []string) ([]string, error) {
+	existing := make([]string, 0, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	query := map[string]any{
+		"size":    len(ids),
+		"_source": false,
+		"query": map[string]any{
+			"ids": map[string]any{"values": ids},
+		},
+	}
 
-	req := opensearchapi.DeleteRequest{
-		Index:      indexName,
-		DocumentID: logID,
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
+	req := r.searchRequest(tenantID, queryJSON)
+
 	res, err := req.Do(ctx, r.client)
 	if err != nil {
-		return fmt.Errorf("failed to delete document: %w", err)
+		return nil, fmt.Errorf("failed to execute search: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("error deleting document: %s", res.String())
+		if res.StatusCode == 404 {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("search request failed: %s", res.String())
 	}
 
-	return nil
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, hit := range result.Hits.Hits {
+		existing = append(existing, hit.ID)
+	}
+	return existing, nil
+}
+
+// readIndices returns the indices holding the logs of a tenant: the indices
+// behind its alias and the daily indices that were not migrated yet
+func (r *repository) readIndices(tenantID string) []string {
+	return []string{r.config.GetAliasName(tenantID), r.config.GetIndexPattern(tenantID)}
+}
+
+// searchRequest searches the indices of a tenant. The alias is missing until
+// the first log of the tenant is indexed, which is not an error.
+func (r *repository) searchRequest(tenantID string, queryJSON []byte) opensearchapi.SearchRequest {
+	ignoreUnavailable := true
+	return opensearchapi.SearchRequest{
+		Index:             r.readIndices(tenantID),
+		Body:              strings.NewReader(string(queryJSON)),
+		IgnoreUnavailable: &ignoreUnavailable,
+	}
+}
+
+// buildExclusionQueries returns the queries matching the logs a cleanup keeps
+func buildExclusionQueries(exclusions domain.CleanupExclusions) []map[string]any {
+	queries := make([]map[string]any, 0, len(exclusions.Holds)+1)
+	if len(exclusions.KeepSeverities) > 0 {
+		queries = append(queries, map[string]any{
+			"terms": map[string]any{"severity": exclusions.KeepSeverities},
+		})
+	}
+
+	for _, hold := range exclusions.Holds {
+		if len(hold.LogIDs) > 0 {
+			queries = append(queries, map[string]any{
+				"ids": map[string]any{"values": hold.LogIDs},
+			})
+			continue
+		}
+		if !hold.HasFilter() {
+			continue
+		}
+
+		filter := make([]map[string]any, 0)
+		exactMatches := map[string]string{
+			"user_id":       hold.UserID,
+			"resource_type": hold.ResourceType,
+			"resource_id":   hold.ResourceID,
+		}
+		for field, value := range exactMatches {
+			if value != "" {
+				filter = append(filter, createTermQuery(field, value))
+			}
+		}
+		if hold.StartTime != nil || hold.EndTime != nil {
+			var startTime, endTime time.Time
+			if hold.StartTime != nil {
+				startTime = *hold.StartTime
+			}
+			if hold.EndTime != nil {
+				endTime = *hold.EndTime
+			}
+			filter = append(filter, createTimeRangeQuery(startTime, endTime))
+		}
+		queries = append(queries, map[string]any{
+			"bool": map[string]any{"filter": filter},
+		})
+	}
+
+	return queries
 }
@@ -0,0 +1,141 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type termsAggregation struct {
+	Buckets []struct {
+		Key      string `json:"key"`
+		DocCount int64  `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+type topHitAggregation struct {
+	Hits struct {
+		Hits []struct {
+			Source domain.AuditLog `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// Sessions sums up the sessions of the logs matching the filter, the limit
+// sessions with the most recent events first, along with the total number of
+// sessions. Logs without a session ID are left out.
+func (r *repository) Sessions(ctx context.Context, filter *domain.AuditLogFilter, limit int) ([]domain.SessionSummary, int64, error) {
+	distinct := func(field string) map[string]any {
+		return map[string]any{"terms": map[string]any{"field": field, "size": domain.MaxSessionValues}}
+	}
+	event := func(order string) map[string]any {
+		return map[string]any{"top_hits": map[string]any{
+			"size": 1,
+			"sort": []map[string]any{{"timestamp": map[string]any{"order": order}}, {"id": map[string]any{"order": order}}},
+		}}
+	}
+
+	query := map[string]any{
+		"size":  0,
+		"query": r.buildSearchQuery(filter)["query"],
+		"aggs": map[string]any{
+			"session_count": map[string]any{"cardinality": map[string]any{"field": "session_id"}},
+			"sessions": map[string]any{
+				"terms": map[string]any{
+					"field": "session_id",
+					"size":  limit,
+					"order": map[string]any{"last_seen": "desc"},
+				},
+				"aggs": map[string]any{
+					"last_seen":    map[string]any{"max": map[string]any{"field": "timestamp"}},
+					"user_ids":     distinct("user_id"),
+					"ip_addresses": distinct("ip_address"),
+					"user_agents":  distinct("user_agent.keyword"),
+					"actions":      map[string]any{"terms": map[string]any{"field": "action", "size": domain.MaxSessionValues}},
+					"first_event":  event("asc"),
+					"last_event":   event("desc"),
+				},
+			},
+		},
+	}
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req := r.searchRequest(filter.TenantID, queryJSON)
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return []domain.SessionSummary{}, 0, nil
+		}
+		return nil, 0, fmt.Errorf("search request failed: %s", res.String())
+	}
+
+	var result struct {
+		Aggregations struct {
+			SessionCount struct {
+				Value int64 `json:"value"`
+			} `json:"session_count"`
+			Sessions struct {
+				Buckets []struct {
+					Key         string            `json:"key"`
+					DocCount    int64             `json:"doc_count"`
+					UserIDs     termsAggregation  `json:"user_ids"`
+					IPAddresses termsAggregation  `json:"ip_addresses"`
+					UserAgents  termsAggregation  `json:"user_agents"`
+					Actions     termsAggregation  `json:"actions"`
+					FirstEvent  topHitAggregation `json:"first_event"`
+					LastEvent   topHitAggregation `json:"last_event"`
+				} `json:"buckets"`
+			} `json:"sessions"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	keys := func(agg termsAggregation) []string {
+		values := make([]string, 0, len(agg.Buckets))
+		for _, bucket := range agg.Buckets {
+			values = append(values, bucket.Key)
+		}
+		slices.Sort(values)
+		return values
+	}
+
+	sessions := make([]domain.SessionSummary, 0, len(result.Aggregations.Sessions.Buckets))
+	for _, bucket := range result.Aggregations.Sessions.Buckets {
+		session := domain.NewSessionSummary(bucket.Key)
+		session.EventCount = bucket.DocCount
+		session.UserIDs = keys(bucket.UserIDs)
+		session.IPAddresses = keys(bucket.IPAddresses)
+		session.UserAgents = keys(bucket.UserAgents)
+		for _, action := range bucket.Actions.Buckets {
+			session.ActionCounts[action.Key] = action.DocCount
+		}
+		if hits := bucket.FirstEvent.Hits.Hits; len(hits) > 0 {
+			session.FirstEvent = &hits[0].Source
+			session.StartTime = hits[0].Source.Timestamp
+		}
+		if hits := bucket.LastEvent.Hits.Hits; len(hits) > 0 {
+			session.LastEvent = &hits[0].Source
+			session.EndTime = hits[0].Source.Timestamp
+		}
+		sessions = append(sessions, *session)
+	}
+
+	// The cardinality is approximate, it can't be below the sessions found
+	total := max(result.Aggregations.SessionCount.Value, int64(len(sessions)))
+	return sessions, total, nil
+}
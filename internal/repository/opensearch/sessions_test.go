@@ -0,0 +1,140 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+const sessionsPath = "/audit-logs-tenant1,audit_logs_tenant1_*/_search"
+
+type SessionsTestSuite struct {
+	suite.Suite
+	stub *openSearchStub
+	repo *repository
+}
+
+func (s *SessionsTestSuite) SetupTest() {
+	s.stub = newOpenSearchStub()
+	repo, err := s.stub.repository()
+	s.Require().NoError(err)
+	s.repo = repo
+}
+
+func (s *SessionsTestSuite) TearDownTest() {
+	s.stub.server.Close()
+}
+
+func TestSessions(t *testing.T) {
+	suite.Run(t, new(SessionsTestSuite))
+}
+
+func (s *SessionsTestSuite) TestSessions_AggregatesSessionsByLastEvent() {
+	// Arrange
+	s.stub.on(http.MethodPost, sessionsPath, http.StatusOK, `{"aggregations":{
+		"session_count":{"value":3},
+		"sessions":{"buckets":[{
+			"key":"sess1","doc_count":3,
+			"user_ids":{"buckets":[{"key":"user1","doc_count":3}]},
+			"ip_addresses":{"buckets":[{"key":"10.0.0.2","doc_count":1},{"key":"10.0.0.1","doc_count":2}]},
+			"user_agents":{"buckets":[]},
+			"actions":{"buckets":[{"key":"VIEW","doc_count":2},{"key":"UPDATE","doc_count":1}]},
+			"first_event":{"hits":{"hits":[{"_source":{"id":"log1","timestamp":"2024-01-01T12:00:00Z"}}]}},
+			"last_event":{"hits":{"hits":[{"_source":{"id":"log3","timestamp":"2024-01-01T12:30:00Z"}}]}}
+		}]}
+	}}`)
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", UserID: "user1"}
+
+	// Act
+	sessions, total, err := s.repo.Sessions(context.Background(), filter, 10)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(3), total)
+	s.Require().Len(sessions, 1)
+	session := sessions[0]
+	s.Equal("sess1", session.SessionID)
+	s.Equal(int64(3), session.EventCount)
+	s.Equal([]string{"user1"}, session.UserIDs)
+	s.Equal([]string{"10.0.0.1", "10.0.0.2"}, session.IPAddresses)
+	s.Empty(session.UserAgents)
+	s.Equal(map[string]int64{"VIEW": 2, "UPDATE": 1}, session.ActionCounts)
+	s.Equal("log1", session.FirstEvent.ID)
+	s.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), session.StartTime)
+	s.Equal("log3", session.LastEvent.ID)
+	s.Equal(time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC), session.EndTime)
+
+	requests := s.stub.received()
+	s.Require().Len(requests, 1)
+	s.Contains(requests[0].Query, "ignore_unavailable=true")
+	var body map[string]any
+	s.Require().NoError(json.Unmarshal([]byte(requests[0].Body), &body))
+	s.Equal(float64(0), body["size"])
+	s.Equal(map[string]any{"bool": map[string]any{"must": []any{
+		map[string]any{"term": map[string]any{"user_id": "user1"}},
+	}}}, body["query"])
+
+	aggs := body["aggs"].(map[string]any)
+	s.Equal(map[string]any{"cardinality": map[string]any{"field": "session_id"}}, aggs["session_count"])
+	terms := aggs["sessions"].(map[string]any)
+	s.Equal(map[string]any{
+		"field": "session_id",
+		"size":  float64(10),
+		"order": map[string]any{"last_seen": "desc"},
+	}, terms["terms"])
+	sub := terms["aggs"].(map[string]any)
+	s.Equal(map[string]any{"max": map[string]any{"field": "timestamp"}}, sub["last_seen"])
+	s.Equal(map[string]any{"terms": map[string]any{"field": "user_agent.keyword", "size": float64(domain.MaxSessionValues)}}, sub["user_agents"])
+	s.Equal(map[string]any{"top_hits": map[string]any{
+		"size": float64(1),
+		"sort": []any{
+			map[string]any{"timestamp": map[string]any{"order": "desc"}},
+			map[string]any{"id": map[string]any{"order": "desc"}},
+		},
+	}}, sub["last_event"])
+}
+
+func (s *SessionsTestSuite) TestSessions_TotalIsAtLeastTheSessionsFound() {
+	// Arrange
+	s.stub.on(http.MethodPost, sessionsPath, http.StatusOK, `{"aggregations":{
+		"session_count":{"value":1},
+		"sessions":{"buckets":[{"key":"sess1","doc_count":1},{"key":"sess2","doc_count":1}]}
+	}}`)
+
+	// Act
+	sessions, total, err := s.repo.Sessions(context.Background(), &domain.AuditLogFilter{TenantID: "tenant1"}, 10)
+
+	// Assert
+	s.NoError(err)
+	s.Len(sessions, 2)
+	s.Equal(int64(2), total)
+	s.Nil(sessions[0].FirstEvent)
+}
+
+func (s *SessionsTestSuite) TestSessions_NoIndices() {
+	// Act
+	sessions, total, err := s.repo.Sessions(context.Background(), &domain.AuditLogFilter{TenantID: "tenant1"}, 10)
+
+	// Assert
+	s.NoError(err)
+	s.Empty(sessions)
+	s.Zero(total)
+}
+
+func (s *SessionsTestSuite) TestSessions_FailedSearch() {
+	// Arrange
+	s.stub.on(http.MethodPost, sessionsPath, http.StatusInternalServerError, `{"error":"boom"}`)
+
+	// Act
+	sessions, _, err := s.repo.Sessions(context.Background(), &domain.AuditLogFilter{TenantID: "tenant1"}, 10)
+
+	// Assert
+	s.Error(err)
+	s.Nil(sessions)
+}
@@ -0,0 +1,462 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+)
+
+// The indices of every tenant share one component template and one ISM
+// policy. Each tenant has an index template on top, which names the alias its
+// indices roll over behind.
+const (
+	componentTemplateName = "audit-logs"
+	policyID              = "audit-logs"
+	// aliasIndexPattern matches the indices behind the aliases of all tenants
+	aliasIndexPattern = "audit-logs-*"
+	// tenantTemplatePriority ranks the index templates of the tenants above
+	// the broader templates of the cluster
+	tenantTemplatePriority = 100
+)
+
+// auditLogMappings is the mapping of the audit log indices. Changes must be
+// additive, Setup puts them on the existing indices as well. The JSON fields
+// are not mapped dynamically, arbitrary payloads would run into the limit of
+// fields. Only the paths the tenants declare are mapped, by the component
+// template of each tenant.
+const auditLogMappings = `{
+	"properties": {
+		"id": { "type": "keyword" },
+		"tenant_id": { "type": "keyword" },
+		"user_id": { "type": "keyword" },
+		"session_id": { "type": "keyword" },
+		"action": { "type": "keyword" },
+		"resource_type": { "type": "keyword" },
+		"resource_id": { "type": "keyword" },
+		"message": { "type": "text" },
+		"metadata": {
+			"type": "object",
+			"dynamic": false
+		},
+		"before_state": {
+			"type": "object",
+			"dynamic": false
+		},
+		"after_state": {
+			"type": "object",
+			"dynamic": false
+		},
+		"changed_fields": { "type": "keyword" },
+		"severity": { "type": "keyword" },
+		"timestamp": { "type": "date" },
+		"chain_seq": { "type": "long" },
+		"prev_hash": { "type": "keyword", "index": false },
+		"hash": { "type": "keyword" },
+		"ip_address": { "type": "ip" },
+		"user_agent": {
+			"type": "text",
+			"fields": {
+				"keyword": { "type": "keyword", "ignore_above": 1024 }
+			}
+		}
+	}
+}`
+
+// auditLogSettings are the settings of the audit log indices. A rolled over
+// index holds weeks of logs, one shard stays within the rollover size.
+const auditLogSettings = `{
+	"index": {
+		"number_of_shards": 1,
+		"number_of_replicas": 1,
+		"refresh_interval": "1s",
+		"mapping": {
+			"total_fields": {
+				"limit": 2000
+			}
+		}
+	}
+}`
+
+// Setup installs the ISM policy and the component template, puts the mapping
+// on the existing indices and creates the index template and write alias of
+// each tenant. It is idempotent and runs when the index worker starts.
+func (r *repository) Setup(ctx context.Context, tenantIDs []string) error {
+	if err := r.putPolicy(ctx); err != nil {
+		return err
+	}
+	if err := r.putComponentTemplate(ctx); err != nil {
+		return err
+	}
+	if err := r.putMappings(ctx); err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if err := r.CreateIndex(ctx, tenantID); err != nil {
+			return fmt.Errorf("failed to set up indices of tenant %s: %w", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// CreateIndex installs the index template of a tenant and, unless its alias
+// exists, creates the first index behind the alias
+func (r *repository) CreateIndex(ctx context.Context, tenantID string) error {
+	// The paths of the tenant are kept, they are only put by PutIndexedPaths
+	if err := r.putPathsTemplate(ctx, tenantID, nil, true); err != nil {
+		return err
+	}
+	if err := r.putTenantTemplate(ctx, tenantID); err != nil {
+		return err
+	}
+
+	alias := r.config.GetAliasName(tenantID)
+	exists := opensearchapi.IndicesExistsAliasRequest{
+		Name: []string{alias},
+	}
+	res, err := exists.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to check alias existence: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		r.tenants.Store(tenantID, true)
+		return nil // Alias already exists
+	}
+
+	// Rollover numbers the following indices on from the name of the first
+	body, err := json.Marshal(map[string]any{
+		"aliases": map[string]any{
+			alias: map[string]any{"is_write_index": true},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal aliases: %w", err)
+	}
+	create := opensearchapi.IndicesCreateRequest{
+		Index: alias + "-000001",
+		Body:  strings.NewReader(string(body)),
+	}
+
+	res, err = create.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Another worker may have created the index since the check
+	if res.IsError() && !strings.Contains(res.String(), "resource_already_exists_exception") {
+		return fmt.Errorf("error creating index: %s", res.String())
+	}
+
+	r.tenants.Store(tenantID, true)
+	return nil
+}
+
+// ensureAlias creates the write alias of the tenant unless it is known to
+// exist, which saves a round trip for every log after the first
+func (r *repository) ensureAlias(ctx context.Context, tenantID string) error {
+	if _, ok := r.tenants.Load(tenantID); ok {
+		return nil
+	}
+	return r.CreateIndex(ctx, tenantID)
+}
+
+// PutIndexedPaths maps the declared paths in the indices of the tenant. The
+// component template of the tenant maps them in the indices rolled over to,
+// the indices behind the alias get them at once.
+func (r *repository) PutIndexedPaths(ctx context.Context, tenantID string, paths []domain.IndexedPath) error {
+	if err := r.putPathsTemplate(ctx, tenantID, paths, false); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(pathsMappings(paths))
+	if err != nil {
+		return fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+	allowNoIndices := true
+	req := opensearchapi.IndicesPutMappingRequest{
+		Index:          []string{r.config.GetAliasIndexPattern(tenantID)},
+		Body:           strings.NewReader(string(body)),
+		AllowNoIndices: &allowNoIndices,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to put mapping: %w", err)
+	}
+	defer res.Body.Close()
+
+	// A mapped field keeps its type
+	if res.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w: %s", domain.ErrIndexedPathConflict, res.String())
+	}
+	if res.IsError() {
+		return fmt.Errorf("error putting mapping: %s", res.String())
+	}
+	return nil
+}
+
+// pathsTemplateName returns the name of the component template mapping the
+// declared paths of a tenant
+func (r *repository) pathsTemplateName(tenantID string) string {
+	return r.config.GetAliasName(tenantID) + "-paths"
+}
+
+// putPathsTemplate installs the component template mapping the declared
+// paths of a tenant. With create it leaves an installed template alone.
+func (r *repository) putPathsTemplate(ctx context.Context, tenantID string, paths []domain.IndexedPath, create bool) error {
+	body, err := json.Marshal(map[string]any{
+		"template": map[string]any{"mappings": pathsMappings(paths)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal component template: %w", err)
+	}
+
+	req := opensearchapi.ClusterPutComponentTemplateRequest{
+		Name:   r.pathsTemplateName(tenantID),
+		Body:   strings.NewReader(string(body)),
+		Create: &create,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to put component template: %w", err)
+	}
+	defer res.Body.Close()
+
+	if create && res.StatusCode == http.StatusBadRequest && strings.Contains(res.String(), "already exists") {
+		return nil
+	}
+	if res.IsError() {
+		return fmt.Errorf("error putting component template: %s", res.String())
+	}
+	return nil
+}
+
+// pathsMappings returns the mapping of the declared paths, nested in the
+// objects of their keys. Values that don't fit the type are left unindexed
+// rather than failing the log.
+func pathsMappings(paths []domain.IndexedPath) map[string]any {
+	root := map[string]any{}
+	for _, path := range paths {
+		keys := strings.Split(path.Path, ".")
+		properties := root
+		for _, key := range keys[:len(keys)-1] {
+			object, ok := properties[key].(map[string]any)
+			if !ok || object["properties"] == nil {
+				object = map[string]any{"properties": map[string]any{}}
+				properties[key] = object
+			}
+			properties = object["properties"].(map[string]any)
+		}
+
+		mapping := map[string]any{"type": path.Type}
+		if path.Type == domain.IndexedPathKeyword {
+			mapping["ignore_above"] = 1024
+		} else {
+			mapping["ignore_malformed"] = true
+		}
+		properties[keys[len(keys)-1]] = mapping
+	}
+	return map[string]any{"properties": root}
+}
+
+// putTenantTemplate installs the index template of a tenant. It applies the
+// shared component template and the one of the declared paths of the tenant
+// to the indices behind its alias and names the alias ISM rolls them over
+// behind.
+func (r *repository) putTenantTemplate(ctx context.Context, tenantID string) error {
+	body, err := json.Marshal(map[string]any{
+		"index_patterns": []string{r.config.GetAliasIndexPattern(tenantID)},
+		"composed_of":    []string{componentTemplateName, r.pathsTemplateName(tenantID)},
+		"priority":       tenantTemplatePriority,
+		"template": map[string]any{
+			"settings": map[string]any{
+				"plugins.index_state_management.rollover_alias": r.config.GetAliasName(tenantID),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index template: %w", err)
+	}
+
+	req := opensearchapi.IndicesPutIndexTemplateRequest{
+		Name: r.config.GetAliasName(tenantID),
+		Body: strings.NewReader(string(body)),
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to put index template: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("error putting index template: %s", res.String())
+	}
+	return nil
+}
+
+func (r *repository) putComponentTemplate(ctx context.Context) error {
+	body := fmt.Sprintf(`{"template": {"settings": %s, "mappings": %s}}`, auditLogSettings, auditLogMappings)
+
+	req := opensearchapi.ClusterPutComponentTemplateRequest{
+		Name: componentTemplateName,
+		Body: strings.NewReader(body),
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to put component template: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("error putting component template: %s", res.String())
+	}
+	return nil
+}
+
+// putMappings puts the mapping on the existing indices, templates only apply
+// to the indices created after them
+func (r *repository) putMappings(ctx context.Context) error {
+	allowNoIndices := true
+	req := opensearchapi.IndicesPutMappingRequest{
+		Index:          []string{aliasIndexPattern},
+		Body:           strings.NewReader(auditLogMappings),
+		AllowNoIndices: &allowNoIndices,
+	}
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to put mapping: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("error putting mapping: %s", res.String())
+	}
+	return nil
+}
+
+// policy returns the ISM policy of the audit log indices. The write index
+// rolls over by size or age and is deleted once it is old enough. New indices
+// behind the aliases are attached to the policy when they are created.
+func (r *repository) policy() map[string]any {
+	return map[string]any{
+		"description":   r.policyDescription(),
+		"default_state": "hot",
+		"states": []map[string]any{
+			{
+				"name": "hot",
+				"actions": []map[string]any{
+					{"rollover": map[string]any{
+						"min_size":      r.config.RolloverMaxSize,
+						"min_index_age": r.config.RolloverMaxAge,
+					}},
+				},
+				"transitions": []map[string]any{
+					{
+						"state_name": "delete",
+						"conditions": map[string]any{"min_rollover_age": r.config.DeleteAfter},
+					},
+				},
+			},
+			{
+				"name":        "delete",
+				"actions":     []map[string]any{{"delete": map[string]any{}}},
+				"transitions": []map[string]any{},
+			},
+		},
+		"ism_template": []map[string]any{
+			{"index_patterns": []string{aliasIndexPattern}, "priority": tenantTemplatePriority},
+		},
+	}
+}
+
+// policyDescription describes the bounds of the policy, an installed policy
+// with the same description needs no update
+func (r *repository) policyDescription() string {
+	return fmt.Sprintf("Audit log indices: roll over at %s or %s, delete %s after rollover",
+		r.config.RolloverMaxSize, r.config.RolloverMaxAge, r.config.DeleteAfter)
+}
+
+// putPolicy installs the ISM policy, or updates it when its bounds changed.
+// Indices already managed keep the version of the policy they started with.
+func (r *repository) putPolicy(ctx context.Context) error {
+	path := "/_plugins/_ism/policies/" + policyID
+
+	res, err := r.perform(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get ISM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	var installed struct {
+		SeqNo       int64 `json:"_seq_no"`
+		PrimaryTerm int64 `json:"_primary_term"`
+		Policy      struct {
+			Description string `json:"description"`
+		} `json:"policy"`
+	}
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		// Not installed yet
+	case res.IsError():
+		return fmt.Errorf("error getting ISM policy: %s", res.String())
+	default:
+		if err := json.NewDecoder(res.Body).Decode(&installed); err != nil {
+			return fmt.Errorf("failed to decode ISM policy: %w", err)
+		}
+		if installed.Policy.Description == r.policyDescription() {
+			return nil
+		}
+		// An update names the version it replaces
+		path = fmt.Sprintf("%s?if_seq_no=%d&if_primary_term=%d", path, installed.SeqNo, installed.PrimaryTerm)
+	}
+
+	body, err := json.Marshal(map[string]any{"policy": r.policy()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ISM policy: %w", err)
+	}
+
+	res, err = r.perform(ctx, http.MethodPut, path, strings.NewReader(string(body)))
+	if err != nil {
+		return fmt.Errorf("failed to put ISM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Another worker may have installed the policy since it was read
+	if res.StatusCode == http.StatusConflict {
+		return nil
+	}
+	if res.IsError() {
+		return fmt.Errorf("error putting ISM policy: %s", res.String())
+	}
+	return nil
+}
+
+// perform sends a request to an API the client has no request type for, such
+// as the APIs of the plugins
+func (r *repository) perform(ctx context.Context, method, path string, body io.Reader) (*opensearchapi.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := r.client.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+	return &opensearchapi.Response{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Header:     res.Header,
+	}, nil
+}
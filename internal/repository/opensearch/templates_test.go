@@ -0,0 +1,257 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type TemplatesTestSuite struct {
+	suite.Suite
+	stub *openSearchStub
+	repo *repository
+}
+
+func (s *TemplatesTestSuite) SetupTest() {
+	s.stub = newOpenSearchStub()
+	repo, err := s.stub.repository()
+	s.Require().NoError(err)
+	repo.config = &config.OpenSearchConfig{RolloverMaxSize: "30gb", RolloverMaxAge: "30d", DeleteAfter: "3650d"}
+	s.repo = repo
+}
+
+func (s *TemplatesTestSuite) TearDownTest() {
+	s.stub.server.Close()
+}
+
+func TestTemplates(t *testing.T) {
+	suite.Run(t, new(TemplatesTestSuite))
+}
+
+// calls returns the method and path of each request received
+func (s *TemplatesTestSuite) calls() []string {
+	var calls []string
+	for _, req := range s.stub.received() {
+		calls = append(calls, req.Method+" "+req.Path)
+	}
+	return calls
+}
+
+// body decodes the body of the first request to the path
+func (s *TemplatesTestSuite) body(method, path string) map[string]any {
+	for _, req := range s.stub.received() {
+		if req.Method == method && req.Path == path {
+			var body map[string]any
+			s.Require().NoError(json.Unmarshal([]byte(req.Body), &body))
+			return body
+		}
+	}
+	s.FailNow("request not received", "%s %s", method, path)
+	return nil
+}
+
+// request returns the first request to the path
+func (s *TemplatesTestSuite) request(method, path string) stubRequest {
+	for _, req := range s.stub.received() {
+		if req.Method == method && req.Path == path {
+			return req
+		}
+	}
+	s.FailNow("request not received", "%s %s", method, path)
+	return stubRequest{}
+}
+
+func (s *TemplatesTestSuite) onTenantTemplates(tenantID string) {
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs-"+tenantID+"-paths", http.StatusOK, `{"acknowledged":true}`)
+	s.stub.on(http.MethodPut, "/_index_template/audit-logs-"+tenantID, http.StatusOK, `{"acknowledged":true}`)
+}
+
+func (s *TemplatesTestSuite) TestSetup_InstallsPolicyTemplatesAndWriteAlias() {
+	// Arrange
+	s.stub.on(http.MethodPut, "/_plugins/_ism/policies/audit-logs", http.StatusCreated, `{}`)
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs", http.StatusOK, `{"acknowledged":true}`)
+	s.stub.on(http.MethodPut, "/audit-logs-*/_mapping", http.StatusOK, `{"acknowledged":true}`)
+	s.onTenantTemplates("tenant1")
+	s.stub.on(http.MethodPut, "/audit-logs-tenant1-000001", http.StatusOK, `{"acknowledged":true}`)
+
+	// Act
+	err := s.repo.Setup(context.Background(), []string{"tenant1"})
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]string{
+		"GET /_plugins/_ism/policies/audit-logs",
+		"PUT /_plugins/_ism/policies/audit-logs",
+		"PUT /_component_template/audit-logs",
+		"PUT /audit-logs-*/_mapping",
+		"PUT /_component_template/audit-logs-tenant1-paths",
+		"PUT /_index_template/audit-logs-tenant1",
+		"HEAD /_alias/audit-logs-tenant1",
+		"PUT /audit-logs-tenant1-000001",
+	}, s.calls())
+
+	policy := s.body(http.MethodPut, "/_plugins/_ism/policies/audit-logs")["policy"].(map[string]any)
+	s.Equal("hot", policy["default_state"])
+	states := policy["states"].([]any)
+	s.Require().Len(states, 2)
+	hot := states[0].(map[string]any)
+	s.Equal([]any{map[string]any{"rollover": map[string]any{"min_size": "30gb", "min_index_age": "30d"}}}, hot["actions"])
+	s.Equal([]any{map[string]any{
+		"state_name": "delete",
+		"conditions": map[string]any{"min_rollover_age": "3650d"},
+	}}, hot["transitions"])
+	s.Equal("delete", states[1].(map[string]any)["name"])
+	s.Equal([]any{map[string]any{"index_patterns": []any{"audit-logs-*"}, "priority": float64(100)}}, policy["ism_template"])
+
+	component := s.body(http.MethodPut, "/_component_template/audit-logs")["template"].(map[string]any)
+	settings := component["settings"].(map[string]any)["index"].(map[string]any)
+	s.Equal(float64(1), settings["number_of_shards"])
+	properties := component["mappings"].(map[string]any)["properties"].(map[string]any)
+	s.Equal(map[string]any{"type": "keyword"}, properties["tenant_id"])
+	s.Equal(map[string]any{"type": "object", "dynamic": false}, properties["metadata"])
+
+	paths := s.request(http.MethodPut, "/_component_template/audit-logs-tenant1-paths")
+	s.Contains(paths.Query, "create=true")
+
+	s.Equal(map[string]any{
+		"index_patterns": []any{"audit-logs-tenant1-*"},
+		"composed_of":    []any{"audit-logs", "audit-logs-tenant1-paths"},
+		"priority":       float64(100),
+		"template": map[string]any{
+			"settings": map[string]any{"plugins.index_state_management.rollover_alias": "audit-logs-tenant1"},
+		},
+	}, s.body(http.MethodPut, "/_index_template/audit-logs-tenant1"))
+
+	s.Equal(map[string]any{
+		"aliases": map[string]any{"audit-logs-tenant1": map[string]any{"is_write_index": true}},
+	}, s.body(http.MethodPut, "/audit-logs-tenant1-000001"))
+
+	_, known := s.repo.tenants.Load("tenant1")
+	s.True(known)
+}
+
+func (s *TemplatesTestSuite) TestSetup_KeepsUnchangedPolicy() {
+	// Arrange
+	installed, err := json.Marshal(map[string]any{
+		"_seq_no": 3, "_primary_term": 1,
+		"policy": map[string]any{"description": s.repo.policyDescription()},
+	})
+	s.Require().NoError(err)
+	s.stub.on(http.MethodGet, "/_plugins/_ism/policies/audit-logs", http.StatusOK, string(installed))
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/audit-logs-*/_mapping", http.StatusOK, `{}`)
+
+	// Act
+	err = s.repo.Setup(context.Background(), nil)
+
+	// Assert
+	s.NoError(err)
+	s.NotContains(s.calls(), "PUT /_plugins/_ism/policies/audit-logs")
+}
+
+func (s *TemplatesTestSuite) TestSetup_UpdatesChangedPolicy() {
+	// Arrange
+	s.stub.on(http.MethodGet, "/_plugins/_ism/policies/audit-logs", http.StatusOK,
+		`{"_seq_no":7,"_primary_term":2,"policy":{"description":"Audit log indices: roll over at 50gb or 30d, delete 3650d after rollover"}}`)
+	s.stub.on(http.MethodPut, "/_plugins/_ism/policies/audit-logs", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/audit-logs-*/_mapping", http.StatusOK, `{}`)
+
+	// Act
+	err := s.repo.Setup(context.Background(), nil)
+
+	// Assert
+	s.NoError(err)
+	update := s.request(http.MethodPut, "/_plugins/_ism/policies/audit-logs")
+	s.Equal("if_seq_no=7&if_primary_term=2", update.Query)
+}
+
+func (s *TemplatesTestSuite) TestCreateIndex_KeepsExistingAlias() {
+	// Arrange
+	s.onTenantTemplates("tenant1")
+	s.stub.on(http.MethodHead, "/_alias/audit-logs-tenant1", http.StatusOK, ``)
+
+	// Act
+	err := s.repo.CreateIndex(context.Background(), "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.NotContains(s.calls(), "PUT /audit-logs-tenant1-000001")
+	_, known := s.repo.tenants.Load("tenant1")
+	s.True(known)
+}
+
+func (s *TemplatesTestSuite) TestCreateIndex_AliasCreatedMeanwhile() {
+	// Arrange
+	s.onTenantTemplates("tenant1")
+	s.stub.on(http.MethodPut, "/audit-logs-tenant1-000001", http.StatusBadRequest,
+		`{"error":{"type":"resource_already_exists_exception"},"status":400}`)
+
+	// Act
+	err := s.repo.CreateIndex(context.Background(), "tenant1")
+
+	// Assert
+	s.NoError(err)
+}
+
+func (s *TemplatesTestSuite) TestCreateIndex_KeepsInstalledPathsTemplate() {
+	// Arrange
+	s.onTenantTemplates("tenant1")
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs-tenant1-paths", http.StatusBadRequest,
+		`{"error":{"type":"illegal_argument_exception","reason":"component template [audit-logs-tenant1-paths] already exists"},"status":400}`)
+	s.stub.on(http.MethodHead, "/_alias/audit-logs-tenant1", http.StatusOK, ``)
+
+	// Act
+	err := s.repo.CreateIndex(context.Background(), "tenant1")
+
+	// Assert
+	s.NoError(err)
+}
+
+func (s *TemplatesTestSuite) TestPutIndexedPaths_MapsPathsInTemplateAndIndices() {
+	// Arrange
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs-tenant1-paths", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/audit-logs-tenant1-*/_mapping", http.StatusOK, `{}`)
+	paths := []domain.IndexedPath{
+		{Path: "metadata.order.id", Type: domain.IndexedPathKeyword},
+		{Path: "metadata.order.total", Type: domain.IndexedPathDouble},
+	}
+	want := map[string]any{"properties": map[string]any{
+		"metadata": map[string]any{"properties": map[string]any{
+			"order": map[string]any{"properties": map[string]any{
+				"id":    map[string]any{"type": "keyword", "ignore_above": float64(1024)},
+				"total": map[string]any{"type": "double", "ignore_malformed": true},
+			}},
+		}},
+	}}
+
+	// Act
+	err := s.repo.PutIndexedPaths(context.Background(), "tenant1", paths)
+
+	// Assert
+	s.NoError(err)
+	template := s.request(http.MethodPut, "/_component_template/audit-logs-tenant1-paths")
+	s.Contains(template.Query, "create=false")
+	s.Equal(map[string]any{"template": map[string]any{"mappings": want}},
+		s.body(http.MethodPut, "/_component_template/audit-logs-tenant1-paths"))
+	s.Equal(want, s.body(http.MethodPut, "/audit-logs-tenant1-*/_mapping"))
+}
+
+func (s *TemplatesTestSuite) TestPutIndexedPaths_Conflict() {
+	// Arrange
+	s.stub.on(http.MethodPut, "/_component_template/audit-logs-tenant1-paths", http.StatusOK, `{}`)
+	s.stub.on(http.MethodPut, "/audit-logs-tenant1-*/_mapping", http.StatusBadRequest,
+		`{"error":{"type":"illegal_argument_exception","reason":"mapper [metadata.order.id] cannot be changed from type [keyword] to [long]"},"status":400}`)
+
+	// Act
+	err := s.repo.PutIndexedPaths(context.Background(), "tenant1", []domain.IndexedPath{{Path: "metadata.order.id", Type: domain.IndexedPathLong}})
+
+	// Assert
+	s.ErrorIs(err, domain.ErrIndexedPathConflict)
+}
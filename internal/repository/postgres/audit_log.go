@@ -2,11 +2,15 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
 	"github.com/buiminhduc234/audit-log-api/internal/utils"
@@ -30,7 +34,16 @@ func (r *AuditLogRepository) Create(ctx context.Context, log *domain.AuditLog) e
 	}
 
 	// Use writer database for create operations
-	return r.writerDB.WithContext(ctx).Create(log).Error
+	return r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := advanceChainHead(tx, []domain.AuditLog{*log}); err != nil {
+			return err
+		}
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+		// The outbox entry commits with the log, the relay publishes it for indexing
+		return tx.Create(domain.NewOutboxEntries([]domain.AuditLog{*log})).Error
+	})
 }
 
 func (r *AuditLogRepository) GetByID(ctx context.Context, id string) (*domain.AuditLog, error) {
@@ -52,12 +65,66 @@ func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFil
 	var logs []domain.AuditLog
 
 	// Use reader database for read operations
-	db := r.readerDB.WithContext(ctx)
+	db, err := applyFilter(r.readerDB.WithContext(ctx), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Continue after the cursor, the keyset replaces the offset
+	if filter.After != nil {
+		db = db.Where("(timestamp, id) < (?::timestamptz, ?::uuid)", filter.After.Timestamp, filter.After.ID)
+	} else if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	// Apply pagination
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+
+	// Apply sorting, the ID breaks ties so that keyset pages are stable
+	db = db.Order("timestamp DESC").Order("id DESC")
+
+	if err := db.Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// EstimateCount returns the planner's estimate of the number of logs matching
+// the filter, which is far cheaper than an exact count on large ranges
+func (r *AuditLogRepository) EstimateCount(ctx context.Context, filter domain.AuditLogFilter) (int64, error) {
+	// Use reader database for read operations
+	db, err := applyFilter(r.readerDB.WithContext(ctx).Model(&domain.AuditLog{}), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var plan string
+	if err := r.readerDB.WithContext(ctx).Raw("EXPLAIN (FORMAT JSON) ?", db.Select("1")).Scan(&plan).Error; err != nil {
+		return 0, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	var explain []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil || len(explain) == 0 {
+		return 0, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+
+	return int64(explain[0].Plan.PlanRows), nil
+}
+
+// applyFilter adds the tenant scope, the equality and time range filters, the
+// conditions of the query and the JSON path filters and the changed field
+func applyFilter(db *gorm.DB, filter domain.AuditLogFilter) (*gorm.DB, error) {
 	if filter.TenantID == "" {
 		return nil, fmt.Errorf("tenant_id is required")
-	} else {
-		db = db.Where("tenant_id = ?", filter.TenantID)
 	}
+	db = db.Where("tenant_id = ?", filter.TenantID)
 
 	// Apply additional filters
 	if filter.UserID != "" {
@@ -75,45 +142,253 @@ func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditLogFil
 	if filter.Severity != "" {
 		db = db.Where("severity = ?", filter.Severity)
 	}
+	if filter.SessionID != "" {
+		db = db.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.IPAddress != "" {
+		db = db.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.UserAgent != "" {
+		db = db.Where("user_agent ILIKE ?", "%"+filter.UserAgent+"%")
+	}
+	if filter.Message != "" {
+		db = db.Where("message ILIKE ?", "%"+filter.Message+"%")
+	}
 	if !filter.StartTime.IsZero() {
 		db = db.Where("timestamp >= ?", filter.StartTime)
 	}
 	if !filter.EndTime.IsZero() {
 		db = db.Where("timestamp <= ?", filter.EndTime)
 	}
+	if filter.Query != nil {
+		cond, args := queryCondition(filter.Query.Root)
+		db = db.Where(cond, args...)
+	}
+	for i := range filter.JSONFilters {
+		cond, args := jsonFilterCondition(&filter.JSONFilters[i])
+		db = db.Where(cond, args...)
+	}
+	if filter.ChangedField != "" {
+		// Containment is answered by the GIN index of the changed fields
+		field, _ := json.Marshal([]string{filter.ChangedField})
+		db = db.Where("changed_fields @> ?::jsonb", string(field))
+	}
 
-	// Apply pagination
-	if filter.Limit > 0 {
-		db = db.Limit(filter.Limit)
+	return db, nil
+}
+
+// ListChunks returns the chunks of the audit_logs hypertable that only hold
+// logs from before the date, oldest first
+func (r *AuditLogRepository) ListChunks(ctx context.Context, before time.Time) ([]domain.LogChunk, error) {
+	var chunks []domain.LogChunk
+	err := r.writerDB.WithContext(ctx).
+		Table("timescaledb_information.chunks").
+		Select("chunk_schema, chunk_name, range_start, range_end, is_compressed").
+		Where("hypertable_name = ? AND range_end <= ?", domain.AuditLog{}.TableName(), before).
+		Order("range_start").
+		Find(&chunks).Error
+	if err != nil {
+		return nil, err
 	}
-	if filter.Offset > 0 {
-		db = db.Offset(filter.Offset)
+	return chunks, nil
+}
+
+// CountByTenant returns the number of logs of each tenant between startTime,
+// inclusive, and endTime, exclusive
+func (r *AuditLogRepository) CountByTenant(ctx context.Context, startTime, endTime time.Time) (map[string]int64, error) {
+	var rows []struct {
+		TenantID string
+		Count    int64
+	}
+	err := r.writerDB.WithContext(ctx).
+		Model(&domain.AuditLog{}).
+		Select("tenant_id, COUNT(*) AS count").
+		Where("timestamp >= ? AND timestamp < ?", startTime, endTime).
+		Group("tenant_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
 	}
 
-	// Apply sorting
-	db = db.Order("timestamp DESC")
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TenantID] = row.Count
+	}
+	return counts, nil
+}
 
-	if err := db.Find(&logs).Error; err != nil {
+// CountByDay returns the number of logs of the tenant on each UTC day between
+// startTime, inclusive, and endTime, exclusive, keyed by domain.DayLayout
+func (r *AuditLogRepository) CountByDay(ctx context.Context, tenantID string, startTime, endTime time.Time) (map[string]int64, error) {
+	var rows []struct {
+		Day   time.Time
+		Count int64
+	}
+	err := r.readerDB.WithContext(ctx).
+		Model(&domain.AuditLog{}).
+		Select("time_bucket('1 day', timestamp) AS day, COUNT(*) AS count").
+		Where("tenant_id = ? AND timestamp >= ? AND timestamp < ?", tenantID, startTime, endTime).
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return logs, nil
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day.UTC().Format(domain.DayLayout)] = row.Count
+	}
+	return counts, nil
+}
+
+// CountExcluded returns the number of logs of the tenant between startTime,
+// inclusive, and endTime, exclusive, that a cleanup keeps
+func (r *AuditLogRepository) CountExcluded(ctx context.Context, tenantID string, startTime, endTime time.Time, exclusions domain.CleanupExclusions) (int64, error) {
+	var conds []string
+	var args []any
+	if len(exclusions.KeepSeverities) > 0 {
+		conds = append(conds, "severity IN ?")
+		args = append(args, exclusions.KeepSeverities)
+	}
+	if len(exclusions.Holds) > 0 {
+		cond, holdArgs := heldCondition(exclusions.Holds)
+		conds = append(conds, cond)
+		args = append(args, holdArgs...)
+	}
+	if len(conds) == 0 {
+		return 0, nil
+	}
+
+	var count int64
+	err := r.tenantRange(r.writerDB.WithContext(ctx), tenantID, startTime, endTime).
+		Where("("+strings.Join(conds, " OR ")+")", args...).
+		Count(&count).Error
+	return count, err
 }
 
-func (r *AuditLogRepository) DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time) (int64, error) {
+// DeleteBatch deletes up to limit logs of the tenant between startTime,
+// inclusive, and endTime, exclusive, except for the excluded ones. A zero
+// startTime leaves the range open. Each batch is a short transaction, so a
+// cleanup can be interrupted and resumed without holding long locks.
+func (r *AuditLogRepository) DeleteBatch(ctx context.Context, tenantID string, startTime, endTime time.Time, exclusions domain.CleanupExclusions, limit int) (int64, error) {
 	// Use writer database for delete operations
 	db := r.writerDB.WithContext(ctx)
 
-	result := db.Where("tenant_id = ? AND timestamp < ?", tenantID, beforeDate).
-		Delete(&domain.AuditLog{})
+	batch := r.tenantRange(db, tenantID, startTime, endTime).Select("id, timestamp")
+	if len(exclusions.KeepSeverities) > 0 {
+		batch = batch.Where("severity NOT IN ?", exclusions.KeepSeverities)
+	}
+	if len(exclusions.Holds) > 0 {
+		cond, args := heldCondition(exclusions.Holds)
+		batch = batch.Where("NOT "+cond, args...)
+	}
 
+	result := db.Where("(id, timestamp) IN (?)", batch.Limit(limit)).Delete(&domain.AuditLog{})
 	if result.Error != nil {
 		return 0, result.Error
 	}
-
 	return result.RowsAffected, nil
 }
 
+// DropChunk drops the chunk as a whole, provided it still holds the expected
+// number of logs. The chunk is locked against writes while it is counted so
+// that logs written in the meantime are never dropped unseen.
+func (r *AuditLogRepository) DropChunk(ctx context.Context, chunk domain.LogChunk, expectedLogs int64) (bool, error) {
+	dropped := false
+	err := r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		table := clause.Table{Name: chunk.Schema + "." + chunk.Name}
+		if err := tx.Exec("LOCK TABLE ? IN EXCLUSIVE MODE", table).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		err := tx.Model(&domain.AuditLog{}).
+			Where("timestamp >= ? AND timestamp < ?", chunk.RangeStart, chunk.RangeEnd).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count != expectedLogs {
+			return nil
+		}
+
+		err = tx.Exec("SELECT drop_chunks(?, older_than => ?::timestamptz, newer_than => ?::timestamptz)",
+			domain.AuditLog{}.TableName(), chunk.RangeEnd, chunk.RangeStart).Error
+		if err != nil {
+			return err
+		}
+		dropped = true
+		return nil
+	})
+	return dropped, err
+}
+
+// RefreshStats recomputes the hourly stats between startTime and endTime,
+// widened to whole hours, where logs changed since they were last
+// materialized, so deleted logs no longer count. The refresh policy only
+// covers the last month.
+func (r *AuditLogRepository) RefreshStats(ctx context.Context, startTime, endTime time.Time) error {
+	return r.writerDB.WithContext(ctx).
+		Exec("CALL refresh_continuous_aggregate('audit_logs_hourly_stats', ?::timestamptz, ?::timestamptz)",
+			startTime.Truncate(time.Hour), endTime.Truncate(time.Hour).Add(time.Hour)).Error
+}
+
+// tenantRange scopes the query to the logs of the tenant between startTime,
+// inclusive, and endTime, exclusive. A zero startTime leaves the range open.
+func (r *AuditLogRepository) tenantRange(db *gorm.DB, tenantID string, startTime, endTime time.Time) *gorm.DB {
+	db = db.Model(&domain.AuditLog{}).Where("tenant_id = ? AND timestamp < ?", tenantID, endTime)
+	if !startTime.IsZero() {
+		db = db.Where("timestamp >= ?", startTime)
+	}
+	return db
+}
+
+// heldCondition returns a condition matching the logs held by any of the holds
+func heldCondition(holds []domain.LegalHold) (string, []any) {
+	var (
+		clauses []string
+		args    []any
+	)
+	for _, hold := range holds {
+		if len(hold.LogIDs) > 0 {
+			clauses = append(clauses, "id IN ?")
+			args = append(args, hold.LogIDs)
+			continue
+		}
+		if !hold.HasFilter() {
+			continue
+		}
+
+		var conds []string
+		if hold.UserID != "" {
+			conds = append(conds, "user_id = ?")
+			args = append(args, hold.UserID)
+		}
+		if hold.ResourceType != "" {
+			conds = append(conds, "resource_type = ?")
+			args = append(args, hold.ResourceType)
+		}
+		if hold.ResourceID != "" {
+			conds = append(conds, "resource_id = ?")
+			args = append(args, hold.ResourceID)
+		}
+		if hold.StartTime != nil {
+			conds = append(conds, "timestamp >= ?")
+			args = append(args, *hold.StartTime)
+		}
+		if hold.EndTime != nil {
+			conds = append(conds, "timestamp <= ?")
+			args = append(args, *hold.EndTime)
+		}
+		clauses = append(clauses, "("+strings.Join(conds, " AND ")+")")
+	}
+	if len(clauses) == 0 {
+		return "FALSE", nil
+	}
+
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
 func (r *AuditLogRepository) BulkCreate(ctx context.Context, logs []domain.AuditLog) error {
 	tenantID, err := utils.GetTenantIDFromContext(ctx)
 	if err != nil {
@@ -128,8 +403,37 @@ func (r *AuditLogRepository) BulkCreate(ctx context.Context, logs []domain.Audit
 		logs[i].TenantID = tenantID
 	}
 
+	// Restored logs already have their place in the chain and may have been
+	// restored before, so they neither move the head nor fail on duplicates.
+	// The restore indexes them itself, they skip the outbox.
+	if utils.IsRestore(ctx) {
+		return r.writerDB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(logs, 100).Error
+	}
+
 	// Use writer database for create operations
-	return r.writerDB.WithContext(ctx).CreateInBatches(logs, 100).Error
+	return r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return appendLogs(tx, logs)
+	})
+}
+
+// ExistingIDs returns which of the given log IDs are already stored. The time
+// range must cover the logs, it limits the chunks that are searched.
+func (r *AuditLogRepository) ExistingIDs(ctx context.Context, tenantID string, ids []string, startTime, endTime time.Time) ([]string, error) {
+	var existing []string
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	// Use writer database, restores check for rows they have just written
+	if err := r.writerDB.WithContext(ctx).
+		Model(&domain.AuditLog{}).
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Where("timestamp >= ? AND timestamp <= ?", startTime, endTime).
+		Pluck("id", &existing).Error; err != nil {
+		return nil, err
+	}
+
+	return existing, nil
 }
 
 func (r *AuditLogRepository) GetStats(ctx context.Context, filter domain.AuditLogFilter) (*domain.AuditLogStats, error) {
@@ -275,3 +579,120 @@ func (r *AuditLogRepository) GetRecentLogs(ctx context.Context, tenantID string,
 
 	return logs, nil
 }
+
+func (r *AuditLogRepository) GetChainHead(ctx context.Context, tenantID string) (*domain.ChainHead, error) {
+	head := domain.ChainHead{TenantID: tenantID}
+
+	// Use writer database, a lagging replica would only cause chain conflicts
+	err := r.writerDB.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Limit(1).
+		Find(&head).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain head: %w", err)
+	}
+
+	return &head, nil
+}
+
+func (r *AuditLogRepository) GetChainBounds(ctx context.Context, tenantID string, startTime, endTime time.Time) (int64, int64, error) {
+	var bounds struct {
+		MinSeq sql.NullInt64
+		MaxSeq sql.NullInt64
+	}
+
+	// Use reader database for read operations
+	err := r.readerDB.WithContext(ctx).Raw(`
+		SELECT MIN(chain_seq) AS min_seq, MAX(chain_seq) AS max_seq
+		FROM audit_logs
+		WHERE tenant_id = ? AND timestamp >= ? AND timestamp <= ? AND chain_seq IS NOT NULL`,
+		tenantID, startTime, endTime).
+		Scan(&bounds).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get chain bounds: %w", err)
+	}
+
+	return bounds.MinSeq.Int64, bounds.MaxSeq.Int64, nil
+}
+
+// GetTimeBounds returns the timestamps of the oldest and newest logs of the
+// tenant, zero when it has none
+func (r *AuditLogRepository) GetTimeBounds(ctx context.Context, tenantID string) (time.Time, time.Time, error) {
+	var bounds struct {
+		MinTime sql.NullTime
+		MaxTime sql.NullTime
+	}
+
+	// Use writer database, the bounds are taken right before the logs are deleted
+	err := r.writerDB.WithContext(ctx).Raw(`
+		SELECT MIN(timestamp) AS min_time, MAX(timestamp) AS max_time
+		FROM audit_logs
+		WHERE tenant_id = ?`,
+		tenantID).
+		Scan(&bounds).Error
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to get time bounds: %w", err)
+	}
+
+	return bounds.MinTime.Time, bounds.MaxTime.Time, nil
+}
+
+func (r *AuditLogRepository) ListChain(ctx context.Context, tenantID string, fromSeq, toSeq int64, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+
+	// Use reader database for read operations
+	err := r.readerDB.WithContext(ctx).
+		Where("tenant_id = ? AND chain_seq >= ? AND chain_seq <= ?", tenantID, fromSeq, toSeq).
+		Order("chain_seq ASC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chain: %w", err)
+	}
+
+	return logs, nil
+}
+
+// appendLogs stores sealed logs in the transaction, advancing the chain head
+// of their tenant, together with their outbox entries
+func appendLogs(tx *gorm.DB, logs []domain.AuditLog) error {
+	if err := advanceChainHead(tx, logs); err != nil {
+		return err
+	}
+	if err := tx.CreateInBatches(logs, 100).Error; err != nil {
+		return err
+	}
+	return tx.CreateInBatches(domain.NewOutboxEntries(logs), 100).Error
+}
+
+// advanceChainHead moves the tenant's chain head to the last of the sealed
+// logs. It fails with domain.ErrChainConflict when the logs were not sealed
+// on top of the current head. Logs without a chain position are ignored.
+func advanceChainHead(tx *gorm.DB, logs []domain.AuditLog) error {
+	if len(logs) == 0 || logs[0].ChainSeq == 0 {
+		return nil
+	}
+	first, last := logs[0], logs[len(logs)-1]
+
+	if err := tx.Exec(`
+		INSERT INTO audit_log_chain_heads (tenant_id) VALUES (?)
+		ON CONFLICT (tenant_id) DO NOTHING`, first.TenantID).Error; err != nil {
+		return fmt.Errorf("failed to initialize chain head: %w", err)
+	}
+
+	var head domain.ChainHead
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&head, "tenant_id = ?", first.TenantID).Error; err != nil {
+		return fmt.Errorf("failed to lock chain head: %w", err)
+	}
+
+	if head.LastSeq != first.ChainSeq-1 || head.LastHash != first.PrevHash {
+		return domain.ErrChainConflict
+	}
+
+	return tx.Model(&head).Updates(map[string]any{
+		"last_seq":   last.ChainSeq,
+		"last_hash":  last.Hash,
+		"updated_at": time.Now(),
+	}).Error
+}
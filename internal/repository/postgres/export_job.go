@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type ExportJobRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewExportJobRepository(writerDB, readerDB *gorm.DB) *ExportJobRepository {
+	return &ExportJobRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *ExportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID returns the job of the tenant. It reads from the writer database,
+// clients poll the job right after creating it and replicas may lag behind.
+func (r *ExportJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.ExportJob, error) {
+	var job domain.ExportJob
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportJobRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
+
+// UpdateRun saves the job if the run started at startedAt still owns it, nil
+// for a job no run has started. It reports whether the job was saved.
+func (r *ExportJobRepository) UpdateRun(ctx context.Context, job *domain.ExportJob, startedAt *time.Time) (bool, error) {
+	db := r.writerDB.WithContext(ctx).Model(job).Select("*").Omit("id", "created_at")
+	if startedAt == nil {
+		db = db.Where("started_at IS NULL")
+	} else {
+		db = db.Where("started_at = ?", *startedAt)
+	}
+	result := db.Updates(job)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ListExpired returns up to limit jobs that expired before the given time
+func (r *ExportJobRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.ExportJob, error) {
+	var jobs []domain.ExportJob
+	if err := r.writerDB.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *ExportJobRepository) Delete(ctx context.Context, id string) error {
+	return r.writerDB.WithContext(ctx).Delete(&domain.ExportJob{}, "id = ?", id).Error
+}
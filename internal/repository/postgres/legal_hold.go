@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type LegalHoldRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewLegalHoldRepository(writerDB, readerDB *gorm.DB) *LegalHoldRepository {
+	return &LegalHoldRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+// Create stores the hold together with the sealed audit log recording it
+func (r *LegalHoldRepository) Create(ctx context.Context, hold *domain.LegalHold, log *domain.AuditLog) error {
+	return r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(hold).Error; err != nil {
+			return err
+		}
+		return appendLogs(tx, []domain.AuditLog{*log})
+	})
+}
+
+func (r *LegalHoldRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.LegalHold, error) {
+	var hold domain.LegalHold
+	if err := r.writerDB.WithContext(ctx).First(&hold, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// List returns the holds of the tenant, newest first. Released holds are
+// only included when asked for.
+func (r *LegalHoldRepository) List(ctx context.Context, tenantID string, includeReleased bool) ([]domain.LegalHold, error) {
+	db := r.readerDB.WithContext(ctx).Where("tenant_id = ?", tenantID)
+	if !includeReleased {
+		db = db.Where("released_at IS NULL")
+	}
+
+	var holds []domain.LegalHold
+	if err := db.Order("created_at DESC").Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// ListActive returns the holds of the tenant that are not released. It reads
+// from the writer database so that a cleanup never misses a hold just placed.
+func (r *LegalHoldRepository) ListActive(ctx context.Context, tenantID string) ([]domain.LegalHold, error) {
+	var holds []domain.LegalHold
+	if err := r.writerDB.WithContext(ctx).Where("tenant_id = ? AND released_at IS NULL", tenantID).Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// Update saves the hold together with the sealed audit log recording the
+// change
+func (r *LegalHoldRepository) Update(ctx context.Context, hold *domain.LegalHold, log *domain.AuditLog) error {
+	return r.writerDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(hold).Error; err != nil {
+			return err
+		}
+		return appendLogs(tx, []domain.AuditLog{*log})
+	})
+}
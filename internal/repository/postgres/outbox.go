@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type OutboxRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewOutboxRepository(writerDB, readerDB *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+// Claim leases up to limit due entries, oldest first. Claimed entries are not
+// due again until the lease expires, so relays running side by side never
+// publish the same entry twice and entries of a crashed relay are retried.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEntry, error) {
+	var entries []domain.OutboxEntry
+	err := r.writerDB.WithContext(ctx).Raw(`
+		UPDATE audit_log_outbox
+		SET attempts = attempts + 1, next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM audit_log_outbox
+			WHERE next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, time.Now().Add(lease), time.Now(), limit).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Logs returns the stored logs of the entries. Logs that no longer exist are
+// left out.
+func (r *OutboxRepository) Logs(ctx context.Context, entries []domain.OutboxEntry) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	if len(entries) == 0 {
+		return logs, nil
+	}
+
+	keys := make([][]any, len(entries))
+	for i, entry := range entries {
+		keys[i] = []any{entry.LogID, entry.LogTimestamp}
+	}
+
+	// Use writer database, the entries may have just been committed
+	if err := r.writerDB.WithContext(ctx).
+		Where("(id, timestamp) IN ?", keys).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// Delete removes published entries
+func (r *OutboxRepository) Delete(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.writerDB.WithContext(ctx).Delete(&domain.OutboxEntry{}, "id IN ?", ids).Error
+}
+
+// DeleteByTenant removes the entries of a tenant, published or not
+func (r *OutboxRepository) DeleteByTenant(ctx context.Context, tenantID string) error {
+	return r.writerDB.WithContext(ctx).Delete(&domain.OutboxEntry{}, "tenant_id = ?", tenantID).Error
+}
+
+// Retry records why the entries failed and when they are due again
+func (r *OutboxRepository) Retry(ctx context.Context, ids []int64, lastError string, nextAttemptAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.OutboxEntry{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+		}).Error
+}
+
+// Lag returns how many entries are pending and when the oldest was written
+func (r *OutboxRepository) Lag(ctx context.Context) (*domain.OutboxLag, error) {
+	var result struct {
+		Pending int64
+		Oldest  *time.Time
+	}
+	if err := r.writerDB.WithContext(ctx).
+		Model(&domain.OutboxEntry{}).
+		Select("COUNT(*) AS pending, MIN(created_at) AS oldest").
+		Scan(&result).Error; err != nil {
+		return nil, err
+	}
+
+	lag := &domain.OutboxLag{Pending: result.Pending}
+	if result.Oldest != nil {
+		lag.Oldest = *result.Oldest
+	}
+	return lag, nil
+}
@@ -12,6 +12,13 @@ type postgresRepository struct {
 	readerDB     *gorm.DB
 	auditLogRepo repository.AuditLogRepository
 	tenantRepo   repository.TenantRepository
+	exportRepo   repository.ExportJobRepository
+	restoreRepo  repository.RestoreJobRepository
+	reindexRepo  repository.ReindexJobRepository
+	policyRepo   repository.RetentionPolicyRepository
+	holdRepo     repository.LegalHoldRepository
+	progressRepo repository.RetentionProgressRepository
+	outboxRepo   repository.OutboxRepository
 }
 
 func NewPostgresRepository(dbConnections *config.DatabaseConnections) repository.PostgresRepository {
@@ -20,6 +27,13 @@ func NewPostgresRepository(dbConnections *config.DatabaseConnections) repository
 		readerDB:     dbConnections.Reader,
 		auditLogRepo: NewAuditLogRepository(dbConnections.Writer, dbConnections.Reader),
 		tenantRepo:   NewTenantRepository(dbConnections.Writer, dbConnections.Reader),
+		exportRepo:   NewExportJobRepository(dbConnections.Writer, dbConnections.Reader),
+		restoreRepo:  NewRestoreJobRepository(dbConnections.Writer, dbConnections.Reader),
+		reindexRepo:  NewReindexJobRepository(dbConnections.Writer, dbConnections.Reader),
+		policyRepo:   NewRetentionPolicyRepository(dbConnections.Writer, dbConnections.Reader),
+		holdRepo:     NewLegalHoldRepository(dbConnections.Writer, dbConnections.Reader),
+		progressRepo: NewRetentionProgressRepository(dbConnections.Writer, dbConnections.Reader),
+		outboxRepo:   NewOutboxRepository(dbConnections.Writer, dbConnections.Reader),
 	}
 }
 
@@ -30,3 +44,31 @@ func (r *postgresRepository) AuditLog() repository.AuditLogRepository {
 func (r *postgresRepository) Tenant() repository.TenantRepository {
 	return r.tenantRepo
 }
+
+func (r *postgresRepository) ExportJob() repository.ExportJobRepository {
+	return r.exportRepo
+}
+
+func (r *postgresRepository) RestoreJob() repository.RestoreJobRepository {
+	return r.restoreRepo
+}
+
+func (r *postgresRepository) ReindexJob() repository.ReindexJobRepository {
+	return r.reindexRepo
+}
+
+func (r *postgresRepository) RetentionPolicy() repository.RetentionPolicyRepository {
+	return r.policyRepo
+}
+
+func (r *postgresRepository) LegalHold() repository.LegalHoldRepository {
+	return r.holdRepo
+}
+
+func (r *postgresRepository) RetentionProgress() repository.RetentionProgressRepository {
+	return r.progressRepo
+}
+
+func (r *postgresRepository) Outbox() repository.OutboxRepository {
+	return r.outboxRepo
+}
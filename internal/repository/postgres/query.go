@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns, backslash is the default
+// escape character of PostgreSQL
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// queryCondition compiles the syntax tree of a log query into a parameterized
+// condition. Field names come from the parser's allow-list, values and JSON
+// paths are always passed as parameters.
+func queryCondition(node domain.QueryNode) (string, []any) {
+	switch q := node.(type) {
+	case *domain.AndQuery:
+		return joinConditions(q.Clauses, " AND ")
+	case *domain.OrQuery:
+		return joinConditions(q.Clauses, " OR ")
+	case *domain.NotQuery:
+		// A missing value doesn't match, so its negation must match like it
+		// does in OpenSearch instead of being NULL
+		cond, args := queryCondition(q.Clause)
+		return "NOT COALESCE(" + cond + ", FALSE)", args
+	case *domain.FieldQuery:
+		return fieldCondition(q)
+	}
+	return "FALSE", nil
+}
+
+func joinConditions(clauses []domain.QueryNode, sep string) (string, []any) {
+	conds := make([]string, 0, len(clauses))
+	var args []any
+	for _, clause := range clauses {
+		cond, clauseArgs := queryCondition(clause)
+		conds = append(conds, cond)
+		args = append(args, clauseArgs...)
+	}
+	return "(" + strings.Join(conds, sep) + ")", args
+}
+
+func fieldCondition(q *domain.FieldQuery) (string, []any) {
+	column := q.Field
+	if q.Kind() == domain.QueryFieldJSON {
+		return jsonFilterCondition(q.JSONFilter())
+	}
+
+	switch {
+	case q.Kind() == domain.QueryFieldText && q.Prefix:
+		return column + " ~* ?", []any{`\m` + regexp.QuoteMeta(q.Value)}
+	case q.Kind() == domain.QueryFieldText:
+		return column + " ILIKE ?", []any{"%" + likeEscaper.Replace(q.Value) + "%"}
+	case q.Prefix:
+		return column + " LIKE ?", []any{likeEscaper.Replace(q.Value) + "%"}
+	default:
+		return column + " = ?", []any{q.Value}
+	}
+}
+
+// jsonFilterCondition compiles a JSON path filter into a jsonpath predicate,
+// which the GIN indexes of the JSON fields answer for existence and equality.
+// The field comes from the allow-list of domain.SplitJSONPath. Predicates
+// only use @@, the ? of @? would be taken for a placeholder.
+func jsonFilterCondition(f *domain.JSONFilter) (string, []any) {
+	path := jsonPath(f.Path)
+	switch f.Op {
+	case domain.JSONFilterExists:
+		cond := f.Field + " @@ ?::jsonpath"
+		if f.Value != "true" {
+			cond = "NOT COALESCE(" + cond + ", FALSE)"
+		}
+		return cond, []any{"exists(" + path + " ? (@ != null))"}
+	case domain.JSONFilterEq:
+		// The value may be stored as a string or as a number or boolean
+		predicate := path + " == " + jsonString(f.Value)
+		if literal, ok := jsonScalar(f.Value); ok {
+			predicate += " || " + path + " == " + literal
+		}
+		return f.Field + " @@ ?::jsonpath", []any{predicate}
+	case domain.JSONFilterPrefix:
+		return f.Field + " @@ ?::jsonpath", []any{path + " starts with " + jsonString(f.Value)}
+	}
+
+	operators := map[domain.JSONFilterOp]string{
+		domain.JSONFilterGt:  ">",
+		domain.JSONFilterGte: ">=",
+		domain.JSONFilterLt:  "<",
+		domain.JSONFilterLte: "<=",
+	}
+	value := jsonString(f.Value)
+	if n, ok := domain.ParseJSONNumber(f.Value); ok {
+		value = strconv.FormatFloat(n, 'g', -1, 64)
+	}
+	return f.Field + " @@ ?::jsonpath", []any{path + " " + operators[f.Op] + " " + value}
+}
+
+// jsonPath returns the jsonpath of the keys, keys of digits index arrays
+func jsonPath(keys []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, key := range keys {
+		if _, err := strconv.Atoi(key); err == nil {
+			b.WriteString("[" + key + "]")
+			continue
+		}
+		b.WriteString("." + jsonString(key))
+	}
+	return b.String()
+}
+
+// jsonString quotes the value as a jsonpath string literal
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// jsonScalar returns the value as a jsonpath number or boolean literal, if it is one
+func jsonScalar(s string) (string, bool) {
+	if s == "true" || s == "false" {
+		return s, true
+	}
+	n, ok := domain.ParseJSONNumber(s)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatFloat(n, 'g', -1, 64), true
+}
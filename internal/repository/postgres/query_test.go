@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+}
+
+func TestQuery(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+func (s *QueryTestSuite) TestQueryCondition() {
+	tests := []struct {
+		name  string
+		query string
+		cond  string
+		args  []any
+	}{
+		{name: "keyword", query: "severity:ERROR", cond: "severity = ?", args: []any{"ERROR"}},
+		{name: "user ID is text", query: "user_id:svc-billing", cond: "user_id = ?", args: []any{"svc-billing"}},
+		{name: "user ID prefix", query: "user_id:svc-*", cond: "user_id LIKE ?", args: []any{"svc-%"}},
+		{
+			name:  "UUID",
+			query: "id:3F2504E0-4F89-11D3-9A0C-0305E82C3301",
+			cond:  "id = ?",
+			args:  []any{"3f2504e0-4f89-11d3-9a0c-0305e82c3301"},
+		},
+		{name: "prefix escapes wildcards", query: `resource_id:50%_off\*`, cond: "resource_id LIKE ?", args: []any{`50\%\_off\\%`}},
+		{name: "text", query: `message:"100% done"`, cond: "message ILIKE ?", args: []any{`%100\% done%`}},
+		{name: "text prefix", query: "message:fail.*", cond: "message ~* ?", args: []any{`\mfail\.`}},
+		{name: "IP", query: "ip_address:10.0.0.1", cond: "ip_address = ?", args: []any{"10.0.0.1"}},
+		{
+			name:  "JSON equality",
+			query: "metadata.order.id:42",
+			cond:  "metadata @@ ?::jsonpath",
+			args:  []any{`$."order"."id" == "42" || $."order"."id" == 42`},
+		},
+		{
+			name:  "JSON array index",
+			query: "after_state.tags.0:red",
+			cond:  "after_state @@ ?::jsonpath",
+			args:  []any{`$."tags"[0] == "red"`},
+		},
+		{
+			name:  "JSON prefix",
+			query: "before_state.name:new*",
+			cond:  "before_state @@ ?::jsonpath",
+			args:  []any{`$."name" starts with "new"`},
+		},
+		{
+			name:  "precedence",
+			query: "action:a OR action:b severity:ERROR",
+			cond:  "(action = ? OR (action = ? AND severity = ?))",
+			args:  []any{"a", "b", "ERROR"},
+		},
+		{
+			name:  "negation matches missing values",
+			query: "NOT (action:a OR user_agent:curl)",
+			cond:  "NOT COALESCE((action = ? OR user_agent ILIKE ?), FALSE)",
+			args:  []any{"a", "%curl%"},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			// Arrange
+			query, err := domain.ParseLogQuery(tt.query)
+			s.Require().NoError(err)
+
+			// Act
+			cond, args := queryCondition(query.Root)
+
+			// Assert
+			s.Equal(tt.cond, cond)
+			s.Equal(tt.args, args)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type ReindexJobRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewReindexJobRepository(writerDB, readerDB *gorm.DB) *ReindexJobRepository {
+	return &ReindexJobRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *ReindexJobRepository) Create(ctx context.Context, job *domain.ReindexJob) error {
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID returns the job. It reads from the writer database so that progress
+// is reported without replication lag.
+func (r *ReindexJobRepository) GetByID(ctx context.Context, id string) (*domain.ReindexJob, error) {
+	var job domain.ReindexJob
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ReindexJobRepository) Update(ctx context.Context, job *domain.ReindexJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type RestoreJobRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewRestoreJobRepository(writerDB, readerDB *gorm.DB) *RestoreJobRepository {
+	return &RestoreJobRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+func (r *RestoreJobRepository) Create(ctx context.Context, job *domain.RestoreJob) error {
+	return r.writerDB.WithContext(ctx).Create(job).Error
+}
+
+// GetByID returns the job of the tenant. It reads from the writer database so
+// that progress is reported without replication lag.
+func (r *RestoreJobRepository) GetByID(ctx context.Context, tenantID, id string) (*domain.RestoreJob, error) {
+	var job domain.RestoreJob
+	if err := r.writerDB.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *RestoreJobRepository) Update(ctx context.Context, job *domain.RestoreJob) error {
+	return r.writerDB.WithContext(ctx).Save(job).Error
+}
+
+// UpdateRun saves the job if the run started at startedAt still owns it, nil
+// for a job no run has started. It reports whether the job was saved.
+func (r *RestoreJobRepository) UpdateRun(ctx context.Context, job *domain.RestoreJob, startedAt *time.Time) (bool, error) {
+	db := r.writerDB.WithContext(ctx).Model(job).Select("*").Omit("id", "created_at")
+	if startedAt == nil {
+		db = db.Where("started_at IS NULL")
+	} else {
+		db = db.Where("started_at = ?", *startedAt)
+	}
+	result := db.Updates(job)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type RetentionPolicyRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewRetentionPolicyRepository(writerDB, readerDB *gorm.DB) *RetentionPolicyRepository {
+	return &RetentionPolicyRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+// Get returns the policy of the tenant. It reads from the writer database so
+// that the cleanup worker never deletes logs under a policy that has changed.
+func (r *RetentionPolicyRepository) Get(ctx context.Context, tenantID string) (*domain.RetentionPolicy, error) {
+	var policy domain.RetentionPolicy
+	if err := r.writerDB.WithContext(ctx).First(&policy, "tenant_id = ?", tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Upsert creates the policy of the tenant or replaces its settings
+func (r *RetentionPolicyRepository) Upsert(ctx context.Context, policy *domain.RetentionPolicy) error {
+	return r.writerDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"archive_after_days", "delete_after_days", "keep_severities", "enabled", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func (r *RetentionPolicyRepository) Delete(ctx context.Context, tenantID string) error {
+	result := r.writerDB.WithContext(ctx).Delete(&domain.RetentionPolicy{}, "tenant_id = ?", tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListEnabled returns the policies the scheduler applies
+func (r *RetentionPolicyRepository) ListEnabled(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	var policies []domain.RetentionPolicy
+	if err := r.readerDB.WithContext(ctx).Where("enabled").Order("tenant_id").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// MarkScheduled records when the scheduler last enqueued work for the tenant
+func (r *RetentionPolicyRepository) MarkScheduled(ctx context.Context, tenantID string, at time.Time) error {
+	return r.writerDB.WithContext(ctx).
+		Model(&domain.RetentionPolicy{}).
+		Where("tenant_id = ?", tenantID).
+		Update("last_scheduled_at", at).Error
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type RetentionProgressRepository struct {
+	writerDB *gorm.DB
+	readerDB *gorm.DB
+}
+
+func NewRetentionProgressRepository(writerDB, readerDB *gorm.DB) *RetentionProgressRepository {
+	return &RetentionProgressRepository{
+		writerDB: writerDB,
+		readerDB: readerDB,
+	}
+}
+
+// Get returns the progress of the tenant. It reads from the writer database
+// so that a chunk is never dropped on stale archive progress.
+func (r *RetentionProgressRepository) Get(ctx context.Context, tenantID string) (*domain.RetentionProgress, error) {
+	var progress domain.RetentionProgress
+	if err := r.writerDB.WithContext(ctx).First(&progress, "tenant_id = ?", tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// SaveCleanup stores the cleanup progress of the tenant, leaving its archive
+// progress untouched, if the cleanup started at startedAt still owns it, nil
+// when no cleanup runs. It reports whether the progress was saved.
+func (r *RetentionProgressRepository) SaveCleanup(ctx context.Context, progress *domain.RetentionProgress, startedAt *time.Time) (bool, error) {
+	progress.UpdatedAt = time.Now()
+	result := r.writerDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cleanup_before", "cleaned_up_to", "deleted_logs", "held_logs", "dropped_chunks", "cleanup_started_at", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "retention_progress.cleanup_started_at IS NOT DISTINCT FROM ?", Vars: []any{startedAt}},
+		}},
+	}).Omit("archived_before").Create(progress)
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkArchived records that the logs of the tenant before the date are
+// archived. Archive progress never moves backwards.
+func (r *RetentionProgressRepository) MarkArchived(ctx context.Context, tenantID string, before time.Time) error {
+	progress := domain.RetentionProgress{TenantID: tenantID, ArchivedBefore: &before, UpdatedAt: time.Now()}
+	return r.writerDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"archived_before": gorm.Expr("GREATEST(retention_progress.archived_before, EXCLUDED.archived_before)"),
+			"updated_at":      gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Select("tenant_id", "archived_before", "updated_at").Create(&progress).Error
+}
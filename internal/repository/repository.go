@@ -12,19 +12,38 @@ type AuditLogRepository interface {
 	Create(ctx context.Context, log *domain.AuditLog) error
 	GetByID(ctx context.Context, id string) (*domain.AuditLog, error)
 	List(ctx context.Context, filter domain.AuditLogFilter) ([]domain.AuditLog, error)
-	DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time) (int64, error)
+	EstimateCount(ctx context.Context, filter domain.AuditLogFilter) (int64, error)
+	ListChunks(ctx context.Context, before time.Time) ([]domain.LogChunk, error)
+	CountByTenant(ctx context.Context, startTime, endTime time.Time) (map[string]int64, error)
+	CountByDay(ctx context.Context, tenantID string, startTime, endTime time.Time) (map[string]int64, error)
+	CountExcluded(ctx context.Context, tenantID string, startTime, endTime time.Time, exclusions domain.CleanupExclusions) (int64, error)
+	DeleteBatch(ctx context.Context, tenantID string, startTime, endTime time.Time, exclusions domain.CleanupExclusions, limit int) (int64, error)
+	DropChunk(ctx context.Context, chunk domain.LogChunk, expectedLogs int64) (bool, error)
+	RefreshStats(ctx context.Context, startTime, endTime time.Time) error
 	BulkCreate(ctx context.Context, logs []domain.AuditLog) error
+	ExistingIDs(ctx context.Context, tenantID string, ids []string, startTime, endTime time.Time) ([]string, error)
 	GetRecentLogs(ctx context.Context, tenantID string, since time.Time) ([]domain.AuditLog, error)
 	GetStats(ctx context.Context, filter domain.AuditLogFilter) (*domain.AuditLogStats, error)
+	GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]domain.TimeSeriesCount, error)
+	GetChainHead(ctx context.Context, tenantID string) (*domain.ChainHead, error)
+	GetChainBounds(ctx context.Context, tenantID string, startTime, endTime time.Time) (int64, int64, error)
+	GetTimeBounds(ctx context.Context, tenantID string) (time.Time, time.Time, error)
+	ListChain(ctx context.Context, tenantID string, fromSeq, toSeq int64, limit int) ([]domain.AuditLog, error)
 }
 
 //go:generate mockery --name OpenSearchRepository --output ../mocks
 type OpenSearchRepository interface {
 	Index(ctx context.Context, log *domain.AuditLog) error
 	BulkIndex(ctx context.Context, logs []domain.AuditLog) error
-	Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, error)
-	CreateIndex(ctx context.Context, tenantID string, t time.Time) error
-	DeleteIndex(ctx context.Context, tenantID string) error
+	Search(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, error)
+	Sessions(ctx context.Context, filter *domain.AuditLogFilter, limit int) ([]domain.SessionSummary, int64, error)
+	CreateIndex(ctx context.Context, tenantID string) error
+	PutIndexedPaths(ctx context.Context, tenantID string, paths []domain.IndexedPath) error
+	DeleteIndex(ctx context.Context, tenantID string) (int64, error)
+	DeleteBeforeDate(ctx context.Context, tenantID string, beforeDate time.Time, exclusions domain.CleanupExclusions) (int64, error)
+	DeleteRange(ctx context.Context, tenantID string, startTime, endTime time.Time) (int64, error)
+	CountByDay(ctx context.Context, tenantID string, startTime, endTime time.Time) (map[string]int64, error)
+	ExistingIDs(ctx context.Context, tenantID string, ids []string) ([]string, error)
 }
 
 //go:generate mockery --name TenantRepository --output ../mocks
@@ -36,10 +55,77 @@ type TenantRepository interface {
 	List(ctx context.Context) ([]domain.Tenant, error)
 }
 
+//go:generate mockery --name ExportJobRepository --output ../mocks
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *domain.ExportJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.ExportJob, error)
+	Update(ctx context.Context, job *domain.ExportJob) error
+	UpdateRun(ctx context.Context, job *domain.ExportJob, startedAt *time.Time) (bool, error)
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.ExportJob, error)
+	Delete(ctx context.Context, id string) error
+}
+
+//go:generate mockery --name RestoreJobRepository --output ../mocks
+type RestoreJobRepository interface {
+	Create(ctx context.Context, job *domain.RestoreJob) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.RestoreJob, error)
+	Update(ctx context.Context, job *domain.RestoreJob) error
+	UpdateRun(ctx context.Context, job *domain.RestoreJob, startedAt *time.Time) (bool, error)
+}
+
+//go:generate mockery --name ReindexJobRepository --output ../mocks
+type ReindexJobRepository interface {
+	Create(ctx context.Context, job *domain.ReindexJob) error
+	GetByID(ctx context.Context, id string) (*domain.ReindexJob, error)
+	Update(ctx context.Context, job *domain.ReindexJob) error
+}
+
+//go:generate mockery --name RetentionPolicyRepository --output ../mocks
+type RetentionPolicyRepository interface {
+	Get(ctx context.Context, tenantID string) (*domain.RetentionPolicy, error)
+	Upsert(ctx context.Context, policy *domain.RetentionPolicy) error
+	Delete(ctx context.Context, tenantID string) error
+	ListEnabled(ctx context.Context) ([]domain.RetentionPolicy, error)
+	MarkScheduled(ctx context.Context, tenantID string, at time.Time) error
+}
+
+//go:generate mockery --name RetentionProgressRepository --output ../mocks
+type RetentionProgressRepository interface {
+	Get(ctx context.Context, tenantID string) (*domain.RetentionProgress, error)
+	SaveCleanup(ctx context.Context, progress *domain.RetentionProgress, startedAt *time.Time) (bool, error)
+	MarkArchived(ctx context.Context, tenantID string, before time.Time) error
+}
+
+//go:generate mockery --name LegalHoldRepository --output ../mocks
+type LegalHoldRepository interface {
+	Create(ctx context.Context, hold *domain.LegalHold, log *domain.AuditLog) error
+	GetByID(ctx context.Context, tenantID, id string) (*domain.LegalHold, error)
+	List(ctx context.Context, tenantID string, includeReleased bool) ([]domain.LegalHold, error)
+	ListActive(ctx context.Context, tenantID string) ([]domain.LegalHold, error)
+	Update(ctx context.Context, hold *domain.LegalHold, log *domain.AuditLog) error
+}
+
+//go:generate mockery --name OutboxRepository --output ../mocks
+type OutboxRepository interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEntry, error)
+	Logs(ctx context.Context, entries []domain.OutboxEntry) ([]domain.AuditLog, error)
+	Delete(ctx context.Context, ids []int64) error
+	DeleteByTenant(ctx context.Context, tenantID string) error
+	Retry(ctx context.Context, ids []int64, lastError string, nextAttemptAt time.Time) error
+	Lag(ctx context.Context) (*domain.OutboxLag, error)
+}
+
 //go:generate mockery --name PostgresRepository --output ../mocks
 type PostgresRepository interface {
 	AuditLog() AuditLogRepository
 	Tenant() TenantRepository
+	ExportJob() ExportJobRepository
+	RestoreJob() RestoreJobRepository
+	ReindexJob() ReindexJobRepository
+	RetentionPolicy() RetentionPolicyRepository
+	LegalHold() LegalHoldRepository
+	RetentionProgress() RetentionProgressRepository
+	Outbox() OutboxRepository
 }
 
 //go:generate mockery --name Repository --output ../mocks
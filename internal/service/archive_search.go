@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// archiveLiveCheckSize is the number of archived matches looked up in the
+// live logs at once
+const archiveLiveCheckSize = 1000
+
+// SetArchiveStorage sets the storage of the S3 archives searched by List when
+// the filter includes archived logs
+func (s *AuditLogService) SetArchiveStorage(storage ArchiveStorage) {
+	s.archives = storage
+}
+
+// listWithArchives merges the live logs matching the filter with the logs of
+// the archives that overlap its time range. Logs found in both, such as
+// restored ones, are reported and counted once, as live. The IDs of the logs
+// only found in archives are returned.
+func (s *AuditLogService) listWithArchives(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, map[string]bool, error) {
+	if s.archives == nil {
+		return nil, 0, nil, ErrArchiveSearchUnavailable
+	}
+
+	// Neither source can skip ahead on its own, so without a cursor both
+	// return every log up to the end of the requested page
+	limit, offset := filter.Limit, filter.Offset
+	liveFilter := *filter
+	if filter.After == nil {
+		liveFilter.Limit = offset + limit
+		liveFilter.Offset = 0
+	}
+
+	logs, total, err := s.listLive(ctx, &liveFilter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	archived, archivedTotal, err := s.searchArchives(ctx, &liveFilter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	live := make(map[string]bool, len(logs))
+	for i := range logs {
+		live[logs[i].ID] = true
+	}
+	archivedIDs := make(map[string]bool, len(archived))
+	for _, log := range archived {
+		if !live[log.ID] {
+			archivedIDs[log.ID] = true
+			logs = append(logs, log)
+		}
+	}
+
+	slices.SortFunc(logs, func(a, b domain.AuditLog) int {
+		return domain.CompareLogs(&a, &b)
+	})
+	if filter.After == nil {
+		logs = logs[min(offset, len(logs)):]
+	}
+	logs = logs[:min(limit, len(logs))]
+
+	return logs, total + archivedTotal, archivedIDs, nil
+}
+
+// searchArchives scans the archives of the tenant that may hold logs in the
+// time range of the filter. It returns the newest filter.Limit matches and the
+// total number of matches, leaving out the matches that are also stored live.
+func (s *AuditLogService) searchArchives(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, error) {
+	keys, err := s.archivesInRange(ctx, filter.TenantID, filter.StartTime, filter.EndTime)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		matches []domain.AuditLog
+		total   int64
+	)
+	// Only the newest matches are kept, so memory stays bounded by the page
+	// size rather than by the size of the archives
+	trim := func() {
+		slices.SortFunc(matches, func(a, b domain.AuditLog) int {
+			return domain.CompareLogs(&a, &b)
+		})
+		matches = matches[:min(filter.Limit, len(matches))]
+	}
+
+	// Matches are kept once they are known not to be live
+	var pending []domain.AuditLog
+	flush := func() error {
+		archivedOnly, err := s.archivedOnly(ctx, filter.TenantID, pending)
+		if err != nil {
+			return err
+		}
+		pending = pending[:0]
+		total += int64(len(archivedOnly))
+		matches = append(matches, archivedOnly...)
+		if len(matches) > 2*filter.Limit {
+			trim()
+		}
+		return nil
+	}
+
+	for _, key := range keys {
+		var flushErr error
+		err := s.scanArchive(ctx, key, func(log *domain.AuditLog) {
+			if flushErr != nil || !filter.Matches(log) || (filter.After != nil && !filter.After.Includes(log)) {
+				return
+			}
+			pending = append(pending, *log)
+			if len(pending) == archiveLiveCheckSize {
+				flushErr = flush()
+			}
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if flushErr != nil {
+			return nil, 0, flushErr
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, 0, err
+	}
+	trim()
+
+	return matches, total, nil
+}
+
+// archivedOnly returns the archived logs that are not stored live
+func (s *AuditLogService) archivedOnly(ctx context.Context, tenantID string, logs []domain.AuditLog) ([]domain.AuditLog, error) {
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(logs))
+	startTime, endTime := logs[0].Timestamp, logs[0].Timestamp
+	for i := range logs {
+		ids[i] = logs[i].ID
+		if logs[i].Timestamp.Before(startTime) {
+			startTime = logs[i].Timestamp
+		}
+		if logs[i].Timestamp.After(endTime) {
+			endTime = logs[i].Timestamp
+		}
+	}
+
+	existing, err := s.repo.AuditLog().ExistingIDs(ctx, tenantID, ids, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check archived logs against live logs: %w", err)
+	}
+	live := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		live[id] = true
+	}
+
+	archivedOnly := make([]domain.AuditLog, 0, len(logs)-len(existing))
+	for _, log := range logs {
+		if !live[log.ID] {
+			archivedOnly = append(archivedOnly, log)
+		}
+	}
+	return archivedOnly, nil
+}
+
+// archivesInRange returns the keys of the archives of the tenant holding logs
+// between startTime and endTime. Each archive holds the logs from the date of
+// the previous archive up to its own date.
+func (s *AuditLogService) archivesInRange(ctx context.Context, tenantID string, startTime, endTime time.Time) ([]string, error) {
+	objects, err := s.archives.List(ctx, domain.ArchiveKeyPrefix(tenantID))
+	if err != nil {
+		return nil, err
+	}
+
+	type archive struct {
+		key        string
+		beforeDate time.Time
+	}
+	archives := make([]archive, 0, len(objects))
+	for _, obj := range objects {
+		if beforeDate, ok := domain.ParseArchiveBeforeDate(obj.Key); ok {
+			archives = append(archives, archive{key: obj.Key, beforeDate: beforeDate})
+		}
+	}
+	slices.SortFunc(archives, func(a, b archive) int {
+		return a.beforeDate.Compare(b.beforeDate)
+	})
+
+	// Ranges that start after the latest archive are still live
+	var keys []string
+	var from time.Time
+	for _, a := range archives {
+		if a.beforeDate.After(startTime) && (from.IsZero() || !from.After(endTime)) {
+			keys = append(keys, a.key)
+		}
+		from = a.beforeDate
+	}
+	return keys, nil
+}
+
+// scanArchive decodes the logs of an archive one at a time and hands them to fn
+func (s *AuditLogService) scanArchive(ctx context.Context, key string, fn func(log *domain.AuditLog)) error {
+	body, err := s.archives.Download(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to decode archive %s: %w", key, err)
+	}
+	for dec.More() {
+		field, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode archive %s: %w", key, err)
+		}
+		if field != "logs" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("failed to decode archive %s: %w", key, err)
+			}
+			continue
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to decode archive %s: %w", key, err)
+		}
+		if tok != json.Delim('[') {
+			// An archive without logs
+			continue
+		}
+		for dec.More() {
+			var log domain.AuditLog
+			if err := dec.Decode(&log); err != nil {
+				return fmt.Errorf("failed to decode archive %s: %w", key, err)
+			}
+			fn(&log)
+		}
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("failed to decode archive %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
@@ -2,12 +2,25 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
 	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+)
+
+const (
+	// maxChainAppendAttempts bounds the retries when concurrent writers move the chain head
+	maxChainAppendAttempts = 5
+	// chainVerifyBatchSize is the number of logs loaded per query while verifying the chain
+	chainVerifyBatchSize = 1000
+	// exportBatchSize is the number of logs loaded per query while exporting
+	exportBatchSize = 1000
 )
 
 //go:generate mockery --name WebSocketBroadcaster --output ../mocks
@@ -15,24 +28,26 @@ type WebSocketBroadcaster interface {
 	BroadcastLog(log *dto.AuditLogResponse)
 }
 
-//go:generate mockery --name SQSService --output ../mocks
-type SQSService interface {
-	SendIndexMessage(ctx context.Context, log *domain.AuditLog) error
-	SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error
+//go:generate mockery --name QueueService --output ../mocks
+type QueueService interface {
 	SendArchiveMessage(ctx context.Context, tenantID string, beforeDate time.Time) error
 	SendCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error
+	SendExportMessage(ctx context.Context, tenantID, jobID string) error
+	SendRestoreMessage(ctx context.Context, tenantID, jobID string) error
+	SendReindexMessage(ctx context.Context, jobID string) error
 }
 
 type AuditLogService struct {
 	repo        repository.Repository
-	sqsSvc      SQSService
+	queueSvc    QueueService
 	broadcaster WebSocketBroadcaster
+	archives    ArchiveStorage
 }
 
-func NewAuditLogService(repo repository.Repository, sqsSvc SQSService) *AuditLogService {
+func NewAuditLogService(repo repository.Repository, queueSvc QueueService) *AuditLogService {
 	return &AuditLogService{
-		repo:   repo,
-		sqsSvc: sqsSvc,
+		repo:     repo,
+		queueSvc: queueSvc,
 	}
 }
 
@@ -42,16 +57,31 @@ func (s *AuditLogService) SetWebSocketBroadcaster(broadcaster WebSocketBroadcast
 }
 
 func (s *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogRequest) error {
-	auditLog := req.ToAuditLog()
+	return s.Append(ctx, req, func(log *domain.AuditLog) error {
+		return s.repo.AuditLog().Create(ctx, log)
+	})
+}
 
-	// Store in PostgreSQL
-	if err := s.repo.AuditLog().Create(ctx, auditLog); err != nil {
-		return fmt.Errorf("failed to store log in PostgreSQL: %w", err)
+// Append links the log of the request onto the tenant's hash chain and stores
+// it with store, which may write other changes in the same transaction. Store
+// is called again when another log took the place in the chain meanwhile.
+func (s *AuditLogService) Append(ctx context.Context, req dto.CreateAuditLogRequest, store func(log *domain.AuditLog) error) error {
+	tenantID, err := requestTenant(ctx, req)
+	if err != nil {
+		return err
 	}
 
-	// Send message to SQS for asynchronous indexing
-	if err := s.sqsSvc.SendIndexMessage(ctx, auditLog); err != nil {
-		fmt.Printf("failed to send index message to SQS: %v\n", err)
+	logs := []domain.AuditLog{*req.ToAuditLog()}
+	auditLog := &logs[0]
+
+	// Link the log onto the tenant's hash chain and store it in PostgreSQL. Its
+	// outbox entry is stored in the same transaction and the outbox relay
+	// enqueues it for indexing.
+	err = s.appendToChain(ctx, tenantID, logs, func() error {
+		return store(auditLog)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store log in PostgreSQL: %w", err)
 	}
 
 	// Broadcast to WebSocket clients if broadcaster is available
@@ -63,21 +93,25 @@ func (s *AuditLogService) Create(ctx context.Context, req dto.CreateAuditLogRequ
 }
 
 func (s *AuditLogService) BulkCreate(ctx context.Context, req []dto.CreateAuditLogRequest) error {
+	tenantID, err := requestTenant(ctx, req...)
+	if err != nil {
+		return err
+	}
+
 	auditLogs := make([]domain.AuditLog, len(req))
 	for i := range req {
 		auditLogs[i] = *req[i].ToAuditLog()
 	}
 
-	// Store in PostgreSQL
-	if err := s.repo.AuditLog().BulkCreate(ctx, auditLogs); err != nil {
+	// Link the logs onto the tenant's hash chain and store them in PostgreSQL,
+	// together with their outbox entries
+	err = s.appendToChain(ctx, tenantID, auditLogs, func() error {
+		return s.repo.AuditLog().BulkCreate(ctx, auditLogs)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to bulk store logs in PostgreSQL: %w", err)
 	}
 
-	// Send message to SQS for asynchronous bulk indexing
-	if err := s.sqsSvc.SendBulkIndexMessage(ctx, auditLogs); err != nil {
-		fmt.Printf("failed to send bulk index message to SQS: %v\n", err)
-	}
-
 	// Broadcast each log to WebSocket clients if broadcaster is available
 	if s.broadcaster != nil {
 		for _, log := range auditLogs {
@@ -88,15 +122,32 @@ func (s *AuditLogService) BulkCreate(ctx context.Context, req []dto.CreateAuditL
 	return nil
 }
 
+// requestTenant returns the tenant of the caller, the logs are appended to
+// its chain. Logs naming another tenant are rejected.
+func requestTenant(ctx context.Context, reqs ...dto.CreateAuditLogRequest) (string, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	for i := range reqs {
+		if reqs[i].TenantID != "" && reqs[i].TenantID != tenantID {
+			return "", ErrTenantMismatch
+		}
+	}
+	return tenantID, nil
+}
+
 func (s *AuditLogService) GetByID(ctx context.Context, id string) (*dto.AuditLogResponse, error) {
 	log, err := s.repo.AuditLog().GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	return dto.FromAuditLog(log), nil
+	response := dto.FromAuditLog(log)
+	response.Diff = dto.FromFieldChanges(domain.DiffStates(log.BeforeState, log.AfterState))
+	return response, nil
 }
 
-func (s *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) ([]dto.AuditLogResponse, error) {
+func (s *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilter, usePagination bool) (*dto.ListAuditLogsResponse, error) {
 	// Set default values for pagination
 	if filter.Page < 1 {
 		filter.Page = 1
@@ -105,33 +156,114 @@ func (s *AuditLogService) List(ctx context.Context, filter *domain.AuditLogFilte
 		filter.PageSize = 10
 	}
 
-	// Convert page and page size to limit and offset
-	filter.Limit = filter.PageSize
+	// Convert page and page size to limit and offset, fetching one extra log
+	// to find out whether there is a next page
+	filter.Limit = filter.PageSize + 1
 	filter.Offset = (filter.Page - 1) * filter.PageSize
 
+	var (
+		logs     []domain.AuditLog
+		total    int64
+		archived map[string]bool
+		err      error
+	)
+	if filter.IncludeArchived {
+		logs, total, archived, err = s.listWithArchives(ctx, filter)
+	} else {
+		logs, total, err = s.listLive(ctx, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListAuditLogsResponse{
+		ApproximateTotal: total,
+	}
+	if len(logs) > filter.PageSize {
+		logs = logs[:filter.PageSize]
+		response.NextCursor = domain.NewLogCursor(&logs[len(logs)-1]).Encode()
+	}
+	response.Items = dto.FromAuditLogs(logs)
+
+	// Mark where each log was found when archives were searched too
+	if filter.IncludeArchived {
+		for i := range response.Items {
+			response.Items[i].Source = dto.LogSourceLive
+			if archived[response.Items[i].ID] {
+				response.Items[i].Source = dto.LogSourceArchive
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// listLive returns the logs matching the filter from OpenSearch or PostgreSQL
+// along with an estimate of the number of matches
+func (s *AuditLogService) listLive(ctx context.Context, filter *domain.AuditLogFilter) ([]domain.AuditLog, int64, error) {
 	// Use OpenSearch for searching if there are search criteria benefit from it
 	if s.hasSearchCriteria(filter) {
-		logs, err := s.repo.OpenSearch().Search(ctx, filter)
+		indexed, err := s.indexesJSONPaths(ctx, filter)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
+		}
+		if indexed {
+			return s.repo.OpenSearch().Search(ctx, filter)
 		}
-		return dto.FromAuditLogs(logs), nil
 	}
 
 	// Otherwise, use PostgreSQL for simple listing if there are no search criteria benefit from it
 	logs, err := s.repo.AuditLog().List(ctx, *filter)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	// The total is only an estimate, it is left unknown when it fails
+	total, _ := s.repo.AuditLog().EstimateCount(ctx, *filter)
+
+	return logs, total, nil
+}
+
+// Export streams the logs matching the filter from PostgreSQL to fn in
+// batches, newest first, so that exports of large ranges never hold more than
+// one batch in memory. It stops at the first error returned by fn.
+func (s *AuditLogService) Export(ctx context.Context, filter *domain.AuditLogFilter, fn func(logs []domain.AuditLog) error) error {
+	return exportLogs(ctx, s.repo.AuditLog(), *filter, fn)
+}
+
+// exportLogs walks the logs matching the filter in keyset order, one batch at a time
+func exportLogs(ctx context.Context, repo repository.AuditLogRepository, filter domain.AuditLogFilter, fn func(logs []domain.AuditLog) error) error {
+	filter.Limit = exportBatchSize
+	filter.Offset = 0
+	filter.After = nil
+
+	for {
+		logs, err := repo.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to list audit logs: %w", err)
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+
+		if err := fn(logs); err != nil {
+			return err
+		}
+
+		if len(logs) < exportBatchSize {
+			return nil
+		}
+		filter.After = domain.NewLogCursor(&logs[len(logs)-1])
 	}
-	return dto.FromAuditLogs(logs), nil
 }
 
 func (s *AuditLogService) GetStats(ctx context.Context, filter *domain.AuditLogFilter) (*dto.GetAuditLogStatsResponse, error) {
 	// Use OpenSearch for aggregations if available, otherwise fall back to PostgreSQL
-	logs, err := s.List(ctx, filter, false)
+	page, err := s.List(ctx, filter, false)
 	if err != nil {
 		return nil, err
 	}
+	logs := page.Items
 
 	stats := &dto.GetAuditLogStatsResponse{
 		TotalLogs:      int64(len(logs)),
@@ -183,19 +315,132 @@ func (s *AuditLogService) GetStatsV2(ctx context.Context, filter *domain.AuditLo
 	return response, nil
 }
 
+// appendToChain seals the logs on top of the tenant's chain head and stores
+// them, retrying when a concurrent writer has moved the head in the meantime
+func (s *AuditLogService) appendToChain(ctx context.Context, tenantID string, logs []domain.AuditLog, store func() error) error {
+	// IDs and tenant are part of the hash, so they must be final before sealing
+	for i := range logs {
+		if logs[i].ID == "" {
+			logs[i].ID = uuid.New().String()
+		}
+		logs[i].TenantID = tenantID
+	}
+
+	for attempt := 1; ; attempt++ {
+		head, err := s.repo.AuditLog().GetChainHead(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		domain.SealChain(*head, logs)
+
+		err = store()
+		if !errors.Is(err, domain.ErrChainConflict) || attempt == maxChainAppendAttempts {
+			return err
+		}
+	}
+}
+
+// VerifyChain walks the tenant's hash chain across the logs in the time range
+// and reports the first broken link
+func (s *AuditLogService) VerifyChain(ctx context.Context, tenantID string, startTime, endTime time.Time) (*dto.ChainVerificationResponse, error) {
+	result := &dto.ChainVerificationResponse{
+		TenantID:  tenantID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Valid:     true,
+	}
+
+	firstSeq, lastSeq, err := s.repo.AuditLog().GetChainBounds(ctx, tenantID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain bounds: %w", err)
+	}
+	if firstSeq == 0 {
+		return result, nil
+	}
+	result.FirstSeq = firstSeq
+	result.LastSeq = lastSeq
+
+	// The predecessor of the first log anchors the walk. Once it has been
+	// archived and cleaned up, the first log's own prev_hash is trusted and
+	// must be checked against the archive's chain anchor instead.
+	prevSeq, prevHash := firstSeq-1, ""
+	result.Anchored = prevSeq == 0
+	if !result.Anchored {
+		prev, err := s.repo.AuditLog().ListChain(ctx, tenantID, prevSeq, prevSeq, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chain anchor: %w", err)
+		}
+		if len(prev) == 1 {
+			prevHash = prev[0].Hash
+			result.Anchored = true
+		}
+	}
+
+	for fromSeq := firstSeq; fromSeq <= lastSeq; fromSeq = prevSeq + 1 {
+		logs, err := s.repo.AuditLog().ListChain(ctx, tenantID, fromSeq, lastSeq, chainVerifyBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list chain: %w", err)
+		}
+		if len(logs) == 0 {
+			break
+		}
+
+		for i := range logs {
+			if result.CheckedLogs == 0 {
+				if !result.Anchored {
+					prevHash = logs[i].PrevHash
+				}
+				result.AnchorPrevHash = prevHash
+			}
+
+			if brk := domain.VerifyLink(prevSeq, prevHash, &logs[i]); brk != nil {
+				result.Valid = false
+				result.FirstBrokenLink = dto.FromChainBreak(brk)
+				return result, nil
+			}
+
+			prevSeq, prevHash = logs[i].ChainSeq, logs[i].Hash
+			result.CheckedLogs++
+		}
+	}
+	result.LastHash = prevHash
+
+	return result, nil
+}
+
 // hasSearchCriteria checks if the filter contains search criteria that would benefit from OpenSearch
 func (s *AuditLogService) hasSearchCriteria(filter *domain.AuditLogFilter) bool {
 	return filter.UserID != "" ||
 		filter.Action != "" ||
 		filter.ResourceType != "" ||
+		filter.ResourceID != "" ||
 		filter.Severity != "" ||
 		filter.IPAddress != "" ||
 		filter.UserAgent != "" ||
 		filter.Message != "" ||
-		filter.SessionID != ""
+		filter.SessionID != "" ||
+		filter.Query != nil ||
+		len(filter.JSONFilters) > 0 ||
+		filter.ChangedField != ""
+}
+
+// indexesJSONPaths checks if OpenSearch can answer the JSON path filters of
+// the filter. Only the paths the tenant declared are mapped in its indices,
+// PostgreSQL answers the others.
+func (s *AuditLogService) indexesJSONPaths(ctx context.Context, filter *domain.AuditLogFilter) (bool, error) {
+	filters := filter.AllJSONFilters()
+	if len(filters) == 0 {
+		return true, nil
+	}
+
+	tenant, err := s.repo.Tenant().GetByID(ctx, filter.TenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant.Searches(filters), nil
 }
 
-// ScheduleArchive schedules an archive operation by sending a message to SQS
+// ScheduleArchive schedules an archive operation by sending a message to the archive queue
 func (s *AuditLogService) ScheduleArchive(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	return s.sqsSvc.SendArchiveMessage(ctx, tenantID, beforeDate)
+	return s.queueSvc.SendArchiveMessage(ctx, tenantID, beforeDate)
 }
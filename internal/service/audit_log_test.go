@@ -1,13 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
 	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -17,7 +24,8 @@ type AuditLogServiceTestSuite struct {
 	mockRepo        *mocks.Repository
 	mockAuditLog    *mocks.AuditLogRepository
 	mockOpenSearch  *mocks.OpenSearchRepository
-	mockSQS         *mocks.SQSService
+	mockTenant      *mocks.TenantRepository
+	mockQueue       *mocks.QueueService
 	mockBroadcaster *mocks.WebSocketBroadcaster
 	service         *AuditLogService
 }
@@ -26,13 +34,15 @@ func (s *AuditLogServiceTestSuite) SetupTest() {
 	s.mockRepo = new(mocks.Repository)
 	s.mockAuditLog = new(mocks.AuditLogRepository)
 	s.mockOpenSearch = new(mocks.OpenSearchRepository)
-	s.mockSQS = new(mocks.SQSService)
+	s.mockTenant = new(mocks.TenantRepository)
+	s.mockQueue = new(mocks.QueueService)
 	s.mockBroadcaster = new(mocks.WebSocketBroadcaster)
 
 	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
 	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+	s.mockRepo.On("Tenant").Return(s.mockTenant)
 
-	s.service = NewAuditLogService(s.mockRepo, s.mockSQS)
+	s.service = NewAuditLogService(s.mockRepo, s.mockQueue)
 	s.service.SetWebSocketBroadcaster(s.mockBroadcaster)
 }
 
@@ -42,7 +52,7 @@ func TestAuditLogService(t *testing.T) {
 
 func (s *AuditLogServiceTestSuite) TestCreate_Success() {
 	// Arrange
-	ctx := context.Background()
+	ctx := tenantContext("tenant1")
 	req := dto.CreateAuditLogRequest{
 		TenantID:     "tenant1",
 		UserID:       "user1",
@@ -57,8 +67,10 @@ func (s *AuditLogServiceTestSuite) TestCreate_Success() {
 		Timestamp:    time.Now(),
 	}
 
-	s.mockAuditLog.On("Create", ctx, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
-	s.mockSQS.On("SendIndexMessage", ctx, mock.AnythingOfType("*domain.AuditLog")).Return(nil)
+	s.mockAuditLog.On("GetChainHead", ctx, "tenant1").Return(&domain.ChainHead{TenantID: "tenant1"}, nil)
+	s.mockAuditLog.On("Create", ctx, mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return log.ChainSeq == 1 && log.PrevHash == "" && log.Hash == domain.ComputeHash(log)
+	})).Return(nil)
 	s.mockBroadcaster.On("BroadcastLog", mock.AnythingOfType("*dto.AuditLogResponse")).Return()
 
 	// Act
@@ -67,13 +79,99 @@ func (s *AuditLogServiceTestSuite) TestCreate_Success() {
 	// Assert
 	s.NoError(err)
 	s.mockAuditLog.AssertExpectations(s.T())
-	s.mockSQS.AssertExpectations(s.T())
+	s.mockQueue.AssertExpectations(s.T())
 	s.mockBroadcaster.AssertExpectations(s.T())
 }
 
-func (s *AuditLogServiceTestSuite) TestBulkCreate_Success() {
+func (s *AuditLogServiceTestSuite) TestCreate_StoresChangedFields() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateAuditLogRequest{
+		TenantID:    "tenant1",
+		Action:      "UPDATE",
+		Severity:    "INFO",
+		BeforeState: json.RawMessage(`{"email": "a@example.com", "profile": {"city": "Berlin"}, "roles": ["user"]}`),
+		AfterState:  json.RawMessage(`{"email": "a@example.com", "profile": {"city": "Hamburg"}, "roles": ["user", "admin"]}`),
+		Timestamp:   time.Now(),
+	}
+
+	s.mockAuditLog.On("GetChainHead", ctx, "tenant1").Return(&domain.ChainHead{TenantID: "tenant1"}, nil)
+	s.mockAuditLog.On("Create", ctx, mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return s.Equal([]string{"profile", "profile.city", "roles"}, log.ChangedFields)
+	})).Return(nil)
+	s.mockBroadcaster.On("BroadcastLog", mock.AnythingOfType("*dto.AuditLogResponse")).Return()
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetByID_ReturnsDiffOfStates() {
 	// Arrange
 	ctx := context.Background()
+	log := &domain.AuditLog{
+		ID:          "log1",
+		TenantID:    "tenant1",
+		Action:      "UPDATE",
+		BeforeState: json.RawMessage(`{"name": "Ann", "age": 30, "tags": ["a", "b"], "address": {"city": "Berlin", "zip": "10115"}}`),
+		AfterState:  json.RawMessage(`{"name": "Ann", "age": 30.0, "tags": ["a"], "address": {"city": "Hamburg"}, "email": "ann@example.com"}`),
+	}
+	s.mockAuditLog.On("GetByID", ctx, "log1").Return(log, nil)
+
+	// Act
+	result, err := s.service.GetByID(ctx, "log1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]dto.FieldChangeResponse{
+		{Path: "address.city", Type: "changed", OldValue: json.RawMessage(`"Berlin"`), NewValue: json.RawMessage(`"Hamburg"`)},
+		{Path: "address.zip", Type: "removed", OldValue: json.RawMessage(`"10115"`)},
+		{Path: "email", Type: "added", NewValue: json.RawMessage(`"ann@example.com"`)},
+		{Path: "tags.1", Type: "removed", OldValue: json.RawMessage(`"b"`)},
+	}, result.Diff)
+}
+
+func (s *AuditLogServiceTestSuite) TestCreate_RejectsOtherTenant() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant2",
+		Action:    "create",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.ErrorIs(err, ErrTenantMismatch)
+	s.mockAuditLog.AssertNotCalled(s.T(), "GetChainHead", mock.Anything, mock.Anything)
+	s.mockAuditLog.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_RejectsOtherTenant() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	reqs := []dto.CreateAuditLogRequest{
+		{TenantID: "tenant1", Action: "create", Severity: "info", Timestamp: time.Now()},
+		{TenantID: "tenant2", Action: "create", Severity: "info", Timestamp: time.Now()},
+	}
+
+	// Act
+	err := s.service.BulkCreate(ctx, reqs)
+
+	// Assert
+	s.ErrorIs(err, ErrTenantMismatch)
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestBulkCreate_Success() {
+	// Arrange
+	ctx := tenantContext("tenant1")
 	reqs := []dto.CreateAuditLogRequest{
 		{
 			TenantID:     "tenant1",
@@ -97,8 +195,12 @@ func (s *AuditLogServiceTestSuite) TestBulkCreate_Success() {
 		},
 	}
 
-	s.mockAuditLog.On("BulkCreate", ctx, mock.AnythingOfType("[]domain.AuditLog")).Return(nil)
-	s.mockSQS.On("SendBulkIndexMessage", ctx, mock.AnythingOfType("[]domain.AuditLog")).Return(nil)
+	s.mockAuditLog.On("GetChainHead", ctx, "tenant1").Return(&domain.ChainHead{TenantID: "tenant1", LastSeq: 7, LastHash: "head"}, nil)
+	s.mockAuditLog.On("BulkCreate", ctx, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 2 &&
+			logs[0].ChainSeq == 8 && logs[0].PrevHash == "head" &&
+			logs[1].ChainSeq == 9 && logs[1].PrevHash == logs[0].Hash
+	})).Return(nil)
 	s.mockBroadcaster.On("BroadcastLog", mock.AnythingOfType("*dto.AuditLogResponse")).Return().Times(2)
 
 	// Act
@@ -107,10 +209,85 @@ func (s *AuditLogServiceTestSuite) TestBulkCreate_Success() {
 	// Assert
 	s.NoError(err)
 	s.mockAuditLog.AssertExpectations(s.T())
-	s.mockSQS.AssertExpectations(s.T())
+	s.mockQueue.AssertExpectations(s.T())
 	s.mockBroadcaster.AssertExpectations(s.T())
 }
 
+func (s *AuditLogServiceTestSuite) TestCreate_RetriesOnChainConflict() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateAuditLogRequest{
+		TenantID:  "tenant1",
+		Action:    "create",
+		Severity:  "info",
+		Timestamp: time.Now(),
+	}
+
+	s.mockAuditLog.On("GetChainHead", ctx, "tenant1").Return(&domain.ChainHead{TenantID: "tenant1"}, nil).Once()
+	s.mockAuditLog.On("GetChainHead", ctx, "tenant1").Return(&domain.ChainHead{TenantID: "tenant1", LastSeq: 1, LastHash: "moved"}, nil).Once()
+	s.mockAuditLog.On("Create", ctx, mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return log.ChainSeq == 1
+	})).Return(domain.ErrChainConflict).Once()
+	s.mockAuditLog.On("Create", ctx, mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return log.ChainSeq == 2 && log.PrevHash == "moved"
+	})).Return(nil).Once()
+	s.mockBroadcaster.On("BroadcastLog", mock.AnythingOfType("*dto.AuditLogResponse")).Return()
+
+	// Act
+	err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestVerifyChain_Valid() {
+	// Arrange
+	ctx := context.Background()
+	start, end := time.Now().Add(-time.Hour), time.Now()
+	logs := sealedLogs(3)
+	// JSONB hands documents back with sorted keys and without whitespace
+	logs[2].Metadata = json.RawMessage(`{"a":[true,null],"b":1}`)
+
+	s.mockAuditLog.On("GetChainBounds", ctx, "tenant1", start, end).Return(int64(2), int64(3), nil)
+	s.mockAuditLog.On("ListChain", ctx, "tenant1", int64(1), int64(1), 1).Return(logs[:1], nil)
+	s.mockAuditLog.On("ListChain", ctx, "tenant1", int64(2), int64(3), chainVerifyBatchSize).Return(logs[1:], nil)
+
+	// Act
+	result, err := s.service.VerifyChain(ctx, "tenant1", start, end)
+
+	// Assert
+	s.NoError(err)
+	s.True(result.Valid)
+	s.True(result.Anchored)
+	s.Equal(int64(2), result.CheckedLogs)
+	s.Equal(logs[0].Hash, result.AnchorPrevHash)
+	s.Equal(logs[2].Hash, result.LastHash)
+	s.Nil(result.FirstBrokenLink)
+}
+
+func (s *AuditLogServiceTestSuite) TestVerifyChain_ReportsFirstBrokenLink() {
+	// Arrange
+	ctx := context.Background()
+	start, end := time.Now().Add(-time.Hour), time.Now()
+	logs := sealedLogs(3)
+	logs[1].Message = "tampered"
+
+	s.mockAuditLog.On("GetChainBounds", ctx, "tenant1", start, end).Return(int64(1), int64(3), nil)
+	s.mockAuditLog.On("ListChain", ctx, "tenant1", int64(1), int64(3), chainVerifyBatchSize).Return(logs, nil)
+
+	// Act
+	result, err := s.service.VerifyChain(ctx, "tenant1", start, end)
+
+	// Assert
+	s.NoError(err)
+	s.False(result.Valid)
+	s.Equal(int64(1), result.CheckedLogs)
+	s.Require().NotNil(result.FirstBrokenLink)
+	s.Equal(logs[1].ID, result.FirstBrokenLink.LogID)
+	s.Equal(domain.ChainBreakHashMismatch, result.FirstBrokenLink.Reason)
+}
+
 func (s *AuditLogServiceTestSuite) TestList_WithSearchCriteria_UsesOpenSearch() {
 	// Arrange
 	ctx := context.Background()
@@ -132,16 +309,18 @@ func (s *AuditLogServiceTestSuite) TestList_WithSearchCriteria_UsesOpenSearch()
 		},
 	}
 
-	s.mockOpenSearch.On("Search", ctx, filter).Return(expectedLogs, nil)
+	s.mockOpenSearch.On("Search", ctx, filter).Return(expectedLogs, int64(1), nil)
 
 	// Act
 	result, err := s.service.List(ctx, filter, true)
 
 	// Assert
 	s.NoError(err)
-	s.Len(result, 1)
-	s.Equal(expectedLogs[0].ID, result[0].ID)
-	s.Equal(expectedLogs[0].UserID, result[0].UserID)
+	s.Len(result.Items, 1)
+	s.Equal(expectedLogs[0].ID, result.Items[0].ID)
+	s.Equal(expectedLogs[0].UserID, result.Items[0].UserID)
+	s.Equal(int64(1), result.ApproximateTotal)
+	s.Empty(result.NextCursor)
 	s.mockOpenSearch.AssertExpectations(s.T())
 }
 
@@ -165,14 +344,298 @@ func (s *AuditLogServiceTestSuite) TestList_WithoutSearchCriteria_UsesPostgres()
 	}
 
 	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(expectedLogs, nil)
+	s.mockAuditLog.On("EstimateCount", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(int64(1), nil)
 
 	// Act
 	result, err := s.service.List(ctx, filter, true)
 
 	// Assert
 	s.NoError(err)
-	s.Len(result, 1)
-	s.Equal(expectedLogs[0].ID, result[0].ID)
-	s.Equal(expectedLogs[0].UserID, result[0].UserID)
+	s.Len(result.Items, 1)
+	s.Equal(expectedLogs[0].ID, result.Items[0].ID)
+	s.Equal(expectedLogs[0].UserID, result.Items[0].UserID)
+	s.Equal(int64(1), result.ApproximateTotal)
 	s.mockAuditLog.AssertExpectations(s.T())
 }
+
+func (s *AuditLogServiceTestSuite) TestList_JSONFilterOnIndexedPath_UsesOpenSearch() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID: "tenant1",
+		JSONFilters: []domain.JSONFilter{
+			{Field: "metadata", Path: []string{"request_id"}, Op: domain.JSONFilterEq, Value: "abc"},
+		},
+		Page:     1,
+		PageSize: 10,
+	}
+	tenant := &domain.Tenant{
+		ID:           "tenant1",
+		IndexedPaths: []domain.IndexedPath{{Path: "metadata.request_id", Type: domain.IndexedPathKeyword}},
+	}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockOpenSearch.On("Search", ctx, filter).Return([]domain.AuditLog{{ID: "1", TenantID: "tenant1"}}, int64(1), nil)
+
+	// Act
+	result, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result.Items, 1)
+	s.mockOpenSearch.AssertExpectations(s.T())
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestList_JSONFilterOnUnindexedPath_UsesPostgres() {
+	// Arrange
+	ctx := context.Background()
+	query, err := domain.ParseLogQuery("severity:ERROR after_state.status:suspended")
+	s.Require().NoError(err)
+	filter := &domain.AuditLogFilter{
+		TenantID: "tenant1",
+		Query:    query,
+		JSONFilters: []domain.JSONFilter{
+			{Field: "metadata", Path: []string{"count"}, Op: domain.JSONFilterGte, Value: "10"},
+		},
+		Page:     1,
+		PageSize: 10,
+	}
+	// The count is indexed as a number, the status not at all
+	tenant := &domain.Tenant{
+		ID:           "tenant1",
+		IndexedPaths: []domain.IndexedPath{{Path: "metadata.count", Type: domain.IndexedPathLong}},
+	}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(tenant, nil)
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return([]domain.AuditLog{{ID: "1", TenantID: "tenant1"}}, nil)
+	s.mockAuditLog.On("EstimateCount", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(int64(1), nil)
+
+	// Act
+	result, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result.Items, 1)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOpenSearch.AssertNotCalled(s.T(), "Search", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestList_ChangedField_UsesOpenSearch() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID:     "tenant1",
+		ChangedField: "role",
+		Page:         1,
+		PageSize:     10,
+	}
+	s.mockOpenSearch.On("Search", ctx, filter).Return([]domain.AuditLog{{ID: "1", TenantID: "tenant1", ChangedFields: []string{"role"}}}, int64(1), nil)
+
+	// Act
+	result, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result.Items, 1)
+	s.Equal([]string{"role"}, result.Items[0].ChangedFields)
+	s.mockOpenSearch.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestList_ReturnsNextCursorWhenMoreLogsExist() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		PageSize: 2,
+	}
+
+	now := time.Now().UTC()
+	expectedLogs := []domain.AuditLog{
+		{ID: "3", TenantID: "tenant1", Timestamp: now},
+		{ID: "2", TenantID: "tenant1", Timestamp: now.Add(-time.Second)},
+		{ID: "1", TenantID: "tenant1", Timestamp: now.Add(-2 * time.Second)},
+	}
+
+	s.mockAuditLog.On("List", ctx, mock.MatchedBy(func(f domain.AuditLogFilter) bool {
+		return f.Limit == 3
+	})).Return(expectedLogs, nil)
+	s.mockAuditLog.On("EstimateCount", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(int64(3), nil)
+
+	// Act
+	result, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Len(result.Items, 2)
+	s.Require().NotEmpty(result.NextCursor)
+
+	cursor, err := domain.DecodeLogCursor(result.NextCursor)
+	s.NoError(err)
+	s.Equal("2", cursor.ID)
+	s.True(cursor.Timestamp.Equal(expectedLogs[1].Timestamp))
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestList_IncludeArchived_MergesArchivedLogsOnce() {
+	// Arrange
+	ctx := context.Background()
+	mockArchives := new(mocks.ArchiveStorage)
+	s.service.SetArchiveStorage(mockArchives)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	filter := &domain.AuditLogFilter{
+		TenantID:        "tenant1",
+		StartTime:       day(1),
+		EndTime:         day(31),
+		PageSize:        10,
+		IncludeArchived: true,
+	}
+
+	liveLogs := []domain.AuditLog{
+		{ID: "4", TenantID: "tenant1", Timestamp: day(12)},
+		{ID: "3", TenantID: "tenant1", Timestamp: day(8)},
+	}
+	archive, err := json.Marshal(domain.Archive{
+		TenantID: "tenant1",
+		Logs: []domain.AuditLog{
+			{ID: "0", TenantID: "tenant1", Timestamp: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)},
+			{ID: "2", TenantID: "tenant1", Timestamp: day(5)},
+			{ID: "3", TenantID: "tenant1", Timestamp: day(8)},
+		},
+	})
+	s.Require().NoError(err)
+
+	currentKey := domain.ArchiveKey("tenant1", day(10))
+	mockArchives.On("List", ctx, "audit-logs/tenant1/").Return([]domain.ArchiveObject{
+		{Key: domain.ArchiveKey("tenant1", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))},
+		{Key: currentKey},
+	}, nil)
+	mockArchives.On("Download", ctx, currentKey).Return(io.NopCloser(bytes.NewReader(archive)), nil)
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(liveLogs, nil)
+	s.mockAuditLog.On("EstimateCount", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(int64(2), nil)
+	s.mockAuditLog.On("ExistingIDs", ctx, "tenant1", []string{"2", "3"}, day(5), day(8)).Return([]string{"3"}, nil)
+
+	// Act
+	result, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(3), result.ApproximateTotal)
+	s.Require().Len(result.Items, 3)
+	s.Equal("4", result.Items[0].ID)
+	s.Equal(dto.LogSourceLive, result.Items[0].Source)
+	s.Equal("3", result.Items[1].ID)
+	s.Equal(dto.LogSourceLive, result.Items[1].Source)
+	s.Equal("2", result.Items[2].ID)
+	s.Equal(dto.LogSourceArchive, result.Items[2].Source)
+	s.Empty(result.NextCursor)
+	mockArchives.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestList_IncludeArchived_AppliesQueryToArchivedLogs() {
+	// Arrange
+	ctx := context.Background()
+	mockArchives := new(mocks.ArchiveStorage)
+	s.service.SetArchiveStorage(mockArchives)
+
+	query, err := domain.ParseLogQuery(`severity:(ERROR OR CRITICAL) AND NOT user_id:svc-* AND metadata.region:eu`)
+	s.Require().NoError(err)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	filter := &domain.AuditLogFilter{
+		TenantID:        "tenant1",
+		StartTime:       day(1),
+		EndTime:         day(31),
+		PageSize:        10,
+		IncludeArchived: true,
+		Query:           query,
+	}
+
+	archive, err := json.Marshal(domain.Archive{
+		TenantID: "tenant1",
+		Logs: []domain.AuditLog{
+			{ID: "1", TenantID: "tenant1", UserID: "alice", Severity: "ERROR", Metadata: json.RawMessage(`{"region": "eu"}`), Timestamp: day(1)},
+			{ID: "2", TenantID: "tenant1", UserID: "svc-backup", Severity: "ERROR", Metadata: json.RawMessage(`{"region": "eu"}`), Timestamp: day(2)},
+			{ID: "3", TenantID: "tenant1", UserID: "alice", Severity: "INFO", Metadata: json.RawMessage(`{"region": "eu"}`), Timestamp: day(3)},
+			{ID: "4", TenantID: "tenant1", UserID: "bob", Severity: "CRITICAL", Metadata: json.RawMessage(`{"region": "us"}`), Timestamp: day(4)},
+		},
+	})
+	s.Require().NoError(err)
+
+	key := domain.ArchiveKey("tenant1", day(10))
+	mockArchives.On("List", ctx, "audit-logs/tenant1/").Return([]domain.ArchiveObject{{Key: key}}, nil)
+	mockArchives.On("Download", ctx, key).Return(io.NopCloser(bytes.NewReader(archive)), nil)
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{
+		ID:           "tenant1",
+		IndexedPaths: []domain.IndexedPath{{Path: "metadata.region", Type: domain.IndexedPathKeyword}},
+	}, nil)
+	s.mockOpenSearch.On("Search", ctx, mock.AnythingOfType("*domain.AuditLogFilter")).Return([]domain.AuditLog{}, int64(0), nil)
+	s.mockAuditLog.On("ExistingIDs", ctx, "tenant1", []string{"1"}, day(1), day(1)).Return([]string{}, nil)
+
+	// Act
+	result, err := s.service.List(ctx, filter, true)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(result.Items, 1)
+	s.Equal("1", result.Items[0].ID)
+	s.Equal(dto.LogSourceArchive, result.Items[0].Source)
+	s.mockOpenSearch.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestExport_StreamsInBatches() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1"}
+
+	now := time.Now().UTC()
+	firstBatch := make([]domain.AuditLog, exportBatchSize)
+	for i := range firstBatch {
+		firstBatch[i] = domain.AuditLog{ID: fmt.Sprintf("log-%d", i), Timestamp: now.Add(-time.Duration(i) * time.Second)}
+	}
+	secondBatch := []domain.AuditLog{{ID: "log-last", Timestamp: now.Add(-time.Hour)}}
+	last := firstBatch[len(firstBatch)-1]
+
+	s.mockAuditLog.On("List", ctx, mock.MatchedBy(func(f domain.AuditLogFilter) bool {
+		return f.After == nil && f.Limit == exportBatchSize
+	})).Return(firstBatch, nil).Once()
+	s.mockAuditLog.On("List", ctx, mock.MatchedBy(func(f domain.AuditLogFilter) bool {
+		return f.After != nil && f.After.ID == last.ID && f.After.Timestamp.Equal(last.Timestamp)
+	})).Return(secondBatch, nil).Once()
+
+	var exported int
+
+	// Act
+	err := s.service.Export(ctx, filter, func(logs []domain.AuditLog) error {
+		exported += len(logs)
+		return nil
+	})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(exportBatchSize+1, exported)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func tenantContext(tenantID string) context.Context {
+	return context.WithValue(context.Background(), string(utils.ClaimsKey), jwt.MapClaims{
+		string(utils.TenantIDKey): tenantID,
+	})
+}
+
+func sealedLogs(n int) []domain.AuditLog {
+	logs := make([]domain.AuditLog, n)
+	for i := range logs {
+		logs[i] = domain.AuditLog{
+			ID:        fmt.Sprintf("log%d", i+1),
+			TenantID:  "tenant1",
+			Action:    "create",
+			Message:   "Test message",
+			Severity:  "info",
+			Metadata:  json.RawMessage(`{"b": 1, "a": [true, null]}`),
+			Timestamp: time.Now(),
+		}
+	}
+	domain.SealChain(domain.ChainHead{TenantID: "tenant1"}, logs)
+	return logs
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+)
+
+const (
+	// cleanupBatchSize is the number of logs deleted per transaction where
+	// whole chunks cannot be dropped
+	cleanupBatchSize = 5000
+
+	// cleanupLease is how long a running cleanup is left to its worker after
+	// its last progress, long enough to delete the logs from the search index.
+	// Messages redelivered within the lease are dropped.
+	cleanupLease = 15 * time.Minute
+)
+
+// errCleanupRunLost stops a cleanup that was taken over by another run
+var errCleanupRunLost = errors.New("cleanup was taken over by another run")
+
+// tenantRetention is what a cleanup may delete of the logs of a tenant
+type tenantRetention struct {
+	deleteBefore time.Time
+	exclusions   domain.CleanupExclusions
+	expires      bool
+}
+
+type CleanupService struct {
+	repo repository.Repository
+}
+
+func NewCleanupService(repo repository.Repository) *CleanupService {
+	return &CleanupService{repo: repo}
+}
+
+// Run deletes the logs of the tenant before the date, within its retention
+// policy and except for the logs under a legal hold. Chunks of the hypertable
+// whose logs are past the retention of every tenant are dropped as a whole,
+// the logs of the tenant in any other chunk are deleted in batches. Progress
+// is recorded after every step and handed to onProgress, and a cleanup
+// interrupted before the same date resumes from the last completed chunk.
+// Only one cleanup of a tenant runs at a time, Run returns nil progress when
+// the policy never deletes logs or another cleanup of the tenant is running.
+func (s *CleanupService) Run(ctx context.Context, tenantID string, beforeDate time.Time, onProgress func(*domain.RetentionProgress)) (*domain.RetentionProgress, error) {
+	retention, err := s.retention(ctx, tenantID, beforeDate)
+	if err != nil {
+		return nil, err
+	}
+	if !retention.expires {
+		return nil, nil
+	}
+	beforeDate = retention.deleteBefore
+
+	progress, err := s.repo.RetentionProgress().Get(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		progress = &domain.RetentionProgress{TenantID: tenantID}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get retention progress: %w", err)
+	}
+	if progress.CleanupStartedAt != nil && time.Since(progress.UpdatedAt) < cleanupLease {
+		return nil, nil
+	}
+
+	var resumeFrom time.Time
+	if progress.Resumes(beforeDate) {
+		resumeFrom = *progress.CleanedUpTo
+	} else {
+		progress.CleanupBefore = &beforeDate
+		progress.CleanedUpTo = nil
+		progress.DeletedLogs, progress.HeldLogs, progress.DroppedChunks = 0, 0, 0
+	}
+
+	// Claim the cleanup, a run that claimed it meanwhile keeps it. The start
+	// is stored with microsecond precision, it must compare equal when read back.
+	previousRun := progress.CleanupStartedAt
+	startedAt := time.Now().Truncate(time.Microsecond)
+	progress.CleanupStartedAt = &startedAt
+	claimed, err := s.repo.RetentionProgress().SaveCleanup(ctx, progress, previousRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim cleanup: %w", err)
+	}
+	if !claimed {
+		return nil, nil
+	}
+
+	err = s.cleanUp(ctx, tenantID, beforeDate, resumeFrom, retention, progress, onProgress)
+	if errors.Is(err, errCleanupRunLost) {
+		return nil, nil
+	}
+
+	// Release the cleanup, a failed one is resumed when its message is retried
+	progress.CleanupStartedAt = nil
+	released, releaseErr := s.repo.RetentionProgress().SaveCleanup(ctx, progress, &startedAt)
+	if err != nil {
+		if releaseErr != nil {
+			fmt.Printf("failed to release cleanup of tenant %s: %v\n", tenantID, releaseErr)
+		}
+		return nil, err
+	}
+	if releaseErr != nil {
+		return nil, fmt.Errorf("failed to save retention progress: %w", releaseErr)
+	}
+	if !released {
+		return nil, nil
+	}
+
+	return progress, nil
+}
+
+// cleanUp deletes the logs of the tenant from resumeFrom up to the date
+func (s *CleanupService) cleanUp(ctx context.Context, tenantID string, beforeDate, resumeFrom time.Time, retention *tenantRetention, progress *domain.RetentionProgress, onProgress func(*domain.RetentionProgress)) error {
+	chunks, err := s.repo.AuditLog().ListChunks(ctx, beforeDate)
+	if err != nil {
+		return fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	// The retention of the other tenants is only loaded for the chunks it
+	// decides on
+	others := map[string]*tenantRetention{tenantID: retention}
+	edgeStart := resumeFrom
+	for _, chunk := range chunks {
+		if !chunk.RangeEnd.After(resumeFrom) {
+			continue
+		}
+
+		dropped, err := s.dropChunk(ctx, chunk, tenantID, others, progress)
+		if err != nil {
+			return err
+		}
+		if !dropped {
+			if err := s.deleteRange(ctx, chunk.RangeStart, chunk.RangeEnd, retention, progress, onProgress); err != nil {
+				return err
+			}
+		}
+
+		cleanedUpTo := chunk.RangeEnd
+		progress.CleanedUpTo = &cleanedUpTo
+		if err := s.saveProgress(ctx, progress, onProgress); err != nil {
+			return err
+		}
+		edgeStart = chunk.RangeEnd
+	}
+
+	// The chunk the date falls into also holds logs to keep
+	if err := s.deleteRange(ctx, edgeStart, beforeDate, retention, progress, onProgress); err != nil {
+		return err
+	}
+	progress.CleanedUpTo = &beforeDate
+	if err := s.saveProgress(ctx, progress, onProgress); err != nil {
+		return err
+	}
+
+	// Remove the same logs from the search index. A cleanup run again finds
+	// nothing left to delete in PostgreSQL and retries this step.
+	if _, err := s.repo.OpenSearch().DeleteBeforeDate(ctx, tenantID, beforeDate, retention.exclusions); err != nil {
+		return fmt.Errorf("failed to delete indexed logs: %w", err)
+	}
+	return nil
+}
+
+// retention returns what a cleanup before the date may delete of the logs of
+// the tenant. The retention policy of the tenant takes precedence over the
+// date and may keep logs of some severities forever.
+func (s *CleanupService) retention(ctx context.Context, tenantID string, beforeDate time.Time) (*tenantRetention, error) {
+	retention := &tenantRetention{deleteBefore: beforeDate, expires: true}
+
+	policy, err := s.repo.RetentionPolicy().Get(ctx, tenantID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	case policy.Enabled:
+		retention.deleteBefore, retention.expires = policy.DeleteBefore(beforeDate, time.Now())
+		retention.exclusions.KeepSeverities = policy.KeepSeverities
+	}
+
+	// Logs under a legal hold are kept whatever the retention
+	retention.exclusions.Holds, err = s.repo.LegalHold().ListActive(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal holds: %w", err)
+	}
+
+	return retention, nil
+}
+
+// otherRetention returns what may be deleted of the logs of a tenant other
+// than the one being cleaned up. Only logs that are archived and past an
+// enabled retention policy may be deleted.
+func (s *CleanupService) otherRetention(ctx context.Context, tenantID string) (*tenantRetention, error) {
+	progress, err := s.repo.RetentionProgress().Get(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && progress.ArchivedBefore == nil) {
+		return &tenantRetention{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention progress of tenant %s: %w", tenantID, err)
+	}
+
+	policy, err := s.repo.RetentionPolicy().Get(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !policy.Enabled) {
+		return &tenantRetention{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policy of tenant %s: %w", tenantID, err)
+	}
+
+	retention := &tenantRetention{}
+	retention.deleteBefore, retention.expires = policy.DeleteBefore(*progress.ArchivedBefore, time.Now())
+	retention.exclusions.KeepSeverities = policy.KeepSeverities
+	retention.exclusions.Holds, err = s.repo.LegalHold().ListActive(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal holds of tenant %s: %w", tenantID, err)
+	}
+	return retention, nil
+}
+
+// dropChunk drops the chunk when every log in it may be deleted. The logs of
+// the tenant being cleaned up are counted as deleted, the logs of the other
+// tenants are deleted from the search index here.
+func (s *CleanupService) dropChunk(ctx context.Context, chunk domain.LogChunk, tenantID string, retentions map[string]*tenantRetention, progress *domain.RetentionProgress) (bool, error) {
+	counts, err := s.repo.AuditLog().CountByTenant(ctx, chunk.RangeStart, chunk.RangeEnd)
+	if err != nil {
+		return false, fmt.Errorf("failed to count logs of chunk %s: %w", chunk.Name, err)
+	}
+
+	var total int64
+	for id, count := range counts {
+		total += count
+
+		retention, ok := retentions[id]
+		if !ok {
+			if retention, err = s.otherRetention(ctx, id); err != nil {
+				return false, err
+			}
+			retentions[id] = retention
+		}
+		if !retention.expires || chunk.RangeEnd.After(retention.deleteBefore) {
+			return false, nil
+		}
+
+		excluded, err := s.repo.AuditLog().CountExcluded(ctx, id, chunk.RangeStart, chunk.RangeEnd, retention.exclusions)
+		if err != nil {
+			return false, fmt.Errorf("failed to count kept logs of chunk %s: %w", chunk.Name, err)
+		}
+		if excluded > 0 {
+			return false, nil
+		}
+	}
+
+	dropped, err := s.repo.AuditLog().DropChunk(ctx, chunk, total)
+	if err != nil {
+		return false, fmt.Errorf("failed to drop chunk %s: %w", chunk.Name, err)
+	}
+	if !dropped {
+		return false, nil
+	}
+	progress.DeletedLogs += counts[tenantID]
+	progress.DroppedChunks++
+
+	// Dropped chunks leave their rows in the hourly stats until refreshed
+	if err := s.repo.AuditLog().RefreshStats(ctx, chunk.RangeStart, chunk.RangeEnd); err != nil {
+		return false, fmt.Errorf("failed to refresh stats of chunk %s: %w", chunk.Name, err)
+	}
+
+	// The cleaned up tenant's logs are deleted from the search index once the
+	// cleanup completes. Indexed logs of another tenant left by a failure here
+	// are deleted by the next cleanup of that tenant.
+	for id := range counts {
+		if id == tenantID {
+			continue
+		}
+		if _, err := s.repo.OpenSearch().DeleteRange(ctx, id, chunk.RangeStart, chunk.RangeEnd); err != nil {
+			return false, fmt.Errorf("failed to delete indexed logs of chunk %s: %w", chunk.Name, err)
+		}
+	}
+	return true, nil
+}
+
+// deleteRange deletes the logs of the tenant between startTime and endTime in
+// batches, recording the progress after each batch
+func (s *CleanupService) deleteRange(ctx context.Context, startTime, endTime time.Time, retention *tenantRetention, progress *domain.RetentionProgress, onProgress func(*domain.RetentionProgress)) error {
+	if len(retention.exclusions.Holds) > 0 {
+		held, err := s.repo.AuditLog().CountExcluded(ctx, progress.TenantID, startTime, endTime, domain.CleanupExclusions{Holds: retention.exclusions.Holds})
+		if err != nil {
+			return fmt.Errorf("failed to count held logs: %w", err)
+		}
+		progress.HeldLogs += held
+	}
+
+	for {
+		deleted, err := s.repo.AuditLog().DeleteBatch(ctx, progress.TenantID, startTime, endTime, retention.exclusions, cleanupBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to delete logs: %w", err)
+		}
+		if deleted == 0 {
+			return nil
+		}
+
+		progress.DeletedLogs += deleted
+		if err := s.saveProgress(ctx, progress, onProgress); err != nil {
+			return err
+		}
+		if deleted < cleanupBatchSize {
+			return nil
+		}
+	}
+}
+
+// saveProgress records the progress of the running cleanup, it fails with
+// errCleanupRunLost when another run took the cleanup over
+func (s *CleanupService) saveProgress(ctx context.Context, progress *domain.RetentionProgress, onProgress func(*domain.RetentionProgress)) error {
+	saved, err := s.repo.RetentionProgress().SaveCleanup(ctx, progress, progress.CleanupStartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save retention progress: %w", err)
+	}
+	if !saved {
+		return errCleanupRunLost
+	}
+	if onProgress != nil {
+		onProgress(progress)
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type CleanupServiceTestSuite struct {
+	suite.Suite
+	mockRepo       *mocks.Repository
+	mockAuditLog   *mocks.AuditLogRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	mockPolicy     *mocks.RetentionPolicyRepository
+	mockHold       *mocks.LegalHoldRepository
+	mockProgress   *mocks.RetentionProgressRepository
+	service        *CleanupService
+}
+
+func (s *CleanupServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOpenSearch = new(mocks.OpenSearchRepository)
+	s.mockPolicy = new(mocks.RetentionPolicyRepository)
+	s.mockHold = new(mocks.LegalHoldRepository)
+	s.mockProgress = new(mocks.RetentionProgressRepository)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+	s.mockRepo.On("RetentionPolicy").Return(s.mockPolicy)
+	s.mockRepo.On("LegalHold").Return(s.mockHold)
+	s.mockRepo.On("RetentionProgress").Return(s.mockProgress)
+
+	s.service = NewCleanupService(s.mockRepo)
+}
+
+func TestCleanupService(t *testing.T) {
+	suite.Run(t, new(CleanupServiceTestSuite))
+}
+
+// chunk returns the daily chunk starting on the date
+func chunk(name string, start time.Time) domain.LogChunk {
+	return domain.LogChunk{Schema: "_timescaledb_internal", Name: name, RangeStart: start, RangeEnd: start.AddDate(0, 0, 1)}
+}
+
+func (s *CleanupServiceTestSuite) TestRun_DropsExpiredChunkAndDeletesEdge() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	c1 := chunk("_hyper_1_1_chunk", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	archivedBefore := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockProgress.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{c1}, nil)
+	s.mockAuditLog.On("CountByTenant", ctx, c1.RangeStart, c1.RangeEnd).
+		Return(map[string]int64{"tenant1": 10, "tenant2": 5}, nil)
+
+	// The other tenant has archived and is past its retention
+	s.mockProgress.On("Get", ctx, "tenant2").Return(&domain.RetentionProgress{TenantID: "tenant2", ArchivedBefore: &archivedBefore}, nil)
+	s.mockPolicy.On("Get", ctx, "tenant2").Return(&domain.RetentionPolicy{TenantID: "tenant2", ArchiveAfterDays: 30, DeleteAfterDays: 90, Enabled: true}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant2").Return(nil, nil)
+	s.mockAuditLog.On("CountExcluded", ctx, mock.Anything, c1.RangeStart, c1.RangeEnd, mock.Anything).Return(int64(0), nil)
+
+	s.mockAuditLog.On("DropChunk", ctx, c1, int64(15)).Return(true, nil)
+	s.mockAuditLog.On("RefreshStats", ctx, c1.RangeStart, c1.RangeEnd).Return(nil)
+	s.mockOpenSearch.On("DeleteRange", ctx, "tenant2", c1.RangeStart, c1.RangeEnd).Return(int64(5), nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", c1.RangeEnd, before, mock.Anything, cleanupBatchSize).Return(int64(3), nil)
+	s.mockProgress.On("SaveCleanup", ctx, mock.Anything, mock.Anything).Return(true, nil)
+	s.mockOpenSearch.On("DeleteBeforeDate", ctx, "tenant1", before, mock.Anything).Return(int64(13), nil)
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", before, nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(13), progress.DeletedLogs)
+	s.Equal(1, progress.DroppedChunks)
+	s.True(progress.CleanedUpTo.Equal(before))
+	s.Nil(progress.CleanupStartedAt)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOpenSearch.AssertExpectations(s.T())
+	s.mockOpenSearch.AssertNotCalled(s.T(), "DeleteRange", ctx, "tenant1", mock.Anything, mock.Anything)
+}
+
+func (s *CleanupServiceTestSuite) TestRun_DeletesInBatchesWhenAnotherTenantKeepsLogs() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	c1 := chunk("_hyper_1_1_chunk", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockProgress.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{c1}, nil)
+	s.mockAuditLog.On("CountByTenant", ctx, c1.RangeStart, c1.RangeEnd).
+		Return(map[string]int64{"tenant1": 7000, "tenant2": 5}, nil)
+	s.mockAuditLog.On("CountExcluded", ctx, "tenant1", c1.RangeStart, c1.RangeEnd, mock.Anything).Return(int64(0), nil)
+
+	// The other tenant never archived, its logs are kept
+	s.mockProgress.On("Get", ctx, "tenant2").Return(nil, gorm.ErrRecordNotFound)
+
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", c1.RangeStart, c1.RangeEnd, mock.Anything, cleanupBatchSize).
+		Return(int64(cleanupBatchSize), nil).Once()
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", c1.RangeStart, c1.RangeEnd, mock.Anything, cleanupBatchSize).
+		Return(int64(2000), nil).Once()
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", c1.RangeEnd, before, mock.Anything, cleanupBatchSize).Return(int64(0), nil)
+	s.mockProgress.On("SaveCleanup", ctx, mock.Anything, mock.Anything).Return(true, nil)
+	s.mockOpenSearch.On("DeleteBeforeDate", ctx, "tenant1", before, mock.Anything).Return(int64(7000), nil)
+
+	var reports int
+	onProgress := func(*domain.RetentionProgress) { reports++ }
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", before, onProgress)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(7000), progress.DeletedLogs)
+	s.Equal(0, progress.DroppedChunks)
+	s.Equal(4, reports)
+	s.mockAuditLog.AssertNotCalled(s.T(), "DropChunk", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CleanupServiceTestSuite) TestRun_ResumesAfterLastCompletedChunk() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	c1 := chunk("_hyper_1_1_chunk", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c2 := chunk("_hyper_1_2_chunk", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	progress := &domain.RetentionProgress{TenantID: "tenant1", CleanupBefore: &before, CleanedUpTo: &c1.RangeEnd, DeletedLogs: 100}
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockProgress.On("Get", ctx, "tenant1").Return(progress, nil)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{c1, c2}, nil)
+	s.mockAuditLog.On("CountByTenant", ctx, c2.RangeStart, c2.RangeEnd).Return(map[string]int64{"tenant1": 10}, nil)
+	s.mockAuditLog.On("CountExcluded", ctx, "tenant1", c2.RangeStart, c2.RangeEnd, mock.Anything).Return(int64(0), nil)
+	s.mockAuditLog.On("DropChunk", ctx, c2, int64(10)).Return(true, nil)
+	s.mockAuditLog.On("RefreshStats", ctx, c2.RangeStart, c2.RangeEnd).Return(nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", c2.RangeEnd, before, mock.Anything, cleanupBatchSize).Return(int64(0), nil)
+	s.mockProgress.On("SaveCleanup", ctx, mock.Anything, mock.Anything).Return(true, nil)
+	s.mockOpenSearch.On("DeleteBeforeDate", ctx, "tenant1", before, mock.Anything).Return(int64(0), nil)
+
+	// Act
+	result, err := s.service.Run(ctx, "tenant1", before, nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(110), result.DeletedLogs)
+	s.mockAuditLog.AssertNotCalled(s.T(), "CountByTenant", ctx, c1.RangeStart, c1.RangeEnd)
+}
+
+func (s *CleanupServiceTestSuite) TestRun_PolicyNeverDeletes() {
+	// Arrange
+	ctx := context.Background()
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(&domain.RetentionPolicy{TenantID: "tenant1", ArchiveAfterDays: 30, Enabled: true}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", time.Now(), nil)
+
+	// Assert
+	s.NoError(err)
+	s.Nil(progress)
+	s.mockAuditLog.AssertNotCalled(s.T(), "ListChunks", mock.Anything, mock.Anything)
+}
+
+// storeProgress keeps the progress of the tenant as the database would, the
+// progress read is a copy and saving it only succeeds for the cleanup that
+// owns it
+func (s *CleanupServiceTestSuite) storeProgress(stored domain.RetentionProgress) *domain.RetentionProgress {
+	s.mockProgress.On("Get", mock.Anything, stored.TenantID).Return(
+		func(context.Context, string) (*domain.RetentionProgress, error) {
+			progress := stored
+			return &progress, nil
+		})
+	s.mockProgress.On("SaveCleanup", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, progress *domain.RetentionProgress, startedAt *time.Time) (bool, error) {
+			owner := stored.CleanupStartedAt
+			if (startedAt == nil) != (owner == nil) || (startedAt != nil && !startedAt.Equal(*owner)) {
+				return false, nil
+			}
+			stored = *progress
+			stored.UpdatedAt = time.Now()
+			return true, nil
+		})
+	return &stored
+}
+
+func (s *CleanupServiceTestSuite) TestRun_SkipsDeliveryDuringRun() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	stored := s.storeProgress(domain.RetentionProgress{TenantID: "tenant1"})
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{}, nil)
+
+	var (
+		redelivered    *domain.RetentionProgress
+		redeliveredErr error
+	)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", time.Time{}, before, mock.Anything, cleanupBatchSize).Run(func(mock.Arguments) {
+		// The message is received again while the logs are deleted
+		redelivered, redeliveredErr = s.service.Run(ctx, "tenant1", before, nil)
+	}).Return(int64(3), nil).Once()
+	s.mockOpenSearch.On("DeleteBeforeDate", ctx, "tenant1", before, mock.Anything).Return(int64(3), nil)
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", before, nil)
+
+	// Assert
+	s.NoError(err)
+	s.NoError(redeliveredErr)
+	s.Nil(redelivered)
+	s.Equal(int64(3), progress.DeletedLogs)
+	s.mockAuditLog.AssertNumberOfCalls(s.T(), "DeleteBatch", 1)
+	s.mockOpenSearch.AssertNumberOfCalls(s.T(), "DeleteBeforeDate", 1)
+	s.Equal(int64(3), stored.DeletedLogs)
+	s.Nil(stored.CleanupStartedAt)
+}
+
+func (s *CleanupServiceTestSuite) TestRun_TakesOverCleanupAfterLease() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	c1 := chunk("_hyper_1_1_chunk", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	previousRun := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	stored := s.storeProgress(domain.RetentionProgress{
+		TenantID:         "tenant1",
+		CleanupBefore:    &before,
+		CleanedUpTo:      &c1.RangeEnd,
+		DeletedLogs:      100,
+		CleanupStartedAt: &previousRun,
+		UpdatedAt:        time.Now().Add(-cleanupLease - time.Minute),
+	})
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{c1}, nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", c1.RangeEnd, before, mock.Anything, cleanupBatchSize).Return(int64(5), nil)
+	s.mockOpenSearch.On("DeleteBeforeDate", ctx, "tenant1", before, mock.Anything).Return(int64(5), nil)
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", before, nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(105), progress.DeletedLogs)
+	s.Equal(int64(105), stored.DeletedLogs)
+	s.Nil(stored.CleanupStartedAt)
+	s.mockAuditLog.AssertNotCalled(s.T(), "CountByTenant", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CleanupServiceTestSuite) TestRun_StopsWhenTakenOver() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	stored := s.storeProgress(domain.RetentionProgress{TenantID: "tenant1"})
+	otherRun := time.Now().Add(time.Minute).Truncate(time.Microsecond)
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{}, nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", time.Time{}, before, mock.Anything, cleanupBatchSize).Run(func(mock.Arguments) {
+		// Another run takes the cleanup over while the logs are deleted
+		stored.CleanupStartedAt = &otherRun
+		stored.DeletedLogs = 0
+	}).Return(int64(3), nil)
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", before, nil)
+
+	// Assert
+	s.NoError(err)
+	s.Nil(progress)
+	s.Zero(stored.DeletedLogs)
+	s.Equal(&otherRun, stored.CleanupStartedAt)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "DeleteBeforeDate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CleanupServiceTestSuite) TestRun_ReleasesCleanupOnFailure() {
+	// Arrange
+	ctx := context.Background()
+	before := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	stored := s.storeProgress(domain.RetentionProgress{TenantID: "tenant1"})
+
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockAuditLog.On("ListChunks", ctx, before).Return([]domain.LogChunk{}, nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", time.Time{}, before, mock.Anything, cleanupBatchSize).
+		Return(int64(0), errors.New("db error"))
+
+	// Act
+	progress, err := s.service.Run(ctx, "tenant1", before, nil)
+
+	// Assert
+	s.ErrorContains(err, "db error")
+	s.Nil(progress)
+	s.Nil(stored.CleanupStartedAt)
+	s.True(stored.CleanupBefore.Equal(before))
+}
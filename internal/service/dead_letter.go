@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+)
+
+const (
+	defaultDeadLetterLimit = 100
+	maxDeadLetterLimit     = 1000
+)
+
+//go:generate mockery --name DeadLetterQueue --output ../mocks
+type DeadLetterQueue interface {
+	ListDeadLetters(ctx context.Context, queue string, limit int) ([]domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, queue, messageID string) (*domain.DeadLetter, error)
+	RedriveDeadLetters(ctx context.Context, queue string, messageIDs []string) (int, error)
+	PurgeDeadLetters(ctx context.Context, queue string) error
+}
+
+type DeadLetterService struct {
+	queue DeadLetterQueue
+}
+
+func NewDeadLetterService(queue DeadLetterQueue) *DeadLetterService {
+	return &DeadLetterService{queue: queue}
+}
+
+// List returns the dead letters of the named queue, up to 100 unless a limit
+// is given
+func (s *DeadLetterService) List(ctx context.Context, queueName string, limit int) ([]dto.DeadLetterResponse, error) {
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+	limit = min(limit, maxDeadLetterLimit)
+
+	letters, err := s.queue.ListDeadLetters(ctx, queueName, limit)
+	if err != nil {
+		return nil, deadLetterError(err)
+	}
+
+	resp := make([]dto.DeadLetterResponse, len(letters))
+	for i := range letters {
+		resp[i] = *dto.FromDeadLetter(&letters[i])
+	}
+	return resp, nil
+}
+
+func (s *DeadLetterService) Get(ctx context.Context, queueName, messageID string) (*dto.DeadLetterResponse, error) {
+	letter, err := s.queue.GetDeadLetter(ctx, queueName, messageID)
+	if err != nil {
+		return nil, deadLetterError(err)
+	}
+	return dto.FromDeadLetter(letter), nil
+}
+
+// Redrive sends the selected dead letters back to the named queue, all of
+// them when no message IDs are given
+func (s *DeadLetterService) Redrive(ctx context.Context, queueName string, req dto.RedriveDeadLettersRequest) (*dto.RedriveDeadLettersResponse, error) {
+	redriven, err := s.queue.RedriveDeadLetters(ctx, queueName, req.MessageIDs)
+	if err != nil {
+		return nil, deadLetterError(err)
+	}
+	return &dto.RedriveDeadLettersResponse{Redriven: redriven}, nil
+}
+
+func (s *DeadLetterService) Purge(ctx context.Context, queueName string) error {
+	if err := s.queue.PurgeDeadLetters(ctx, queueName); err != nil {
+		return deadLetterError(err)
+	}
+	return nil
+}
+
+func deadLetterError(err error) error {
+	switch {
+	case errors.Is(err, queue.ErrUnknownQueue):
+		return ErrUnknownQueue
+	case errors.Is(err, queue.ErrDeadLetterNotFound):
+		return ErrDeadLetterNotFound
+	default:
+		return err
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadLetterServiceTestSuite struct {
+	suite.Suite
+	mockQueue *mocks.DeadLetterQueue
+	service   *DeadLetterService
+}
+
+func (s *DeadLetterServiceTestSuite) SetupTest() {
+	s.mockQueue = new(mocks.DeadLetterQueue)
+	s.service = NewDeadLetterService(s.mockQueue)
+}
+
+func TestDeadLetterService(t *testing.T) {
+	suite.Run(t, new(DeadLetterServiceTestSuite))
+}
+
+func (s *DeadLetterServiceTestSuite) TestList_DefaultsAndCapsLimit() {
+	// Arrange
+	ctx := context.Background()
+	failedAt := time.Date(2025, 7, 17, 21, 20, 48, 0, time.UTC)
+	letters := []domain.DeadLetter{{MessageID: "msg1", Queue: "index", Body: "{}", Reason: "boom", ReceiveCount: 5, FailedAt: failedAt}}
+	s.mockQueue.On("ListDeadLetters", ctx, "index", defaultDeadLetterLimit).Return(letters, nil)
+	s.mockQueue.On("ListDeadLetters", ctx, "index", maxDeadLetterLimit).Return(letters, nil)
+
+	// Act
+	resp, err := s.service.List(ctx, "index", 0)
+	_, capErr := s.service.List(ctx, "index", 5000)
+
+	// Assert
+	s.NoError(err)
+	s.NoError(capErr)
+	s.Len(resp, 1)
+	s.Equal("boom", resp[0].Reason)
+	s.Equal(failedAt, *resp[0].FailedAt)
+	s.Nil(resp[0].SentAt)
+	s.mockQueue.AssertExpectations(s.T())
+}
+
+func (s *DeadLetterServiceTestSuite) TestGet_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockQueue.On("GetDeadLetter", ctx, "index", "missing").Return(nil, queue.ErrDeadLetterNotFound)
+
+	// Act
+	resp, err := s.service.Get(ctx, "index", "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrDeadLetterNotFound)
+	s.Nil(resp)
+}
+
+func (s *DeadLetterServiceTestSuite) TestRedrive_UnknownQueue() {
+	// Arrange
+	ctx := context.Background()
+	s.mockQueue.On("RedriveDeadLetters", ctx, "missing", []string(nil)).
+		Return(0, fmt.Errorf("%w: missing", queue.ErrUnknownQueue))
+
+	// Act
+	resp, err := s.service.Redrive(ctx, "missing", dto.RedriveDeadLettersRequest{})
+
+	// Assert
+	s.ErrorIs(err, ErrUnknownQueue)
+	s.Nil(resp)
+}
+
+func (s *DeadLetterServiceTestSuite) TestRedrive_Selected() {
+	// Arrange
+	ctx := context.Background()
+	ids := []string{"msg1", "msg2"}
+	s.mockQueue.On("RedriveDeadLetters", ctx, "export", ids).Return(2, nil)
+
+	// Act
+	resp, err := s.service.Redrive(ctx, "export", dto.RedriveDeadLettersRequest{MessageIDs: ids})
+
+	// Assert
+	s.NoError(err)
+	s.Equal(2, resp.Redriven)
+}
+
+func (s *DeadLetterServiceTestSuite) TestFailAndRedrive_MemoryQueue() {
+	// Arrange
+	ctx := context.Background()
+	queueService := queue.NewService(queue.NewMemoryQueue(), config.RetryPolicy{MaxReceives: 2})
+	service := NewDeadLetterService(queueService)
+	s.Require().NoError(queueService.SendExportMessage(ctx, "tenant1", "job1"))
+
+	// Act
+	var deadLettered bool
+	for i := 0; i < 2; i++ {
+		messages, err := queueService.ReceiveMessages(ctx, queue.QueueExport, 10, 0)
+		s.Require().NoError(err)
+		s.Require().Len(messages, 1)
+		s.Equal(i+1, messages[0].ReceiveCount)
+
+		deadLettered, err = queueService.Fail(ctx, queue.QueueExport, messages[0], errors.New("export failed"))
+		s.Require().NoError(err)
+	}
+	letters, listErr := service.List(ctx, queue.QueueExport, 0)
+	redrive, redriveErr := service.Redrive(ctx, queue.QueueExport, dto.RedriveDeadLettersRequest{})
+	redriven, receiveErr := queueService.ReceiveMessages(ctx, queue.QueueExport, 10, 0)
+
+	// Assert
+	s.True(deadLettered)
+	s.NoError(listErr)
+	s.Require().Len(letters, 1)
+	s.Equal("export failed", letters[0].Reason)
+	s.Equal(2, letters[0].ReceiveCount)
+	s.NoError(redriveErr)
+	s.Equal(1, redrive.Redriven)
+	s.NoError(receiveErr)
+	s.Require().Len(redriven, 1)
+	s.Equal("job1", redriven[0].Message.JobID)
+	s.Equal(1, redriven[0].ReceiveCount)
+}
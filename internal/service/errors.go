@@ -6,6 +6,49 @@ var (
 	// Tenant errors
 	ErrTenantNotFound = errors.New("tenant not found")
 	ErrTenantExists   = errors.New("tenant already exists")
+	ErrTenantMismatch = errors.New("tenant_id does not match the tenant of the caller")
+
+	// Tenant purge errors
+	ErrTenantUnderLegalHold     = errors.New("tenant has active legal holds, release them before purging the tenant")
+	ErrReceiptSigningKeyMissing = errors.New("RECEIPT_SIGNING_KEY is not configured, deletion receipts cannot be signed")
+
+	// Export job errors
+	ErrExportJobNotFound = errors.New("export job not found")
+
+	// Restore job errors
+	ErrRestoreJobNotFound = errors.New("restore job not found")
+	ErrArchiveNotFound    = errors.New("archive not found")
+
+	// Reindex job errors
+	ErrReindexJobNotFound  = errors.New("reindex job not found")
+	ErrReindexJobCompleted = errors.New("reindex job already completed")
+	ErrReindexJobRunning   = errors.New("reindex job is still running")
+	ErrInvalidReindexRange = errors.New("start_date must not be after end_date")
+
+	// Dead-letter queue errors
+	ErrUnknownQueue       = errors.New("unknown queue, expected index, archive, cleanup or export")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+	// Retention policy errors
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrInvalidRetentionPolicy  = errors.New("delete_after_days must be 0 or at least archive_after_days")
+
+	// Indexed path errors
+	ErrInvalidIndexedPath = errors.New("invalid indexed path")
+
+	// Time series errors
+	ErrTooManyBuckets = errors.New("too many buckets, use a longer interval or a shorter time range")
+
+	// Session errors
+	ErrSessionNotFound = errors.New("session not found")
+
+	// Legal hold errors
+	ErrLegalHoldNotFound = errors.New("legal hold not found")
+	ErrLegalHoldReleased = errors.New("legal hold already released")
+	ErrInvalidLegalHold  = errors.New("a legal hold needs either log_ids or a filter, and start_time must not be after end_time")
+
+	// Archive search errors
+	ErrArchiveSearchUnavailable = errors.New("archive search is not configured")
 
 	// User errors
 	ErrUserNotFound       = errors.New("user not found")
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// parquetRowGroupSize bounds the number of rows buffered in memory before a
+// row group is written out
+const parquetRowGroupSize = 10000
+
+// parquetWriter writes a Parquet file with one column per selected field.
+// JSON fields are optional columns, every other field is required.
+type parquetWriter struct {
+	w       *parquet.Writer
+	columns []column
+	// indexes maps each selected column to its leaf index in the schema,
+	// which orders the columns by name
+	indexes []int
+	row     parquet.Row
+}
+
+func newParquetWriter(w io.Writer, columns []column) *parquetWriter {
+	group := make(parquet.Group, len(columns))
+	for _, col := range columns {
+		switch col.kind {
+		case kindJSON:
+			group[col.name] = parquet.Optional(parquet.JSON())
+		case kindTimestamp:
+			group[col.name] = parquet.Timestamp(parquet.Microsecond)
+		case kindInt:
+			group[col.name] = parquet.Int(64)
+		default:
+			group[col.name] = parquet.String()
+		}
+	}
+	schema := parquet.NewSchema("audit_log", group)
+
+	leaves := make(map[string]int)
+	for i, path := range schema.Columns() {
+		leaves[strings.Join(path, ".")] = i
+	}
+	indexes := make([]int, len(columns))
+	for i, col := range columns {
+		indexes[i] = leaves[col.name]
+	}
+
+	return &parquetWriter{
+		w:       parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy), parquet.MaxRowsPerRowGroup(parquetRowGroupSize)),
+		columns: columns,
+		indexes: indexes,
+		row:     make(parquet.Row, len(columns)),
+	}
+}
+
+func (w *parquetWriter) Write(log *domain.AuditLog) error {
+	for i, col := range w.columns {
+		index := w.indexes[i]
+
+		switch v := col.value(log).(type) {
+		case string:
+			w.row[index] = parquet.ByteArrayValue([]byte(v)).Level(0, 0, index)
+		case json.RawMessage:
+			if len(v) == 0 {
+				w.row[index] = parquet.NullValue().Level(0, 0, index)
+			} else {
+				w.row[index] = parquet.ByteArrayValue(v).Level(0, 1, index)
+			}
+		case time.Time:
+			w.row[index] = parquet.Int64Value(v.UnixMicro()).Level(0, 0, index)
+		case int64:
+			w.row[index] = parquet.Int64Value(v).Level(0, 0, index)
+		}
+	}
+
+	_, err := w.w.WriteRows([]parquet.Row{w.row})
+	return err
+}
+
+// Flush is a no-op, rows are buffered until a row group is complete since
+// many small row groups make the file slow to read
+func (w *parquetWriter) Flush() error {
+	return nil
+}
+
+func (w *parquetWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,317 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+type Format string
+
+const (
+	FormatJSON    Format = "json"
+	FormatNDJSON  Format = "ndjson"
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates an export format given by a client
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(s); format {
+	case FormatJSON, FormatNDJSON, FormatCSV, FormatParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, s)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/json"
+	}
+}
+
+// FileName returns the name of the attachment served for the format
+func (f Format) FileName() string {
+	return "audit_logs." + string(f)
+}
+
+// Writer encodes audit logs one at a time into an export file
+type Writer interface {
+	// Write appends a log to the export
+	Write(log *domain.AuditLog) error
+	// Flush writes any buffered data to the underlying writer
+	Flush() error
+	// Close writes the end of the export. The writer must not be used afterwards.
+	Close() error
+}
+
+// NewWriter returns a writer encoding the given fields of each log in the
+// format. Fields must have been validated by ParseFields.
+func NewWriter(format Format, w io.Writer, fields []string) (Writer, error) {
+	columns := make([]column, len(fields))
+	for i, name := range fields {
+		col, ok := columnByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		columns[i] = col
+	}
+
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{w: w, columns: columns}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case FormatCSV:
+		return newCSVWriter(w, columns), nil
+	case FormatParquet:
+		return newParquetWriter(w, columns), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// ParseFields parses a comma separated list of field names. An empty list
+// selects every field.
+func ParseFields(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		fields := make([]string, len(columns))
+		for i, col := range columns {
+			fields[i] = col.name
+		}
+		return fields, nil
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := columnByName(name); !ok {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindJSON
+	kindTimestamp
+	kindInt
+)
+
+type column struct {
+	name  string
+	kind  columnKind
+	value func(log *domain.AuditLog) any
+}
+
+// columns lists the exportable fields in their default order. Names match the
+// JSON representation of a log in the API.
+var columns = []column{
+	{"id", kindString, func(l *domain.AuditLog) any { return l.ID }},
+	{"tenant_id", kindString, func(l *domain.AuditLog) any { return l.TenantID }},
+	{"user_id", kindString, func(l *domain.AuditLog) any { return l.UserID }},
+	{"session_id", kindString, func(l *domain.AuditLog) any { return l.SessionID }},
+	{"ip_address", kindString, func(l *domain.AuditLog) any { return l.IPAddress }},
+	{"user_agent", kindString, func(l *domain.AuditLog) any { return l.UserAgent }},
+	{"action", kindString, func(l *domain.AuditLog) any { return l.Action }},
+	{"resource_type", kindString, func(l *domain.AuditLog) any { return l.ResourceType }},
+	{"resource_id", kindString, func(l *domain.AuditLog) any { return l.ResourceID }},
+	{"severity", kindString, func(l *domain.AuditLog) any { return l.Severity }},
+	{"message", kindString, func(l *domain.AuditLog) any { return l.Message }},
+	{"before_state", kindJSON, func(l *domain.AuditLog) any { return l.BeforeState }},
+	{"after_state", kindJSON, func(l *domain.AuditLog) any { return l.AfterState }},
+	{"metadata", kindJSON, func(l *domain.AuditLog) any { return l.Metadata }},
+	{"timestamp", kindTimestamp, func(l *domain.AuditLog) any { return l.Timestamp }},
+	{"chain_seq", kindInt, func(l *domain.AuditLog) any { return l.ChainSeq }},
+	{"prev_hash", kindString, func(l *domain.AuditLog) any { return l.PrevHash }},
+	{"hash", kindString, func(l *domain.AuditLog) any { return l.Hash }},
+}
+
+func columnByName(name string) (column, bool) {
+	for _, col := range columns {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return column{}, false
+}
+
+// encodeObject encodes the columns of a log as a JSON object, keeping the
+// order of the columns
+func encodeObject(log *domain.AuditLog, columns []column) ([]byte, error) {
+	buf := []byte{'{'}
+	for i, col := range columns {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendQuote(buf, col.name)
+		buf = append(buf, ':')
+
+		switch v := col.value(log).(type) {
+		case json.RawMessage:
+			if len(v) == 0 {
+				buf = append(buf, "null"...)
+			} else {
+				buf = append(buf, v...)
+			}
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode field %s: %w", col.name, err)
+			}
+			buf = append(buf, data...)
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+// jsonWriter writes the logs as a single JSON array
+type jsonWriter struct {
+	w       io.Writer
+	columns []column
+	count   int
+}
+
+func (w *jsonWriter) Write(log *domain.AuditLog) error {
+	data, err := encodeObject(log, w.columns)
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if w.count == 0 {
+		sep = "[\n"
+	}
+	w.count++
+
+	if _, err := io.WriteString(w.w, sep); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Flush() error {
+	return nil
+}
+
+func (w *jsonWriter) Close() error {
+	end := "\n]\n"
+	if w.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+// ndjsonWriter writes one JSON object per line
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []column
+}
+
+func (w *ndjsonWriter) Write(log *domain.AuditLog) error {
+	data, err := encodeObject(log, w.columns)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(data, '\n'))
+	return err
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+// csvWriter writes RFC 4180 CSV with a header row
+type csvWriter struct {
+	w         *csv.Writer
+	columns   []column
+	record    []string
+	hasHeader bool
+}
+
+func newCSVWriter(w io.Writer, columns []column) *csvWriter {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	return &csvWriter{
+		w:       cw,
+		columns: columns,
+		record:  make([]string, len(columns)),
+	}
+}
+
+func (w *csvWriter) writeHeader() error {
+	for i, col := range w.columns {
+		w.record[i] = col.name
+	}
+	w.hasHeader = true
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Write(log *domain.AuditLog) error {
+	if !w.hasHeader {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	for i, col := range w.columns {
+		switch v := col.value(log).(type) {
+		case string:
+			w.record[i] = v
+		case json.RawMessage:
+			w.record[i] = string(v)
+		case time.Time:
+			w.record[i] = v.UTC().Format(time.RFC3339Nano)
+		case int64:
+			w.record[i] = strconv.FormatInt(v, 10)
+		}
+	}
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close() error {
+	if !w.hasHeader {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/service/export"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+)
+
+const (
+	// exportJobTTL is how long a job and its file are kept after the job was created
+	exportJobTTL = 7 * 24 * time.Hour
+	// exportDownloadURLTTL is how long a pre-signed download URL stays valid
+	exportDownloadURLTTL = 15 * time.Minute
+	// expiredExportBatchSize is the number of expired jobs removed per query
+	expiredExportBatchSize = 100
+
+	// exportLease is how long a running job is left to its worker after its
+	// last update, long enough for the upload of a large file. Messages
+	// redelivered within the lease are dropped.
+	exportLease = 15 * time.Minute
+)
+
+// errExportRunLost stops a run whose job was taken over by another run
+var errExportRunLost = errors.New("export job was taken over by another run")
+
+//go:generate mockery --name ExportStorage --output ../mocks
+type ExportStorage interface {
+	Upload(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+	PresignGetObject(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type ExportJobService struct {
+	repo     repository.PostgresRepository
+	queueSvc QueueService
+	storage  ExportStorage
+}
+
+func NewExportJobService(repo repository.PostgresRepository, queueSvc QueueService, storage ExportStorage) *ExportJobService {
+	return &ExportJobService{
+		repo:     repo,
+		queueSvc: queueSvc,
+		storage:  storage,
+	}
+}
+
+// Create stores a pending export job for the tenant in the context and
+// enqueues it for the export worker
+func (s *ExportJobService) Create(ctx context.Context, req dto.CreateExportJobRequest) (*dto.ExportJobResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &domain.ExportJob{
+		TenantID:  tenantID,
+		Status:    domain.ExportJobPending,
+		Format:    req.Format,
+		Fields:    req.Fields,
+		Filter:    req.ToAuditLogFilter(tenantID),
+		ExpiresAt: time.Now().Add(exportJobTTL),
+	}
+	if err := s.repo.ExportJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	if err := s.queueSvc.SendExportMessage(ctx, tenantID, job.ID); err != nil {
+		job.Status = domain.ExportJobFailed
+		job.Error = "failed to enqueue export job"
+		if updateErr := s.repo.ExportJob().Update(ctx, job); updateErr != nil {
+			fmt.Printf("failed to mark export job %s as failed: %v\n", job.ID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to enqueue export job: %w", err)
+	}
+
+	return dto.FromExportJob(job), nil
+}
+
+// Get returns the export job of the tenant in the context, with a pre-signed
+// download URL once the job has completed
+func (s *ExportJobService) Get(ctx context.Context, id string) (*dto.ExportJobResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.ExportJob().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrExportJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.FromExportJob(job)
+	if job.Status == domain.ExportJobCompleted && job.S3Key != "" {
+		// Never hand out a URL that outlives the file
+		ttl := min(exportDownloadURLTTL, time.Until(job.ExpiresAt))
+		if ttl <= 0 {
+			return nil, ErrExportJobNotFound
+		}
+
+		url, err := s.storage.PresignGetObject(ctx, job.S3Key, ttl)
+		if err != nil {
+			return nil, err
+		}
+		response.DownloadURL = url
+	}
+
+	return response, nil
+}
+
+// Run executes the export job and uploads the file to S3. Failures are
+// recorded on the job. Jobs that already finished and jobs another worker is
+// running are skipped, so a redelivered message does not export twice. A run
+// only records the job while it owns it, a run whose job was taken over after
+// its lease expired stops without recording anything.
+func (s *ExportJobService) Run(ctx context.Context, tenantID, id string) error {
+	job, err := s.repo.ExportJob().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The job expired before it could run
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get export job %s: %w", id, err)
+	}
+	if job.Status == domain.ExportJobCompleted || job.Status == domain.ExportJobFailed {
+		return nil
+	}
+	if job.Status == domain.ExportJobRunning && time.Since(job.UpdatedAt) < exportLease {
+		return nil
+	}
+
+	// The run is known by its start time, PostgreSQL keeps microseconds
+	previousRun := job.StartedAt
+	startedAt := time.Now().Truncate(time.Microsecond)
+	job.Status = domain.ExportJobRunning
+	job.StartedAt = &startedAt
+	job.RowCount = 0
+	claimed, err := s.repo.ExportJob().UpdateRun(ctx, job, previousRun)
+	if err != nil {
+		return fmt.Errorf("failed to start export job %s: %w", id, err)
+	}
+	if !claimed {
+		// Another worker started the job meanwhile
+		return nil
+	}
+
+	rowCount, key, runErr := s.writeExport(ctx, job)
+	if errors.Is(runErr, errExportRunLost) {
+		return nil
+	}
+
+	// The job of a stopping worker is recorded after its context is cancelled
+	updateCtx := ctx
+	completedAt := time.Now()
+	switch {
+	case runErr != nil && ctx.Err() != nil:
+		// A stopping worker returns the message, and the job starts over when
+		// it is received again
+		job.Status = domain.ExportJobPending
+		updateCtx = context.WithoutCancel(ctx)
+	case runErr != nil:
+		job.CompletedAt = &completedAt
+		job.RowCount = rowCount
+		job.Status = domain.ExportJobFailed
+		job.Error = runErr.Error()
+	default:
+		job.CompletedAt = &completedAt
+		job.RowCount = rowCount
+		job.Status = domain.ExportJobCompleted
+		job.S3Key = key
+	}
+	owned, err := s.repo.ExportJob().UpdateRun(updateCtx, job, &startedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish export job %s: %w", id, err)
+	}
+	if !owned {
+		// The run that took the job over records its outcome
+		return nil
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("export job %s failed: %w", id, runErr)
+	}
+	return nil
+}
+
+// writeExport streams the logs of the job into a temporary file and uploads
+// it, returning the number of exported logs and the key of the file. The
+// progress saved after each batch keeps the lease of the job.
+func (s *ExportJobService) writeExport(ctx context.Context, job *domain.ExportJob) (int64, string, error) {
+	format, err := export.ParseFormat(job.Format)
+	if err != nil {
+		return 0, "", err
+	}
+	fields, err := export.ParseFields("")
+	if err != nil {
+		return 0, "", err
+	}
+	if len(job.Fields) > 0 {
+		fields = job.Fields
+	}
+
+	file, err := os.CreateTemp("", "audit-log-export-*")
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer, err := export.NewWriter(format, file, fields)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var rowCount int64
+	err = exportLogs(ctx, s.repo.AuditLog(), job.Filter, func(logs []domain.AuditLog) error {
+		for i := range logs {
+			if err := writer.Write(&logs[i]); err != nil {
+				return err
+			}
+		}
+		rowCount += int64(len(logs))
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		return s.saveProgress(ctx, job, rowCount)
+	})
+	if err != nil {
+		return rowCount, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return rowCount, "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return rowCount, "", fmt.Errorf("failed to rewind export file: %w", err)
+	}
+
+	// The job is checked once more, the file of a run that lost the job
+	// would replace the file of the run that owns it
+	if err := s.saveProgress(ctx, job, rowCount); err != nil {
+		return rowCount, "", err
+	}
+
+	key := fmt.Sprintf("%s%s.%s", domain.ExportKeyPrefix(job.TenantID), job.ID, format)
+	if err := s.storage.Upload(ctx, key, file, format.ContentType()); err != nil {
+		return rowCount, "", err
+	}
+
+	return rowCount, key, nil
+}
+
+// saveProgress records the number of exported logs on the job, as long as the
+// run still owns it
+func (s *ExportJobService) saveProgress(ctx context.Context, job *domain.ExportJob, rowCount int64) error {
+	job.RowCount = rowCount
+	owned, err := s.repo.ExportJob().UpdateRun(ctx, job, job.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	if !owned {
+		return errExportRunLost
+	}
+	return nil
+}
+
+// DeleteExpired removes expired jobs along with their files and returns the
+// number of removed jobs
+func (s *ExportJobService) DeleteExpired(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		jobs, err := s.repo.ExportJob().ListExpired(ctx, time.Now(), expiredExportBatchSize)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list expired export jobs: %w", err)
+		}
+
+		for _, job := range jobs {
+			if job.S3Key != "" {
+				if err := s.storage.Delete(ctx, job.S3Key); err != nil {
+					return deleted, err
+				}
+			}
+			if err := s.repo.ExportJob().Delete(ctx, job.ID); err != nil {
+				return deleted, fmt.Errorf("failed to delete export job %s: %w", job.ID, err)
+			}
+			deleted++
+		}
+
+		if len(jobs) < expiredExportBatchSize {
+			return deleted, nil
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ExportJobServiceTestSuite struct {
+	suite.Suite
+	mockRepo      *mocks.PostgresRepository
+	mockExportJob *mocks.ExportJobRepository
+	mockAuditLog  *mocks.AuditLogRepository
+	mockQueue     *mocks.QueueService
+	mockStorage   *mocks.ExportStorage
+	service       *ExportJobService
+}
+
+func (s *ExportJobServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockExportJob = new(mocks.ExportJobRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockQueue = new(mocks.QueueService)
+	s.mockStorage = new(mocks.ExportStorage)
+
+	s.mockRepo.On("ExportJob").Return(s.mockExportJob)
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+
+	s.service = NewExportJobService(s.mockRepo, s.mockQueue, s.mockStorage)
+}
+
+func TestExportJobService(t *testing.T) {
+	suite.Run(t, new(ExportJobServiceTestSuite))
+}
+
+func (s *ExportJobServiceTestSuite) TestCreate_EnqueuesJob() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateExportJobRequest{
+		Format:    "csv",
+		Fields:    []string{"id", "action"},
+		StartTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		Action:    "CREATE",
+	}
+
+	s.mockExportJob.On("Create", ctx, mock.MatchedBy(func(job *domain.ExportJob) bool {
+		return job.TenantID == "tenant1" &&
+			job.Status == domain.ExportJobPending &&
+			job.Filter.TenantID == "tenant1" &&
+			job.Filter.Action == "CREATE" &&
+			job.ExpiresAt.After(time.Now())
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.ExportJob).ID = "job1"
+	}).Return(nil)
+	s.mockQueue.On("SendExportMessage", ctx, "tenant1", "job1").Return(nil)
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("job1", result.ID)
+	s.Equal(string(domain.ExportJobPending), result.Status)
+	s.mockExportJob.AssertExpectations(s.T())
+	s.mockQueue.AssertExpectations(s.T())
+}
+
+func (s *ExportJobServiceTestSuite) TestGet_CompletedJobHasDownloadURL() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	job := &domain.ExportJob{
+		ID:        "job1",
+		TenantID:  "tenant1",
+		Status:    domain.ExportJobCompleted,
+		Format:    "csv",
+		RowCount:  42,
+		S3Key:     "exports/tenant1/job1.csv",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockStorage.On("PresignGetObject", ctx, job.S3Key, exportDownloadURLTTL).Return("https://example.com/job1.csv", nil)
+
+	// Act
+	result, err := s.service.Get(ctx, "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(42), result.RowCount)
+	s.Equal("https://example.com/job1.csv", result.DownloadURL)
+	s.mockStorage.AssertExpectations(s.T())
+}
+
+func (s *ExportJobServiceTestSuite) TestGet_NotFound() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := s.service.Get(ctx, "job1")
+
+	// Assert
+	s.ErrorIs(err, ErrExportJobNotFound)
+	s.Nil(result)
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_UploadsFileAndCompletesJob() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{
+		ID:       "job1",
+		TenantID: "tenant1",
+		Status:   domain.ExportJobPending,
+		Format:   "ndjson",
+		Fields:   []string{"id"},
+		Filter:   domain.AuditLogFilter{TenantID: "tenant1"},
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockExportJob.On("UpdateRun", ctx, job, mock.Anything).Return(true, nil)
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).
+		Return([]domain.AuditLog{{ID: "log1"}, {ID: "log2"}}, nil)
+
+	var uploaded string
+	s.mockStorage.On("Upload", ctx, "exports/tenant1/job1.ndjson", mock.Anything, "application/x-ndjson").
+		Run(func(args mock.Arguments) {
+			data, err := io.ReadAll(args.Get(2).(io.Reader))
+			s.Require().NoError(err)
+			uploaded = string(data)
+		}).
+		Return(nil)
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.ExportJobCompleted, job.Status)
+	s.Equal(int64(2), job.RowCount)
+	s.Equal("exports/tenant1/job1.ndjson", job.S3Key)
+	s.Equal("{\"id\":\"log1\"}\n{\"id\":\"log2\"}\n", uploaded)
+	s.mockStorage.AssertExpectations(s.T())
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_RecordsFailure() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{
+		ID:       "job1",
+		TenantID: "tenant1",
+		Status:   domain.ExportJobPending,
+		Format:   "csv",
+		Filter:   domain.AuditLogFilter{TenantID: "tenant1"},
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockExportJob.On("UpdateRun", ctx, job, mock.Anything).Return(true, nil)
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).
+		Return(nil, errors.New("connection refused"))
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.Error(err)
+	s.Equal(domain.ExportJobFailed, job.Status)
+	s.Contains(job.Error, "connection refused")
+	s.mockStorage.AssertNotCalled(s.T(), "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_SkipsJobRunningWithinLease() {
+	// Arrange
+	ctx := context.Background()
+	startedAt := time.Now().Add(-20 * time.Minute)
+	job := &domain.ExportJob{
+		ID:        "job1",
+		TenantID:  "tenant1",
+		Status:    domain.ExportJobRunning,
+		StartedAt: &startedAt,
+		UpdatedAt: time.Now().Add(-time.Minute),
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.mockExportJob.AssertNotCalled(s.T(), "UpdateRun", mock.Anything, mock.Anything, mock.Anything)
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_TakesOverJobAfterLease() {
+	// Arrange
+	ctx := context.Background()
+	previousRun := time.Now().Add(-time.Hour)
+	job := &domain.ExportJob{
+		ID:        "job1",
+		TenantID:  "tenant1",
+		Status:    domain.ExportJobRunning,
+		Format:    "ndjson",
+		Filter:    domain.AuditLogFilter{TenantID: "tenant1"},
+		RowCount:  500,
+		StartedAt: &previousRun,
+		UpdatedAt: time.Now().Add(-exportLease - time.Minute),
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	claim := s.mockExportJob.On("UpdateRun", ctx, job, &previousRun).Return(true, nil).Once()
+	s.mockExportJob.On("UpdateRun", ctx, job, mock.MatchedBy(func(startedAt *time.Time) bool {
+		return startedAt.After(previousRun)
+	})).Return(true, nil).NotBefore(claim)
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return([]domain.AuditLog{{ID: "log1"}}, nil)
+	s.mockStorage.On("Upload", ctx, "exports/tenant1/job1.ndjson", mock.Anything, "application/x-ndjson").Return(nil)
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.ExportJobCompleted, job.Status)
+	s.Equal(int64(1), job.RowCount)
+	s.True(job.StartedAt.After(previousRun))
+	s.mockExportJob.AssertExpectations(s.T())
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_SkipsJobClaimedMeanwhile() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{ID: "job1", TenantID: "tenant1", Status: domain.ExportJobPending}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockExportJob.On("UpdateRun", ctx, job, (*time.Time)(nil)).Return(false, nil)
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_StopsWhenTakenOver() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{
+		ID:       "job1",
+		TenantID: "tenant1",
+		Status:   domain.ExportJobPending,
+		Format:   "csv",
+		Filter:   domain.AuditLogFilter{TenantID: "tenant1"},
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockExportJob.On("UpdateRun", ctx, job, (*time.Time)(nil)).Return(true, nil).Once()
+	// Another run took the job over while the logs were written
+	s.mockExportJob.On("UpdateRun", ctx, job, mock.AnythingOfType("*time.Time")).Return(false, nil).Once()
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return([]domain.AuditLog{{ID: "log1"}}, nil)
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.mockExportJob.AssertNumberOfCalls(s.T(), "UpdateRun", 2)
+	s.mockStorage.AssertNotCalled(s.T(), "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ExportJobServiceTestSuite) TestRun_LateFailureKeepsJobOfOtherRun() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.ExportJob{
+		ID:       "job1",
+		TenantID: "tenant1",
+		Status:   domain.ExportJobPending,
+		Format:   "csv",
+		Filter:   domain.AuditLogFilter{TenantID: "tenant1"},
+	}
+
+	s.mockExportJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockExportJob.On("UpdateRun", ctx, job, (*time.Time)(nil)).Return(true, nil).Once()
+	// The other run completed the job, the failure of this one is dropped
+	s.mockExportJob.On("UpdateRun", ctx, job, mock.AnythingOfType("*time.Time")).Return(false, nil).Once()
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return(nil, errors.New("connection refused"))
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.mockExportJob.AssertExpectations(s.T())
+}
+
+func (s *ExportJobServiceTestSuite) TestDeleteExpired_RemovesFiles() {
+	// Arrange
+	ctx := context.Background()
+	jobs := []domain.ExportJob{
+		{ID: "job1", S3Key: "exports/tenant1/job1.csv"},
+		{ID: "job2"},
+	}
+
+	s.mockExportJob.On("ListExpired", ctx, mock.AnythingOfType("time.Time"), expiredExportBatchSize).Return(jobs, nil)
+	s.mockStorage.On("Delete", ctx, "exports/tenant1/job1.csv").Return(nil)
+	s.mockExportJob.On("Delete", ctx, "job1").Return(nil)
+	s.mockExportJob.On("Delete", ctx, "job2").Return(nil)
+
+	// Act
+	deleted, err := s.service.DeleteExpired(ctx)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(2, deleted)
+	s.mockStorage.AssertExpectations(s.T())
+	s.mockExportJob.AssertExpectations(s.T())
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+)
+
+type IndexedPathService struct {
+	repo repository.Repository
+}
+
+func NewIndexedPathService(repo repository.Repository) *IndexedPathService {
+	return &IndexedPathService{
+		repo: repo,
+	}
+}
+
+// Get returns the indexed paths of the tenant
+func (s *IndexedPathService) Get(ctx context.Context, tenantID string) (*dto.IndexedPathsResponse, error) {
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromIndexedPaths(tenant), nil
+}
+
+// Put replaces the indexed paths of the tenant. The paths are mapped in
+// OpenSearch before they are stored, so that filters on them are only sent to
+// OpenSearch once it indexes them. Paths that are no longer declared stay
+// mapped, their filters are answered by PostgreSQL.
+func (s *IndexedPathService) Put(ctx context.Context, tenantID string, req dto.IndexedPathsRequest) (*dto.IndexedPathsResponse, error) {
+	paths, err := toIndexedPaths(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.OpenSearch().PutIndexedPaths(ctx, tenantID, paths); err != nil {
+		return nil, fmt.Errorf("failed to map indexed paths: %w", err)
+	}
+
+	tenant.IndexedPaths = paths
+	tenant.UpdatedAt = time.Now()
+	if err := s.repo.Tenant().Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return dto.FromIndexedPaths(tenant), nil
+}
+
+func (s *IndexedPathService) getTenant(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	tenant, err := s.repo.Tenant().GetByID(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, err
+}
+
+// toIndexedPaths validates the declared paths. OpenSearch flattens arrays, so
+// keys indexing arrays can't be mapped, and a path can't be mapped both as a
+// value and as an object holding another path.
+func toIndexedPaths(req dto.IndexedPathsRequest) ([]domain.IndexedPath, error) {
+	paths := make([]domain.IndexedPath, 0, len(req.Paths))
+	for _, path := range req.Paths {
+		_, keys, err := domain.SplitJSONPath(path.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidIndexedPath, err)
+		}
+		for _, key := range keys {
+			if _, err := strconv.Atoi(key); err == nil {
+				return nil, fmt.Errorf("%w: %s, keys indexing arrays can't be indexed", ErrInvalidIndexedPath, path.Path)
+			}
+		}
+
+		for _, other := range paths {
+			if other.Path == path.Path ||
+				strings.HasPrefix(other.Path, path.Path+".") ||
+				strings.HasPrefix(path.Path, other.Path+".") {
+				return nil, fmt.Errorf("%w: %s overlaps %s", ErrInvalidIndexedPath, path.Path, other.Path)
+			}
+		}
+
+		pathType := path.Type
+		if pathType == "" {
+			pathType = domain.IndexedPathKeyword
+		}
+		paths = append(paths, domain.IndexedPath{Path: path.Path, Type: pathType})
+	}
+	return paths, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexedPathServiceTestSuite struct {
+	suite.Suite
+	mockRepo       *mocks.Repository
+	mockTenant     *mocks.TenantRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	service        *IndexedPathService
+}
+
+func (s *IndexedPathServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockTenant = new(mocks.TenantRepository)
+	s.mockOpenSearch = new(mocks.OpenSearchRepository)
+
+	s.mockRepo.On("Tenant").Return(s.mockTenant)
+	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+
+	s.service = NewIndexedPathService(s.mockRepo)
+}
+
+func TestIndexedPathService(t *testing.T) {
+	suite.Run(t, new(IndexedPathServiceTestSuite))
+}
+
+func (s *IndexedPathServiceTestSuite) TestPut_MapsPathsBeforeStoringThem() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.IndexedPathsRequest{Paths: []dto.IndexedPathRequest{
+		{Path: "metadata.request_id"},
+		{Path: "after_state.count", Type: "long"},
+	}}
+	expected := []domain.IndexedPath{
+		{Path: "metadata.request_id", Type: domain.IndexedPathKeyword},
+		{Path: "after_state.count", Type: domain.IndexedPathLong},
+	}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1", IndexedPaths: []domain.IndexedPath{}}, nil)
+	mapped := s.mockOpenSearch.On("PutIndexedPaths", ctx, "tenant1", expected).Return(nil)
+	s.mockTenant.On("Update", ctx, mock.MatchedBy(func(t *domain.Tenant) bool {
+		return len(t.IndexedPaths) == 2
+	})).Return(nil).NotBefore(mapped)
+
+	// Act
+	result, err := s.service.Put(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]dto.IndexedPathResponse{
+		{Path: "metadata.request_id", Type: "keyword"},
+		{Path: "after_state.count", Type: "long"},
+	}, result.Paths)
+	s.mockOpenSearch.AssertExpectations(s.T())
+	s.mockTenant.AssertExpectations(s.T())
+}
+
+func (s *IndexedPathServiceTestSuite) TestPut_MappingConflictKeepsPaths() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.IndexedPathsRequest{Paths: []dto.IndexedPathRequest{{Path: "metadata.count", Type: "long"}}}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockOpenSearch.On("PutIndexedPaths", ctx, "tenant1", mock.Anything).Return(domain.ErrIndexedPathConflict)
+
+	// Act
+	result, err := s.service.Put(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, domain.ErrIndexedPathConflict)
+	s.Nil(result)
+	s.mockTenant.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (s *IndexedPathServiceTestSuite) TestPut_RejectsInvalidPaths() {
+	ctx := context.Background()
+	for _, paths := range [][]dto.IndexedPathRequest{
+		{{Path: "request_id"}},
+		{{Path: "metadata.items.0.id"}},
+		{{Path: "metadata.user"}, {Path: "metadata.user.email"}},
+	} {
+		// Act
+		result, err := s.service.Put(ctx, "tenant1", dto.IndexedPathsRequest{Paths: paths})
+
+		// Assert
+		s.ErrorIs(err, ErrInvalidIndexedPath)
+		s.Nil(result)
+	}
+	s.mockOpenSearch.AssertNotCalled(s.T(), "PutIndexedPaths", mock.Anything, mock.Anything, mock.Anything)
+}
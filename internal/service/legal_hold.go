@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+)
+
+// legalHoldResourceType is the resource type of the audit logs recording
+// changes to legal holds
+const legalHoldResourceType = "legal_hold"
+
+//go:generate mockery --name AuditLogWriter --output ../mocks
+type AuditLogWriter interface {
+	Append(ctx context.Context, req dto.CreateAuditLogRequest, store func(log *domain.AuditLog) error) error
+}
+
+type LegalHoldService struct {
+	repo  repository.PostgresRepository
+	audit AuditLogWriter
+}
+
+func NewLegalHoldService(repo repository.PostgresRepository, audit AuditLogWriter) *LegalHoldService {
+	return &LegalHoldService{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+// Create places a hold on the logs of the tenant in the context. The hold is
+// stored together with its record in the audit log of the tenant.
+func (s *LegalHoldService) Create(ctx context.Context, req dto.CreateLegalHoldRequest) (*dto.LegalHoldResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := req.ToLegalHold(tenantID)
+	if len(hold.LogIDs) > 0 == hold.HasFilter() {
+		return nil, ErrInvalidLegalHold
+	}
+	if hold.StartTime != nil && hold.EndTime != nil && hold.StartTime.After(*hold.EndTime) {
+		return nil, ErrInvalidLegalHold
+	}
+	hold.CreatedBy = utils.GetUserIDFromContext(ctx)
+	// The audit log records the hold as stored, so it is complete beforehand
+	hold.ID = uuid.New().String()
+	hold.CreatedAt = time.Now()
+	hold.UpdatedAt = hold.CreatedAt
+
+	resp := dto.FromLegalHold(hold)
+	message := fmt.Sprintf("Legal hold placed: %s", hold.Reason)
+	err = s.recordChange(ctx, hold, domain.ActionCreate, message, nil, resp, func(log *domain.AuditLog) error {
+		return s.repo.LegalHold().Create(ctx, hold, log)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create legal hold: %w", err)
+	}
+
+	return resp, nil
+}
+
+// List returns the holds of the tenant in the context
+func (s *LegalHoldService) List(ctx context.Context, includeReleased bool) ([]dto.LegalHoldResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	holds, err := s.repo.LegalHold().List(ctx, tenantID, includeReleased)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.LegalHoldResponse, len(holds))
+	for i := range holds {
+		resp[i] = *dto.FromLegalHold(&holds[i])
+	}
+	return resp, nil
+}
+
+// Release lifts a hold of the tenant in the context, cleanups may then delete
+// the logs it held. The release is stored together with its record in the
+// audit log of the tenant.
+func (s *LegalHoldService) Release(ctx context.Context, id string) (*dto.LegalHoldResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.repo.LegalHold().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLegalHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !hold.Active() {
+		return nil, ErrLegalHoldReleased
+	}
+
+	before := dto.FromLegalHold(hold)
+	releasedAt := time.Now()
+	hold.ReleasedAt = &releasedAt
+	hold.ReleasedBy = utils.GetUserIDFromContext(ctx)
+	hold.UpdatedAt = releasedAt
+
+	resp := dto.FromLegalHold(hold)
+	message := fmt.Sprintf("Legal hold released: %s", hold.Reason)
+	err = s.recordChange(ctx, hold, domain.ActionUpdate, message, before, resp, func(log *domain.AuditLog) error {
+		return s.repo.LegalHold().Update(ctx, hold, log)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to release legal hold: %w", err)
+	}
+
+	return resp, nil
+}
+
+// recordChange writes an audit log of a change to the hold, made by the user
+// of the request. Store saves the hold in the transaction of the log.
+func (s *LegalHoldService) recordChange(ctx context.Context, hold *domain.LegalHold, action domain.ActionType, message string, before, after *dto.LegalHoldResponse, store func(log *domain.AuditLog) error) error {
+	req := dto.CreateAuditLogRequest{
+		TenantID:     hold.TenantID,
+		UserID:       utils.GetUserIDFromContext(ctx),
+		Action:       string(action),
+		ResourceType: legalHoldResourceType,
+		ResourceID:   hold.ID,
+		Severity:     string(domain.SeverityWarning),
+		Message:      message,
+		Timestamp:    time.Now(),
+	}
+
+	var err error
+	if before != nil {
+		if req.BeforeState, err = json.Marshal(before); err != nil {
+			return fmt.Errorf("failed to marshal legal hold: %w", err)
+		}
+	}
+	if req.AfterState, err = json.Marshal(after); err != nil {
+		return fmt.Errorf("failed to marshal legal hold: %w", err)
+	}
+
+	return s.audit.Append(ctx, req, store)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type LegalHoldServiceTestSuite struct {
+	suite.Suite
+	mockRepo  *mocks.PostgresRepository
+	mockHold  *mocks.LegalHoldRepository
+	mockAudit *mocks.AuditLogWriter
+	service   *LegalHoldService
+}
+
+func (s *LegalHoldServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockHold = new(mocks.LegalHoldRepository)
+	s.mockAudit = new(mocks.AuditLogWriter)
+
+	s.mockRepo.On("LegalHold").Return(s.mockHold)
+
+	s.service = NewLegalHoldService(s.mockRepo, s.mockAudit)
+}
+
+func TestLegalHoldService(t *testing.T) {
+	suite.Run(t, new(LegalHoldServiceTestSuite))
+}
+
+// appendLog stands in for the audit log service, storing the log of the
+// request with the store given to it
+func appendLog(ctx context.Context, req dto.CreateAuditLogRequest, store func(log *domain.AuditLog) error) error {
+	return store(&domain.AuditLog{ID: "log1", ResourceID: req.ResourceID})
+}
+
+func (s *LegalHoldServiceTestSuite) TestCreate_RecordsHoldInAuditLog() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateLegalHoldRequest{Reason: "Litigation", UserID: "user1", ResourceType: "invoice"}
+
+	var stored *domain.LegalHold
+	s.mockAudit.On("Append", ctx, mock.MatchedBy(func(req dto.CreateAuditLogRequest) bool {
+		var after dto.LegalHoldResponse
+		return req.TenantID == "tenant1" &&
+			req.Action == string(domain.ActionCreate) &&
+			req.ResourceType == legalHoldResourceType &&
+			req.ResourceID != "" &&
+			req.BeforeState == nil &&
+			json.Unmarshal(req.AfterState, &after) == nil && after.Active && after.ID == req.ResourceID
+	}), mock.Anything).Return(appendLog)
+	s.mockHold.On("Create", ctx, mock.MatchedBy(func(hold *domain.LegalHold) bool {
+		return hold.TenantID == "tenant1" && hold.UserID == "user1" && hold.ResourceType == "invoice"
+	}), mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return log.ID == "log1"
+	})).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*domain.LegalHold)
+	}).Return(nil)
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal(stored.ID, result.ID)
+	s.False(result.CreatedAt.IsZero())
+	s.True(result.Active)
+	s.mockHold.AssertExpectations(s.T())
+	s.mockAudit.AssertExpectations(s.T())
+}
+
+func (s *LegalHoldServiceTestSuite) TestCreate_FailedStore() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateLegalHoldRequest{Reason: "Litigation", UserID: "user1"}
+
+	s.mockAudit.On("Append", ctx, mock.Anything, mock.Anything).Return(appendLog)
+	s.mockHold.On("Create", ctx, mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.ErrorContains(err, "db error")
+	s.Nil(result)
+}
+
+func (s *LegalHoldServiceTestSuite) TestCreate_FilterAndLogIDs() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateLegalHoldRequest{
+		Reason: "Litigation",
+		UserID: "user1",
+		LogIDs: []string{"550e8400-e29b-41d4-a716-446655440000"},
+	}
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidLegalHold)
+	s.Nil(result)
+	s.mockHold.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LegalHoldServiceTestSuite) TestCreate_NoCriteria() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateLegalHoldRequest{Reason: "Litigation"}
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidLegalHold)
+	s.Nil(result)
+}
+
+func (s *LegalHoldServiceTestSuite) TestRelease_RecordsReleaseInAuditLog() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	hold := &domain.LegalHold{ID: "hold1", TenantID: "tenant1", Reason: "Litigation", UserID: "user1"}
+
+	s.mockHold.On("GetByID", ctx, "tenant1", "hold1").Return(hold, nil)
+	s.mockAudit.On("Append", ctx, mock.MatchedBy(func(req dto.CreateAuditLogRequest) bool {
+		var before, after dto.LegalHoldResponse
+		return req.Action == string(domain.ActionUpdate) &&
+			req.ResourceID == "hold1" &&
+			json.Unmarshal(req.BeforeState, &before) == nil && before.Active &&
+			json.Unmarshal(req.AfterState, &after) == nil && !after.Active
+	}), mock.Anything).Return(appendLog)
+	s.mockHold.On("Update", ctx, mock.MatchedBy(func(hold *domain.LegalHold) bool {
+		return hold.ReleasedAt != nil
+	}), mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return log.ID == "log1"
+	})).Return(nil)
+
+	// Act
+	result, err := s.service.Release(ctx, "hold1")
+
+	// Assert
+	s.NoError(err)
+	s.False(result.Active)
+	s.NotNil(result.ReleasedAt)
+	s.mockHold.AssertExpectations(s.T())
+	s.mockAudit.AssertExpectations(s.T())
+}
+
+func (s *LegalHoldServiceTestSuite) TestRelease_AlreadyReleased() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	releasedAt := time.Now()
+	hold := &domain.LegalHold{ID: "hold1", TenantID: "tenant1", ReleasedAt: &releasedAt}
+	s.mockHold.On("GetByID", ctx, "tenant1", "hold1").Return(hold, nil)
+
+	// Act
+	result, err := s.service.Release(ctx, "hold1")
+
+	// Assert
+	s.ErrorIs(err, ErrLegalHoldReleased)
+	s.Nil(result)
+	s.mockAudit.AssertNotCalled(s.T(), "Append", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LegalHoldServiceTestSuite) TestRelease_NotFound() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	s.mockHold.On("GetByID", ctx, "tenant1", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := s.service.Release(ctx, "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrLegalHoldNotFound)
+	s.Nil(result)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+)
+
+const (
+	// outboxLease is how long a claimed entry is held by a relay before
+	// another relay may publish it
+	outboxLease = time.Minute
+
+	// outboxMessageSize is the number of logs published per index message,
+	// it keeps messages well below the SQS size limit
+	outboxMessageSize = 25
+)
+
+//go:generate mockery --name IndexPublisher --output ../mocks
+type IndexPublisher interface {
+	SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error
+}
+
+// OutboxResult counts what one relay pass did with the claimed entries
+type OutboxResult struct {
+	Published int
+	Failed    int
+	// Missing counts entries whose log no longer exists, they are dropped
+	Missing int
+}
+
+type OutboxService struct {
+	repo      repository.PostgresRepository
+	publisher IndexPublisher
+}
+
+func NewOutboxService(repo repository.PostgresRepository, publisher IndexPublisher) *OutboxService {
+	return &OutboxService{
+		repo:      repo,
+		publisher: publisher,
+	}
+}
+
+// Relay publishes up to limit due outbox entries to the index queue, grouped
+// by tenant. Published entries are removed, entries that fail are retried
+// with a growing delay. A log may be published more than once, indexing it
+// again is harmless.
+func (s *OutboxService) Relay(ctx context.Context, limit int) (*OutboxResult, error) {
+	result := &OutboxResult{}
+
+	entries, err := s.repo.Outbox().Claim(ctx, limit, outboxLease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return result, nil
+	}
+
+	logs, err := s.repo.Outbox().Logs(ctx, entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load outbox logs: %w", err)
+	}
+	logsByID := make(map[string]domain.AuditLog, len(logs))
+	for _, log := range logs {
+		logsByID[log.ID] = log
+	}
+
+	// Logs deleted since they were written have nothing left to index
+	var missing []int64
+	var tenants []string
+	byTenant := make(map[string][]domain.OutboxEntry)
+	for _, entry := range entries {
+		if _, ok := logsByID[entry.LogID]; !ok {
+			missing = append(missing, entry.ID)
+			continue
+		}
+		if _, ok := byTenant[entry.TenantID]; !ok {
+			tenants = append(tenants, entry.TenantID)
+		}
+		byTenant[entry.TenantID] = append(byTenant[entry.TenantID], entry)
+	}
+	if err := s.repo.Outbox().Delete(ctx, missing); err != nil {
+		return nil, fmt.Errorf("failed to delete outbox entries: %w", err)
+	}
+	result.Missing = len(missing)
+
+	for _, tenantID := range tenants {
+		tenantEntries := byTenant[tenantID]
+		for start := 0; start < len(tenantEntries); start += outboxMessageSize {
+			batch := tenantEntries[start:min(start+outboxMessageSize, len(tenantEntries))]
+			if err := s.publish(ctx, batch, logsByID); err != nil {
+				result.Failed += len(batch)
+				fmt.Printf("failed to relay outbox entries of tenant %s: %v\n", tenantID, err)
+				continue
+			}
+			result.Published += len(batch)
+		}
+	}
+
+	return result, nil
+}
+
+// publish sends the logs of the entries in one index message and removes the
+// entries. When the message cannot be sent the entries are scheduled again.
+func (s *OutboxService) publish(ctx context.Context, entries []domain.OutboxEntry, logsByID map[string]domain.AuditLog) error {
+	ids := make([]int64, len(entries))
+	logs := make([]domain.AuditLog, len(entries))
+	var delay time.Duration
+	for i, entry := range entries {
+		ids[i] = entry.ID
+		logs[i] = logsByID[entry.LogID]
+		delay = max(delay, entry.RetryDelay())
+	}
+
+	if err := s.publisher.SendBulkIndexMessage(ctx, logs); err != nil {
+		if retryErr := s.repo.Outbox().Retry(ctx, ids, err.Error(), time.Now().Add(delay)); retryErr != nil {
+			return fmt.Errorf("%w (failed to schedule retry: %v)", err, retryErr)
+		}
+		return err
+	}
+
+	// A failed delete only means the logs are published again once the lease expires
+	if err := s.repo.Outbox().Delete(ctx, ids); err != nil {
+		fmt.Printf("failed to delete published outbox entries: %v\n", err)
+	}
+	return nil
+}
+
+// Lag returns how far the relay is behind
+func (s *OutboxService) Lag(ctx context.Context) (*domain.OutboxLag, error) {
+	return s.repo.Outbox().Lag(ctx)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxServiceTestSuite struct {
+	suite.Suite
+	mockRepo      *mocks.PostgresRepository
+	mockOutbox    *mocks.OutboxRepository
+	mockPublisher *mocks.IndexPublisher
+	service       *OutboxService
+}
+
+func (s *OutboxServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockOutbox = new(mocks.OutboxRepository)
+	s.mockPublisher = new(mocks.IndexPublisher)
+
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+
+	s.service = NewOutboxService(s.mockRepo, s.mockPublisher)
+}
+
+func TestOutboxService(t *testing.T) {
+	suite.Run(t, new(OutboxServiceTestSuite))
+}
+
+// outboxEntry returns the outbox entry of the log
+func outboxEntry(id int64, log domain.AuditLog) domain.OutboxEntry {
+	return domain.OutboxEntry{ID: id, TenantID: log.TenantID, LogID: log.ID, LogTimestamp: log.Timestamp, Attempts: 1}
+}
+
+func (s *OutboxServiceTestSuite) TestRelay_PublishesPerTenant() {
+	// Arrange
+	ctx := context.Background()
+	now := time.Now()
+	log1 := domain.AuditLog{ID: "log1", TenantID: "tenant1", Timestamp: now}
+	log2 := domain.AuditLog{ID: "log2", TenantID: "tenant2", Timestamp: now}
+	log3 := domain.AuditLog{ID: "log3", TenantID: "tenant1", Timestamp: now}
+	entries := []domain.OutboxEntry{outboxEntry(1, log1), outboxEntry(2, log2), outboxEntry(3, log3)}
+
+	s.mockOutbox.On("Claim", ctx, 100, outboxLease).Return(entries, nil)
+	s.mockOutbox.On("Logs", ctx, entries).Return([]domain.AuditLog{log1, log2, log3}, nil)
+	s.mockOutbox.On("Delete", ctx, []int64(nil)).Return(nil)
+	s.mockPublisher.On("SendBulkIndexMessage", ctx, []domain.AuditLog{log1, log3}).Return(nil)
+	s.mockPublisher.On("SendBulkIndexMessage", ctx, []domain.AuditLog{log2}).Return(nil)
+	s.mockOutbox.On("Delete", ctx, []int64{1, 3}).Return(nil)
+	s.mockOutbox.On("Delete", ctx, []int64{2}).Return(nil)
+
+	// Act
+	result, err := s.service.Relay(ctx, 100)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(&OutboxResult{Published: 3}, result)
+	s.mockPublisher.AssertExpectations(s.T())
+	s.mockOutbox.AssertExpectations(s.T())
+}
+
+func (s *OutboxServiceTestSuite) TestRelay_RetriesFailedPublish() {
+	// Arrange
+	ctx := context.Background()
+	log1 := domain.AuditLog{ID: "log1", TenantID: "tenant1", Timestamp: time.Now()}
+	entries := []domain.OutboxEntry{outboxEntry(1, log1)}
+	entries[0].Attempts = 3
+
+	s.mockOutbox.On("Claim", ctx, 100, outboxLease).Return(entries, nil)
+	s.mockOutbox.On("Logs", ctx, entries).Return([]domain.AuditLog{log1}, nil)
+	s.mockOutbox.On("Delete", ctx, []int64(nil)).Return(nil)
+	s.mockPublisher.On("SendBulkIndexMessage", ctx, []domain.AuditLog{log1}).Return(errors.New("queue unavailable"))
+	s.mockOutbox.On("Retry", ctx, []int64{1}, "queue unavailable", mock.MatchedBy(func(next time.Time) bool {
+		delay := time.Until(next)
+		return delay > 7*time.Second && delay <= 8*time.Second
+	})).Return(nil)
+
+	// Act
+	result, err := s.service.Relay(ctx, 100)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(&OutboxResult{Failed: 1}, result)
+	s.mockOutbox.AssertExpectations(s.T())
+	s.mockOutbox.AssertNotCalled(s.T(), "Delete", ctx, []int64{1})
+}
+
+func (s *OutboxServiceTestSuite) TestRelay_DropsEntriesOfDeletedLogs() {
+	// Arrange
+	ctx := context.Background()
+	log1 := domain.AuditLog{ID: "log1", TenantID: "tenant1", Timestamp: time.Now()}
+	entries := []domain.OutboxEntry{outboxEntry(1, log1)}
+
+	s.mockOutbox.On("Claim", ctx, 100, outboxLease).Return(entries, nil)
+	s.mockOutbox.On("Logs", ctx, entries).Return([]domain.AuditLog{}, nil)
+	s.mockOutbox.On("Delete", ctx, []int64{1}).Return(nil)
+
+	// Act
+	result, err := s.service.Relay(ctx, 100)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(&OutboxResult{Missing: 1}, result)
+	s.mockPublisher.AssertNotCalled(s.T(), "SendBulkIndexMessage", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,164 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// Attributes attached to a dead letter
+const (
+	attrFailureReason = "FailureReason"
+	attrSourceQueue   = "SourceQueue"
+	attrReceiveCount  = "ReceiveCount"
+	attrFailedAt      = "FailedAt"
+)
+
+// maxReasonLength keeps the failure reason well within the size limit of a
+// message
+const maxReasonLength = 1024
+
+// Fail handles a message that could not be processed. The message is hidden
+// for a backoff doubling with every receive until it was received the maximum
+// number of times, and is then moved to the dead-letter queue with the reason
+// attached. A message that cannot be decoded is moved right away. It reports
+// whether the message was dead-lettered.
+func (s *Service) Fail(ctx context.Context, queue string, msg ReceivedMessage, cause error) (bool, error) {
+	if msg.Err == nil && msg.ReceiveCount < s.retry.MaxReceives {
+		if err := s.ExtendVisibility(ctx, queue, msg, s.retry.Delay(msg.ReceiveCount)); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	reason := cause.Error()
+	if len(reason) > maxReasonLength {
+		reason = reason[:maxReasonLength]
+	}
+
+	err := s.backend.SendDeadLetter(ctx, queue, msg.Body, map[string]string{
+		attrFailureReason: reason,
+		attrSourceQueue:   queue,
+		attrReceiveCount:  strconv.Itoa(msg.ReceiveCount),
+		attrFailedAt:      time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to send message to dead-letter queue: %w", err)
+	}
+
+	// A message that is not deleted is received again and dead-lettered twice,
+	// which a redrive tolerates
+	if err := s.DeleteMessage(ctx, queue, msg); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// ListDeadLetters returns up to limit dead letters of the named queue. The
+// messages stay in the dead-letter queue.
+func (s *Service) ListDeadLetters(ctx context.Context, queue string, limit int) ([]domain.DeadLetter, error) {
+	if err := knownQueue(queue); err != nil {
+		return nil, err
+	}
+
+	letters := []domain.DeadLetter{}
+	if limit <= 0 {
+		return letters, nil
+	}
+	err := s.backend.ScanDeadLetters(ctx, queue, func(delivery Delivery) (bool, bool, error) {
+		letters = append(letters, deadLetter(queue, delivery))
+		return false, len(letters) < limit, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// GetDeadLetter returns a dead letter of the named queue
+func (s *Service) GetDeadLetter(ctx context.Context, queue, messageID string) (*domain.DeadLetter, error) {
+	if err := knownQueue(queue); err != nil {
+		return nil, err
+	}
+
+	var found *domain.DeadLetter
+	err := s.backend.ScanDeadLetters(ctx, queue, func(delivery Delivery) (bool, bool, error) {
+		if delivery.ID != messageID {
+			return false, true, nil
+		}
+		letter := deadLetter(queue, delivery)
+		found = &letter
+		return false, false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dead letters: %w", err)
+	}
+	if found == nil {
+		return nil, ErrDeadLetterNotFound
+	}
+	return found, nil
+}
+
+// RedriveDeadLetters sends the dead letters back to the named queue, all of
+// them when no message IDs are given. It returns the number of messages sent
+// back.
+func (s *Service) RedriveDeadLetters(ctx context.Context, queue string, messageIDs []string) (int, error) {
+	if err := knownQueue(queue); err != nil {
+		return 0, err
+	}
+
+	wanted := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		wanted[id] = true
+	}
+
+	var redriven int
+	err := s.backend.ScanDeadLetters(ctx, queue, func(delivery Delivery) (bool, bool, error) {
+		if len(wanted) > 0 && !wanted[delivery.ID] {
+			return false, true, nil
+		}
+
+		if err := s.backend.Send(ctx, queue, delivery.Body); err != nil {
+			return false, false, fmt.Errorf("failed to redrive message %s: %w", delivery.ID, err)
+		}
+
+		redriven++
+		return true, len(wanted) == 0 || redriven < len(wanted), nil
+	})
+	if err != nil {
+		return redriven, err
+	}
+	if len(wanted) > 0 && redriven == 0 {
+		return 0, ErrDeadLetterNotFound
+	}
+	return redriven, nil
+}
+
+// PurgeDeadLetters deletes every dead letter of the named queue
+func (s *Service) PurgeDeadLetters(ctx context.Context, queue string) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	if err := s.backend.PurgeDeadLetters(ctx, queue); err != nil {
+		return fmt.Errorf("failed to purge dead-letter queue: %w", err)
+	}
+	return nil
+}
+
+// deadLetter reads a dead letter and the attributes Fail attached to it
+func deadLetter(queue string, delivery Delivery) domain.DeadLetter {
+	letter := domain.DeadLetter{
+		MessageID: delivery.ID,
+		Queue:     queue,
+		Body:      delivery.Body,
+		Reason:    delivery.Attributes[attrFailureReason],
+		SentAt:    delivery.SentAt,
+	}
+	letter.ReceiveCount, _ = strconv.Atoi(delivery.Attributes[attrReceiveCount])
+	letter.FailedAt, _ = time.Parse(time.RFC3339, delivery.Attributes[attrFailedAt])
+	return letter
+}
@@ -0,0 +1,177 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryPollInterval is how often a receive waiting for messages checks the
+// queue again
+const memoryPollInterval = 100 * time.Millisecond
+
+type memoryMessage struct {
+	Delivery
+	visibleAt time.Time
+}
+
+// MemoryQueue is a queue backend that keeps its messages in memory. Messages
+// are only delivered within the process and are lost when it stops, it serves
+// tests and deployments running every worker in one process.
+type MemoryQueue struct {
+	mu       sync.Mutex
+	messages map[string][]*memoryMessage
+	dead     map[string][]Delivery
+	sequence int64
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		messages: make(map[string][]*memoryMessage),
+		dead:     make(map[string][]Delivery),
+	}
+}
+
+func (q *MemoryQueue) Send(ctx context.Context, queue, body string) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.messages[queue] = append(q.messages[queue], &memoryMessage{
+		Delivery: Delivery{ID: q.nextID(), Body: body, SentAt: time.Now().UTC()},
+	})
+	return nil
+}
+
+// Receive waits up to waitTime for visible messages
+func (q *MemoryQueue) Receive(ctx context.Context, queue string, maxMessages int, waitTime time.Duration) ([]Delivery, error) {
+	if err := knownQueue(queue); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(waitTime)
+	for {
+		if deliveries := q.receive(queue, maxMessages); len(deliveries) > 0 || !time.Now().Before(deadline) {
+			return deliveries, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(memoryPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+func (q *MemoryQueue) receive(queue string, maxMessages int) []Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var deliveries []Delivery
+	for _, msg := range q.messages[queue] {
+		if len(deliveries) == maxMessages {
+			break
+		}
+		if msg.visibleAt.After(now) {
+			continue
+		}
+
+		// A new receipt makes the receipts of earlier receives stale
+		msg.ReceiveCount++
+		msg.Receipt = q.nextID()
+		msg.visibleAt = now.Add(visibilityTimeout(queue))
+		deliveries = append(deliveries, msg.Delivery)
+	}
+	return deliveries
+}
+
+func (q *MemoryQueue) Delete(ctx context.Context, queue string, delivery Delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i, err := q.find(queue, delivery)
+	if err != nil {
+		return err
+	}
+	q.messages[queue] = slices.Delete(q.messages[queue], i, i+1)
+	return nil
+}
+
+func (q *MemoryQueue) ExtendVisibility(ctx context.Context, queue string, delivery Delivery, timeout time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i, err := q.find(queue, delivery)
+	if err != nil {
+		return err
+	}
+	q.messages[queue][i].visibleAt = time.Now().Add(timeout)
+	return nil
+}
+
+func (q *MemoryQueue) SendDeadLetter(ctx context.Context, queue, body string, attributes map[string]string) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.dead[queue] = append(q.dead[queue], Delivery{
+		ID:         q.nextID(),
+		Body:       body,
+		SentAt:     time.Now().UTC(),
+		Attributes: attributes,
+	})
+	return nil
+}
+
+// ScanDeadLetters visits a snapshot of the dead letters, so visit may send
+// messages to the queue
+func (q *MemoryQueue) ScanDeadLetters(ctx context.Context, queue string, visit func(Delivery) (remove, more bool, err error)) error {
+	q.mu.Lock()
+	letters := slices.Clone(q.dead[queue])
+	q.mu.Unlock()
+
+	for _, letter := range letters {
+		remove, more, err := visit(letter)
+		if err == nil && remove {
+			q.mu.Lock()
+			q.dead[queue] = slices.DeleteFunc(q.dead[queue], func(d Delivery) bool { return d.ID == letter.ID })
+			q.mu.Unlock()
+		}
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *MemoryQueue) PurgeDeadLetters(ctx context.Context, queue string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.dead, queue)
+	return nil
+}
+
+// find returns the position of the message while the delivery holds it
+func (q *MemoryQueue) find(queue string, delivery Delivery) (int, error) {
+	i := slices.IndexFunc(q.messages[queue], func(msg *memoryMessage) bool { return msg.ID == delivery.ID })
+	if i < 0 || q.messages[queue][i].Receipt != delivery.Receipt {
+		return 0, fmt.Errorf("message %s is no longer held by this receipt", delivery.ID)
+	}
+	return i, nil
+}
+
+func (q *MemoryQueue) nextID() string {
+	q.sequence++
+	return strconv.FormatInt(q.sequence, 10)
+}
@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+)
+
+// Names of the queues, each one has its own dead-letter queue
+const (
+	QueueIndex   = "index"
+	QueueArchive = "archive"
+	QueueCleanup = "cleanup"
+	QueueExport  = "export"
+)
+
+// maxVisibilityTimeout is the longest a received message is hidden
+const maxVisibilityTimeout = 12 * time.Hour
+
+var (
+	ErrUnknownQueue       = errors.New("unknown queue")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+// Queue is a queue backend. Queues are addressed by name. A received message
+// is hidden from other receivers until it is deleted or its visibility
+// timeout expires, it is then received again.
+type Queue interface {
+	Send(ctx context.Context, queue, body string) error
+	Receive(ctx context.Context, queue string, maxMessages int, waitTime time.Duration) ([]Delivery, error)
+	Delete(ctx context.Context, queue string, delivery Delivery) error
+	// ExtendVisibility hides a received message for the timeout from now on
+	ExtendVisibility(ctx context.Context, queue string, delivery Delivery, timeout time.Duration) error
+}
+
+// DeadLetters keeps the messages that were given up on, in one dead-letter
+// queue per queue
+type DeadLetters interface {
+	SendDeadLetter(ctx context.Context, queue, body string, attributes map[string]string) error
+	// ScanDeadLetters hands the dead letters of the queue to visit until it
+	// asks for no more or none is left. The dead letters visit reports as
+	// removed are deleted from the dead-letter queue.
+	ScanDeadLetters(ctx context.Context, queue string, visit func(Delivery) (remove, more bool, err error)) error
+	PurgeDeadLetters(ctx context.Context, queue string) error
+}
+
+// Backend is a queue backend with its dead-letter queues
+type Backend interface {
+	Queue
+	DeadLetters
+}
+
+// Delivery is a message as received from a backend
+type Delivery struct {
+	ID           string
+	Body         string
+	ReceiveCount int
+	SentAt       time.Time
+	// Attributes are only set on dead letters
+	Attributes map[string]string
+	// Receipt identifies this receipt of the message to the backend
+	Receipt string
+}
+
+// NewBackend connects to the queue backend of the configuration
+func NewBackend(cfg *config.QueueConfig) (Backend, error) {
+	switch cfg.Backend {
+	case config.QueueBackendSQS:
+		sqsConfig := config.DefaultSQSConfig()
+		client, err := sqsConfig.GetClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to SQS: %w", err)
+		}
+		return NewSQSQueue(client, sqsConfig), nil
+	case config.QueueBackendRedis:
+		client, err := config.DefaultRedisConfig().GetClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewRedisQueue(client, cfg.RedisPrefix, cfg.RedisGroup, cfg.RedisConsumer), nil
+	case config.QueueBackendMemory:
+		return NewMemoryQueue(), nil
+	default:
+		return nil, fmt.Errorf("unknown queue backend: %s", cfg.Backend)
+	}
+}
+
+// visibilityTimeout is how long a received message of the queue is hidden,
+// the same as the visibility timeout of the SQS queue
+func visibilityTimeout(queue string) time.Duration {
+	switch queue {
+	case QueueArchive, QueueCleanup:
+		return time.Minute
+	case QueueExport:
+		return 15 * time.Minute
+	default:
+		return 30 * time.Second
+	}
+}
+
+func knownQueue(queue string) error {
+	switch queue {
+	case QueueIndex, QueueArchive, QueueCleanup, QueueExport:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownQueue, queue)
+	}
+}
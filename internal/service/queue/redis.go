@@ -0,0 +1,359 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisOrphanIdle is how long a message stays pending before it is received
+// again without a visibility deadline. Only a receiver that stopped between
+// reading a message and recording its deadline leaves such a message behind.
+const redisOrphanIdle = maxVisibilityTimeout + time.Minute
+
+// redisScanPage is the number of dead letters read per XRANGE
+const redisScanPage = 100
+
+// claimDue records a new visibility deadline for the messages whose deadline
+// passed and counts their receive, returning their IDs and receive counts
+var claimDue = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+local result = {}
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[1], ARGV[2], id)
+	table.insert(result, id)
+	table.insert(result, redis.call('HINCRBY', KEYS[2], id, 1))
+end
+return result
+`)
+
+// RedisQueue is the queue backend on Redis Streams. Each queue is a stream
+// read by one consumer group. The visibility deadlines of the received
+// messages are kept in a sorted set next to the stream, a message whose
+// deadline passed is claimed by the next receiver.
+type RedisQueue struct {
+	client   *redis.Client
+	prefix   string
+	group    string
+	consumer string
+	groups   sync.Map
+}
+
+func NewRedisQueue(client *redis.Client, prefix, group, consumer string) *RedisQueue {
+	return &RedisQueue{
+		client:   client,
+		prefix:   prefix,
+		group:    group,
+		consumer: consumer,
+	}
+}
+
+func (q *RedisQueue) Send(ctx context.Context, queue, body string) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	return q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream(queue),
+		Values: map[string]interface{}{"body": body},
+	}).Err()
+}
+
+// Receive returns the messages whose visibility deadline passed first, then
+// waits up to waitTime for new messages
+func (q *RedisQueue) Receive(ctx context.Context, queue string, maxMessages int, waitTime time.Duration) ([]Delivery, error) {
+	if err := knownQueue(queue); err != nil {
+		return nil, err
+	}
+	if err := q.ensureGroup(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := q.receiveDue(ctx, queue, maxMessages)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) < maxMessages {
+		orphans, err := q.receiveOrphans(ctx, queue, maxMessages-len(deliveries))
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, orphans...)
+	}
+	if len(deliveries) >= maxMessages {
+		return deliveries, nil
+	}
+
+	// Wait for new messages only when there is nothing to return yet
+	block := time.Duration(-1)
+	if len(deliveries) == 0 && waitTime > 0 {
+		block = waitTime
+	}
+	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.group,
+		Consumer: q.consumer,
+		Streams:  []string{q.stream(queue), ">"},
+		Count:    int64(maxMessages - len(deliveries)),
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return deliveries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(visibilityTimeout(queue))
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			if err := q.client.ZAdd(ctx, q.inflight(queue), redis.Z{Score: float64(deadline.UnixMilli()), Member: msg.ID}).Err(); err != nil {
+				return nil, err
+			}
+			deliveries = append(deliveries, redisDelivery(msg, 1))
+		}
+	}
+	return deliveries, nil
+}
+
+// receiveDue claims the received messages whose visibility deadline passed
+func (q *RedisQueue) receiveDue(ctx context.Context, queue string, maxMessages int) ([]Delivery, error) {
+	now := time.Now()
+	result, err := claimDue.Run(ctx, q.client,
+		[]string{q.inflight(queue), q.receives(queue)},
+		now.UnixMilli(), now.Add(visibilityTimeout(queue)).UnixMilli(), maxMessages,
+	).Slice()
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(result)/2)
+	ids := make([]string, 0, len(result)/2)
+	for i := 0; i+1 < len(result); i += 2 {
+		id, _ := result[i].(string)
+		count, _ := result[i+1].(int64)
+		counts[id] = int(count) + 1
+		ids = append(ids, id)
+	}
+
+	messages, err := q.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   q.stream(queue),
+		Group:    q.group,
+		Consumer: q.consumer,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]Delivery, 0, len(messages))
+	for _, msg := range messages {
+		deliveries = append(deliveries, redisDelivery(msg, counts[msg.ID]))
+		delete(counts, msg.ID)
+	}
+
+	// The messages left were deleted from the stream after their deadline
+	// was recorded. When they cannot be forgotten the claimed messages are
+	// received again once their new deadline passes.
+	for id := range counts {
+		if err := q.forget(ctx, queue, id); err != nil {
+			return nil, err
+		}
+	}
+	return deliveries, nil
+}
+
+// receiveOrphans claims the pending messages without a visibility deadline
+func (q *RedisQueue) receiveOrphans(ctx context.Context, queue string, maxMessages int) ([]Delivery, error) {
+	messages, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.stream(queue),
+		Group:    q.group,
+		Consumer: q.consumer,
+		MinIdle:  redisOrphanIdle,
+		Start:    "0-0",
+		Count:    int64(maxMessages),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(visibilityTimeout(queue))
+	deliveries := make([]Delivery, 0, len(messages))
+	for _, msg := range messages {
+		if err := q.client.ZAdd(ctx, q.inflight(queue), redis.Z{Score: float64(deadline.UnixMilli()), Member: msg.ID}).Err(); err != nil {
+			return nil, err
+		}
+		count, err := q.client.HIncrBy(ctx, q.receives(queue), msg.ID, 1).Result()
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, redisDelivery(msg, int(count)+1))
+	}
+	return deliveries, nil
+}
+
+func (q *RedisQueue) Delete(ctx context.Context, queue string, delivery Delivery) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, q.stream(queue), q.group, delivery.ID)
+		pipe.XDel(ctx, q.stream(queue), delivery.ID)
+		pipe.ZRem(ctx, q.inflight(queue), delivery.ID)
+		pipe.HDel(ctx, q.receives(queue), delivery.ID)
+		return nil
+	})
+	return err
+}
+
+func (q *RedisQueue) ExtendVisibility(ctx context.Context, queue string, delivery Delivery, timeout time.Duration) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	if !q.inFlight(ctx, queue, delivery.ID) {
+		return fmt.Errorf("message %s is not in flight", delivery.ID)
+	}
+	deadline := time.Now().Add(timeout)
+	if err := q.client.ZAddXX(ctx, q.inflight(queue), redis.Z{Score: float64(deadline.UnixMilli()), Member: delivery.ID}).Err(); err != nil {
+		return err
+	}
+
+	// Reset the idle time of the message, so it is not taken for an orphan
+	return q.client.XClaimJustID(ctx, &redis.XClaimArgs{
+		Stream:   q.stream(queue),
+		Group:    q.group,
+		Consumer: q.consumer,
+		Messages: []string{delivery.ID},
+	}).Err()
+}
+
+func (q *RedisQueue) SendDeadLetter(ctx context.Context, queue, body string, attributes map[string]string) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{}, len(attributes)+1)
+	for name, value := range attributes {
+		values[name] = value
+	}
+	values["body"] = body
+
+	return q.client.XAdd(ctx, &redis.XAddArgs{Stream: q.deadLetters(queue), Values: values}).Err()
+}
+
+func (q *RedisQueue) ScanDeadLetters(ctx context.Context, queue string, visit func(Delivery) (remove, more bool, err error)) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	start := "-"
+	for {
+		messages, err := q.client.XRangeN(ctx, q.deadLetters(queue), start, "+", redisScanPage).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range messages {
+			remove, more, err := visit(redisDelivery(msg, 0))
+			if err == nil && remove {
+				err = q.client.XDel(ctx, q.deadLetters(queue), msg.ID).Err()
+			}
+			if err != nil || !more {
+				return err
+			}
+		}
+		if len(messages) < redisScanPage {
+			return nil
+		}
+		start = "(" + messages[len(messages)-1].ID
+	}
+}
+
+func (q *RedisQueue) PurgeDeadLetters(ctx context.Context, queue string) error {
+	if err := knownQueue(queue); err != nil {
+		return err
+	}
+
+	return q.client.Del(ctx, q.deadLetters(queue)).Err()
+}
+
+// ensureGroup creates the stream of the queue and its consumer group
+func (q *RedisQueue) ensureGroup(ctx context.Context, queue string) error {
+	if _, ok := q.groups.Load(queue); ok {
+		return nil
+	}
+
+	err := q.client.XGroupCreateMkStream(ctx, q.stream(queue), q.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	q.groups.Store(queue, true)
+	return nil
+}
+
+func (q *RedisQueue) inFlight(ctx context.Context, queue, id string) bool {
+	_, err := q.client.ZScore(ctx, q.inflight(queue), id).Result()
+	return err == nil
+}
+
+// forget drops the visibility deadline and receive count of a message
+func (q *RedisQueue) forget(ctx context.Context, queue, id string) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, q.inflight(queue), id)
+		pipe.HDel(ctx, q.receives(queue), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to forget message %s: %w", id, err)
+	}
+	return nil
+}
+
+func (q *RedisQueue) stream(queue string) string {
+	return q.prefix + ":" + queue
+}
+
+func (q *RedisQueue) inflight(queue string) string {
+	return q.stream(queue) + ":inflight"
+}
+
+func (q *RedisQueue) receives(queue string) string {
+	return q.stream(queue) + ":receives"
+}
+
+func (q *RedisQueue) deadLetters(queue string) string {
+	return q.stream(queue) + ":dlq"
+}
+
+// redisDelivery reads a stream entry, every field but the body is an attribute
+func redisDelivery(msg redis.XMessage, receiveCount int) Delivery {
+	delivery := Delivery{
+		ID:           msg.ID,
+		ReceiveCount: receiveCount,
+		Receipt:      msg.ID,
+	}
+	for name, value := range msg.Values {
+		text, _ := value.(string)
+		if name == "body" {
+			delivery.Body = text
+			continue
+		}
+		if delivery.Attributes == nil {
+			delivery.Attributes = make(map[string]string)
+		}
+		delivery.Attributes[name] = text
+	}
+
+	// Stream IDs start with the time the entry was added in milliseconds
+	if millis, err := strconv.ParseInt(strings.SplitN(msg.ID, "-", 2)[0], 10, 64); err == nil {
+		delivery.SentAt = time.UnixMilli(millis).UTC()
+	}
+	return delivery
+}
@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisQueueTestSuite struct {
+	suite.Suite
+	server *miniredis.Miniredis
+	client *redis.Client
+	queue  *RedisQueue
+}
+
+func (s *RedisQueueTestSuite) SetupTest() {
+	s.server = miniredis.RunT(s.T())
+	s.client = redis.NewClient(&redis.Options{Addr: s.server.Addr()})
+	s.queue = NewRedisQueue(s.client, "audit", "workers", "worker1")
+}
+
+func (s *RedisQueueTestSuite) TearDownTest() {
+	s.client.Close()
+}
+
+func TestRedisQueue(t *testing.T) {
+	suite.Run(t, new(RedisQueueTestSuite))
+}
+
+func (s *RedisQueueTestSuite) TestReceive_HidesMessagesFromTheGroup() {
+	// Arrange
+	ctx := context.Background()
+	other := NewRedisQueue(s.client, "audit", "workers", "worker2")
+	s.NoError(s.queue.Send(ctx, QueueIndex, "first"))
+	s.NoError(s.queue.Send(ctx, QueueIndex, "second"))
+
+	// Act
+	deliveries, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	s.NoError(err)
+	again, againErr := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	otherDeliveries, otherErr := other.Receive(ctx, QueueIndex, 10, 0)
+
+	// Assert
+	s.Len(deliveries, 2)
+	s.Equal("first", deliveries[0].Body)
+	s.Equal("second", deliveries[1].Body)
+	s.Equal(1, deliveries[0].ReceiveCount)
+	s.False(deliveries[0].SentAt.IsZero())
+	s.NoError(againErr)
+	s.Empty(again)
+	s.NoError(otherErr)
+	s.Empty(otherDeliveries)
+
+	pending, err := s.client.XPending(ctx, "audit:index", "workers").Result()
+	s.NoError(err)
+	s.Equal(int64(2), pending.Count)
+	s.Equal(int64(2), s.client.ZCard(ctx, "audit:index:inflight").Val())
+}
+
+func (s *RedisQueueTestSuite) TestReceive_RedeliversAfterVisibilityDeadline() {
+	// Arrange
+	ctx := context.Background()
+	other := NewRedisQueue(s.client, "audit", "workers", "worker2")
+	s.NoError(s.queue.Send(ctx, QueueIndex, "body"))
+	deliveries, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	s.NoError(err)
+	s.Require().Len(deliveries, 1)
+	s.NoError(s.queue.ExtendVisibility(ctx, QueueIndex, deliveries[0], 0))
+
+	// Act
+	redelivered, err := other.Receive(ctx, QueueIndex, 10, 0)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(redelivered, 1)
+	s.Equal(deliveries[0].ID, redelivered[0].ID)
+	s.Equal("body", redelivered[0].Body)
+	s.Equal(2, redelivered[0].ReceiveCount)
+
+	pending, err := s.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: "audit:index", Group: "workers", Start: "-", End: "+", Count: 10,
+	}).Result()
+	s.NoError(err)
+	s.Require().Len(pending, 1)
+	s.Equal("worker2", pending[0].Consumer)
+}
+
+func (s *RedisQueueTestSuite) TestReceive_ReclaimsOrphans() {
+	// Arrange
+	ctx := context.Background()
+	s.NoError(s.queue.Send(ctx, QueueIndex, "body"))
+	deliveries, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	s.NoError(err)
+	s.Require().Len(deliveries, 1)
+	// A receiver that stopped before recording the deadline of the message
+	s.NoError(s.client.ZRem(ctx, "audit:index:inflight", deliveries[0].ID).Err())
+	s.server.SetTime(time.Now().Add(redisOrphanIdle + time.Minute))
+
+	// Act
+	reclaimed, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(reclaimed, 1)
+	s.Equal(deliveries[0].ID, reclaimed[0].ID)
+	s.Equal(2, reclaimed[0].ReceiveCount)
+	s.True(s.queue.inFlight(ctx, QueueIndex, deliveries[0].ID))
+}
+
+func (s *RedisQueueTestSuite) TestReceive_LeavesRecentPendingMessages() {
+	// Arrange
+	ctx := context.Background()
+	s.NoError(s.queue.Send(ctx, QueueIndex, "body"))
+	deliveries, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	s.NoError(err)
+	s.Require().Len(deliveries, 1)
+	s.NoError(s.client.ZRem(ctx, "audit:index:inflight", deliveries[0].ID).Err())
+
+	// Act
+	reclaimed, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+
+	// Assert
+	s.NoError(err)
+	s.Empty(reclaimed)
+}
+
+func (s *RedisQueueTestSuite) TestDelete_AcksAndForgetsMessage() {
+	// Arrange
+	ctx := context.Background()
+	s.NoError(s.queue.Send(ctx, QueueIndex, "body"))
+	deliveries, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	s.NoError(err)
+	s.Require().Len(deliveries, 1)
+	s.NoError(s.queue.ExtendVisibility(ctx, QueueIndex, deliveries[0], 0))
+
+	// Act
+	err = s.queue.Delete(ctx, QueueIndex, deliveries[0])
+	again, againErr := s.queue.Receive(ctx, QueueIndex, 10, 0)
+
+	// Assert
+	s.NoError(err)
+	s.NoError(againErr)
+	s.Empty(again)
+	s.Equal(int64(0), s.client.XLen(ctx, "audit:index").Val())
+	pending, err := s.client.XPending(ctx, "audit:index", "workers").Result()
+	s.NoError(err)
+	s.Equal(int64(0), pending.Count)
+	s.Equal(int64(0), s.client.Exists(ctx, "audit:index:inflight", "audit:index:receives").Val())
+	s.Error(s.queue.ExtendVisibility(ctx, QueueIndex, deliveries[0], time.Minute))
+}
+
+func (s *RedisQueueTestSuite) TestReceive_ForgetsMessagesDeletedFromStream() {
+	// Arrange
+	ctx := context.Background()
+	s.NoError(s.queue.Send(ctx, QueueIndex, "body"))
+	deliveries, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+	s.NoError(err)
+	s.Require().Len(deliveries, 1)
+	s.NoError(s.queue.ExtendVisibility(ctx, QueueIndex, deliveries[0], 0))
+	s.NoError(s.client.XDel(ctx, "audit:index", deliveries[0].ID).Err())
+
+	// Act
+	again, err := s.queue.Receive(ctx, QueueIndex, 10, 0)
+
+	// Assert
+	s.NoError(err)
+	s.Empty(again)
+	s.Equal(int64(0), s.client.Exists(ctx, "audit:index:inflight", "audit:index:receives").Val())
+}
+
+func (s *RedisQueueTestSuite) TestReceive_UnknownQueue() {
+	// Act
+	_, err := s.queue.Receive(context.Background(), "unknown", 10, 0)
+
+	// Assert
+	s.ErrorIs(err, ErrUnknownQueue)
+}
@@ -0,0 +1,202 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+type MessageType string
+
+const (
+	MessageTypeIndex     MessageType = "INDEX"
+	MessageTypeBulkIndex MessageType = "BULK_INDEX"
+	MessageTypeArchive   MessageType = "ARCHIVE"
+	MessageTypeCleanup   MessageType = "CLEANUP"
+	MessageTypeExport    MessageType = "EXPORT"
+	MessageTypeRestore   MessageType = "RESTORE"
+	MessageTypeReindex   MessageType = "REINDEX"
+)
+
+type Message struct {
+	Type      MessageType       `json:"type"`
+	TenantID  string            `json:"tenant_id"`
+	Logs      []domain.AuditLog `json:"logs,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+
+	// Fields for archive/cleanup operations
+	BeforeDate time.Time `json:"before_date,omitempty"`
+
+	// Fields for export operations
+	JobID string `json:"job_id,omitempty"`
+}
+
+// ReceivedMessage is a message received from a queue. Err is set when the
+// body could not be decoded, the other messages of the batch are unaffected.
+type ReceivedMessage struct {
+	Delivery
+	Message Message
+	Err     error
+}
+
+// Service sends and receives the messages of the services and workers over
+// a queue backend
+type Service struct {
+	backend Backend
+	retry   config.RetryPolicy
+}
+
+func NewService(backend Backend, retry config.RetryPolicy) *Service {
+	return &Service{
+		backend: backend,
+		retry:   retry,
+	}
+}
+
+func (s *Service) SendIndexMessage(ctx context.Context, log *domain.AuditLog) error {
+	msg := Message{
+		Type:      MessageTypeIndex,
+		TenantID:  log.TenantID,
+		Logs:      []domain.AuditLog{*log},
+		Timestamp: log.Timestamp,
+	}
+
+	return s.sendMessage(ctx, msg, QueueIndex)
+}
+
+func (s *Service) SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	msg := Message{
+		Type:      MessageTypeBulkIndex,
+		TenantID:  logs[0].TenantID,
+		Logs:      logs,
+		Timestamp: logs[0].Timestamp,
+	}
+
+	return s.sendMessage(ctx, msg, QueueIndex)
+}
+
+func (s *Service) SendArchiveMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
+	msg := Message{
+		Type:       MessageTypeArchive,
+		TenantID:   tenantID,
+		BeforeDate: beforeDate,
+		Timestamp:  time.Now(),
+	}
+
+	return s.sendMessage(ctx, msg, QueueArchive)
+}
+
+func (s *Service) SendCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
+	msg := Message{
+		Type:       MessageTypeCleanup,
+		TenantID:   tenantID,
+		BeforeDate: beforeDate,
+		Timestamp:  time.Now(),
+	}
+
+	return s.sendMessage(ctx, msg, QueueCleanup)
+}
+
+func (s *Service) SendExportMessage(ctx context.Context, tenantID, jobID string) error {
+	msg := Message{
+		Type:      MessageTypeExport,
+		TenantID:  tenantID,
+		JobID:     jobID,
+		Timestamp: time.Now(),
+	}
+
+	return s.sendMessage(ctx, msg, QueueExport)
+}
+
+// SendRestoreMessage enqueues a restore job on the archive queue, since the
+// archive worker owns the S3 archives
+func (s *Service) SendRestoreMessage(ctx context.Context, tenantID, jobID string) error {
+	msg := Message{
+		Type:      MessageTypeRestore,
+		TenantID:  tenantID,
+		JobID:     jobID,
+		Timestamp: time.Now(),
+	}
+
+	return s.sendMessage(ctx, msg, QueueArchive)
+}
+
+// SendReindexMessage enqueues a reindex job on the index queue, since the
+// index worker owns the OpenSearch indices
+func (s *Service) SendReindexMessage(ctx context.Context, jobID string) error {
+	msg := Message{
+		Type:      MessageTypeReindex,
+		JobID:     jobID,
+		Timestamp: time.Now(),
+	}
+
+	return s.sendMessage(ctx, msg, QueueIndex)
+}
+
+func (s *Service) sendMessage(ctx context.Context, msg Message, queue string) error {
+	msgBody, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	if err := s.backend.Send(ctx, queue, string(msgBody)); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// ReceiveMessages receives up to maxMessages messages of the queue, waiting up
+// to waitTime for the first one
+func (s *Service) ReceiveMessages(ctx context.Context, queue string, maxMessages int, waitTime time.Duration) ([]ReceivedMessage, error) {
+	deliveries, err := s.backend.Receive(ctx, queue, maxMessages, waitTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive messages: %w", err)
+	}
+
+	messages := make([]ReceivedMessage, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		received := ReceivedMessage{Delivery: delivery}
+		if err := json.Unmarshal([]byte(delivery.Body), &received.Message); err != nil {
+			received.Err = fmt.Errorf("failed to unmarshal message: %w", err)
+		}
+		messages = append(messages, received)
+	}
+
+	return messages, nil
+}
+
+func (s *Service) DeleteMessage(ctx context.Context, queue string, msg ReceivedMessage) error {
+	if err := s.backend.Delete(ctx, queue, msg.Delivery); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
+
+// ExtendVisibility keeps a message hidden from other workers for the timeout
+// from now on
+func (s *Service) ExtendVisibility(ctx context.Context, queue string, msg ReceivedMessage, timeout time.Duration) error {
+	if err := s.backend.ExtendVisibility(ctx, queue, msg.Delivery, min(timeout, maxVisibilityTimeout)); err != nil {
+		return fmt.Errorf("failed to extend message visibility: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseMessage makes a message visible to other workers again right away
+func (s *Service) ReleaseMessage(ctx context.Context, queue string, msg ReceivedMessage) error {
+	if err := s.backend.ExtendVisibility(ctx, queue, msg.Delivery, 0); err != nil {
+		return fmt.Errorf("failed to release message: %w", err)
+	}
+
+	return nil
+}
@@ -2,161 +2,257 @@ package queue
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 
 	"github.com/buiminhduc234/audit-log-api/internal/config"
-	"github.com/buiminhduc234/audit-log-api/internal/domain"
 )
 
-type MessageType string
+// deadLetterScanTimeout hides the messages received while scanning a
+// dead-letter queue, so each one is seen once. They are made visible again
+// when the scan ends.
+const deadLetterScanTimeout = 30
 
-const (
-	MessageTypeIndex     MessageType = "INDEX"
-	MessageTypeBulkIndex MessageType = "BULK_INDEX"
-	MessageTypeArchive   MessageType = "ARCHIVE"
-	MessageTypeCleanup   MessageType = "CLEANUP"
-)
-
-type Message struct {
-	Type      MessageType       `json:"type"`
-	TenantID  string            `json:"tenant_id"`
-	Logs      []domain.AuditLog `json:"logs,omitempty"`
-	Timestamp time.Time         `json:"timestamp"`
-
-	// Fields for archive/cleanup operations
-	BeforeDate time.Time `json:"before_date,omitempty"`
+// queuePair is an SQS queue and the dead-letter queue of its failed messages
+type queuePair struct {
+	url    string
+	dlqURL string
 }
 
-type ReceivedMessage struct {
-	Message       Message
-	ReceiptHandle *string
+// SQSQueue is the queue backend on Amazon SQS, with one SQS queue per queue
+// and one per dead-letter queue
+type SQSQueue struct {
+	client *sqs.Client
+	queues map[string]queuePair
 }
 
-type SQSService struct {
-	client          *sqs.Client
-	indexQueueURL   string
-	archiveQueueURL string
-	cleanupQueueURL string
+func NewSQSQueue(client *sqs.Client, config *config.SQSConfig) *SQSQueue {
+	return &SQSQueue{
+		client: client,
+		queues: map[string]queuePair{
+			QueueIndex:   {url: config.IndexQueueURL, dlqURL: config.IndexDLQURL},
+			QueueArchive: {url: config.ArchiveQueueURL, dlqURL: config.ArchiveDLQURL},
+			QueueCleanup: {url: config.CleanupQueueURL, dlqURL: config.CleanupDLQURL},
+			QueueExport:  {url: config.ExportQueueURL, dlqURL: config.ExportDLQURL},
+		},
+	}
 }
 
-func NewSQSService(client *sqs.Client, config *config.SQSConfig) *SQSService {
-	return &SQSService{
-		client:          client,
-		indexQueueURL:   config.IndexQueueURL,
-		archiveQueueURL: config.ArchiveQueueURL,
-		cleanupQueueURL: config.CleanupQueueURL,
+func (q *SQSQueue) Send(ctx context.Context, queue, body string) error {
+	pair, err := q.queue(queue)
+	if err != nil {
+		return err
 	}
-}
 
-func (s *SQSService) SendIndexMessage(ctx context.Context, log *domain.AuditLog) error {
-	msg := Message{
-		Type:      MessageTypeIndex,
-		TenantID:  log.TenantID,
-		Logs:      []domain.AuditLog{*log},
-		Timestamp: log.Timestamp,
+	input := &sqs.SendMessageInput{
+		MessageBody: aws.String(body),
+		QueueUrl:    aws.String(pair.url),
 	}
 
-	return s.sendMessage(ctx, msg, s.indexQueueURL)
+	_, err = q.client.SendMessage(ctx, input)
+	return err
 }
 
-func (s *SQSService) SendBulkIndexMessage(ctx context.Context, logs []domain.AuditLog) error {
-	if len(logs) == 0 {
-		return nil
+func (q *SQSQueue) Receive(ctx context.Context, queue string, maxMessages int, waitTime time.Duration) ([]Delivery, error) {
+	pair, err := q.queue(queue)
+	if err != nil {
+		return nil, err
 	}
 
-	msg := Message{
-		Type:      MessageTypeBulkIndex,
-		TenantID:  logs[0].TenantID,
-		Logs:      logs,
-		Timestamp: logs[0].Timestamp,
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(pair.url),
+		MaxNumberOfMessages: int32(maxMessages),
+		WaitTimeSeconds:     int32(waitTime / time.Second),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameApproximateReceiveCount,
+			types.MessageSystemAttributeNameSentTimestamp,
+		},
 	}
 
-	return s.sendMessage(ctx, msg, s.indexQueueURL)
-}
+	output, err := q.client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *SQSService) SendArchiveMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	msg := Message{
-		Type:       MessageTypeArchive,
-		TenantID:   tenantID,
-		BeforeDate: beforeDate,
-		Timestamp:  time.Now(),
+	deliveries := make([]Delivery, 0, len(output.Messages))
+	for _, msg := range output.Messages {
+		deliveries = append(deliveries, delivery(msg))
 	}
+	return deliveries, nil
+}
 
-	return s.sendMessage(ctx, msg, s.archiveQueueURL)
+func (q *SQSQueue) Delete(ctx context.Context, queue string, delivery Delivery) error {
+	pair, err := q.queue(queue)
+	if err != nil {
+		return err
+	}
+	return q.delete(ctx, pair.url, delivery.Receipt)
 }
 
-func (s *SQSService) SendCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	msg := Message{
-		Type:       MessageTypeCleanup,
-		TenantID:   tenantID,
-		BeforeDate: beforeDate,
-		Timestamp:  time.Now(),
+func (q *SQSQueue) ExtendVisibility(ctx context.Context, queue string, delivery Delivery, timeout time.Duration) error {
+	pair, err := q.queue(queue)
+	if err != nil {
+		return err
 	}
 
-	return s.sendMessage(ctx, msg, s.cleanupQueueURL)
+	_, err = q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(pair.url),
+		ReceiptHandle:     aws.String(delivery.Receipt),
+		VisibilityTimeout: int32(timeout / time.Second),
+	})
+	return err
 }
 
-func (s *SQSService) sendMessage(ctx context.Context, msg Message, queueURL string) error {
-	msgBody, err := json.Marshal(msg)
+func (q *SQSQueue) SendDeadLetter(ctx context.Context, queue, body string, attributes map[string]string) error {
+	pair, err := q.queue(queue)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
 	}
 
-	input := &sqs.SendMessageInput{
-		MessageBody: aws.String(string(msgBody)),
-		QueueUrl:    aws.String(queueURL),
+	messageAttributes := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		messageAttributes[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
 	}
 
-	_, err = s.client.SendMessage(ctx, input)
+	_, err = q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(pair.dlqURL),
+		MessageBody:       aws.String(body),
+		MessageAttributes: messageAttributes,
+	})
+	return err
+}
+
+// ScanDeadLetters receives the messages of the dead-letter queue, they are
+// hidden while the scan runs and every message that is not removed is made
+// visible again when it ends
+func (q *SQSQueue) ScanDeadLetters(ctx context.Context, queue string, visit func(Delivery) (remove, more bool, err error)) error {
+	pair, err := q.queue(queue)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return err
 	}
 
-	return nil
-}
+	var hidden []string
+	defer func() {
+		q.release(context.WithoutCancel(ctx), pair.dlqURL, hidden)
+	}()
 
-func (s *SQSService) ReceiveMessages(ctx context.Context, queueURL string, maxMessages int32, waitTimeSeconds int32) ([]ReceivedMessage, error) {
-	input := &sqs.ReceiveMessageInput{
-		QueueUrl:            aws.String(queueURL),
-		MaxNumberOfMessages: maxMessages,
-		WaitTimeSeconds:     waitTimeSeconds,
-	}
+	seen := make(map[string]bool)
+	for {
+		output, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:                    aws.String(pair.dlqURL),
+			MaxNumberOfMessages:         10,
+			VisibilityTimeout:           deadLetterScanTimeout,
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
+			MessageAttributeNames:       []string{"All"},
+		})
+		if err != nil {
+			return err
+		}
+		if len(output.Messages) == 0 {
+			return nil
+		}
 
-	output, err := s.client.ReceiveMessage(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to receive messages: %w", err)
-	}
+		for i, msg := range output.Messages {
+			letter := delivery(msg)
+			if seen[letter.ID] {
+				hidden = append(hidden, letter.Receipt)
+				continue
+			}
+			seen[letter.ID] = true
 
-	var messages []ReceivedMessage
-	for _, msg := range output.Messages {
-		var message Message
-		if err := json.Unmarshal([]byte(*msg.Body), &message); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+			remove, more, err := visit(letter)
+			if err == nil && remove {
+				err = q.delete(ctx, pair.dlqURL, letter.Receipt)
+			}
+			if err != nil || !remove {
+				hidden = append(hidden, letter.Receipt)
+			}
+			if err != nil || !more {
+				for _, rest := range output.Messages[i+1:] {
+					hidden = append(hidden, aws.ToString(rest.ReceiptHandle))
+				}
+				return err
+			}
 		}
-		messages = append(messages, ReceivedMessage{
-			Message:       message,
-			ReceiptHandle: msg.ReceiptHandle,
-		})
 	}
+}
 
-	return messages, nil
+func (q *SQSQueue) PurgeDeadLetters(ctx context.Context, queue string) error {
+	pair, err := q.queue(queue)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.PurgeQueue(ctx, &sqs.PurgeQueueInput{QueueUrl: aws.String(pair.dlqURL)})
+	return err
 }
 
-func (s *SQSService) DeleteMessage(ctx context.Context, queueURL string, receiptHandle *string) error {
+func (q *SQSQueue) delete(ctx context.Context, queueURL, receipt string) error {
 	input := &sqs.DeleteMessageInput{
 		QueueUrl:      aws.String(queueURL),
-		ReceiptHandle: receiptHandle,
+		ReceiptHandle: aws.String(receipt),
 	}
 
-	_, err := s.client.DeleteMessage(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to delete message: %w", err)
+	_, err := q.client.DeleteMessage(ctx, input)
+	return err
+}
+
+// release makes the messages visible again
+func (q *SQSQueue) release(ctx context.Context, queueURL string, receipts []string) {
+	for start := 0; start < len(receipts); start += 10 {
+		end := min(start+10, len(receipts))
+
+		entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, 0, end-start)
+		for i, receipt := range receipts[start:end] {
+			entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				ReceiptHandle:     aws.String(receipt),
+				VisibilityTimeout: 0,
+			})
+		}
+
+		_, err := q.client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			fmt.Printf("Failed to release dead letters: %v\n", err)
+		}
+	}
+}
+
+func (q *SQSQueue) queue(name string) (queuePair, error) {
+	pair, ok := q.queues[name]
+	if !ok {
+		return queuePair{}, fmt.Errorf("%w: %s", ErrUnknownQueue, name)
 	}
+	return pair, nil
+}
 
-	return nil
+// delivery reads a received SQS message
+func delivery(msg types.Message) Delivery {
+	delivery := Delivery{
+		ID:           aws.ToString(msg.MessageId),
+		Body:         aws.ToString(msg.Body),
+		ReceiveCount: 1,
+		Receipt:      aws.ToString(msg.ReceiptHandle),
+	}
+	if count, err := strconv.Atoi(msg.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]); err == nil {
+		delivery.ReceiveCount = count
+	}
+	if sent, err := strconv.ParseInt(msg.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+		delivery.SentAt = time.UnixMilli(sent).UTC()
+	}
+	if len(msg.MessageAttributes) > 0 {
+		delivery.Attributes = make(map[string]string, len(msg.MessageAttributes))
+		for name, value := range msg.MessageAttributes {
+			delivery.Attributes[name] = aws.ToString(value.StringValue)
+		}
+	}
+	return delivery
 }
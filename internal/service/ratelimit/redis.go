@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+)
+
+const (
+	keyPrefix = "rate_limit:"
+
+	// window is the period a tenant's rate limit applies to
+	window = time.Second
+	// limitCacheTTL bounds how long a cached tenant limit is trusted if an
+	// invalidation is ever missed
+	limitCacheTTL = time.Minute
+)
+
+// allowScript atomically consumes cost units from the current window unless
+// that would exceed the limit. Rejected requests do not consume anything, so a
+// bulk request that does not fit leaves the remaining budget to others.
+var allowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local cost = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+if current + cost > limit then
+	return {0, current, redis.call('PTTL', KEYS[1])}
+end
+current = redis.call('INCRBY', KEYS[1], cost)
+if current == cost then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, current, redis.call('PTTL', KEYS[1])}
+`)
+
+// Result is the outcome of a rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+	RetryAfter time.Duration
+}
+
+type RedisRateLimiter struct {
+	client     *redis.Client
+	tenantRepo repository.TenantRepository
+}
+
+func NewRedisRateLimiter(client *redis.Client, tenantRepo repository.TenantRepository) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:     client,
+		tenantRepo: tenantRepo,
+	}
+}
+
+func (l *RedisRateLimiter) getCounterKey(tenantID string, now time.Time) string {
+	return fmt.Sprintf("%scount:%s:%d", keyPrefix, tenantID, now.Truncate(window).Unix())
+}
+
+func (l *RedisRateLimiter) getLimitKey(tenantID string) string {
+	return keyPrefix + "limit:" + tenantID
+}
+
+// Allow consumes cost units of the tenant's budget for the current window.
+// Counters live in Redis so the limit holds across API replicas.
+func (l *RedisRateLimiter) Allow(ctx context.Context, tenantID string, cost int) (*Result, error) {
+	limit, err := l.getTenantLimit(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key := l.getCounterKey(tenantID, now)
+	values, err := allowScript.Run(ctx, l.client, []string{key}, cost, limit, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+
+	allowed, current, ttl := values[0] == 1, int(values[1]), time.Duration(values[2])*time.Millisecond
+	if ttl <= 0 {
+		ttl = window - now.Sub(now.Truncate(window))
+	}
+
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(limit-current, 0),
+		ResetAt:   now.Add(ttl),
+	}
+	if !allowed {
+		result.RetryAfter = ttl
+	}
+
+	return result, nil
+}
+
+// InvalidateTenantLimit drops the cached limit of a tenant so that a changed
+// limit takes effect on the next request of every replica
+func (l *RedisRateLimiter) InvalidateTenantLimit(ctx context.Context, tenantID string) error {
+	if err := l.client.Del(ctx, l.getLimitKey(tenantID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate rate limit for tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+// getTenantLimit returns the tenant's limit from the Redis cache, loading it
+// from the tenant repository on a miss
+func (l *RedisRateLimiter) getTenantLimit(ctx context.Context, tenantID string) (int, error) {
+	key := l.getLimitKey(tenantID)
+
+	cached, err := l.client.Get(ctx, key).Result()
+	if err == nil {
+		if limit, err := strconv.Atoi(cached); err == nil {
+			return limit, nil
+		}
+	} else if err != redis.Nil {
+		return 0, fmt.Errorf("failed to get cached rate limit: %w", err)
+	}
+
+	tenant, err := l.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tenant %s: %w", tenantID, err)
+	}
+
+	if err := l.client.Set(ctx, key, tenant.RateLimit, limitCacheTTL).Err(); err != nil {
+		return 0, fmt.Errorf("failed to cache rate limit: %w", err)
+	}
+
+	return tenant.RateLimit, nil
+}
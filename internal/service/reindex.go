@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+)
+
+const (
+	// reindexBatchSize is the number of logs compared and indexed at once
+	reindexBatchSize = 1000
+
+	// reindexLease is how long a running job is left to its worker after its
+	// last update. Messages redelivered within the lease are dropped.
+	reindexLease = 5 * time.Minute
+
+	// maxReportedDrift bounds the days of drift listed on a job, the
+	// counters of the job cover every day
+	maxReportedDrift = 1000
+)
+
+type ReindexService struct {
+	repo     repository.Repository
+	queueSvc QueueService
+}
+
+func NewReindexService(repo repository.Repository, queueSvc QueueService) *ReindexService {
+	return &ReindexService{
+		repo:     repo,
+		queueSvc: queueSvc,
+	}
+}
+
+// Prepare validates the request and stores a pending reindex job
+func (s *ReindexService) Prepare(ctx context.Context, req dto.CreateReindexJobRequest) (*domain.ReindexJob, error) {
+	startDate, err := time.Parse(domain.DayLayout, req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date: %w", err)
+	}
+	endDate, err := time.Parse(domain.DayLayout, req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date: %w", err)
+	}
+	if startDate.After(endDate) {
+		return nil, ErrInvalidReindexRange
+	}
+
+	job := &domain.ReindexJob{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		DryRun:     req.DryRun,
+		CompareIDs: req.CompareIDs,
+		Status:     domain.ReindexJobPending,
+		Drift:      []domain.IndexDrift{},
+		CreatedBy:  utils.GetUserIDFromContext(ctx),
+	}
+	if req.TenantID != "" {
+		if _, err := s.repo.Tenant().GetByID(ctx, req.TenantID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTenantNotFound
+			}
+			return nil, err
+		}
+		job.TenantID = &req.TenantID
+	}
+
+	if err := s.repo.ReindexJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create reindex job: %w", err)
+	}
+	return job, nil
+}
+
+// Create stores a pending reindex job and enqueues it for the index worker
+func (s *ReindexService) Create(ctx context.Context, req dto.CreateReindexJobRequest) (*dto.ReindexJobResponse, error) {
+	job, err := s.Prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return dto.FromReindexJob(job), nil
+}
+
+func (s *ReindexService) Get(ctx context.Context, id string) (*dto.ReindexJobResponse, error) {
+	job, err := s.repo.ReindexJob().GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReindexJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromReindexJob(job), nil
+}
+
+// Reopen marks a failed or interrupted job as pending again. A running job
+// can only be reopened once its lease has expired. It keeps its
+// cursor and counters, so running it resumes after the last completed day.
+func (s *ReindexService) Reopen(ctx context.Context, id string) (*domain.ReindexJob, error) {
+	job, err := s.repo.ReindexJob().GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReindexJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == domain.ReindexJobCompleted {
+		return nil, ErrReindexJobCompleted
+	}
+	if job.Status == domain.ReindexJobRunning && time.Since(job.UpdatedAt) < reindexLease {
+		return nil, ErrReindexJobRunning
+	}
+
+	job.Status = domain.ReindexJobPending
+	job.Error = ""
+	job.CompletedAt = nil
+	if err := s.repo.ReindexJob().Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to reopen reindex job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// Resume reopens the job and enqueues it for the index worker
+func (s *ReindexService) Resume(ctx context.Context, id string) (*dto.ReindexJobResponse, error) {
+	job, err := s.Reopen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return dto.FromReindexJob(job), nil
+}
+
+func (s *ReindexService) enqueue(ctx context.Context, job *domain.ReindexJob) error {
+	if err := s.queueSvc.SendReindexMessage(ctx, job.ID); err != nil {
+		job.Status = domain.ReindexJobFailed
+		job.Error = "failed to enqueue reindex job"
+		if updateErr := s.repo.ReindexJob().Update(ctx, job); updateErr != nil {
+			fmt.Printf("failed to mark reindex job %s as failed: %v\n", job.ID, updateErr)
+		}
+		return fmt.Errorf("failed to enqueue reindex job: %w", err)
+	}
+	return nil
+}
+
+// Run reconciles the days of the job that it has not completed yet and hands
+// the job to onProgress after every day with logs. Failures are recorded on
+// the job. Finished jobs and jobs another worker is running are skipped, a
+// failed job runs again once reopened.
+func (s *ReindexService) Run(ctx context.Context, id string, onProgress func(*domain.ReindexJob)) error {
+	job, err := s.repo.ReindexJob().GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reindex job %s: %w", id, err)
+	}
+	if job.Status == domain.ReindexJobCompleted || job.Status == domain.ReindexJobFailed {
+		return nil
+	}
+	if job.Status == domain.ReindexJobRunning && time.Since(job.UpdatedAt) < reindexLease {
+		return nil
+	}
+
+	job.Status = domain.ReindexJobRunning
+	if job.StartedAt == nil {
+		startedAt := time.Now()
+		job.StartedAt = &startedAt
+	}
+	if err := s.repo.ReindexJob().Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to start reindex job %s: %w", id, err)
+	}
+
+	runErr := s.reconcile(ctx, job, onProgress)
+
+	completedAt := time.Now()
+	switch {
+	case runErr != nil && ctx.Err() != nil:
+		// A stopping worker returns the message, and the job resumes when it
+		// is received again
+		job.Status = domain.ReindexJobPending
+	case runErr != nil:
+		job.CompletedAt = &completedAt
+		job.Status = domain.ReindexJobFailed
+		job.Error = runErr.Error()
+	default:
+		job.CompletedAt = &completedAt
+		job.Status = domain.ReindexJobCompleted
+	}
+	// An interrupted job is still recorded, so it can be resumed
+	if err := s.repo.ReindexJob().Update(context.WithoutCancel(ctx), job); err != nil {
+		return fmt.Errorf("failed to finish reindex job %s: %w", id, err)
+	}
+	if onProgress != nil {
+		onProgress(job)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("reindex job %s failed: %w", id, runErr)
+	}
+	return nil
+}
+
+// reconcile compares the per-day counts of each tenant in PostgreSQL and
+// OpenSearch. Days with fewer logs in OpenSearch, or every day with logs when
+// the job compares IDs, are compared log by log.
+func (s *ReindexService) reconcile(ctx context.Context, job *domain.ReindexJob, onProgress func(*domain.ReindexJob)) error {
+	tenantIDs, err := s.tenantIDs(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	days := job.Days()
+	for _, tenantID := range tenantIDs {
+		remaining := slices.DeleteFunc(slices.Clone(days), func(day time.Time) bool {
+			return job.Completed(tenantID, day)
+		})
+		if len(remaining) == 0 {
+			continue
+		}
+		startTime, endTime := remaining[0], remaining[len(remaining)-1].AddDate(0, 0, 1)
+
+		pgCounts, err := s.repo.AuditLog().CountByDay(ctx, tenantID, startTime, endTime)
+		if err != nil {
+			return fmt.Errorf("failed to count logs of tenant %s: %w", tenantID, err)
+		}
+		osCounts, err := s.repo.OpenSearch().CountByDay(ctx, tenantID, startTime, endTime)
+		if err != nil {
+			return fmt.Errorf("failed to count indexed logs of tenant %s: %w", tenantID, err)
+		}
+
+		for i, day := range remaining {
+			key := day.Format(domain.DayLayout)
+			pgCount, osCount := pgCounts[key], osCounts[key]
+
+			var missing int64
+			if pgCount > osCount || (job.CompareIDs && pgCount > 0) {
+				missing, err = s.reconcileDay(ctx, job, tenantID, day)
+				if err != nil {
+					return fmt.Errorf("failed to reconcile logs of tenant %s on %s: %w", tenantID, key, err)
+				}
+			}
+
+			// Logs in OpenSearch beyond the stored ones that were found there
+			extra := max(osCount-(pgCount-missing), 0)
+			if missing > 0 || extra > 0 {
+				job.DriftedDays++
+				job.MissingLogs += missing
+				job.ExtraLogs += extra
+				if len(job.Drift) < maxReportedDrift {
+					job.Drift = append(job.Drift, domain.IndexDrift{
+						TenantID:        tenantID,
+						Day:             key,
+						PostgresCount:   pgCount,
+						OpenSearchCount: osCount,
+						MissingLogs:     missing,
+					})
+				}
+			}
+
+			job.CheckedDays++
+			job.Advance(tenantID, day)
+			// Days without logs are only saved when they end the tenant
+			if pgCount == 0 && osCount == 0 && i < len(remaining)-1 {
+				continue
+			}
+			if err := s.repo.ReindexJob().Update(ctx, job); err != nil {
+				return fmt.Errorf("failed to update reindex job: %w", err)
+			}
+			if onProgress != nil {
+				onProgress(job)
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileDay looks up the logs of the tenant on the day in OpenSearch in
+// batches and indexes the ones that are missing, unless the job is a dry run.
+// It returns the number of missing logs.
+func (s *ReindexService) reconcileDay(ctx context.Context, job *domain.ReindexJob, tenantID string, day time.Time) (int64, error) {
+	filter := domain.AuditLogFilter{
+		TenantID:  tenantID,
+		StartTime: day,
+		EndTime:   day.AddDate(0, 0, 1).Add(-time.Microsecond),
+		Limit:     reindexBatchSize,
+	}
+
+	var missingLogs int64
+	for {
+		logs, err := s.repo.AuditLog().List(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		if len(logs) == 0 {
+			return missingLogs, nil
+		}
+
+		ids := make([]string, len(logs))
+		for i := range logs {
+			ids[i] = logs[i].ID
+		}
+		existing, err := s.repo.OpenSearch().ExistingIDs(ctx, tenantID, ids)
+		if err != nil {
+			return 0, err
+		}
+		indexed := make(map[string]bool, len(existing))
+		for _, id := range existing {
+			indexed[id] = true
+		}
+
+		missing := make([]domain.AuditLog, 0, len(logs)-len(existing))
+		for _, log := range logs {
+			if !indexed[log.ID] {
+				missing = append(missing, log)
+			}
+		}
+		if len(missing) > 0 && !job.DryRun {
+			if err := s.repo.OpenSearch().BulkIndex(ctx, missing); err != nil {
+				return 0, fmt.Errorf("failed to index missing logs: %w", err)
+			}
+			job.ReindexedLogs += int64(len(missing))
+		}
+
+		// Saving keeps the lease of the job through long days
+		if err := s.repo.ReindexJob().Update(ctx, job); err != nil {
+			return 0, fmt.Errorf("failed to update reindex job: %w", err)
+		}
+		missingLogs += int64(len(missing))
+
+		if len(logs) < reindexBatchSize {
+			return missingLogs, nil
+		}
+		filter.After = domain.NewLogCursor(&logs[len(logs)-1])
+	}
+}
+
+// tenantIDs returns the tenants of the job in ID order, the order its cursor
+// relies on
+func (s *ReindexService) tenantIDs(ctx context.Context, job *domain.ReindexJob) ([]string, error) {
+	if job.TenantID != nil {
+		return []string{*job.TenantID}, nil
+	}
+
+	tenants, err := s.repo.Tenant().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	ids := make([]string, len(tenants))
+	for i := range tenants {
+		ids[i] = tenants[i].ID
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
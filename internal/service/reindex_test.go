@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ReindexServiceTestSuite struct {
+	suite.Suite
+	mockRepo       *mocks.Repository
+	mockAuditLog   *mocks.AuditLogRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	mockTenant     *mocks.TenantRepository
+	mockJob        *mocks.ReindexJobRepository
+	mockQueue      *mocks.QueueService
+	service        *ReindexService
+}
+
+func (s *ReindexServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOpenSearch = new(mocks.OpenSearchRepository)
+	s.mockTenant = new(mocks.TenantRepository)
+	s.mockJob = new(mocks.ReindexJobRepository)
+	s.mockQueue = new(mocks.QueueService)
+
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+	s.mockRepo.On("Tenant").Return(s.mockTenant)
+	s.mockRepo.On("ReindexJob").Return(s.mockJob)
+
+	s.service = NewReindexService(s.mockRepo, s.mockQueue)
+}
+
+func TestReindexService(t *testing.T) {
+	suite.Run(t, new(ReindexServiceTestSuite))
+}
+
+// reindexJob returns a pending job of the tenant over the days
+func reindexJob(tenantID string, start, end time.Time) *domain.ReindexJob {
+	return &domain.ReindexJob{
+		ID:        "job1",
+		TenantID:  &tenantID,
+		StartDate: start,
+		EndDate:   end,
+		Status:    domain.ReindexJobPending,
+		Drift:     []domain.IndexDrift{},
+	}
+}
+
+func (s *ReindexServiceTestSuite) TestCreate_InvalidRange() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateReindexJobRequest{StartDate: "2025-08-01", EndDate: "2025-07-31"}
+
+	// Act
+	job, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidReindexRange)
+	s.Nil(job)
+	s.mockJob.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *ReindexServiceTestSuite) TestCreate_EnqueuesJob() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.CreateReindexJobRequest{TenantID: "tenant1", StartDate: "2025-07-01", EndDate: "2025-07-31", DryRun: true}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockJob.On("Create", ctx, mock.MatchedBy(func(job *domain.ReindexJob) bool {
+		job.ID = "job1"
+		return *job.TenantID == "tenant1" && job.DryRun && job.Status == domain.ReindexJobPending
+	})).Return(nil)
+	s.mockQueue.On("SendReindexMessage", ctx, "job1").Return(nil)
+
+	// Act
+	job, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("job1", job.ID)
+	s.Equal("2025-07-01", job.StartDate)
+	s.mockQueue.AssertExpectations(s.T())
+}
+
+func (s *ReindexServiceTestSuite) TestRun_IndexesMissingLogs() {
+	// Arrange
+	ctx := context.Background()
+	day1 := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	job := reindexJob("tenant1", day1, day2)
+	log1 := domain.AuditLog{ID: "log1", TenantID: "tenant1", Timestamp: day2.Add(time.Hour)}
+	log2 := domain.AuditLog{ID: "log2", TenantID: "tenant1", Timestamp: day2.Add(2 * time.Hour)}
+
+	s.mockJob.On("GetByID", ctx, "job1").Return(job, nil)
+	s.mockJob.On("Update", mock.Anything, job).Return(nil)
+	s.mockAuditLog.On("CountByDay", ctx, "tenant1", day1, day2.AddDate(0, 0, 1)).
+		Return(map[string]int64{"2025-07-01": 5, "2025-07-02": 2}, nil)
+	s.mockOpenSearch.On("CountByDay", ctx, "tenant1", day1, day2.AddDate(0, 0, 1)).
+		Return(map[string]int64{"2025-07-01": 5, "2025-07-02": 1}, nil)
+	s.mockAuditLog.On("List", ctx, mock.MatchedBy(func(filter domain.AuditLogFilter) bool {
+		return filter.TenantID == "tenant1" && filter.StartTime.Equal(day2)
+	})).Return([]domain.AuditLog{log2, log1}, nil)
+	s.mockOpenSearch.On("ExistingIDs", ctx, "tenant1", []string{"log2", "log1"}).Return([]string{"log2"}, nil)
+	s.mockOpenSearch.On("BulkIndex", ctx, []domain.AuditLog{log1}).Return(nil)
+
+	// Act
+	err := s.service.Run(ctx, "job1", nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.ReindexJobCompleted, job.Status)
+	s.Equal(int64(2), job.CheckedDays)
+	s.Equal(int64(1), job.DriftedDays)
+	s.Equal(int64(1), job.MissingLogs)
+	s.Equal(int64(1), job.ReindexedLogs)
+	s.Equal([]domain.IndexDrift{{TenantID: "tenant1", Day: "2025-07-02", PostgresCount: 2, OpenSearchCount: 1, MissingLogs: 1}}, job.Drift)
+	s.Equal(day2, *job.CursorDate)
+	s.mockOpenSearch.AssertExpectations(s.T())
+}
+
+func (s *ReindexServiceTestSuite) TestRun_DryRunResumesAfterCursor() {
+	// Arrange
+	ctx := context.Background()
+	day1 := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	job := reindexJob("tenant1", day1, day2)
+	job.DryRun = true
+	job.Advance("tenant1", day1)
+	job.CheckedDays = 1
+	log1 := domain.AuditLog{ID: "log1", TenantID: "tenant1", Timestamp: day2.Add(time.Hour)}
+
+	s.mockJob.On("GetByID", ctx, "job1").Return(job, nil)
+	s.mockJob.On("Update", mock.Anything, job).Return(nil)
+	s.mockAuditLog.On("CountByDay", ctx, "tenant1", day2, day2.AddDate(0, 0, 1)).
+		Return(map[string]int64{"2025-07-02": 1}, nil)
+	s.mockOpenSearch.On("CountByDay", ctx, "tenant1", day2, day2.AddDate(0, 0, 1)).
+		Return(map[string]int64{}, nil)
+	s.mockAuditLog.On("List", ctx, mock.Anything).Return([]domain.AuditLog{log1}, nil)
+	s.mockOpenSearch.On("ExistingIDs", ctx, "tenant1", []string{"log1"}).Return([]string{}, nil)
+
+	// Act
+	err := s.service.Run(ctx, "job1", nil)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), job.CheckedDays)
+	s.Equal(int64(1), job.MissingLogs)
+	s.Equal(int64(0), job.ReindexedLogs)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "BulkIndex", mock.Anything, mock.Anything)
+}
+
+func (s *ReindexServiceTestSuite) TestRun_InterruptedJobStaysPending() {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	job := reindexJob("tenant1", day, day)
+
+	s.mockJob.On("GetByID", ctx, "job1").Return(job, nil)
+	s.mockJob.On("Update", mock.Anything, job).Return(nil)
+	s.mockAuditLog.On("CountByDay", ctx, "tenant1", day, day.AddDate(0, 0, 1)).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil, context.Canceled)
+
+	// Act
+	err := s.service.Run(ctx, "job1", nil)
+
+	// Assert
+	s.ErrorIs(err, context.Canceled)
+	s.Equal(domain.ReindexJobPending, job.Status)
+	s.Nil(job.CompletedAt)
+	s.Empty(job.Error)
+}
+
+func (s *ReindexServiceTestSuite) TestRun_SkipsJobRunningElsewhere() {
+	// Arrange
+	ctx := context.Background()
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	job := reindexJob("tenant1", day, day)
+	job.Status = domain.ReindexJobRunning
+	job.UpdatedAt = time.Now()
+
+	s.mockJob.On("GetByID", ctx, "job1").Return(job, nil)
+
+	// Act
+	err := s.service.Run(ctx, "job1", nil)
+
+	// Assert
+	s.NoError(err)
+	s.mockJob.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything)
+}
+
+func (s *ReindexServiceTestSuite) TestResume_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockJob.On("GetByID", ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	job, err := s.service.Resume(ctx, "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrReindexJobNotFound)
+	s.Nil(job)
+}
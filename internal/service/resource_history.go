@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// maxHistoryEvents is the number of the latest events of a resource returned
+// in its timeline, older events are left out
+const maxHistoryEvents = 10000
+
+// History returns the timeline of a resource, oldest first, merged from the
+// live logs and the S3 archives when they are configured. The state of the
+// resource is replayed up to asOf, or up to its latest event when asOf is
+// zero, and events after asOf are left out.
+func (s *AuditLogService) History(ctx context.Context, tenantID, resourceType, resourceID string, asOf time.Time) (*dto.ResourceHistoryResponse, error) {
+	filter := domain.AuditLogFilter{
+		TenantID:     tenantID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		EndTime:      asOf,
+	}
+
+	var (
+		events      []domain.AuditLog
+		stateLog    *domain.AuditLog
+		seen        = make(map[string]bool)
+		archivedIDs = make(map[string]bool)
+		truncated   bool
+	)
+	// Only the latest events are kept, the state only depends on the latest
+	// log that set it, which is tracked separately
+	trim := func() {
+		slices.SortFunc(events, func(a, b domain.AuditLog) int {
+			return domain.CompareLogs(&a, &b)
+		})
+		if len(events) > maxHistoryEvents {
+			truncated = true
+			events = events[:maxHistoryEvents]
+		}
+	}
+	add := func(log *domain.AuditLog) {
+		// Logs found both live and in an archive are reported once, as live
+		if seen[log.ID] {
+			return
+		}
+		seen[log.ID] = true
+		if domain.SetsState(log) && (stateLog == nil || domain.CompareLogs(log, stateLog) < 0) {
+			latest := *log
+			stateLog = &latest
+		}
+		events = append(events, *log)
+		if len(events) > 2*maxHistoryEvents {
+			trim()
+		}
+	}
+
+	err := exportLogs(ctx, s.repo.AuditLog(), filter, func(logs []domain.AuditLog) error {
+		for i := range logs {
+			add(&logs[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.archives != nil {
+		endTime := asOf
+		if endTime.IsZero() {
+			endTime = time.Now()
+		}
+		keys, err := s.archivesInRange(ctx, tenantID, time.Time{}, endTime)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			err := s.scanArchive(ctx, key, func(log *domain.AuditLog) {
+				if !filter.Matches(log) || seen[log.ID] {
+					return
+				}
+				archivedIDs[log.ID] = true
+				add(log)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	trim()
+	slices.Reverse(events)
+
+	response := &dto.ResourceHistoryResponse{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Events:       make([]dto.AuditLogResponse, 0, len(events)),
+		Truncated:    truncated,
+	}
+	if !asOf.IsZero() {
+		response.AsOf = &asOf
+	}
+	if stateLog != nil {
+		response.State = domain.StateAfter(stateLog)
+		response.Deleted = stateLog.Action == string(domain.ActionDelete)
+		response.StateLogID = stateLog.ID
+	}
+	for i := range events {
+		event := dto.FromAuditLog(&events[i])
+		event.Diff = dto.FromFieldChanges(domain.DiffStates(events[i].BeforeState, events[i].AfterState))
+		event.Source = dto.LogSourceLive
+		if archivedIDs[events[i].ID] {
+			event.Source = dto.LogSourceArchive
+		}
+		response.Events = append(response.Events, *event)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *AuditLogServiceTestSuite) TestHistory_MergesArchivesAndReplaysState() {
+	// Arrange
+	ctx := context.Background()
+	mockArchives := new(mocks.ArchiveStorage)
+	s.service.SetArchiveStorage(mockArchives)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	resource := func(id, action string, d int, state string) domain.AuditLog {
+		log := domain.AuditLog{ID: id, TenantID: "tenant1", ResourceType: "user", ResourceID: "user1", Action: action, Timestamp: day(d)}
+		if state != "" {
+			log.AfterState = json.RawMessage(state)
+		}
+		return log
+	}
+
+	liveLogs := []domain.AuditLog{
+		resource("4", "VIEW", 12, ""),
+		resource("3", "UPDATE", 8, `{"name":"Bob"}`),
+	}
+	archive, err := json.Marshal(domain.Archive{
+		TenantID: "tenant1",
+		Logs: []domain.AuditLog{
+			resource("1", "CREATE", 2, `{"name":"Alice"}`),
+			{ID: "other", TenantID: "tenant1", ResourceType: "user", ResourceID: "user2", Action: "CREATE", Timestamp: day(3)},
+			resource("3", "UPDATE", 8, `{"name":"Bob"}`),
+		},
+	})
+	s.Require().NoError(err)
+
+	key := domain.ArchiveKey("tenant1", day(10))
+	mockArchives.On("List", ctx, "audit-logs/tenant1/").Return([]domain.ArchiveObject{{Key: key}}, nil)
+	mockArchives.On("Download", ctx, key).Return(io.NopCloser(bytes.NewReader(archive)), nil)
+	s.mockAuditLog.On("List", ctx, mock.MatchedBy(func(f domain.AuditLogFilter) bool {
+		return f.ResourceType == "user" && f.ResourceID == "user1" && f.EndTime.IsZero()
+	})).Return(liveLogs, nil)
+
+	// Act
+	result, err := s.service.History(ctx, "tenant1", "user", "user1", time.Time{})
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(result.Events, 3)
+	s.Equal("1", result.Events[0].ID)
+	s.Equal(dto.LogSourceArchive, result.Events[0].Source)
+	s.Equal("3", result.Events[1].ID)
+	s.Equal(dto.LogSourceLive, result.Events[1].Source)
+	s.Equal("4", result.Events[2].ID)
+	s.JSONEq(`{"name":"Bob"}`, string(result.State))
+	s.Equal("3", result.StateLogID)
+	s.False(result.Deleted)
+	s.False(result.Truncated)
+	mockArchives.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestHistory_AsOfStopsAtInstant() {
+	// Arrange
+	ctx := context.Background()
+	asOf := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	s.mockAuditLog.On("List", ctx, mock.MatchedBy(func(f domain.AuditLogFilter) bool {
+		return f.EndTime.Equal(asOf)
+	})).Return([]domain.AuditLog{
+		{ID: "2", ResourceType: "user", ResourceID: "user1", Action: "DELETE", BeforeState: json.RawMessage(`{"name":"Alice"}`), Timestamp: asOf.Add(-time.Hour)},
+		{ID: "1", ResourceType: "user", ResourceID: "user1", Action: "CREATE", AfterState: json.RawMessage(`{"name":"Alice"}`), Timestamp: asOf.Add(-48 * time.Hour)},
+	}, nil)
+
+	// Act
+	result, err := s.service.History(ctx, "tenant1", "user", "user1", asOf)
+
+	// Assert
+	s.NoError(err)
+	s.Require().Len(result.Events, 2)
+	s.Equal("1", result.Events[0].ID)
+	s.Equal(&asOf, result.AsOf)
+	s.True(result.Deleted)
+	s.Nil(result.State)
+	s.Equal("2", result.StateLogID)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+)
+
+const (
+	// restoreBatchSize is the number of archived logs written and indexed at once
+	restoreBatchSize = 1000
+
+	// restoreLease is how long a running job is left to its worker after its
+	// last update. Messages redelivered within the lease are dropped.
+	restoreLease = 5 * time.Minute
+)
+
+// errRestoreRunLost stops a run whose job was taken over by another run
+var errRestoreRunLost = errors.New("restore job was taken over by another run")
+
+//go:generate mockery --name ArchiveStorage --output ../mocks
+type ArchiveStorage interface {
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	List(ctx context.Context, prefix string) ([]domain.ArchiveObject, error)
+}
+
+type RestoreService struct {
+	repo     repository.Repository
+	queueSvc QueueService
+	storage  ArchiveStorage
+}
+
+func NewRestoreService(repo repository.Repository, queueSvc QueueService, storage ArchiveStorage) *RestoreService {
+	return &RestoreService{
+		repo:     repo,
+		queueSvc: queueSvc,
+		storage:  storage,
+	}
+}
+
+// Create stores a pending restore job for the tenant in the context and
+// enqueues it for the archive worker. Only archives of the tenant can be restored.
+func (s *RestoreService) Create(ctx context.Context, req dto.CreateRestoreJobRequest) (*dto.RestoreJobResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(req.ArchiveKey, domain.ArchiveKeyPrefix(tenantID)) {
+		return nil, ErrArchiveNotFound
+	}
+
+	job := &domain.RestoreJob{
+		TenantID:   tenantID,
+		ArchiveKey: req.ArchiveKey,
+		Status:     domain.RestoreJobPending,
+	}
+	if err := s.repo.RestoreJob().Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create restore job: %w", err)
+	}
+
+	if err := s.queueSvc.SendRestoreMessage(ctx, tenantID, job.ID); err != nil {
+		job.Status = domain.RestoreJobFailed
+		job.Error = "failed to enqueue restore job"
+		if updateErr := s.repo.RestoreJob().Update(ctx, job); updateErr != nil {
+			fmt.Printf("failed to mark restore job %s as failed: %v\n", job.ID, updateErr)
+		}
+		return nil, fmt.Errorf("failed to enqueue restore job: %w", err)
+	}
+
+	return dto.FromRestoreJob(job), nil
+}
+
+// Get returns the restore job of the tenant in the context
+func (s *RestoreService) Get(ctx context.Context, id string) (*dto.RestoreJobResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.RestoreJob().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRestoreJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.FromRestoreJob(job), nil
+}
+
+// ListArchives returns the archives of the tenant in the context
+func (s *RestoreService) ListArchives(ctx context.Context) ([]dto.ArchiveResponse, error) {
+	tenantID, err := utils.GetTenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := s.storage.List(ctx, domain.ArchiveKeyPrefix(tenantID))
+	if err != nil {
+		return nil, err
+	}
+
+	archives := make([]dto.ArchiveResponse, len(objects))
+	for i, obj := range objects {
+		archives[i] = dto.FromArchiveObject(obj)
+	}
+	return archives, nil
+}
+
+// Run restores the archive of the job. Failures are recorded on the job. Jobs
+// that already finished and jobs another worker is running are skipped, and
+// logs that are already stored are not written again, so a redelivered
+// message is safe to run. A run only records the job while it owns it, a run
+// whose job was taken over after its lease expired stops.
+func (s *RestoreService) Run(ctx context.Context, tenantID, id string) error {
+	job, err := s.repo.RestoreJob().GetByID(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get restore job %s: %w", id, err)
+	}
+	if job.Status == domain.RestoreJobCompleted || job.Status == domain.RestoreJobFailed {
+		return nil
+	}
+	if job.Status == domain.RestoreJobRunning && time.Since(job.UpdatedAt) < restoreLease {
+		return nil
+	}
+
+	// A job interrupted while running starts over, its counters would
+	// otherwise count the same logs twice. The run is known by its start
+	// time, PostgreSQL keeps microseconds.
+	previousRun := job.StartedAt
+	startedAt := time.Now().Truncate(time.Microsecond)
+	job.Status = domain.RestoreJobRunning
+	job.StartedAt = &startedAt
+	job.TotalLogs, job.RestoredLogs, job.SkippedLogs, job.IndexedLogs = 0, 0, 0, 0
+	job.ChainValid = nil
+	claimed, err := s.repo.RestoreJob().UpdateRun(ctx, job, previousRun)
+	if err != nil {
+		return fmt.Errorf("failed to start restore job %s: %w", id, err)
+	}
+	if !claimed {
+		// Another worker started the job meanwhile
+		return nil
+	}
+
+	runErr := s.restore(ctx, job)
+	if errors.Is(runErr, errRestoreRunLost) {
+		return nil
+	}
+
+	// The job of a stopping worker is recorded after its context is cancelled
+	updateCtx := ctx
+	completedAt := time.Now()
+	switch {
+	case runErr != nil && ctx.Err() != nil:
+		// A stopping worker returns the message, and the job starts over when
+		// it is received again
+		job.Status = domain.RestoreJobPending
+		updateCtx = context.WithoutCancel(ctx)
+	case runErr != nil:
+		job.CompletedAt = &completedAt
+		job.Status = domain.RestoreJobFailed
+		job.Error = runErr.Error()
+	default:
+		job.CompletedAt = &completedAt
+		job.Status = domain.RestoreJobCompleted
+	}
+	owned, err := s.repo.RestoreJob().UpdateRun(updateCtx, job, &startedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish restore job %s: %w", id, err)
+	}
+	if !owned {
+		// The run that took the job over records its outcome
+		return nil
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("restore job %s failed: %w", id, runErr)
+	}
+	return nil
+}
+
+// restore reads the archive of the job and writes its logs back in batches,
+// recording the progress on the job after each batch, which keeps its lease
+func (s *RestoreService) restore(ctx context.Context, job *domain.RestoreJob) error {
+	archive, err := s.readArchive(ctx, job.ArchiveKey)
+	if err != nil {
+		return err
+	}
+	if archive.TenantID != job.TenantID {
+		return fmt.Errorf("archive %s belongs to another tenant", job.ArchiveKey)
+	}
+
+	// The archive is checked against its own anchor. A broken chain is
+	// reported on the job but the logs are restored as archived.
+	slices.SortStableFunc(archive.Logs, func(a, b domain.AuditLog) int {
+		return cmp.Compare(a.ChainSeq, b.ChainSeq)
+	})
+	if archive.Chain != nil {
+		brk := verifyArchiveChain(archive)
+		if brk != nil {
+			fmt.Printf("restore job %s: archive chain broken at %d: %s\n", job.ID, brk.ChainSeq, brk.Reason)
+		}
+		valid := brk == nil
+		job.ChainValid = &valid
+	}
+	job.TotalLogs = int64(len(archive.Logs))
+	if err := s.saveProgress(ctx, job); err != nil {
+		return err
+	}
+
+	// Workers have no request claims, the repository reads the tenant from the context
+	restoreCtx := utils.WithRestore(utils.WithTenantID(ctx, job.TenantID))
+	for start := 0; start < len(archive.Logs); start += restoreBatchSize {
+		batch := archive.Logs[start:min(start+restoreBatchSize, len(archive.Logs))]
+
+		missing, err := s.missingLogs(ctx, job.TenantID, batch)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			if err := s.repo.AuditLog().BulkCreate(restoreCtx, missing); err != nil {
+				return fmt.Errorf("failed to restore logs: %w", err)
+			}
+		}
+		job.RestoredLogs += int64(len(missing))
+		job.SkippedLogs += int64(len(batch) - len(missing))
+
+		// Indexing is idempotent, so logs that were already stored are
+		// indexed again in case they are missing from OpenSearch
+		if err := s.repo.OpenSearch().BulkIndex(ctx, batch); err != nil {
+			return fmt.Errorf("failed to index restored logs: %w", err)
+		}
+		job.IndexedLogs += int64(len(batch))
+
+		if err := s.saveProgress(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveProgress records the counters of the job, as long as the run still owns it
+func (s *RestoreService) saveProgress(ctx context.Context, job *domain.RestoreJob) error {
+	owned, err := s.repo.RestoreJob().UpdateRun(ctx, job, job.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update restore job: %w", err)
+	}
+	if !owned {
+		return errRestoreRunLost
+	}
+	return nil
+}
+
+func (s *RestoreService) readArchive(ctx context.Context, key string) (*domain.Archive, error) {
+	body, err := s.storage.Download(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var archive domain.Archive
+	if err := json.NewDecoder(body).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("failed to decode archive %s: %w", key, err)
+	}
+	return &archive, nil
+}
+
+// missingLogs returns the logs of the batch that are not stored yet
+func (s *RestoreService) missingLogs(ctx context.Context, tenantID string, batch []domain.AuditLog) ([]domain.AuditLog, error) {
+	ids := make([]string, len(batch))
+	startTime, endTime := batch[0].Timestamp, batch[0].Timestamp
+	for i := range batch {
+		ids[i] = batch[i].ID
+		if batch[i].Timestamp.Before(startTime) {
+			startTime = batch[i].Timestamp
+		}
+		if batch[i].Timestamp.After(endTime) {
+			endTime = batch[i].Timestamp
+		}
+	}
+
+	existing, err := s.repo.AuditLog().ExistingIDs(ctx, tenantID, ids, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for restored logs: %w", err)
+	}
+	stored := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		stored[id] = true
+	}
+
+	missing := make([]domain.AuditLog, 0, len(batch)-len(existing))
+	for _, log := range batch {
+		if !stored[log.ID] {
+			missing = append(missing, log)
+		}
+	}
+	return missing, nil
+}
+
+// verifyArchiveChain walks the chained logs of the archive, which must be
+// sorted by ChainSeq, from the first to the last link of its anchor
+func verifyArchiveChain(archive *domain.Archive) *domain.ChainBreak {
+	prevSeq, prevHash := archive.Chain.FirstSeq-1, archive.Chain.FirstPrevHash
+	for i := range archive.Logs {
+		if archive.Logs[i].ChainSeq == 0 {
+			continue
+		}
+		if brk := domain.VerifyLink(prevSeq, prevHash, &archive.Logs[i]); brk != nil {
+			return brk
+		}
+		prevSeq, prevHash = archive.Logs[i].ChainSeq, archive.Logs[i].Hash
+	}
+
+	if prevSeq != archive.Chain.LastSeq || prevHash != archive.Chain.LastHash {
+		return &domain.ChainBreak{
+			ChainSeq: prevSeq,
+			Reason:   domain.ChainBreakSequenceGap,
+			Expected: fmt.Sprintf("%d", archive.Chain.LastSeq),
+			Actual:   fmt.Sprintf("%d", prevSeq),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RestoreServiceTestSuite struct {
+	suite.Suite
+	mockRepo       *mocks.Repository
+	mockRestoreJob *mocks.RestoreJobRepository
+	mockAuditLog   *mocks.AuditLogRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	mockQueue      *mocks.QueueService
+	mockStorage    *mocks.ArchiveStorage
+	service        *RestoreService
+}
+
+func (s *RestoreServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockRestoreJob = new(mocks.RestoreJobRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOpenSearch = new(mocks.OpenSearchRepository)
+	s.mockQueue = new(mocks.QueueService)
+	s.mockStorage = new(mocks.ArchiveStorage)
+
+	s.mockRepo.On("RestoreJob").Return(s.mockRestoreJob)
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+
+	s.service = NewRestoreService(s.mockRepo, s.mockQueue, s.mockStorage)
+}
+
+func TestRestoreService(t *testing.T) {
+	suite.Run(t, new(RestoreServiceTestSuite))
+}
+
+func (s *RestoreServiceTestSuite) TestCreate_EnqueuesJob() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateRestoreJobRequest{ArchiveKey: "audit-logs/tenant1/archive.json"}
+
+	s.mockRestoreJob.On("Create", ctx, mock.MatchedBy(func(job *domain.RestoreJob) bool {
+		return job.TenantID == "tenant1" &&
+			job.ArchiveKey == req.ArchiveKey &&
+			job.Status == domain.RestoreJobPending
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.RestoreJob).ID = "job1"
+	}).Return(nil)
+	s.mockQueue.On("SendRestoreMessage", ctx, "tenant1", "job1").Return(nil)
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("job1", result.ID)
+	s.mockRestoreJob.AssertExpectations(s.T())
+	s.mockQueue.AssertExpectations(s.T())
+}
+
+func (s *RestoreServiceTestSuite) TestCreate_ArchiveOfAnotherTenant() {
+	// Arrange
+	ctx := tenantContext("tenant1")
+	req := dto.CreateRestoreJobRequest{ArchiveKey: "audit-logs/tenant2/archive.json"}
+
+	// Act
+	result, err := s.service.Create(ctx, req)
+
+	// Assert
+	s.ErrorIs(err, ErrArchiveNotFound)
+	s.Nil(result)
+	s.mockRestoreJob.AssertNotCalled(s.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (s *RestoreServiceTestSuite) TestRun_SkipsStoredLogsAndIndexesAll() {
+	// Arrange
+	ctx := context.Background()
+	logs := sealedLogs(3)
+	archive := domain.Archive{
+		TenantID: "tenant1",
+		LogCount: len(logs),
+		Chain:    domain.NewChainAnchor(logs),
+		Logs:     logs,
+	}
+	data, err := json.Marshal(archive)
+	s.Require().NoError(err)
+
+	job := &domain.RestoreJob{
+		ID:         "job1",
+		TenantID:   "tenant1",
+		ArchiveKey: "audit-logs/tenant1/archive.json",
+		Status:     domain.RestoreJobPending,
+	}
+
+	s.mockRestoreJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockRestoreJob.On("UpdateRun", ctx, job, mock.Anything).Return(true, nil)
+	s.mockStorage.On("Download", ctx, job.ArchiveKey).Return(io.NopCloser(bytes.NewReader(data)), nil)
+	s.mockAuditLog.On("ExistingIDs", ctx, "tenant1", []string{"log1", "log2", "log3"}, mock.Anything, mock.Anything).
+		Return([]string{"log2"}, nil)
+	s.mockAuditLog.On("BulkCreate", mock.MatchedBy(func(ctx context.Context) bool {
+		tenantID, err := utils.GetTenantIDFromContext(ctx)
+		return err == nil && tenantID == "tenant1" && utils.IsRestore(ctx)
+	}), mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 2 && logs[0].ID == "log1" && logs[1].ID == "log3"
+	})).Return(nil)
+	s.mockOpenSearch.On("BulkIndex", ctx, mock.MatchedBy(func(logs []domain.AuditLog) bool {
+		return len(logs) == 3
+	})).Return(nil)
+
+	// Act
+	err = s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.RestoreJobCompleted, job.Status)
+	s.Equal(int64(3), job.TotalLogs)
+	s.Equal(int64(2), job.RestoredLogs)
+	s.Equal(int64(1), job.SkippedLogs)
+	s.Equal(int64(3), job.IndexedLogs)
+	s.Require().NotNil(job.ChainValid)
+	s.True(*job.ChainValid)
+	s.mockAuditLog.AssertExpectations(s.T())
+	s.mockOpenSearch.AssertExpectations(s.T())
+}
+
+func (s *RestoreServiceTestSuite) TestRun_ReportsBrokenChain() {
+	// Arrange
+	ctx := context.Background()
+	logs := sealedLogs(2)
+	anchor := domain.NewChainAnchor(logs)
+	logs[1].Message = "tampered"
+	data, err := json.Marshal(domain.Archive{TenantID: "tenant1", Chain: anchor, Logs: logs})
+	s.Require().NoError(err)
+
+	job := &domain.RestoreJob{ID: "job1", TenantID: "tenant1", ArchiveKey: "audit-logs/tenant1/archive.json"}
+
+	s.mockRestoreJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+	s.mockRestoreJob.On("UpdateRun", ctx, job, mock.Anything).Return(true, nil)
+	s.mockStorage.On("Download", ctx, job.ArchiveKey).Return(io.NopCloser(bytes.NewReader(data)), nil)
+	s.mockAuditLog.On("ExistingIDs", ctx, "tenant1", mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{"log1", "log2"}, nil)
+	s.mockOpenSearch.On("BulkIndex", ctx, mock.Anything).Return(nil)
+
+	// Act
+	err = s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.RestoreJobCompleted, job.Status)
+	s.Require().NotNil(job.ChainValid)
+	s.False(*job.ChainValid)
+	s.mockAuditLog.AssertNotCalled(s.T(), "BulkCreate", mock.Anything, mock.Anything)
+}
+
+func (s *RestoreServiceTestSuite) TestRun_SkipsFinishedJob() {
+	// Arrange
+	ctx := context.Background()
+	job := &domain.RestoreJob{ID: "job1", TenantID: "tenant1", Status: domain.RestoreJobCompleted}
+	s.mockRestoreJob.On("GetByID", ctx, "tenant1", "job1").Return(job, nil)
+
+	// Act
+	err := s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.mockStorage.AssertNotCalled(s.T(), "Download", mock.Anything, mock.Anything)
+}
+
+// storeJob keeps the job as the database would, the job read is a copy and
+// saving it only succeeds for the run that owns it
+func (s *RestoreServiceTestSuite) storeJob(stored domain.RestoreJob) *domain.RestoreJob {
+	s.mockRestoreJob.On("GetByID", mock.Anything, stored.TenantID, stored.ID).Return(
+		func(context.Context, string, string) (*domain.RestoreJob, error) {
+			job := stored
+			return &job, nil
+		})
+	s.mockRestoreJob.On("UpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, job *domain.RestoreJob, startedAt *time.Time) (bool, error) {
+			if (startedAt == nil) != (stored.StartedAt == nil) || (startedAt != nil && !startedAt.Equal(*stored.StartedAt)) {
+				return false, nil
+			}
+			stored = *job
+			stored.UpdatedAt = time.Now()
+			return true, nil
+		})
+	return &stored
+}
+
+func (s *RestoreServiceTestSuite) TestRun_SkipsDeliveryDuringRun() {
+	// Arrange
+	ctx := context.Background()
+	logs := sealedLogs(2)
+	data, err := json.Marshal(domain.Archive{TenantID: "tenant1", Chain: domain.NewChainAnchor(logs), Logs: logs})
+	s.Require().NoError(err)
+	stored := s.storeJob(domain.RestoreJob{
+		ID:         "job1",
+		TenantID:   "tenant1",
+		ArchiveKey: "audit-logs/tenant1/archive.json",
+		Status:     domain.RestoreJobPending,
+	})
+
+	var redelivered error
+	s.mockStorage.On("Download", ctx, "audit-logs/tenant1/archive.json").Run(func(mock.Arguments) {
+		// The message is received again while the archive is read
+		redelivered = s.service.Run(ctx, "tenant1", "job1")
+	}).Return(io.NopCloser(bytes.NewReader(data)), nil).Once()
+	s.mockAuditLog.On("ExistingIDs", ctx, "tenant1", mock.Anything, mock.Anything, mock.Anything).Return([]string{}, nil)
+	s.mockAuditLog.On("BulkCreate", mock.Anything, mock.Anything).Return(nil)
+	s.mockOpenSearch.On("BulkIndex", ctx, mock.Anything).Return(nil)
+
+	// Act
+	err = s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.NoError(redelivered)
+	s.mockStorage.AssertNumberOfCalls(s.T(), "Download", 1)
+	s.mockAuditLog.AssertNumberOfCalls(s.T(), "BulkCreate", 1)
+	s.Equal(domain.RestoreJobCompleted, stored.Status)
+	s.Equal(int64(2), stored.TotalLogs)
+	s.Equal(int64(2), stored.RestoredLogs)
+	s.Equal(int64(2), stored.IndexedLogs)
+}
+
+func (s *RestoreServiceTestSuite) TestRun_TakesOverJobAfterLease() {
+	// Arrange
+	ctx := context.Background()
+	data, err := json.Marshal(domain.Archive{TenantID: "tenant1", Logs: []domain.AuditLog{{ID: "log1", TenantID: "tenant1"}}})
+	s.Require().NoError(err)
+	previousRun := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	stored := s.storeJob(domain.RestoreJob{
+		ID:           "job1",
+		TenantID:     "tenant1",
+		ArchiveKey:   "audit-logs/tenant1/archive.json",
+		Status:       domain.RestoreJobRunning,
+		RestoredLogs: 7,
+		StartedAt:    &previousRun,
+		UpdatedAt:    time.Now().Add(-restoreLease - time.Minute),
+	})
+	s.mockStorage.On("Download", ctx, "audit-logs/tenant1/archive.json").Return(io.NopCloser(bytes.NewReader(data)), nil)
+	s.mockAuditLog.On("ExistingIDs", ctx, "tenant1", []string{"log1"}, mock.Anything, mock.Anything).Return([]string{"log1"}, nil)
+	s.mockOpenSearch.On("BulkIndex", ctx, mock.Anything).Return(nil)
+
+	// Act
+	err = s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(domain.RestoreJobCompleted, stored.Status)
+	s.True(stored.StartedAt.After(previousRun))
+	s.Equal(int64(0), stored.RestoredLogs)
+	s.Equal(int64(1), stored.SkippedLogs)
+}
+
+func (s *RestoreServiceTestSuite) TestRun_StopsWhenTakenOver() {
+	// Arrange
+	ctx := context.Background()
+	data, err := json.Marshal(domain.Archive{TenantID: "tenant1", Logs: []domain.AuditLog{{ID: "log1", TenantID: "tenant1"}}})
+	s.Require().NoError(err)
+	stored := s.storeJob(domain.RestoreJob{ID: "job1", TenantID: "tenant1", ArchiveKey: "audit-logs/tenant1/archive.json"})
+	otherRun := time.Now().Add(time.Minute)
+	s.mockStorage.On("Download", ctx, "audit-logs/tenant1/archive.json").Run(func(mock.Arguments) {
+		// Another run takes the job over while the archive is read
+		stored.StartedAt = &otherRun
+	}).Return(io.NopCloser(bytes.NewReader(data)), nil)
+
+	// Act
+	err = s.service.Run(ctx, "tenant1", "job1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal(&otherRun, stored.StartedAt)
+	s.Equal(int64(0), stored.TotalLogs)
+	s.mockAuditLog.AssertNotCalled(s.T(), "ExistingIDs", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
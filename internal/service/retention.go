@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+)
+
+type RetentionService struct {
+	repo repository.PostgresRepository
+}
+
+func NewRetentionService(repo repository.PostgresRepository) *RetentionService {
+	return &RetentionService{
+		repo: repo,
+	}
+}
+
+// Get returns the retention policy of the tenant
+func (s *RetentionService) Get(ctx context.Context, tenantID string) (*dto.RetentionPolicyResponse, error) {
+	policy, err := s.repo.RetentionPolicy().Get(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRetentionPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromRetentionPolicy(policy), nil
+}
+
+// Put creates or replaces the retention policy of the tenant. Logs are only
+// deleted after they have been archived, so deletion may not come first.
+func (s *RetentionService) Put(ctx context.Context, tenantID string, req dto.RetentionPolicyRequest) (*dto.RetentionPolicyResponse, error) {
+	if req.DeleteAfterDays != 0 && req.DeleteAfterDays < req.ArchiveAfterDays {
+		return nil, ErrInvalidRetentionPolicy
+	}
+
+	if _, err := s.repo.Tenant().GetByID(ctx, tenantID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	policy := &domain.RetentionPolicy{
+		TenantID:         tenantID,
+		ArchiveAfterDays: req.ArchiveAfterDays,
+		DeleteAfterDays:  req.DeleteAfterDays,
+		KeepSeverities:   req.KeepSeverities,
+		Enabled:          req.Enabled == nil || *req.Enabled,
+		UpdatedAt:        time.Now(),
+	}
+	if policy.KeepSeverities == nil {
+		policy.KeepSeverities = []string{}
+	}
+	if err := s.repo.RetentionPolicy().Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, tenantID)
+}
+
+// Delete removes the retention policy of the tenant, its logs are then only
+// archived and cleaned up on request
+func (s *RetentionService) Delete(ctx context.Context, tenantID string) error {
+	err := s.repo.RetentionPolicy().Delete(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRetentionPolicyNotFound
+	}
+	return err
+}
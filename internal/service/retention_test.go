@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RetentionServiceTestSuite struct {
+	suite.Suite
+	mockRepo   *mocks.PostgresRepository
+	mockTenant *mocks.TenantRepository
+	mockPolicy *mocks.RetentionPolicyRepository
+	service    *RetentionService
+}
+
+func (s *RetentionServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.PostgresRepository)
+	s.mockTenant = new(mocks.TenantRepository)
+	s.mockPolicy = new(mocks.RetentionPolicyRepository)
+
+	s.mockRepo.On("Tenant").Return(s.mockTenant)
+	s.mockRepo.On("RetentionPolicy").Return(s.mockPolicy)
+
+	s.service = NewRetentionService(s.mockRepo)
+}
+
+func TestRetentionService(t *testing.T) {
+	suite.Run(t, new(RetentionServiceTestSuite))
+}
+
+func (s *RetentionServiceTestSuite) TestPut_StoresPolicy() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.RetentionPolicyRequest{
+		ArchiveAfterDays: 90,
+		DeleteAfterDays:  400,
+		KeepSeverities:   []string{"CRITICAL"},
+	}
+	stored := &domain.RetentionPolicy{
+		TenantID:         "tenant1",
+		ArchiveAfterDays: 90,
+		DeleteAfterDays:  400,
+		KeepSeverities:   []string{"CRITICAL"},
+		Enabled:          true,
+	}
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockPolicy.On("Upsert", ctx, mock.MatchedBy(func(p *domain.RetentionPolicy) bool {
+		return p.TenantID == "tenant1" && p.ArchiveAfterDays == 90 && p.DeleteAfterDays == 400 && p.Enabled
+	})).Return(nil)
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(stored, nil)
+
+	// Act
+	result, err := s.service.Put(ctx, "tenant1", req)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(400, result.DeleteAfterDays)
+	s.Equal([]string{"CRITICAL"}, result.KeepSeverities)
+	s.mockPolicy.AssertExpectations(s.T())
+}
+
+func (s *RetentionServiceTestSuite) TestPut_DeleteBeforeArchive() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.RetentionPolicyRequest{ArchiveAfterDays: 90, DeleteAfterDays: 30}
+
+	// Act
+	result, err := s.service.Put(ctx, "tenant1", req)
+
+	// Assert
+	s.ErrorIs(err, ErrInvalidRetentionPolicy)
+	s.Nil(result)
+	s.mockPolicy.AssertNotCalled(s.T(), "Upsert", mock.Anything, mock.Anything)
+}
+
+func (s *RetentionServiceTestSuite) TestPut_TenantNotFound() {
+	// Arrange
+	ctx := context.Background()
+	req := dto.RetentionPolicyRequest{ArchiveAfterDays: 90}
+	s.mockTenant.On("GetByID", ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := s.service.Put(ctx, "missing", req)
+
+	// Assert
+	s.ErrorIs(err, ErrTenantNotFound)
+	s.Nil(result)
+}
+
+func (s *RetentionServiceTestSuite) TestGet_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockPolicy.On("Get", ctx, "tenant1").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	result, err := s.service.Get(ctx, "tenant1")
+
+	// Assert
+	s.ErrorIs(err, ErrRetentionPolicyNotFound)
+	s.Nil(result)
+}
+
+func (s *RetentionServiceTestSuite) TestDeleteBefore_KeepsRetentionPeriod() {
+	// Arrange
+	now := time.Date(2025, 7, 17, 15, 0, 0, 0, time.UTC)
+	policy := &domain.RetentionPolicy{ArchiveAfterDays: 90, DeleteAfterDays: 400}
+
+	// Act
+	clamped, ok := policy.DeleteBefore(now, now)
+	older, _ := policy.DeleteBefore(now.AddDate(-2, 0, 0), now)
+	_, never := (&domain.RetentionPolicy{ArchiveAfterDays: 90}).DeleteBefore(now, now)
+
+	// Assert
+	s.True(ok)
+	s.Equal(time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), clamped)
+	s.Equal(now.AddDate(-2, 0, 0), older)
+	s.False(never)
+}
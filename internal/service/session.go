@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+const (
+	// defaultActivitySessions and maxActivitySessions bound the sessions
+	// listed in the activity of a user
+	defaultActivitySessions = 20
+	maxActivitySessions     = 500
+	// maxSessionEvents is the number of the latest events of a session
+	// returned with it, older events are left out
+	maxSessionEvents = 1000
+)
+
+// UserActivity groups the logs of filter.UserID by session, the sessions with
+// the most recent events first. filter.PageSize bounds the sessions listed.
+func (s *AuditLogService) UserActivity(ctx context.Context, filter *domain.AuditLogFilter) (*dto.UserActivityResponse, error) {
+	limit := filter.PageSize
+	if limit < 1 {
+		limit = defaultActivitySessions
+	}
+	limit = min(limit, maxActivitySessions)
+
+	sessions, total, err := s.sessions(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UserActivityResponse{
+		UserID:        filter.UserID,
+		Sessions:      make([]dto.SessionSummaryResponse, 0, len(sessions)),
+		TotalSessions: total,
+	}
+	for i := range sessions {
+		response.Sessions = append(response.Sessions, dto.FromSessionSummary(&sessions[i]))
+	}
+	return response, nil
+}
+
+// GetSession sums up the session filter.SessionID and returns its events,
+// oldest first
+func (s *AuditLogService) GetSession(ctx context.Context, filter *domain.AuditLogFilter) (*dto.SessionResponse, error) {
+	sessions, _, err := s.sessions(ctx, filter, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, ErrSessionNotFound
+	}
+
+	eventFilter := *filter
+	eventFilter.Limit = maxSessionEvents + 1
+	eventFilter.Offset = 0
+	eventFilter.After = nil
+	logs, _, err := s.listLive(ctx, &eventFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.SessionResponse{
+		SessionSummaryResponse: dto.FromSessionSummary(&sessions[0]),
+	}
+	if len(logs) > maxSessionEvents {
+		logs = logs[:maxSessionEvents]
+		response.Truncated = true
+	}
+	slices.Reverse(logs)
+	response.Events = dto.FromAuditLogs(logs)
+	return response, nil
+}
+
+// sessions sums up the sessions of the logs matching the filter with
+// OpenSearch aggregations when OpenSearch can answer the filter, otherwise
+// by walking the logs in PostgreSQL
+func (s *AuditLogService) sessions(ctx context.Context, filter *domain.AuditLogFilter, limit int) ([]domain.SessionSummary, int64, error) {
+	if s.hasSearchCriteria(filter) {
+		indexed, err := s.indexesJSONPaths(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		if indexed {
+			return s.repo.OpenSearch().Sessions(ctx, filter, limit)
+		}
+	}
+
+	summaries := make(map[string]*domain.SessionSummary)
+	err := exportLogs(ctx, s.repo.AuditLog(), *filter, func(logs []domain.AuditLog) error {
+		for i := range logs {
+			if logs[i].SessionID == "" {
+				continue
+			}
+			summary, ok := summaries[logs[i].SessionID]
+			if !ok {
+				summary = domain.NewSessionSummary(logs[i].SessionID)
+				summaries[logs[i].SessionID] = summary
+			}
+			summary.Add(&logs[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sessions := make([]domain.SessionSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		sessions = append(sessions, *summary)
+	}
+	slices.SortFunc(sessions, func(a, b domain.SessionSummary) int {
+		return domain.CompareSessions(&a, &b)
+	})
+	return sessions[:min(limit, len(sessions))], int64(len(sessions)), nil
+}
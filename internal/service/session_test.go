@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *AuditLogServiceTestSuite) TestUserActivity_UsesOpenSearchAggregations() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", UserID: "user1"}
+	session := domain.NewSessionSummary("sess1")
+	session.EventCount = 2
+	session.IPAddresses = []string{"10.0.0.1"}
+	session.ActionCounts["VIEW"] = 2
+
+	s.mockOpenSearch.On("Sessions", ctx, filter, defaultActivitySessions).Return([]domain.SessionSummary{*session}, int64(1), nil)
+
+	// Act
+	result, err := s.service.UserActivity(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("user1", result.UserID)
+	s.Equal(int64(1), result.TotalSessions)
+	s.Require().Len(result.Sessions, 1)
+	s.Equal("sess1", result.Sessions[0].SessionID)
+	s.Equal(map[string]int64{"VIEW": 2}, result.Sessions[0].ActionCounts)
+	s.mockAuditLog.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestUserActivity_UnindexedPathGroupsLogsFromPostgres() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{
+		TenantID:    "tenant1",
+		UserID:      "user1",
+		JSONFilters: []domain.JSONFilter{{Field: "metadata", Path: []string{"region"}, Op: domain.JSONFilterEq, Value: "eu"}},
+		PageSize:    1,
+	}
+	at := func(m int) time.Time { return time.Date(2024, 1, 1, 12, m, 0, 0, time.UTC) }
+
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockAuditLog.On("List", ctx, mock.AnythingOfType("domain.AuditLogFilter")).Return([]domain.AuditLog{
+		{ID: "5", SessionID: "sess2", Action: "VIEW", IPAddress: "10.0.0.2", UserAgent: "curl", Timestamp: at(50)},
+		{ID: "4", Action: "VIEW", Timestamp: at(40)},
+		{ID: "3", SessionID: "sess2", Action: "UPDATE", IPAddress: "10.0.0.1", UserAgent: "curl", Timestamp: at(30)},
+		{ID: "2", SessionID: "sess1", Action: "VIEW", IPAddress: "10.0.0.1", Timestamp: at(20)},
+		{ID: "1", SessionID: "sess2", Action: "VIEW", IPAddress: "10.0.0.2", UserAgent: "curl", Timestamp: at(10)},
+	}, nil)
+
+	// Act
+	result, err := s.service.UserActivity(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal(int64(2), result.TotalSessions)
+	s.Require().Len(result.Sessions, 1)
+	session := result.Sessions[0]
+	s.Equal("sess2", session.SessionID)
+	s.Equal(at(10), session.StartTime)
+	s.Equal(at(50), session.EndTime)
+	s.Equal(int64(3), session.EventCount)
+	s.Equal([]string{"10.0.0.1", "10.0.0.2"}, session.IPAddresses)
+	s.Equal([]string{"curl"}, session.UserAgents)
+	s.Equal(map[string]int64{"VIEW": 2, "UPDATE": 1}, session.ActionCounts)
+	s.Equal("1", session.FirstEvent.ID)
+	s.Equal("5", session.LastEvent.ID)
+	s.mockOpenSearch.AssertNotCalled(s.T(), "Sessions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetSession_ReturnsEventsOldestFirst() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", SessionID: "sess1"}
+
+	s.mockOpenSearch.On("Sessions", ctx, filter, 1).Return([]domain.SessionSummary{*domain.NewSessionSummary("sess1")}, int64(1), nil)
+	s.mockOpenSearch.On("Search", ctx, mock.MatchedBy(func(f *domain.AuditLogFilter) bool {
+		return f.SessionID == "sess1" && f.Limit == maxSessionEvents+1
+	})).Return([]domain.AuditLog{{ID: "2"}, {ID: "1"}}, int64(2), nil)
+
+	// Act
+	result, err := s.service.GetSession(ctx, filter)
+
+	// Assert
+	s.NoError(err)
+	s.Equal("sess1", result.SessionID)
+	s.Require().Len(result.Events, 2)
+	s.Equal("1", result.Events[0].ID)
+	s.False(result.Truncated)
+}
+
+func (s *AuditLogServiceTestSuite) TestGetSession_NotFound() {
+	// Arrange
+	ctx := context.Background()
+	filter := &domain.AuditLogFilter{TenantID: "tenant1", SessionID: "missing"}
+
+	s.mockOpenSearch.On("Sessions", ctx, filter, 1).Return([]domain.SessionSummary{}, int64(0), nil)
+
+	// Act
+	result, err := s.service.GetSession(ctx, filter)
+
+	// Assert
+	s.ErrorIs(err, ErrSessionNotFound)
+	s.Nil(result)
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+// S3Storage stores files in the bucket from the S3 configuration
+type S3Storage struct {
+	client    *s3.Client
+	presigner *s3.PresignClient
+	bucket    string
+}
+
+func NewS3Storage(client *s3.Client, config *config.S3Config) *S3Storage {
+	return &S3Storage{
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+		bucket:    config.BucketName,
+	}
+}
+
+// Upload writes the body to the given key
+func (s *S3Storage) Upload(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
+
+// PresignGetObject returns a URL that downloads the object without
+// credentials until it expires
+func (s *S3Storage) PresignGetObject(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return req.URL, nil
+}
+
+// Delete removes the object, deleting a missing object is not an error
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return nil
+}
+
+// Download opens the object for reading, the caller must close it
+func (s *S3Storage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download s3://%s/%s: %w", s.bucket, key, err)
+	}
+	return out.Body, nil
+}
+
+// List returns the objects whose keys start with the prefix
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]domain.ArchiveObject, error) {
+	var objects []domain.ArchiveObject
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.bucket, prefix, err)
+		}
+		for _, obj := range page.Contents {
+			objects = append(objects, domain.ArchiveObject{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+
+	return objects, nil
+}
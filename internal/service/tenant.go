@@ -2,24 +2,40 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
 	"github.com/buiminhduc234/audit-log-api/internal/repository"
 )
 
+//go:generate mockery --name RateLimitInvalidator --output ../mocks
+type RateLimitInvalidator interface {
+	InvalidateTenantLimit(ctx context.Context, tenantID string) error
+}
+
 type TenantService struct {
-	repo repository.Repository
+	repo                 repository.Repository
+	rateLimitInvalidator RateLimitInvalidator
 }
 
 func NewTenantService(repo repository.Repository) *TenantService {
 	return &TenantService{repo: repo}
 }
 
+// SetRateLimitInvalidator sets the invalidator notified when a tenant's rate limit changes
+func (s *TenantService) SetRateLimitInvalidator(invalidator RateLimitInvalidator) {
+	s.rateLimitInvalidator = invalidator
+}
+
 func (s *TenantService) Create(ctx context.Context, req dto.CreateTenantRequest) (dto.CreateTenantResponse, error) {
 	tenant := &domain.Tenant{
-		Name: req.Name,
+		Name:         req.Name,
+		IndexedPaths: []domain.IndexedPath{},
 	}
 
 	createdTenant, err := s.repo.Tenant().Create(ctx, tenant)
@@ -30,18 +46,34 @@ func (s *TenantService) Create(ctx context.Context, req dto.CreateTenantRequest)
 	return dto.CreateTenantResponse{
 		ID:        createdTenant.ID,
 		Name:      createdTenant.Name,
+		RateLimit: createdTenant.RateLimit,
 		CreatedAt: createdTenant.CreatedAt,
 		UpdatedAt: createdTenant.UpdatedAt,
 	}, nil
 }
 
 func (s *TenantService) GetByID(ctx context.Context, id string) (*domain.Tenant, error) {
-	return s.repo.Tenant().GetByID(ctx, id)
+	tenant, err := s.repo.Tenant().GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, err
 }
 
 func (s *TenantService) Update(ctx context.Context, tenant *domain.Tenant) error {
 	tenant.UpdatedAt = time.Now()
-	return s.repo.Tenant().Update(ctx, tenant)
+	if err := s.repo.Tenant().Update(ctx, tenant); err != nil {
+		return err
+	}
+
+	// Drop the cached rate limit so the new limit applies without a restart
+	if s.rateLimitInvalidator != nil {
+		if err := s.rateLimitInvalidator.InvalidateTenantLimit(ctx, tenant.ID); err != nil {
+			fmt.Printf("failed to invalidate rate limit for tenant %s: %v\n", tenant.ID, err)
+		}
+	}
+
+	return nil
 }
 
 func (s *TenantService) Delete(ctx context.Context, id string) error {
@@ -59,6 +91,7 @@ func (s *TenantService) List(ctx context.Context) ([]dto.CreateTenantResponse, e
 		tenantResponses[i] = dto.CreateTenantResponse{
 			ID:        tenant.ID,
 			Name:      tenant.Name,
+			RateLimit: tenant.RateLimit,
 			CreatedAt: tenant.CreatedAt,
 			UpdatedAt: tenant.UpdatedAt,
 		}
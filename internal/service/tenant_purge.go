@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/utils"
+)
+
+// purgeEndTime is after the timestamp of any log, a purge deletes the logs of
+// the tenant before it
+var purgeEndTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+//go:generate mockery --name PurgeStorage --output ../mocks
+type PurgeStorage interface {
+	List(ctx context.Context, prefix string) ([]domain.ArchiveObject, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type TenantPurgeService struct {
+	repo       repository.Repository
+	storage    PurgeStorage
+	signingKey []byte
+}
+
+func NewTenantPurgeService(repo repository.Repository, storage PurgeStorage, signingKey []byte) *TenantPurgeService {
+	return &TenantPurgeService{
+		repo:       repo,
+		storage:    storage,
+		signingKey: signingKey,
+	}
+}
+
+// Purge deletes everything stored for a tenant: its archives and export files
+// in S3, its OpenSearch indices, its logs and every PostgreSQL row of the
+// tenant. A tenant under an active legal hold is not purged. The tenant row is
+// deleted last, so a purge that failed can run again. It returns a signed
+// receipt of what was deleted.
+func (s *TenantPurgeService) Purge(ctx context.Context, tenantID string) (*domain.DeletionReceipt, error) {
+	if len(s.signingKey) == 0 {
+		return nil, ErrReceiptSigningKeyMissing
+	}
+
+	tenant, err := s.repo.Tenant().GetByID(ctx, tenantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	holds, err := s.repo.LegalHold().ListActive(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get legal holds: %w", err)
+	}
+	if len(holds) > 0 {
+		return nil, ErrTenantUnderLegalHold
+	}
+
+	receipt := &domain.DeletionReceipt{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		TenantName:  tenant.Name,
+		RequestedBy: utils.GetUserIDFromContext(ctx),
+		StartedAt:   time.Now().UTC(),
+		Algorithm:   domain.ReceiptAlgorithm,
+	}
+
+	if receipt.DeletedArchives, err = s.deleteObjects(ctx, domain.ArchiveKeyPrefix(tenantID)); err != nil {
+		return nil, fmt.Errorf("failed to delete archives: %w", err)
+	}
+	if receipt.DeletedExports, err = s.deleteObjects(ctx, domain.ExportKeyPrefix(tenantID)); err != nil {
+		return nil, fmt.Errorf("failed to delete export files: %w", err)
+	}
+
+	if receipt.DeletedIndexedLogs, err = s.repo.OpenSearch().DeleteIndex(ctx, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to delete indices: %w", err)
+	}
+
+	// Logs still in the outbox are not indexed again
+	if err := s.repo.Outbox().DeleteByTenant(ctx, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to delete outbox entries: %w", err)
+	}
+	startTime, endTime, err := s.repo.AuditLog().GetTimeBounds(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.DeletedLogs, err = s.deleteLogs(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	// The refresh policy only recomputes the stats of the last month, the
+	// stats are refreshed over the time range the logs of the tenant spanned
+	if !startTime.IsZero() {
+		if err := s.repo.AuditLog().RefreshStats(ctx, startTime, endTime); err != nil {
+			return nil, fmt.Errorf("failed to refresh stats: %w", err)
+		}
+	}
+
+	// The other rows of the tenant are deleted with it
+	if err := s.repo.Tenant().Delete(ctx, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	receipt.CompletedAt = time.Now().UTC()
+	receipt.Signature = s.sign(receipt)
+	return receipt, nil
+}
+
+// VerifyReceipt reports whether the receipt was signed by this service and is
+// unchanged since
+func (s *TenantPurgeService) VerifyReceipt(receipt *domain.DeletionReceipt) bool {
+	if len(s.signingKey) == 0 || receipt.Algorithm != domain.ReceiptAlgorithm {
+		return false
+	}
+	signature, err := hex.DecodeString(receipt.Signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(signature, s.mac(receipt))
+}
+
+// deleteObjects deletes the objects under the prefix and returns their number
+func (s *TenantPurgeService) deleteObjects(ctx context.Context, prefix string) (int, error) {
+	objects, err := s.storage.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	for i, obj := range objects {
+		if err := s.storage.Delete(ctx, obj.Key); err != nil {
+			return i, err
+		}
+	}
+	return len(objects), nil
+}
+
+// deleteLogs deletes the logs of the tenant in batches, so the deletion does
+// not hold one transaction over the whole hypertable
+func (s *TenantPurgeService) deleteLogs(ctx context.Context, tenantID string) (int64, error) {
+	var total int64
+	for {
+		deleted, err := s.repo.AuditLog().DeleteBatch(ctx, tenantID, time.Time{}, purgeEndTime, domain.CleanupExclusions{}, cleanupBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete logs: %w", err)
+		}
+		total += deleted
+		if deleted < cleanupBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (s *TenantPurgeService) sign(receipt *domain.DeletionReceipt) string {
+	return hex.EncodeToString(s.mac(receipt))
+}
+
+func (s *TenantPurgeService) mac(receipt *domain.DeletionReceipt) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write(receipt.SigningPayload())
+	return mac.Sum(nil)
+}
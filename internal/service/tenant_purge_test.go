@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TenantPurgeServiceTestSuite struct {
+	suite.Suite
+	mockRepo       *mocks.Repository
+	mockTenant     *mocks.TenantRepository
+	mockAuditLog   *mocks.AuditLogRepository
+	mockOpenSearch *mocks.OpenSearchRepository
+	mockHold       *mocks.LegalHoldRepository
+	mockOutbox     *mocks.OutboxRepository
+	mockStorage    *mocks.PurgeStorage
+	service        *TenantPurgeService
+}
+
+func (s *TenantPurgeServiceTestSuite) SetupTest() {
+	s.mockRepo = new(mocks.Repository)
+	s.mockTenant = new(mocks.TenantRepository)
+	s.mockAuditLog = new(mocks.AuditLogRepository)
+	s.mockOpenSearch = new(mocks.OpenSearchRepository)
+	s.mockHold = new(mocks.LegalHoldRepository)
+	s.mockOutbox = new(mocks.OutboxRepository)
+	s.mockStorage = new(mocks.PurgeStorage)
+
+	s.mockRepo.On("Tenant").Return(s.mockTenant)
+	s.mockRepo.On("AuditLog").Return(s.mockAuditLog)
+	s.mockRepo.On("OpenSearch").Return(s.mockOpenSearch)
+	s.mockRepo.On("LegalHold").Return(s.mockHold)
+	s.mockRepo.On("Outbox").Return(s.mockOutbox)
+
+	s.service = NewTenantPurgeService(s.mockRepo, s.mockStorage, []byte("secret"))
+}
+
+func TestTenantPurgeService(t *testing.T) {
+	suite.Run(t, new(TenantPurgeServiceTestSuite))
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_DeletesEverythingAndSignsReceipt() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1", Name: "Acme"}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockStorage.On("List", ctx, "audit-logs/tenant1/").Return([]domain.ArchiveObject{{Key: "audit-logs/tenant1/a.json"}, {Key: "audit-logs/tenant1/b.json"}}, nil)
+	s.mockStorage.On("List", ctx, "exports/tenant1/").Return([]domain.ArchiveObject{{Key: "exports/tenant1/job1.csv"}}, nil)
+	s.mockStorage.On("Delete", ctx, mock.Anything).Return(nil)
+	s.mockOpenSearch.On("DeleteIndex", ctx, "tenant1").Return(int64(7000), nil)
+	s.mockOutbox.On("DeleteByTenant", ctx, "tenant1").Return(nil)
+	oldest := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	newest := time.Date(2024, 6, 7, 8, 9, 10, 0, time.UTC)
+	s.mockAuditLog.On("GetTimeBounds", ctx, "tenant1").Return(oldest, newest, nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", mock.Anything, purgeEndTime, domain.CleanupExclusions{}, cleanupBatchSize).
+		Return(int64(cleanupBatchSize), nil).Once()
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", mock.Anything, purgeEndTime, domain.CleanupExclusions{}, cleanupBatchSize).
+		Return(int64(2000), nil).Once()
+	s.mockAuditLog.On("RefreshStats", ctx, oldest, newest).Return(nil)
+	s.mockTenant.On("Delete", ctx, "tenant1").Return(nil)
+
+	// Act
+	receipt, err := s.service.Purge(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Equal("tenant1", receipt.TenantID)
+	s.Equal("Acme", receipt.TenantName)
+	s.Equal(int64(cleanupBatchSize+2000), receipt.DeletedLogs)
+	s.Equal(int64(7000), receipt.DeletedIndexedLogs)
+	s.Equal(2, receipt.DeletedArchives)
+	s.Equal(1, receipt.DeletedExports)
+	s.Equal(domain.ReceiptAlgorithm, receipt.Algorithm)
+	s.True(s.service.VerifyReceipt(receipt))
+	s.mockStorage.AssertNumberOfCalls(s.T(), "Delete", 3)
+	s.mockTenant.AssertExpectations(s.T())
+	s.mockAuditLog.AssertExpectations(s.T())
+
+	// Any change to the receipt breaks the signature
+	receipt.DeletedLogs = 0
+	s.False(s.service.VerifyReceipt(receipt))
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_KeepsTenantWhenStatsRefreshFails() {
+	// Arrange
+	ctx := context.Background()
+	oldest := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockStorage.On("List", ctx, mock.Anything).Return(nil, nil)
+	s.mockOpenSearch.On("DeleteIndex", ctx, "tenant1").Return(int64(0), nil)
+	s.mockOutbox.On("DeleteByTenant", ctx, "tenant1").Return(nil)
+	s.mockAuditLog.On("GetTimeBounds", ctx, "tenant1").Return(oldest, oldest, nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", mock.Anything, purgeEndTime, domain.CleanupExclusions{}, cleanupBatchSize).
+		Return(int64(1), nil)
+	s.mockAuditLog.On("RefreshStats", ctx, oldest, oldest).Return(errors.New("connection reset"))
+
+	// Act
+	receipt, err := s.service.Purge(ctx, "tenant1")
+
+	// Assert
+	s.ErrorContains(err, "failed to refresh stats")
+	s.Nil(receipt)
+	s.mockTenant.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_SkipsStatsRefreshWithoutLogs() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockStorage.On("List", ctx, mock.Anything).Return(nil, nil)
+	s.mockOpenSearch.On("DeleteIndex", ctx, "tenant1").Return(int64(0), nil)
+	s.mockOutbox.On("DeleteByTenant", ctx, "tenant1").Return(nil)
+	s.mockAuditLog.On("GetTimeBounds", ctx, "tenant1").Return(time.Time{}, time.Time{}, nil)
+	s.mockAuditLog.On("DeleteBatch", ctx, "tenant1", mock.Anything, purgeEndTime, domain.CleanupExclusions{}, cleanupBatchSize).
+		Return(int64(0), nil)
+	s.mockTenant.On("Delete", ctx, "tenant1").Return(nil)
+
+	// Act
+	receipt, err := s.service.Purge(ctx, "tenant1")
+
+	// Assert
+	s.NoError(err)
+	s.Zero(receipt.DeletedLogs)
+	s.mockAuditLog.AssertNotCalled(s.T(), "RefreshStats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_TenantUnderLegalHold() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return([]domain.LegalHold{{ID: "hold1", TenantID: "tenant1"}}, nil)
+
+	// Act
+	receipt, err := s.service.Purge(ctx, "tenant1")
+
+	// Assert
+	s.ErrorIs(err, ErrTenantUnderLegalHold)
+	s.Nil(receipt)
+	s.mockStorage.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything)
+	s.mockTenant.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_TenantNotFound() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetByID", ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	_, err := s.service.Purge(ctx, "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrTenantNotFound)
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_KeepsTenantWhenIndicesFail() {
+	// Arrange
+	ctx := context.Background()
+	s.mockTenant.On("GetByID", ctx, "tenant1").Return(&domain.Tenant{ID: "tenant1"}, nil)
+	s.mockHold.On("ListActive", ctx, "tenant1").Return(nil, nil)
+	s.mockStorage.On("List", ctx, mock.Anything).Return(nil, nil)
+	s.mockOpenSearch.On("DeleteIndex", ctx, "tenant1").Return(int64(0), errors.New("cluster unavailable"))
+
+	// Act
+	_, err := s.service.Purge(ctx, "tenant1")
+
+	// Assert
+	s.Error(err)
+	s.mockAuditLog.AssertNotCalled(s.T(), "DeleteBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	s.mockTenant.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (s *TenantPurgeServiceTestSuite) TestPurge_RequiresSigningKey() {
+	// Arrange
+	service := NewTenantPurgeService(s.mockRepo, s.mockStorage, nil)
+
+	// Act
+	_, err := service.Purge(context.Background(), "tenant1")
+
+	// Assert
+	s.ErrorIs(err, ErrReceiptSigningKeyMissing)
+	s.mockTenant.AssertNotCalled(s.T(), "GetByID", mock.Anything, mock.Anything)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/buiminhduc234/audit-log-api/internal/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type TenantServiceTestSuite struct {
@@ -103,6 +104,43 @@ func (s *TenantServiceTestSuite) TestUpdate_Success() {
 	s.mockTenant.AssertExpectations(s.T())
 }
 
+func (s *TenantServiceTestSuite) TestUpdate_InvalidatesRateLimit() {
+	// Arrange
+	ctx := context.Background()
+	invalidator := mocks.NewRateLimitInvalidator(s.T())
+	s.service.SetRateLimitInvalidator(invalidator)
+	tenant := &domain.Tenant{
+		ID:        "tenant1",
+		Name:      "Tenant",
+		RateLimit: 500,
+	}
+
+	s.mockTenant.On("Update", ctx, tenant).Return(nil)
+	invalidator.On("InvalidateTenantLimit", ctx, "tenant1").Return(nil)
+
+	// Act
+	err := s.service.Update(ctx, tenant)
+
+	// Assert
+	s.NoError(err)
+	s.mockTenant.AssertExpectations(s.T())
+	invalidator.AssertExpectations(s.T())
+}
+
+func (s *TenantServiceTestSuite) TestGetByID_NotFound() {
+	// Arrange
+	ctx := context.Background()
+
+	s.mockTenant.On("GetByID", ctx, "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	tenant, err := s.service.GetByID(ctx, "missing")
+
+	// Assert
+	s.ErrorIs(err, ErrTenantNotFound)
+	s.Nil(tenant)
+}
+
 func (s *TenantServiceTestSuite) TestDelete_Success() {
 	// Arrange
 	ctx := context.Background()
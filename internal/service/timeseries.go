@@ -0,0 +1,114 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buiminhduc234/audit-log-api/internal/api/dto"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+)
+
+const (
+	// maxTimeSeriesBuckets bounds the buckets of a time series
+	maxTimeSeriesBuckets = 1000
+	// defaultTimeSeriesTopN and maxTimeSeriesTopN bound the groups of a time
+	// series, the other groups are folded into one
+	defaultTimeSeriesTopN = 10
+	maxTimeSeriesTopN     = 100
+)
+
+// GetTimeSeries counts the logs in each bucket of the interval per group. The
+// time range is widened to whole buckets and every bucket of each series is
+// counted, empty ones as zero.
+func (s *AuditLogService) GetTimeSeries(ctx context.Context, query *domain.TimeSeriesQuery) (*dto.TimeSeriesResponse, error) {
+	if query.TopN < 1 {
+		query.TopN = defaultTimeSeriesTopN
+	}
+	query.TopN = min(query.TopN, maxTimeSeriesTopN)
+
+	query.AlignToBuckets()
+	if query.Filter.EndTime.Sub(query.Filter.StartTime)/query.Interval > maxTimeSeriesBuckets {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyBuckets, maxTimeSeriesBuckets)
+	}
+	buckets := query.Buckets()
+
+	counts, err := s.repo.AuditLog().GetTimeSeries(ctx, *query)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketIndex := make(map[int64]int, len(buckets))
+	for i, bucket := range buckets {
+		bucketIndex[bucket.UnixNano()] = i
+	}
+
+	response := &dto.TimeSeriesResponse{
+		Interval:  query.Interval.String(),
+		GroupBy:   make([]string, 0, len(query.GroupBy)),
+		StartTime: query.Filter.StartTime,
+		EndTime:   query.Filter.EndTime,
+		Buckets:   buckets,
+		Series:    []dto.TimeSeriesSeriesResponse{},
+	}
+	for _, dim := range query.GroupBy {
+		response.GroupBy = append(response.GroupBy, string(dim))
+	}
+
+	seriesIndex := make(map[string]int)
+	for i := range counts {
+		values := make([]string, 0, len(query.GroupBy))
+		for _, dim := range query.GroupBy {
+			values = append(values, counts[i].Value(dim))
+		}
+		key := strings.Join(values, "\x00")
+
+		idx, ok := seriesIndex[key]
+		if !ok {
+			group := make(map[string]string, len(query.GroupBy))
+			for j, dim := range query.GroupBy {
+				group[string(dim)] = values[j]
+			}
+			idx = len(response.Series)
+			seriesIndex[key] = idx
+			response.Series = append(response.Series, dto.TimeSeriesSeriesResponse{
+				Group:  group,
+				Counts: make([]int64, len(buckets)),
+			})
+		}
+
+		series := &response.Series[idx]
+		if b, ok := bucketIndex[counts[i].Bucket.UnixNano()]; ok {
+			series.Counts[b] += counts[i].Count
+		}
+		series.Total += counts[i].Count
+	}
+
+	// The largest series first, the folded long tail last
+	slices.SortStableFunc(response.Series, func(a, b dto.TimeSeriesSeriesResponse) int {
+		if aOther, bOther := isOtherGroup(a.Group), isOtherGroup(b.Group); aOther != bOther {
+			if aOther {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(b.Total, a.Total)
+	})
+
+	return response, nil
+}
+
+// isOtherGroup tells if the group is the one the long tail is folded into
+func isOtherGroup(group map[string]string) bool {
+	if len(group) == 0 {
+		return false
+	}
+	for _, value := range group {
+		if value != domain.StatsOtherGroup {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *AuditLogServiceTestSuite) TestGetTimeSeries_FillsBucketsPerGroup() {
+	// Arrange
+	ctx := context.Background()
+	hour := func(h int) time.Time { return time.Date(2024, 1, 1, h, 0, 0, 0, time.UTC) }
+	query := &domain.TimeSeriesQuery{
+		Filter:   domain.AuditLogFilter{TenantID: "tenant1", StartTime: hour(0).Add(30 * time.Minute), EndTime: hour(2).Add(-time.Second)},
+		Interval: time.Hour,
+		GroupBy:  []domain.StatsDimension{domain.StatsBySeverity},
+	}
+
+	s.mockAuditLog.On("GetTimeSeries", ctx, mock.MatchedBy(func(q domain.TimeSeriesQuery) bool {
+		return q.Filter.StartTime.Equal(hour(0)) && q.Filter.EndTime.Equal(hour(2)) && q.TopN == defaultTimeSeriesTopN
+	})).Return([]domain.TimeSeriesCount{
+		{Bucket: hour(0), Severity: "INFO", Count: 4},
+		{Bucket: hour(0), Severity: domain.StatsOtherGroup, Count: 9},
+		{Bucket: hour(1), Severity: "ERROR", Count: 2},
+		{Bucket: hour(1), Severity: "INFO", Count: 1},
+	}, nil)
+
+	// Act
+	result, err := s.service.GetTimeSeries(ctx, query)
+
+	// Assert
+	s.NoError(err)
+	s.Equal([]time.Time{hour(0), hour(1)}, result.Buckets)
+	s.Require().Len(result.Series, 3)
+	s.Equal(map[string]string{"severity": "INFO"}, result.Series[0].Group)
+	s.Equal([]int64{4, 1}, result.Series[0].Counts)
+	s.Equal(int64(5), result.Series[0].Total)
+	s.Equal(map[string]string{"severity": "ERROR"}, result.Series[1].Group)
+	s.Equal([]int64{0, 2}, result.Series[1].Counts)
+	s.Equal(map[string]string{"severity": domain.StatsOtherGroup}, result.Series[2].Group)
+	s.mockAuditLog.AssertExpectations(s.T())
+}
+
+func (s *AuditLogServiceTestSuite) TestGetTimeSeries_TooManyBuckets() {
+	// Arrange
+	ctx := context.Background()
+	query := &domain.TimeSeriesQuery{
+		Filter:   domain.AuditLogFilter{TenantID: "tenant1", StartTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		Interval: time.Minute,
+	}
+
+	// Act
+	result, err := s.service.GetTimeSeries(ctx, query)
+
+	// Assert
+	s.ErrorIs(err, ErrTooManyBuckets)
+	s.Nil(result)
+	s.mockAuditLog.AssertNotCalled(s.T(), "GetTimeSeries", mock.Anything, mock.Anything)
+}
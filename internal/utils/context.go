@@ -9,9 +9,12 @@ import (
 
 type ContextKey string
 
+type restoreKey struct{}
+
 const (
 	ClaimsKey   ContextKey = "claims"
 	TenantIDKey ContextKey = "tenant_id"
+	UserIDKey   ContextKey = "user_id"
 )
 
 var (
@@ -39,3 +42,31 @@ func GetTenantIDFromContext(c context.Context) (string, error) {
 
 	return tenantIDStr, nil
 }
+
+// GetUserIDFromContext returns the user of the request claims, or an empty
+// string when the claims carry no user
+func GetUserIDFromContext(c context.Context) string {
+	claims, _ := c.Value(string(ClaimsKey)).(jwt.MapClaims)
+	userID, _ := claims[string(UserIDKey)].(string)
+	return userID
+}
+
+// WithTenantID returns a context carrying claims for the tenant, for work that
+// runs outside of an authenticated request such as queue workers
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, string(ClaimsKey), jwt.MapClaims{
+		string(TenantIDKey): tenantID,
+	})
+}
+
+// WithRestore marks writes made with the context as restores of archived
+// logs. Restored logs keep the chain fields they were archived with.
+func WithRestore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, restoreKey{}, true)
+}
+
+// IsRestore reports whether the context was marked by WithRestore
+func IsRestore(ctx context.Context) bool {
+	restore, _ := ctx.Value(restoreKey{}).(bool)
+	return restore
+}
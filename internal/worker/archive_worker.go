@@ -2,9 +2,11 @@ package worker
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -13,27 +15,32 @@ import (
 	"github.com/buiminhduc234/audit-log-api/internal/config"
 	"github.com/buiminhduc234/audit-log-api/internal/domain"
 	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
 	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/internal/service/storage"
 	"github.com/buiminhduc234/audit-log-api/pkg/logger"
 )
 
 type ArchiveWorker struct {
-	sqsService   *queue.SQSService
-	repository   repository.PostgresRepository
-	logger       *logger.Logger
-	workerCount  int
-	pollInterval time.Duration
-	maxMessages  int32
-	waitTime     int32
-	shutdownChan chan struct{}
-	waitGroup    sync.WaitGroup
-	s3Client     *s3.Client
-	s3Config     *config.S3Config
+	queueService   *queue.Service
+	repository     repository.PostgresRepository
+	restoreService *service.RestoreService
+	logger         *logger.Logger
+	workerCount    int
+	pollInterval   time.Duration
+	maxMessages    int
+	waitTime       time.Duration
+	drain          *drain
+	waitGroup      sync.WaitGroup
+	s3Client       *s3.Client
+	s3Config       *config.S3Config
+	archives       *storage.S3Storage
 }
 
 func NewArchiveWorker(
-	sqsService *queue.SQSService,
+	queueService *queue.Service,
 	repository repository.PostgresRepository,
+	restoreService *service.RestoreService,
 	logger *logger.Logger,
 	workerCount int,
 	pollInterval time.Duration,
@@ -41,16 +48,18 @@ func NewArchiveWorker(
 	s3Config *config.S3Config,
 ) *ArchiveWorker {
 	return &ArchiveWorker{
-		sqsService:   sqsService,
-		repository:   repository,
-		logger:       logger,
-		workerCount:  workerCount,
-		pollInterval: pollInterval,
-		maxMessages:  10,
-		waitTime:     20,
-		shutdownChan: make(chan struct{}),
-		s3Client:     s3Client,
-		s3Config:     s3Config,
+		queueService:   queueService,
+		repository:     repository,
+		restoreService: restoreService,
+		logger:         logger,
+		workerCount:    workerCount,
+		pollInterval:   pollInterval,
+		maxMessages:    10,
+		waitTime:       20 * time.Second,
+		drain:          newDrain(),
+		s3Client:       s3Client,
+		s3Config:       s3Config,
+		archives:       storage.NewS3Storage(s3Client, s3Config),
 	}
 }
 
@@ -64,10 +73,14 @@ func (w *ArchiveWorker) Start() {
 	}
 }
 
-func (w *ArchiveWorker) Stop() {
+// Stop stops receiving messages and waits for the messages in flight until
+// ctx is done, the messages left then are returned to the queue
+func (w *ArchiveWorker) Stop(ctx context.Context) {
 	w.logger.Info("Stopping Archive workers...")
-	close(w.shutdownChan)
-	w.waitGroup.Wait()
+	if abandoned := w.drain.stop(ctx, &w.waitGroup); abandoned > 0 {
+		w.logger.Warnf("All Archive workers stopped, %d messages abandoned", abandoned)
+		return
+	}
 	w.logger.Info("All Archive workers stopped")
 }
 
@@ -81,11 +94,11 @@ func (w *ArchiveWorker) runWorker(workerID int) {
 
 	for {
 		select {
-		case <-w.shutdownChan:
+		case <-w.drain.stopping():
 			w.logger.Infof("Archive Worker %d shutting down", workerID)
 			return
 		case <-ticker.C:
-			if err := w.processMessages(context.Background()); err != nil {
+			if err := w.processMessages(w.drain.processCtx); err != nil {
 				w.logger.Errorf("Archive Worker %d failed to process messages: %v", workerID, err)
 			}
 		}
@@ -93,39 +106,78 @@ func (w *ArchiveWorker) runWorker(workerID int) {
 }
 
 func (w *ArchiveWorker) processMessages(ctx context.Context) error {
-	// Get archive queue URL from config
-	config := config.DefaultSQSConfig()
-	archiveQueueURL := config.ArchiveQueueURL
-
-	messages, err := w.sqsService.ReceiveMessages(ctx, archiveQueueURL, w.maxMessages, w.waitTime)
+	// Stopping cuts the long poll short
+	messages, err := w.queueService.ReceiveMessages(w.drain.receiveCtx, queue.QueueArchive, w.maxMessages, w.waitTime)
 	if err != nil {
+		if w.drain.receiveCtx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to receive messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		if msg.Message.Type == queue.MessageTypeArchive {
-			if err := w.processArchiveMessage(ctx, msg.Message); err != nil {
-				w.logger.Errorf("Failed to process archive message: %v", err)
-				continue
-			}
+	for i, msg := range messages {
+		if w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueArchive, messages[i:])
+			return nil
+		}
 
-			// Only delete the message if processing was successful
-			if err := w.sqsService.DeleteMessage(ctx, archiveQueueURL, msg.ReceiptHandle); err != nil {
-				w.logger.Errorf("Failed to delete message: %v", err)
-			}
+		err := msg.Err
+		if err == nil {
+			err = w.processMessage(ctx, msg.Message)
+		}
+		if err != nil && w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueArchive, messages[i:])
+			return nil
+		}
+
+		// The outcome is recorded even when the drain deadline passed meanwhile
+		settleCtx := context.WithoutCancel(ctx)
+		if err != nil {
+			w.logger.Errorf("Failed to process archive message %s: %v", msg.ID, err)
+			failMessage(settleCtx, w.queueService, w.logger, queue.QueueArchive, msg, err)
+			continue
+		}
+
+		// Only delete the message if processing was successful
+		if err := w.queueService.DeleteMessage(settleCtx, queue.QueueArchive, msg); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
 		}
 	}
 
 	return nil
 }
 
+func (w *ArchiveWorker) processMessage(ctx context.Context, msg queue.Message) error {
+	switch msg.Type {
+	case queue.MessageTypeArchive:
+		return w.processArchiveMessage(ctx, msg)
+	case queue.MessageTypeRestore:
+		w.logger.Infof("Processing restore job %s for tenant %s", msg.JobID, msg.TenantID)
+		return w.restoreService.Run(ctx, msg.TenantID, msg.JobID)
+	default:
+		return fmt.Errorf("unknown message type: %s", msg.Type)
+	}
+}
+
 func (w *ArchiveWorker) processArchiveMessage(ctx context.Context, msg queue.Message) error {
 	w.logger.Infof("Processing archive message for tenant %s (before: %s)",
 		msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
 
+	// Archives are cut one after the other, each one starts where the latest
+	// one ended. Logs that a retention policy keeps live are not archived again.
+	archivedUntil, err := w.latestArchiveDate(ctx, msg.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to find latest archive for tenant %s: %w", msg.TenantID, err)
+	}
+	if !archivedUntil.IsZero() && !archivedUntil.Before(msg.BeforeDate) {
+		w.logger.Infof("Logs of tenant %s before %s are already archived", msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
+		return w.enqueueCleanupMessage(ctx, msg.TenantID, msg.BeforeDate)
+	}
+
 	filter := domain.AuditLogFilter{
-		TenantID: msg.TenantID,
-		EndTime:  msg.BeforeDate,
+		TenantID:  msg.TenantID,
+		StartTime: archivedUntil,
+		EndTime:   msg.BeforeDate.Add(-time.Microsecond),
 	}
 
 	logs, err := w.repository.AuditLog().List(ctx, filter)
@@ -154,18 +206,23 @@ func (w *ArchiveWorker) processArchiveMessage(ctx context.Context, msg queue.Mes
 
 func (w *ArchiveWorker) archiveLogsToS3(ctx context.Context, tenantID string, logs []domain.AuditLog, beforeDate time.Time) error {
 	// Create S3 key with timestamp and tenant
-	s3Key := fmt.Sprintf("audit-logs/%s/audit_logs_%s_before_%s.json",
-		tenantID,
-		tenantID,
-		beforeDate.Format("2006-01-02_15-04-05"))
+	s3Key := domain.ArchiveKey(tenantID, beforeDate)
+
+	// Archive logs in chain order and record the chain anchors, so the range
+	// can still be verified after the cleanup worker deletes it
+	slices.SortStableFunc(logs, func(a, b domain.AuditLog) int {
+		return cmp.Compare(a.ChainSeq, b.ChainSeq)
+	})
+	anchor := domain.NewChainAnchor(logs)
 
 	// Prepare archive data
-	archiveData := map[string]interface{}{
-		"tenant_id":   tenantID,
-		"before_date": beforeDate,
-		"archived_at": time.Now(),
-		"log_count":   len(logs),
-		"logs":        logs,
+	archiveData := domain.Archive{
+		TenantID:   tenantID,
+		BeforeDate: beforeDate,
+		ArchivedAt: time.Now(),
+		LogCount:   len(logs),
+		Chain:      anchor,
+		Logs:       logs,
 	}
 
 	// Convert to JSON
@@ -174,18 +231,26 @@ func (w *ArchiveWorker) archiveLogsToS3(ctx context.Context, tenantID string, lo
 		return fmt.Errorf("failed to marshal logs to JSON: %w", err)
 	}
 
+	metadata := map[string]string{
+		"tenant-id":   tenantID,
+		"archived-at": time.Now().Format(time.RFC3339),
+		"log-count":   fmt.Sprintf("%d", len(logs)),
+		"before-date": beforeDate.Format(time.RFC3339),
+	}
+	if anchor != nil {
+		metadata["chain-first-seq"] = fmt.Sprintf("%d", anchor.FirstSeq)
+		metadata["chain-first-prev-hash"] = anchor.FirstPrevHash
+		metadata["chain-last-seq"] = fmt.Sprintf("%d", anchor.LastSeq)
+		metadata["chain-last-hash"] = anchor.LastHash
+	}
+
 	// Upload to S3
 	_, err = w.s3Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:      &w.s3Config.BucketName,
 		Key:         &s3Key,
 		Body:        bytes.NewReader(jsonData),
 		ContentType: &[]string{"application/json"}[0],
-		Metadata: map[string]string{
-			"tenant-id":   tenantID,
-			"archived-at": time.Now().Format(time.RFC3339),
-			"log-count":   fmt.Sprintf("%d", len(logs)),
-			"before-date": beforeDate.Format(time.RFC3339),
-		},
+		Metadata:    metadata,
 	})
 
 	if err != nil {
@@ -196,8 +261,31 @@ func (w *ArchiveWorker) archiveLogsToS3(ctx context.Context, tenantID string, lo
 	return nil
 }
 
+// latestArchiveDate returns the date the latest archive of the tenant was cut
+// at, or the zero time when the tenant has no archives
+func (w *ArchiveWorker) latestArchiveDate(ctx context.Context, tenantID string) (time.Time, error) {
+	objects, err := w.archives.List(ctx, domain.ArchiveKeyPrefix(tenantID))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, obj := range objects {
+		if beforeDate, ok := domain.ParseArchiveBeforeDate(obj.Key); ok && beforeDate.After(latest) {
+			latest = beforeDate
+		}
+	}
+	return latest, nil
+}
+
+// enqueueCleanupMessage records that the logs of the tenant before the date are
+// archived, so that cleanups may drop them, and enqueues their cleanup
 func (w *ArchiveWorker) enqueueCleanupMessage(ctx context.Context, tenantID string, beforeDate time.Time) error {
-	if err := w.sqsService.SendCleanupMessage(ctx, tenantID, beforeDate); err != nil {
+	if err := w.repository.RetentionProgress().MarkArchived(ctx, tenantID, beforeDate); err != nil {
+		return fmt.Errorf("failed to record archive progress: %w", err)
+	}
+
+	if err := w.queueService.SendCleanupMessage(ctx, tenantID, beforeDate); err != nil {
 		return fmt.Errorf("failed to enqueue cleanup message: %w", err)
 	}
 
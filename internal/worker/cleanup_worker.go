@@ -6,40 +6,40 @@ import (
 	"sync"
 	"time"
 
-	"github.com/buiminhduc234/audit-log-api/internal/config"
-	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
 	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
 	"github.com/buiminhduc234/audit-log-api/pkg/logger"
 )
 
 type CleanupWorker struct {
-	sqsService   *queue.SQSService
-	repository   repository.PostgresRepository
+	queueService *queue.Service
+	cleanup      *service.CleanupService
 	logger       *logger.Logger
 	workerCount  int
 	pollInterval time.Duration
-	maxMessages  int32
-	waitTime     int32
-	shutdownChan chan struct{}
+	maxMessages  int
+	waitTime     time.Duration
+	drain        *drain
 	waitGroup    sync.WaitGroup
 }
 
 func NewCleanupWorker(
-	sqsService *queue.SQSService,
-	repository repository.PostgresRepository,
+	queueService *queue.Service,
+	cleanup *service.CleanupService,
 	logger *logger.Logger,
 	workerCount int,
 	pollInterval time.Duration,
 ) *CleanupWorker {
 	return &CleanupWorker{
-		sqsService:   sqsService,
-		repository:   repository,
+		queueService: queueService,
+		cleanup:      cleanup,
 		logger:       logger,
 		workerCount:  workerCount,
 		pollInterval: pollInterval,
 		maxMessages:  10,
-		waitTime:     20,
-		shutdownChan: make(chan struct{}),
+		waitTime:     20 * time.Second,
+		drain:        newDrain(),
 	}
 }
 
@@ -53,10 +53,14 @@ func (w *CleanupWorker) Start() {
 	}
 }
 
-func (w *CleanupWorker) Stop() {
+// Stop stops receiving messages and waits for the messages in flight until
+// ctx is done, the messages left then are returned to the queue
+func (w *CleanupWorker) Stop(ctx context.Context) {
 	w.logger.Info("Stopping Cleanup workers...")
-	close(w.shutdownChan)
-	w.waitGroup.Wait()
+	if abandoned := w.drain.stop(ctx, &w.waitGroup); abandoned > 0 {
+		w.logger.Warnf("All Cleanup workers stopped, %d messages abandoned", abandoned)
+		return
+	}
 	w.logger.Info("All Cleanup workers stopped")
 }
 
@@ -70,11 +74,11 @@ func (w *CleanupWorker) runWorker(workerID int) {
 
 	for {
 		select {
-		case <-w.shutdownChan:
+		case <-w.drain.stopping():
 			w.logger.Infof("Cleanup Worker %d shutting down", workerID)
 			return
 		case <-ticker.C:
-			if err := w.processMessages(context.Background()); err != nil {
+			if err := w.processMessages(w.drain.processCtx); err != nil {
 				w.logger.Errorf("Cleanup Worker %d failed to process messages: %v", workerID, err)
 			}
 		}
@@ -82,26 +86,44 @@ func (w *CleanupWorker) runWorker(workerID int) {
 }
 
 func (w *CleanupWorker) processMessages(ctx context.Context) error {
-	// Get cleanup queue URL from config
-	config := config.DefaultSQSConfig()
-	cleanupQueueURL := config.CleanupQueueURL
-
-	messages, err := w.sqsService.ReceiveMessages(ctx, cleanupQueueURL, w.maxMessages, w.waitTime)
+	// Stopping cuts the long poll short
+	messages, err := w.queueService.ReceiveMessages(w.drain.receiveCtx, queue.QueueCleanup, w.maxMessages, w.waitTime)
 	if err != nil {
+		if w.drain.receiveCtx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to receive messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		if msg.Message.Type == queue.MessageTypeCleanup {
-			if err := w.processCleanupMessage(ctx, msg.Message); err != nil {
-				w.logger.Errorf("Failed to process cleanup message: %v", err)
-				continue
-			}
+	for i, msg := range messages {
+		if w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueCleanup, messages[i:])
+			return nil
+		}
 
-			// Only delete the message if processing was successful
-			if err := w.sqsService.DeleteMessage(ctx, cleanupQueueURL, msg.ReceiptHandle); err != nil {
-				w.logger.Errorf("Failed to delete message: %v", err)
-			}
+		err := msg.Err
+		if err == nil && msg.Message.Type != queue.MessageTypeCleanup {
+			err = fmt.Errorf("unknown message type: %s", msg.Message.Type)
+		}
+		if err == nil {
+			err = w.processCleanupMessage(ctx, msg.Message)
+		}
+		if err != nil && w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueCleanup, messages[i:])
+			return nil
+		}
+
+		// The outcome is recorded even when the drain deadline passed meanwhile
+		settleCtx := context.WithoutCancel(ctx)
+		if err != nil {
+			w.logger.Errorf("Failed to process cleanup message %s: %v", msg.ID, err)
+			failMessage(settleCtx, w.queueService, w.logger, queue.QueueCleanup, msg, err)
+			continue
+		}
+
+		// Only delete the message if processing was successful
+		if err := w.queueService.DeleteMessage(settleCtx, queue.QueueCleanup, msg); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
 		}
 	}
 
@@ -112,14 +134,20 @@ func (w *CleanupWorker) processCleanupMessage(ctx context.Context, msg queue.Mes
 	w.logger.Infof("Processing cleanup message for tenant %s (before: %s)",
 		msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
 
-	// Delete logs before the specified date for the tenant
-	deletedCount, err := w.repository.AuditLog().DeleteBeforeDate(ctx, msg.TenantID, msg.BeforeDate)
+	progress, err := w.cleanup.Run(ctx, msg.TenantID, msg.BeforeDate, func(progress *domain.RetentionProgress) {
+		w.logger.Infof("Cleanup of tenant %s up to %s: deleted %d logs, dropped %d chunks",
+			msg.TenantID, progress.CleanedUpTo.Format(time.RFC3339), progress.DeletedLogs, progress.DroppedChunks)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete logs for tenant %s: %w", msg.TenantID, err)
+		return fmt.Errorf("failed to clean up logs for tenant %s: %w", msg.TenantID, err)
+	}
+	if progress == nil {
+		w.logger.Infof("Retention policy of tenant %s never deletes logs or its cleanup is already running, skipping cleanup", msg.TenantID)
+		return nil
 	}
 
-	w.logger.Infof("Successfully deleted %d logs for tenant %s (before: %s)",
-		deletedCount, msg.TenantID, msg.BeforeDate.Format(time.RFC3339))
+	w.logger.Infof("Successfully deleted %d logs for tenant %s (before: %s), dropped %d chunks, kept %d logs under legal hold",
+		progress.DeletedLogs, msg.TenantID, progress.CleanupBefore.Format(time.RFC3339), progress.DroppedChunks, progress.HeldLogs)
 
 	return nil
 }
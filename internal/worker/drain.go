@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// drain holds the contexts a worker runs under. Receiving stops as soon as
+// the worker is asked to stop, while the messages already received are
+// processed until the drain deadline passes. The messages left then are
+// returned to the queue and reported as abandoned.
+type drain struct {
+	receiveCtx    context.Context
+	stopReceiving context.CancelFunc
+	processCtx    context.Context
+	abandon       context.CancelFunc
+	abandoned     atomic.Int64
+}
+
+func newDrain() *drain {
+	d := &drain{}
+	d.receiveCtx, d.stopReceiving = context.WithCancel(context.Background())
+	d.processCtx, d.abandon = context.WithCancel(context.Background())
+	return d
+}
+
+// stopping is closed once the worker is asked to stop
+func (d *drain) stopping() <-chan struct{} {
+	return d.receiveCtx.Done()
+}
+
+// abandoning reports whether the drain deadline passed
+func (d *drain) abandoning() bool {
+	return d.processCtx.Err() != nil
+}
+
+// stop stops receiving and waits for the goroutines of the worker until ctx
+// is done. It then cancels the processing, waits for the goroutines to return
+// their messages and reports how many were abandoned.
+func (d *drain) stop(ctx context.Context, waitGroup *sync.WaitGroup) int64 {
+	d.stopReceiving()
+
+	done := make(chan struct{})
+	go func() {
+		waitGroup.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.abandon()
+		<-done
+	}
+	d.abandon()
+	return d.abandoned.Load()
+}
+
+// release returns messages to the queue right away, so another worker
+// receives them without waiting for their visibility timeout
+func (d *drain) release(queueService *queue.Service, logger *logger.Logger, queueName string, messages []queue.ReceivedMessage) {
+	if len(messages) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		if err := queueService.ReleaseMessage(context.Background(), queueName, msg); err != nil {
+			logger.Errorf("Failed to return message %s to the %s queue: %v", msg.ID, queueName, err)
+		}
+		ids = append(ids, msg.ID)
+	}
+
+	d.abandoned.Add(int64(len(messages)))
+	logger.Warnf("Abandoned %d %s messages at the drain deadline: %s", len(messages), queueName, strings.Join(ids, ", "))
+}
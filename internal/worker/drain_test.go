@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+type DrainTestSuite struct {
+	suite.Suite
+	drain        *drain
+	waitGroup    sync.WaitGroup
+	queueService *queue.Service
+	logger       *logger.Logger
+}
+
+func (s *DrainTestSuite) SetupTest() {
+	s.drain = newDrain()
+	s.waitGroup = sync.WaitGroup{}
+	s.queueService = queue.NewService(queue.NewMemoryQueue(), config.RetryPolicy{MaxReceives: 5})
+	s.logger = &logger.Logger{Logger: zap.NewNop()}
+}
+
+func TestDrain(t *testing.T) {
+	suite.Run(t, new(DrainTestSuite))
+}
+
+func (s *DrainTestSuite) TestStop_FinishesInFlightWorkBeforeDeadline() {
+	// Arrange
+	var cancelled bool
+	s.waitGroup.Add(1)
+	go func() {
+		defer s.waitGroup.Done()
+		<-s.drain.stopping()
+		// Work received before the stop keeps going
+		time.Sleep(20 * time.Millisecond)
+		cancelled = s.drain.processCtx.Err() != nil
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Act
+	abandoned := s.drain.stop(ctx, &s.waitGroup)
+
+	// Assert
+	s.Equal(int64(0), abandoned)
+	s.False(cancelled)
+	s.Error(s.drain.receiveCtx.Err())
+	s.True(s.drain.abandoning())
+}
+
+func (s *DrainTestSuite) TestStop_CancelsWorkAfterDeadline() {
+	// Arrange
+	ctx := context.Background()
+	s.Require().NoError(s.queueService.SendCleanupMessage(ctx, "tenant1", time.Now()))
+	s.Require().NoError(s.queueService.SendCleanupMessage(ctx, "tenant2", time.Now()))
+	messages, err := s.queueService.ReceiveMessages(ctx, queue.QueueCleanup, 10, 0)
+	s.Require().NoError(err)
+	s.Require().Len(messages, 2)
+
+	s.waitGroup.Add(1)
+	go func() {
+		defer s.waitGroup.Done()
+		// Work that outlasts the deadline gives its messages back
+		<-s.drain.processCtx.Done()
+		s.drain.release(s.queueService, s.logger, queue.QueueCleanup, messages)
+	}()
+	stopCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	abandoned := s.drain.stop(stopCtx, &s.waitGroup)
+
+	// Assert
+	s.Equal(int64(2), abandoned)
+	s.True(s.drain.abandoning())
+	released, err := s.queueService.ReceiveMessages(ctx, queue.QueueCleanup, 10, 0)
+	s.NoError(err)
+	s.Len(released, 2)
+}
+
+func (s *DrainTestSuite) TestStop_StopsReceivingRightAway() {
+	// Arrange
+	received := make(chan struct{})
+	s.waitGroup.Add(1)
+	go func() {
+		defer s.waitGroup.Done()
+		_, err := s.queueService.ReceiveMessages(s.drain.receiveCtx, queue.QueueCleanup, 10, time.Minute)
+		s.Error(err)
+		close(received)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Act
+	abandoned := s.drain.stop(ctx, &s.waitGroup)
+
+	// Assert
+	s.Equal(int64(0), abandoned)
+	select {
+	case <-received:
+	default:
+		s.Fail("receiving kept waiting after the stop")
+	}
+}
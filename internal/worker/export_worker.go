@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+type ExportWorker struct {
+	queueService   *queue.Service
+	jobService     *service.ExportJobService
+	logger         *logger.Logger
+	workerCount    int
+	pollInterval   time.Duration
+	expiryInterval time.Duration
+	maxMessages    int
+	waitTime       time.Duration
+	drain          *drain
+	waitGroup      sync.WaitGroup
+}
+
+func NewExportWorker(
+	queueService *queue.Service,
+	jobService *service.ExportJobService,
+	logger *logger.Logger,
+	workerCount int,
+	pollInterval time.Duration,
+	expiryInterval time.Duration,
+) *ExportWorker {
+	return &ExportWorker{
+		queueService:   queueService,
+		jobService:     jobService,
+		logger:         logger,
+		workerCount:    workerCount,
+		pollInterval:   pollInterval,
+		expiryInterval: expiryInterval,
+		maxMessages:    1,
+		waitTime:       20 * time.Second,
+		drain:          newDrain(),
+	}
+}
+
+func (w *ExportWorker) Start() {
+	w.logger.Info("Starting Export workers...")
+
+	// Start multiple worker goroutines
+	for i := 0; i < w.workerCount; i++ {
+		w.waitGroup.Add(1)
+		go w.runWorker(i)
+	}
+
+	// Expired jobs are removed by a single goroutine
+	w.waitGroup.Add(1)
+	go w.runExpiry()
+}
+
+// Stop stops receiving messages and waits for the messages in flight until
+// ctx is done, the messages left then are returned to the queue
+func (w *ExportWorker) Stop(ctx context.Context) {
+	w.logger.Info("Stopping Export workers...")
+	if abandoned := w.drain.stop(ctx, &w.waitGroup); abandoned > 0 {
+		w.logger.Warnf("All Export workers stopped, %d messages abandoned", abandoned)
+		return
+	}
+	w.logger.Info("All Export workers stopped")
+}
+
+func (w *ExportWorker) runWorker(workerID int) {
+	defer w.waitGroup.Done()
+
+	w.logger.Infof("Export Worker %d started", workerID)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.drain.stopping():
+			w.logger.Infof("Export Worker %d shutting down", workerID)
+			return
+		case <-ticker.C:
+			if err := w.processMessages(w.drain.processCtx); err != nil {
+				w.logger.Errorf("Export Worker %d failed to process messages: %v", workerID, err)
+			}
+		}
+	}
+}
+
+func (w *ExportWorker) runExpiry() {
+	defer w.waitGroup.Done()
+
+	ticker := time.NewTicker(w.expiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.drain.stopping():
+			return
+		case <-ticker.C:
+			deleted, err := w.jobService.DeleteExpired(w.drain.processCtx)
+			if err != nil {
+				w.logger.Errorf("Failed to delete expired export jobs: %v", err)
+			}
+			if deleted > 0 {
+				w.logger.Infof("Deleted %d expired export jobs", deleted)
+			}
+		}
+	}
+}
+
+func (w *ExportWorker) processMessages(ctx context.Context) error {
+	// Stopping cuts the long poll short
+	messages, err := w.queueService.ReceiveMessages(w.drain.receiveCtx, queue.QueueExport, w.maxMessages, w.waitTime)
+	if err != nil {
+		if w.drain.receiveCtx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to receive messages: %w", err)
+	}
+
+	for i, msg := range messages {
+		if w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueExport, messages[i:])
+			return nil
+		}
+
+		err := msg.Err
+		if err == nil && msg.Message.Type != queue.MessageTypeExport {
+			err = fmt.Errorf("unknown message type: %s", msg.Message.Type)
+		}
+		if err == nil {
+			w.logger.Infof("Processing export job %s for tenant %s", msg.Message.JobID, msg.Message.TenantID)
+			err = w.jobService.Run(ctx, msg.Message.TenantID, msg.Message.JobID)
+		}
+		if err != nil && w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueExport, messages[i:])
+			return nil
+		}
+
+		// The outcome is recorded even when the drain deadline passed meanwhile
+		settleCtx := context.WithoutCancel(ctx)
+		if err != nil {
+			w.logger.Errorf("Failed to process export message %s: %v", msg.ID, err)
+			failMessage(settleCtx, w.queueService, w.logger, queue.QueueExport, msg, err)
+			continue
+		}
+
+		// Only delete the message if processing was successful
+		if err := w.queueService.DeleteMessage(settleCtx, queue.QueueExport, msg); err != nil {
+			w.logger.Errorf("Failed to delete message: %v", err)
+		}
+	}
+
+	return nil
+}
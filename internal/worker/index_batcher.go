@@ -0,0 +1,212 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/opensearch"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// minBatchLogs is the smallest request the batcher shrinks to while
+// OpenSearch rejects its requests
+const minBatchLogs = 50
+
+// indexBatch is the index messages merged into one _bulk request
+type indexBatch struct {
+	messages []queue.ReceivedMessage
+	logs     int
+	bytes    int
+}
+
+func (b *indexBatch) add(msg queue.ReceivedMessage) {
+	b.messages = append(b.messages, msg)
+	b.logs += len(msg.Message.Logs)
+	b.bytes += len(msg.Body)
+}
+
+// indexBatcher merges the logs of the index messages received by the workers
+// into _bulk requests, bounded by their number of logs, their size and how
+// long the first message waits, and sends several requests at once. A
+// message is deleted once all its logs are indexed, and retried alone when
+// one of them failed.
+type indexBatcher struct {
+	osRepository opensearch.Repository
+	queueService *queue.Service
+	logger       *logger.Logger
+	config       *config.IndexBatchConfig
+	drain        *drain
+	messages     chan queue.ReceivedMessage
+	slots        chan struct{}
+	requests     sync.WaitGroup
+	// maxLogs is the current bound of the number of logs in a request
+	maxLogs atomic.Int64
+}
+
+func newIndexBatcher(
+	osRepository opensearch.Repository,
+	queueService *queue.Service,
+	logger *logger.Logger,
+	config *config.IndexBatchConfig,
+	drain *drain,
+) *indexBatcher {
+	b := &indexBatcher{
+		osRepository: osRepository,
+		queueService: queueService,
+		logger:       logger,
+		config:       config,
+		drain:        drain,
+		messages:     make(chan queue.ReceivedMessage),
+		slots:        make(chan struct{}, max(config.Concurrency, 1)),
+	}
+	b.maxLogs.Store(int64(config.MaxLogs))
+	return b
+}
+
+// add hands a message over to the batcher. It blocks while every request
+// slot is busy, which holds back the receives.
+func (b *indexBatcher) add(msg queue.ReceivedMessage) {
+	b.messages <- msg
+}
+
+// close sends the messages left once no more are added
+func (b *indexBatcher) close() {
+	close(b.messages)
+}
+
+// run collects the added messages into batches until the batcher is closed,
+// and then waits for the requests in flight
+func (b *indexBatcher) run() {
+	timer := time.NewTimer(b.config.MaxWait)
+	timer.Stop()
+
+	var batch indexBatch
+	for {
+		select {
+		case msg, ok := <-b.messages:
+			if !ok {
+				timer.Stop()
+				b.send(batch)
+				b.requests.Wait()
+				return
+			}
+
+			if len(batch.messages) == 0 {
+				timer.Reset(b.config.MaxWait)
+			}
+			batch.add(msg)
+			if batch.logs >= int(b.maxLogs.Load()) || batch.bytes >= b.config.MaxBytes {
+				timer.Stop()
+				b.send(batch)
+				batch = indexBatch{}
+			}
+		case <-timer.C:
+			b.send(batch)
+			batch = indexBatch{}
+		}
+	}
+}
+
+// send indexes the batch once a request slot is free
+func (b *indexBatcher) send(batch indexBatch) {
+	if len(batch.messages) == 0 {
+		return
+	}
+
+	b.slots <- struct{}{}
+	b.requests.Add(1)
+	go func() {
+		defer func() {
+			<-b.slots
+			b.requests.Done()
+		}()
+		b.index(batch)
+	}()
+}
+
+// index sends the logs of the batch in one request, then deletes the
+// messages whose logs were all indexed and retries the others
+func (b *indexBatcher) index(batch indexBatch) {
+	ctx := b.drain.processCtx
+
+	// owners maps each log of the request to its message
+	logs := make([]domain.AuditLog, 0, batch.logs)
+	owners := make([]int, 0, batch.logs)
+	for i, msg := range batch.messages {
+		logs = append(logs, msg.Message.Logs...)
+		for range msg.Message.Logs {
+			owners = append(owners, i)
+		}
+	}
+
+	failures, err := b.osRepository.BulkIndexItems(ctx, logs)
+	if err != nil && b.drain.abandoning() {
+		b.drain.release(b.queueService, b.logger, queue.QueueIndex, batch.messages)
+		return
+	}
+	b.adapt(err, failures)
+
+	// The outcome is recorded even when the drain deadline passed meanwhile
+	settleCtx := context.WithoutCancel(ctx)
+	if err != nil {
+		b.logger.Errorf("Failed to index %d logs of %d messages: %v", len(logs), len(batch.messages), err)
+		for _, msg := range batch.messages {
+			failMessage(settleCtx, b.queueService, b.logger, queue.QueueIndex, msg, err)
+		}
+		return
+	}
+
+	failed := make(map[int][]error)
+	for _, failure := range failures {
+		if failure.Position < len(owners) {
+			owner := owners[failure.Position]
+			failed[owner] = append(failed[owner], failure)
+		}
+	}
+
+	for i, msg := range batch.messages {
+		if errs := failed[i]; len(errs) > 0 {
+			err := fmt.Errorf("%d of %d logs failed to index: %w", len(errs), len(msg.Message.Logs), errors.Join(errs...))
+			b.logger.Errorf("Failed to process message %s: %v", msg.ID, err)
+			failMessage(settleCtx, b.queueService, b.logger, queue.QueueIndex, msg, err)
+			continue
+		}
+
+		// Only delete the message if all its logs were indexed
+		if err := b.queueService.DeleteMessage(settleCtx, queue.QueueIndex, msg); err != nil {
+			b.logger.Errorf("Failed to delete message: %v", err)
+		}
+	}
+
+	b.logger.Infof("Indexed %d logs of %d messages, %d failed", len(logs)-len(failures), len(batch.messages), len(failures))
+}
+
+// adapt halves the number of logs in a request while OpenSearch rejects
+// requests, and grows it back by a tenth with every accepted request
+func (b *indexBatcher) adapt(err error, failures []*opensearch.BulkItemError) {
+	rejected := errors.Is(err, opensearch.ErrRejected)
+	for _, failure := range failures {
+		if errors.Is(failure, opensearch.ErrRejected) {
+			rejected = true
+			break
+		}
+	}
+
+	current := b.maxLogs.Load()
+	switch {
+	case rejected:
+		next := max(current/2, int64(min(minBatchLogs, b.config.MaxLogs)))
+		if next < current && b.maxLogs.CompareAndSwap(current, next) {
+			b.logger.Warnf("OpenSearch rejected a bulk request, sending up to %d logs per request", next)
+		}
+	case err == nil && current < int64(b.config.MaxLogs):
+		b.maxLogs.CompareAndSwap(current, min(current+current/10+1, int64(b.config.MaxLogs)))
+	}
+}
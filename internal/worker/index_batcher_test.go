@@ -0,0 +1,294 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
+	"github.com/buiminhduc234/audit-log-api/internal/repository/opensearch"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// fakeBulkIndexer records the _bulk requests and answers them with respond
+type fakeBulkIndexer struct {
+	opensearch.Repository
+	mu       sync.Mutex
+	requests [][]domain.AuditLog
+	respond  func(logs []domain.AuditLog) ([]*opensearch.BulkItemError, error)
+}
+
+func (f *fakeBulkIndexer) BulkIndexItems(ctx context.Context, logs []domain.AuditLog) ([]*opensearch.BulkItemError, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, logs)
+	respond := f.respond
+	f.mu.Unlock()
+
+	if respond == nil {
+		return nil, nil
+	}
+	return respond(logs)
+}
+
+func (f *fakeBulkIndexer) received() [][]domain.AuditLog {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]domain.AuditLog(nil), f.requests...)
+}
+
+type IndexBatcherTestSuite struct {
+	suite.Suite
+	indexer      *fakeBulkIndexer
+	queueService *queue.Service
+	config       *config.IndexBatchConfig
+	drain        *drain
+	batcher      *indexBatcher
+}
+
+func (s *IndexBatcherTestSuite) SetupTest() {
+	s.indexer = &fakeBulkIndexer{}
+	// Failed messages are received again right away
+	s.queueService = queue.NewService(queue.NewMemoryQueue(), config.RetryPolicy{MaxReceives: 5})
+	s.config = &config.IndexBatchConfig{MaxLogs: 400, MaxBytes: 5 << 20, MaxWait: time.Hour, Concurrency: 2}
+	s.drain = newDrain()
+	s.batcher = newIndexBatcher(s.indexer, s.queueService, &logger.Logger{Logger: zap.NewNop()}, s.config, s.drain)
+}
+
+func TestIndexBatcher(t *testing.T) {
+	suite.Run(t, new(IndexBatcherTestSuite))
+}
+
+// receive sends a message of the given number of logs per count and receives
+// them all
+func (s *IndexBatcherTestSuite) receive(logCounts ...int) []queue.ReceivedMessage {
+	ctx := context.Background()
+	for i, count := range logCounts {
+		logs := make([]domain.AuditLog, 0, count)
+		for j := range count {
+			logs = append(logs, domain.AuditLog{ID: fmt.Sprintf("log%d-%d", i, j), TenantID: "tenant1"})
+		}
+		s.Require().NoError(s.queueService.SendBulkIndexMessage(ctx, logs))
+	}
+
+	messages, err := s.queueService.ReceiveMessages(ctx, queue.QueueIndex, len(logCounts), 0)
+	s.Require().NoError(err)
+	s.Require().Len(messages, len(logCounts))
+	return messages
+}
+
+// batch merges the messages as run does
+func batch(messages ...queue.ReceivedMessage) indexBatch {
+	var b indexBatch
+	for _, msg := range messages {
+		b.add(msg)
+	}
+	return b
+}
+
+// visible receives the messages left in the index queue
+func (s *IndexBatcherTestSuite) visible() []queue.ReceivedMessage {
+	messages, err := s.queueService.ReceiveMessages(context.Background(), queue.QueueIndex, 10, 0)
+	s.Require().NoError(err)
+	return messages
+}
+
+func (s *IndexBatcherTestSuite) TestIndex_DeletesIndexedMessages() {
+	// Arrange
+	messages := s.receive(2, 1)
+
+	// Act
+	s.batcher.index(batch(messages...))
+
+	// Assert
+	requests := s.indexer.received()
+	s.Require().Len(requests, 1)
+	s.Len(requests[0], 3)
+	s.Empty(s.visible())
+}
+
+func (s *IndexBatcherTestSuite) TestIndex_RetriesMessagesWithFailedLogs() {
+	// Arrange
+	messages := s.receive(2, 1, 1)
+	s.indexer.respond = func(logs []domain.AuditLog) ([]*opensearch.BulkItemError, error) {
+		// The second log of the first message and the log of the last one
+		return []*opensearch.BulkItemError{
+			{Position: 1, LogID: logs[1].ID, Status: 400, Type: "mapper_parsing_exception"},
+			{Position: 3, LogID: logs[3].ID, Status: 400, Type: "mapper_parsing_exception"},
+		}, nil
+	}
+
+	// Act
+	s.batcher.index(batch(messages...))
+
+	// Assert
+	retried := s.visible()
+	s.Require().Len(retried, 2)
+	s.Equal(messages[0].ID, retried[0].ID)
+	s.Equal(messages[2].ID, retried[1].ID)
+	s.Equal(2, retried[0].ReceiveCount)
+}
+
+func (s *IndexBatcherTestSuite) TestIndex_RetriesEveryMessageOfFailedRequest() {
+	// Arrange
+	messages := s.receive(1, 1)
+	s.indexer.respond = func([]domain.AuditLog) ([]*opensearch.BulkItemError, error) {
+		return nil, errors.New("connection reset")
+	}
+
+	// Act
+	s.batcher.index(batch(messages...))
+
+	// Assert
+	s.Len(s.visible(), 2)
+	s.Equal(int64(s.config.MaxLogs), s.batcher.maxLogs.Load())
+}
+
+func (s *IndexBatcherTestSuite) TestIndex_ReleasesMessagesAfterDrainDeadline() {
+	// Arrange
+	messages := s.receive(1, 1)
+	s.indexer.respond = func([]domain.AuditLog) ([]*opensearch.BulkItemError, error) {
+		return nil, context.Canceled
+	}
+	s.drain.abandon()
+
+	// Act
+	s.batcher.index(batch(messages...))
+
+	// Assert
+	s.Equal(int64(2), s.drain.abandoned.Load())
+	retried := s.visible()
+	s.Len(retried, 2)
+}
+
+func (s *IndexBatcherTestSuite) TestAdapt_ShrinksOnRejectionsAndGrowsBack() {
+	rejectedItem := []*opensearch.BulkItemError{{Position: 0, Status: 429, Type: "es_rejected_execution_exception"}}
+	otherItem := []*opensearch.BulkItemError{{Position: 0, Status: 400, Type: "mapper_parsing_exception"}}
+	steps := []struct {
+		name     string
+		err      error
+		failures []*opensearch.BulkItemError
+		want     int64
+	}{
+		{name: "rejected request halves", err: fmt.Errorf("%w: 413", opensearch.ErrRejected), want: 200},
+		{name: "rejected item halves", failures: rejectedItem, want: 100},
+		{name: "other item failure keeps", failures: otherItem, want: 111},
+		{name: "failed request keeps", err: errors.New("connection reset"), want: 111},
+		{name: "halves to the minimum", err: opensearch.ErrRejected, want: 55},
+		{name: "stops at the minimum", err: opensearch.ErrRejected, want: minBatchLogs},
+		{name: "stays at the minimum", err: opensearch.ErrRejected, want: minBatchLogs},
+		{name: "accepted grows by a tenth", want: 56},
+	}
+
+	for _, step := range steps {
+		// Act
+		s.batcher.adapt(step.err, step.failures)
+
+		// Assert
+		s.Equal(step.want, s.batcher.maxLogs.Load(), step.name)
+	}
+
+	for range 100 {
+		s.batcher.adapt(nil, nil)
+	}
+	s.Equal(int64(s.config.MaxLogs), s.batcher.maxLogs.Load())
+}
+
+func (s *IndexBatcherTestSuite) TestRun_SendsShrunkBatchesRightAway() {
+	// Arrange
+	s.batcher.maxLogs.Store(2)
+	messages := s.receive(1, 1, 1)
+	done := make(chan struct{})
+	go func() {
+		s.batcher.run()
+		close(done)
+	}()
+
+	// Act
+	for _, msg := range messages {
+		s.batcher.add(msg)
+	}
+
+	// Assert
+	s.Eventually(func() bool { return len(s.indexer.received()) == 1 }, time.Second, time.Millisecond)
+	s.Len(s.indexer.received()[0], 2)
+
+	s.batcher.close()
+	<-done
+	requests := s.indexer.received()
+	s.Require().Len(requests, 2)
+	s.Len(requests[1], 1)
+}
+
+func (s *IndexBatcherTestSuite) TestRun_SendsAtMaxBytes() {
+	// Arrange
+	messages := s.receive(1, 1)
+	s.config.MaxBytes = len(messages[0].Body)
+	done := make(chan struct{})
+	go func() {
+		s.batcher.run()
+		close(done)
+	}()
+
+	// Act
+	s.batcher.add(messages[0])
+
+	// Assert
+	s.Eventually(func() bool { return len(s.indexer.received()) == 1 }, time.Second, time.Millisecond)
+	s.batcher.close()
+	<-done
+}
+
+func (s *IndexBatcherTestSuite) TestRun_SendsAfterMaxWait() {
+	// Arrange
+	s.config.MaxWait = 10 * time.Millisecond
+	messages := s.receive(1)
+	done := make(chan struct{})
+	go func() {
+		s.batcher.run()
+		close(done)
+	}()
+
+	// Act
+	s.batcher.add(messages[0])
+
+	// Assert
+	s.Eventually(func() bool { return len(s.indexer.received()) == 1 }, time.Second, time.Millisecond)
+	s.batcher.close()
+	<-done
+	s.Len(s.indexer.received(), 1)
+}
+
+func (s *IndexBatcherTestSuite) TestRun_FlushesOnClose() {
+	// Arrange
+	messages := s.receive(1, 2)
+	done := make(chan struct{})
+	go func() {
+		s.batcher.run()
+		close(done)
+	}()
+	for _, msg := range messages {
+		s.batcher.add(msg)
+	}
+
+	// Act
+	s.batcher.close()
+
+	// Assert
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.FailNow("batcher kept running after it was closed")
+	}
+	requests := s.indexer.received()
+	s.Require().Len(requests, 1)
+	s.Len(requests[0], 3)
+	s.Empty(s.visible())
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/buiminhduc234/audit-log-api/internal/metrics"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// OutboxRelay publishes the outbox entries written with every stored log to
+// the index queue, so that logs reach OpenSearch even when the queue was
+// unavailable when they were stored
+type OutboxRelay struct {
+	outbox       *service.OutboxService
+	logger       *logger.Logger
+	workerCount  int
+	pollInterval time.Duration
+	batchSize    int
+	drain        *drain
+	waitGroup    sync.WaitGroup
+}
+
+func NewOutboxRelay(
+	outbox *service.OutboxService,
+	logger *logger.Logger,
+	workerCount int,
+	pollInterval time.Duration,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:       outbox,
+		logger:       logger,
+		workerCount:  workerCount,
+		pollInterval: pollInterval,
+		batchSize:    100,
+		drain:        newDrain(),
+	}
+}
+
+func (r *OutboxRelay) Start() {
+	r.logger.Info("Starting Outbox relays...")
+
+	for i := 0; i < r.workerCount; i++ {
+		r.waitGroup.Add(1)
+		go r.runWorker(i)
+	}
+
+	r.waitGroup.Add(1)
+	go r.reportLag()
+}
+
+// Stop stops claiming entries and waits for the entries being published
+// until ctx is done. Entries still claimed then are published again once
+// their lease expires.
+func (r *OutboxRelay) Stop(ctx context.Context) {
+	r.logger.Info("Stopping Outbox relays...")
+	r.drain.stop(ctx, &r.waitGroup)
+	r.logger.Info("All Outbox relays stopped")
+}
+
+func (r *OutboxRelay) runWorker(workerID int) {
+	defer r.waitGroup.Done()
+
+	r.logger.Infof("Outbox Relay %d started", workerID)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.drain.stopping():
+			r.logger.Infof("Outbox Relay %d shutting down", workerID)
+			return
+		case <-ticker.C:
+			r.relay(r.drain.processCtx, workerID)
+		}
+	}
+}
+
+// relay publishes due entries until none are left or a pass fails
+func (r *OutboxRelay) relay(ctx context.Context, workerID int) {
+	for {
+		result, err := r.outbox.Relay(ctx, r.batchSize)
+		if err != nil {
+			r.logger.Errorf("Outbox Relay %d failed to relay entries: %v", workerID, err)
+			return
+		}
+
+		metrics.OutboxPublished.Add(float64(result.Published))
+		metrics.OutboxFailed.Add(float64(result.Failed))
+		if result.Missing > 0 {
+			r.logger.Infof("Outbox Relay %d dropped %d entries of deleted logs", workerID, result.Missing)
+		}
+
+		claimed := result.Published + result.Failed + result.Missing
+		if result.Failed > 0 || claimed < r.batchSize {
+			return
+		}
+
+		select {
+		case <-r.drain.stopping():
+			return
+		default:
+		}
+	}
+}
+
+// reportLag updates the lag metrics on every poll
+func (r *OutboxRelay) reportLag() {
+	defer r.waitGroup.Done()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.drain.stopping():
+			return
+		case <-ticker.C:
+			lag, err := r.outbox.Lag(r.drain.processCtx)
+			if err != nil {
+				r.logger.Errorf("Failed to get outbox lag: %v", err)
+				continue
+			}
+
+			metrics.OutboxPending.Set(float64(lag.Pending))
+			if lag.Oldest.IsZero() {
+				metrics.OutboxLagSeconds.Set(0)
+			} else {
+				metrics.OutboxLagSeconds.Set(time.Since(lag.Oldest).Seconds())
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/buiminhduc234/audit-log-api/internal/repository"
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// RetentionScheduler enqueues an archive message for every tenant with an
+// enabled retention policy on a cron schedule. The archive worker enqueues the
+// cleanup once the logs are archived, and the cleanup worker applies the
+// policy's delete period and kept severities.
+type RetentionScheduler struct {
+	queueService *queue.Service
+	repository   repository.PostgresRepository
+	logger       *logger.Logger
+	schedule     cron.Schedule
+	drain        *drain
+	waitGroup    sync.WaitGroup
+}
+
+func NewRetentionScheduler(
+	queueService *queue.Service,
+	repository repository.PostgresRepository,
+	logger *logger.Logger,
+	schedule cron.Schedule,
+) *RetentionScheduler {
+	return &RetentionScheduler{
+		queueService: queueService,
+		repository:   repository,
+		logger:       logger,
+		schedule:     schedule,
+		drain:        newDrain(),
+	}
+}
+
+func (s *RetentionScheduler) Start() {
+	s.logger.Info("Starting Retention scheduler...")
+
+	s.waitGroup.Add(1)
+	go s.run()
+}
+
+// Stop stops the schedule and waits for a run in progress until ctx is done
+func (s *RetentionScheduler) Stop(ctx context.Context) {
+	s.logger.Info("Stopping Retention scheduler...")
+	s.drain.stop(ctx, &s.waitGroup)
+	s.logger.Info("Retention scheduler stopped")
+}
+
+func (s *RetentionScheduler) run() {
+	defer s.waitGroup.Done()
+
+	for {
+		next := s.schedule.Next(time.Now())
+		s.logger.Infof("Next retention run at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.drain.stopping():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := s.schedulePolicies(s.drain.processCtx); err != nil {
+				s.logger.Errorf("Failed to schedule retention: %v", err)
+			}
+		}
+	}
+}
+
+// schedulePolicies enqueues the archive of every enabled policy. A failure for
+// one tenant does not hold back the others.
+func (s *RetentionScheduler) schedulePolicies(ctx context.Context) error {
+	policies, err := s.repository.RetentionPolicy().ListEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list retention policies: %w", err)
+	}
+
+	now := time.Now()
+	for _, policy := range policies {
+		beforeDate := policy.ArchiveBefore(now)
+		if err := s.queueService.SendArchiveMessage(ctx, policy.TenantID, beforeDate); err != nil {
+			s.logger.Errorf("Failed to enqueue archive message for tenant %s: %v", policy.TenantID, err)
+			continue
+		}
+		if err := s.repository.RetentionPolicy().MarkScheduled(ctx, policy.TenantID, now); err != nil {
+			s.logger.Errorf("Failed to record retention run for tenant %s: %v", policy.TenantID, err)
+		}
+
+		s.logger.Infof("Enqueued archive of logs before %s for tenant %s", beforeDate.Format(time.RFC3339), policy.TenantID)
+	}
+
+	return nil
+}
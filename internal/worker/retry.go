@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
+	"github.com/buiminhduc234/audit-log-api/pkg/logger"
+)
+
+// failMessage retries a message that could not be processed with a backoff,
+// or moves it to the dead-letter queue once it keeps failing
+func failMessage(ctx context.Context, queueService *queue.Service, logger *logger.Logger, queueName string, msg queue.ReceivedMessage, cause error) {
+	deadLettered, err := queueService.Fail(ctx, queueName, msg, cause)
+	if err != nil {
+		logger.Errorf("Failed to handle failed message %s: %v", msg.ID, err)
+		return
+	}
+	if deadLettered {
+		logger.Warnf("Moved message %s to the dead-letter queue after %d receives: %v", msg.ID, msg.ReceiveCount, cause)
+	}
+}
@@ -7,40 +7,50 @@ import (
 	"time"
 
 	"github.com/buiminhduc234/audit-log-api/internal/config"
+	"github.com/buiminhduc234/audit-log-api/internal/domain"
 	"github.com/buiminhduc234/audit-log-api/internal/repository/opensearch"
+	"github.com/buiminhduc234/audit-log-api/internal/service"
 	"github.com/buiminhduc234/audit-log-api/internal/service/queue"
 	"github.com/buiminhduc234/audit-log-api/pkg/logger"
 )
 
 type SQSWorker struct {
-	sqsService   *queue.SQSService
-	osRepository opensearch.Repository
-	logger       *logger.Logger
-	workerCount  int
-	pollInterval time.Duration
-	maxMessages  int32
-	waitTime     int32
-	shutdownChan chan struct{}
-	waitGroup    sync.WaitGroup
+	queueService   *queue.Service
+	osRepository   opensearch.Repository
+	reindexService *service.ReindexService
+	logger         *logger.Logger
+	workerCount    int
+	pollInterval   time.Duration
+	maxMessages    int
+	waitTime       time.Duration
+	batcher        *indexBatcher
+	drain          *drain
+	waitGroup      sync.WaitGroup
+	receivers      sync.WaitGroup
 }
 
 func NewSQSWorker(
-	sqsService *queue.SQSService,
+	queueService *queue.Service,
 	osRepository opensearch.Repository,
+	reindexService *service.ReindexService,
 	logger *logger.Logger,
 	workerCount int,
 	pollInterval time.Duration,
+	batchConfig *config.IndexBatchConfig,
 ) *SQSWorker {
-	return &SQSWorker{
-		sqsService:   sqsService,
-		osRepository: osRepository,
-		logger:       logger,
-		workerCount:  workerCount,
-		pollInterval: pollInterval,
-		maxMessages:  10, // Process up to 10 messages at a time
-		waitTime:     20, // Long polling: wait up to 20 seconds for messages
-		shutdownChan: make(chan struct{}),
+	w := &SQSWorker{
+		queueService:   queueService,
+		osRepository:   osRepository,
+		reindexService: reindexService,
+		logger:         logger,
+		workerCount:    workerCount,
+		pollInterval:   pollInterval,
+		maxMessages:    10,               // Process up to 10 messages at a time
+		waitTime:       20 * time.Second, // Long polling: wait up to 20 seconds for messages
+		drain:          newDrain(),
 	}
+	w.batcher = newIndexBatcher(osRepository, queueService, logger, batchConfig, w.drain)
+	return w
 }
 
 func (w *SQSWorker) Start() {
@@ -49,19 +59,36 @@ func (w *SQSWorker) Start() {
 	// Start multiple worker goroutines
 	for i := 0; i < w.workerCount; i++ {
 		w.waitGroup.Add(1)
+		w.receivers.Add(1)
 		go w.runWorker(i)
 	}
+
+	// The batcher sends the logs left once every worker stopped receiving
+	w.waitGroup.Add(1)
+	go func() {
+		defer w.waitGroup.Done()
+		w.batcher.run()
+	}()
+	go func() {
+		w.receivers.Wait()
+		w.batcher.close()
+	}()
 }
 
-func (w *SQSWorker) Stop() {
+// Stop stops receiving messages and waits for the messages in flight until
+// ctx is done, the messages left then are returned to the queue
+func (w *SQSWorker) Stop(ctx context.Context) {
 	w.logger.Info("Stopping SQS workers...")
-	close(w.shutdownChan)
-	w.waitGroup.Wait()
+	if abandoned := w.drain.stop(ctx, &w.waitGroup); abandoned > 0 {
+		w.logger.Warnf("All SQS workers stopped, %d messages abandoned", abandoned)
+		return
+	}
 	w.logger.Info("All SQS workers stopped")
 }
 
 func (w *SQSWorker) runWorker(workerID int) {
 	defer w.waitGroup.Done()
+	defer w.receivers.Done()
 
 	w.logger.Infof("Worker %d started", workerID)
 
@@ -70,58 +97,100 @@ func (w *SQSWorker) runWorker(workerID int) {
 
 	for {
 		select {
-		case <-w.shutdownChan:
+		case <-w.drain.stopping():
 			w.logger.Infof("Worker %d shutting down", workerID)
 			return
 		case <-ticker.C:
-			if err := w.processMessages(context.Background()); err != nil {
-				w.logger.Errorf("Worker %d failed to process messages: %v", workerID, err)
+			// Keep receiving without waiting for the ticker while the queue
+			// returns full batches
+			for {
+				received, err := w.processMessages(w.drain.processCtx)
+				if err != nil {
+					w.logger.Errorf("Worker %d failed to process messages: %v", workerID, err)
+				}
+				if err != nil || received < w.maxMessages || w.drain.receiveCtx.Err() != nil {
+					break
+				}
 			}
 		}
 	}
 }
 
-func (w *SQSWorker) processMessages(ctx context.Context) error {
-	// Get index queue URL from config
-	config := config.DefaultSQSConfig()
-	indexQueueURL := config.IndexQueueURL
-
-	messages, err := w.sqsService.ReceiveMessages(ctx, indexQueueURL, w.maxMessages, w.waitTime)
+// processMessages receives a batch of messages and returns how many were
+// received. Index messages go to the batcher, the others are processed here.
+func (w *SQSWorker) processMessages(ctx context.Context) (int, error) {
+	// Stopping cuts the long poll short
+	messages, err := w.queueService.ReceiveMessages(w.drain.receiveCtx, queue.QueueIndex, w.maxMessages, w.waitTime)
 	if err != nil {
-		return fmt.Errorf("failed to receive messages: %w", err)
+		if w.drain.receiveCtx.Err() != nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to receive messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		if err := w.processMessage(ctx, msg.Message); err != nil {
-			w.logger.Errorf("Failed to process message: %v", err)
+	for i, msg := range messages {
+		if w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueIndex, messages[i:])
+			return len(messages), nil
+		}
+
+		err := msg.Err
+		switch {
+		case err != nil:
+		case msg.Message.Type == queue.MessageTypeIndex || msg.Message.Type == queue.MessageTypeBulkIndex:
+			if err = checkIndexMessage(msg.Message); err == nil {
+				// The batcher deletes the message once its logs are indexed
+				w.batcher.add(msg)
+				continue
+			}
+		default:
+			err = w.processMessage(ctx, msg.Message)
+		}
+		if err != nil && w.drain.abandoning() {
+			w.drain.release(w.queueService, w.logger, queue.QueueIndex, messages[i:])
+			return len(messages), nil
+		}
+
+		// The outcome is recorded even when the drain deadline passed meanwhile
+		settleCtx := context.WithoutCancel(ctx)
+		if err != nil {
+			w.logger.Errorf("Failed to process message %s: %v", msg.ID, err)
+			failMessage(settleCtx, w.queueService, w.logger, queue.QueueIndex, msg, err)
 			continue
 		}
 
 		// Only delete the message if processing was successful
-		if err := w.sqsService.DeleteMessage(ctx, indexQueueURL, msg.ReceiptHandle); err != nil {
+		if err := w.queueService.DeleteMessage(settleCtx, queue.QueueIndex, msg); err != nil {
 			w.logger.Errorf("Failed to delete message: %v", err)
 		}
 	}
 
-	return nil
+	return len(messages), nil
 }
 
 func (w *SQSWorker) processMessage(ctx context.Context, msg queue.Message) error {
 	w.logger.Infof("Processing message of type %s for tenant %s", msg.Type, msg.TenantID)
 
 	switch msg.Type {
-	case queue.MessageTypeIndex:
-		if len(msg.Logs) != 1 {
-			return fmt.Errorf("invalid number of logs for INDEX message: %d", len(msg.Logs))
-		}
-		return w.osRepository.Index(ctx, &msg.Logs[0])
+	case queue.MessageTypeReindex:
+		return w.reindexService.Run(ctx, msg.JobID, func(job *domain.ReindexJob) {
+			w.logger.Infof("Reindex job %s: checked %d days, %d drifted, %d logs missing, %d reindexed",
+				job.ID, job.CheckedDays, job.DriftedDays, job.MissingLogs, job.ReindexedLogs)
+		})
 
-	case queue.MessageTypeBulkIndex:
-		if len(msg.Logs) == 0 {
-			return fmt.Errorf("empty logs array for BULK_INDEX message")
-		}
-		return w.osRepository.BulkIndex(ctx, msg.Logs)
 	default:
 		return fmt.Errorf("unknown message type: %s", msg.Type)
 	}
 }
+
+// checkIndexMessage checks the number of logs of an index message
+func checkIndexMessage(msg queue.Message) error {
+	switch {
+	case msg.Type == queue.MessageTypeIndex && len(msg.Logs) != 1:
+		return fmt.Errorf("invalid number of logs for INDEX message: %d", len(msg.Logs))
+	case msg.Type == queue.MessageTypeBulkIndex && len(msg.Logs) == 0:
+		return fmt.Errorf("empty logs array for BULK_INDEX message")
+	default:
+		return nil
+	}
+}
@@ -44,6 +44,28 @@ aws --endpoint-url=http://localhost:4566 sqs create-queue \
         "ReceiveMessageWaitTimeSeconds": "20"
     }'
 
+# Create export queue (for asynchronous export jobs)
+echo "Creating audit-log-export-queue..."
+aws --endpoint-url=http://localhost:4566 sqs create-queue \
+    --queue-name audit-log-export-queue \
+    --attributes '{
+        "VisibilityTimeout": "900",
+        "MessageRetentionPeriod": "86400",
+        "DelaySeconds": "0",
+        "ReceiveMessageWaitTimeSeconds": "20"
+    }'
+
+# Create dead-letter queues, the workers move a message to the dead-letter
+# queue of its queue once it failed QUEUE_MAX_RECEIVES times
+for queue in index archive cleanup export; do
+    echo "Creating audit-log-$queue-dlq..."
+    aws --endpoint-url=http://localhost:4566 sqs create-queue \
+        --queue-name audit-log-$queue-dlq \
+        --attributes '{
+            "MessageRetentionPeriod": "1209600"
+        }'
+done
+
 # Create S3 buckets
 echo "Creating S3 buckets..."
 
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- Tamper-evident hash chain columns. Every log stores its position in the
+-- tenant's chain, the hash of its predecessor and its own hash.
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash TEXT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_chain_seq ON audit_logs(tenant_id, chain_seq) WHERE chain_seq IS NOT NULL;
+
+-- Current head of each tenant's chain. Appends lock this row so that
+-- concurrent writers cannot fork the chain.
+CREATE TABLE IF NOT EXISTS audit_log_chain_heads (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    last_seq BIGINT NOT NULL DEFAULT 0,
+    last_hash TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_log_chain_heads;
+
+DROP INDEX IF EXISTS idx_audit_logs_chain_seq;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS chain_seq;
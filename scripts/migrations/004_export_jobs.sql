@@ -0,0 +1,25 @@
+-- +migrate Up
+-- Asynchronous export jobs. The worker writes the file to S3 and the job row
+-- keeps its state until it expires.
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    format TEXT NOT NULL,
+    fields JSONB,
+    filter JSONB NOT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    s3_key TEXT,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_tenant_id ON export_jobs(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_expires_at ON export_jobs(expires_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS export_jobs;
@@ -0,0 +1,23 @@
+-- +migrate Up
+-- Restores of archived logs from S3 back into PostgreSQL and OpenSearch
+CREATE TABLE IF NOT EXISTS restore_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    archive_key TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    total_logs BIGINT NOT NULL DEFAULT 0,
+    restored_logs BIGINT NOT NULL DEFAULT 0,
+    skipped_logs BIGINT NOT NULL DEFAULT 0,
+    indexed_logs BIGINT NOT NULL DEFAULT 0,
+    chain_valid BOOLEAN,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_restore_jobs_tenant_id ON restore_jobs(tenant_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS restore_jobs;
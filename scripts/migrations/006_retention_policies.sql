@@ -0,0 +1,16 @@
+-- +migrate Up
+-- Per-tenant retention policies applied by the retention scheduler and the
+-- archive and cleanup workers
+CREATE TABLE IF NOT EXISTS retention_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    archive_after_days INTEGER NOT NULL,
+    delete_after_days INTEGER NOT NULL DEFAULT 0,
+    keep_severities JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_scheduled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS retention_policies;
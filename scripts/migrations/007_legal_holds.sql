@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Legal holds keep matching logs from being deleted by cleanups, until released
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    user_id TEXT,
+    resource_type TEXT,
+    resource_id TEXT,
+    start_time TIMESTAMP WITH TIME ZONE,
+    end_time TIMESTAMP WITH TIME ZONE,
+    log_ids JSONB NOT NULL DEFAULT '[]',
+    created_by TEXT,
+    released_by TEXT,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The cleanup worker loads the active holds of a tenant
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds (tenant_id) WHERE released_at IS NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS legal_holds;
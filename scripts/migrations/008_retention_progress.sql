@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Tracks how far the logs of each tenant have been archived and cleaned up.
+-- Whole chunks of audit_logs are only dropped once every tenant with logs in
+-- them has archived past the chunk, and the cleanup of a tenant resumes from
+-- the last chunk it completed. A running cleanup owns the row until it
+-- finishes, or until its lease expires without the row being updated.
+CREATE TABLE IF NOT EXISTS retention_progress (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    archived_before TIMESTAMP WITH TIME ZONE,
+    cleanup_before TIMESTAMP WITH TIME ZONE,
+    cleaned_up_to TIMESTAMP WITH TIME ZONE,
+    deleted_logs BIGINT NOT NULL DEFAULT 0,
+    held_logs BIGINT NOT NULL DEFAULT 0,
+    dropped_chunks INTEGER NOT NULL DEFAULT 0,
+    cleanup_started_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS retention_progress;
@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Logs waiting to be published to the index queue. Rows are written in the
+-- same transaction as the logs and removed by the outbox relay once published.
+CREATE TABLE IF NOT EXISTS audit_log_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    log_id UUID NOT NULL,
+    log_timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The relay claims the oldest due rows
+CREATE INDEX IF NOT EXISTS idx_audit_log_outbox_next_attempt ON audit_log_outbox (next_attempt_at, id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_log_outbox;
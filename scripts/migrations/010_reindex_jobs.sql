@@ -0,0 +1,30 @@
+-- +migrate Up
+-- Reconciliations of the logs in PostgreSQL against the OpenSearch indices.
+-- A job walks the tenants in ID order and their days in date order, the
+-- cursor records the last day it completed so an interrupted job resumes there.
+CREATE TABLE IF NOT EXISTS reindex_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    compare_ids BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    cursor_tenant_id UUID,
+    cursor_date DATE,
+    checked_days BIGINT NOT NULL DEFAULT 0,
+    drifted_days BIGINT NOT NULL DEFAULT 0,
+    missing_logs BIGINT NOT NULL DEFAULT 0,
+    extra_logs BIGINT NOT NULL DEFAULT 0,
+    reindexed_logs BIGINT NOT NULL DEFAULT 0,
+    drift JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_by TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS reindex_jobs;
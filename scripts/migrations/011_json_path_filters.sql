@@ -0,0 +1,16 @@
+-- +migrate Up
+-- The paths inside metadata, before_state and after_state a tenant has
+-- declared, only those are mapped in the OpenSearch indices of the tenant
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS indexed_paths JSONB NOT NULL DEFAULT '[]';
+
+-- JSON path filters compile to jsonpath predicates matched with @@, which these
+-- indexes answer for existence and equality
+CREATE INDEX IF NOT EXISTS idx_audit_logs_metadata ON audit_logs USING GIN (metadata jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_before_state ON audit_logs USING GIN (before_state jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_after_state ON audit_logs USING GIN (after_state jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_after_state;
+DROP INDEX IF EXISTS idx_audit_logs_before_state;
+DROP INDEX IF EXISTS idx_audit_logs_metadata;
+ALTER TABLE tenants DROP COLUMN IF EXISTS indexed_paths;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- The paths that differ between before_state and after_state, logs can be
+-- filtered by the fields they changed. Logs stored before are left without,
+-- they never match such a filter.
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS changed_fields JSONB;
+
+-- Filters on a changed field compile to containment (@>)
+CREATE INDEX IF NOT EXISTS idx_audit_logs_changed_fields ON audit_logs USING GIN (changed_fields jsonb_path_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_changed_fields;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS changed_fields;
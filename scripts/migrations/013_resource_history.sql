@@ -0,0 +1,6 @@
+-- +migrate Up
+-- The history of a resource walks all of its logs, newest first
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(tenant_id, resource_type, resource_id, timestamp DESC) WHERE resource_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_resource;
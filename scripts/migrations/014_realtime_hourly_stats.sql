@@ -0,0 +1,7 @@
+-- +migrate Up
+-- Time series read the latest hours from the hourly stats before the policy
+-- materializes them, the hours not materialized yet are counted from audit_logs
+ALTER MATERIALIZED VIEW audit_logs_hourly_stats SET (timescaledb.materialized_only = false);
+
+-- +migrate Down
+ALTER MATERIALIZED VIEW audit_logs_hourly_stats SET (timescaledb.materialized_only = true);